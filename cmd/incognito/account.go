@@ -0,0 +1,373 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func init() {
+	registerCommand(&command{name: "keyinfo", usage: "print the keys of a private key", run: keyInfo})
+	registerCommand(&command{name: "mnemonic", usage: "generate a new BIP39 mnemonic", run: newMnemonic})
+	registerCommand(&command{name: "derive", usage: "derive child accounts from a mnemonic", run: deriveAccounts})
+	registerCommand(&command{name: "keystore", usage: "manage the keystore (list, import, export, delete)", run: keyStore})
+	registerCommand(&command{name: "balance", usage: "print the balance of an account", run: balance})
+	registerCommand(&command{name: "utxo", usage: "list the unspent output coins of an account", run: listUTXOs})
+	registerCommand(&command{name: "auditexport", usage: "export a read-only audit package of one or more accounts (it reveals their full history)", run: auditExport})
+	registerCommand(&command{name: "audit", usage: "audit an audit package, and sign the report (spends rely on the owner-supplied key images)", run: audit})
+	registerCommand(&command{name: "verifyaudit", usage: "verify the signature of an audit report", run: verifyAudit})
+}
+
+func keyInfo(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("keyinfo", true)
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	res, err := incclient.GetAccountInfoFromPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(res)
+}
+
+func newMnemonic(ctx *cliContext, args []string) error {
+	fs, _ := newFlagSet("mnemonic", false)
+	bitSize := fs.Int("bitSize", 128, "the entropy size in bits (128 to 256, a multiple of 32)")
+	_ = fs.Parse(args)
+
+	res, err := wallet.NewMnemonic(*bitSize)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(res)
+}
+
+func deriveAccounts(ctx *cliContext, args []string) error {
+	fs, _ := newFlagSet("derive", false)
+	mnemonicFile := fs.String("mnemonicFile", "", fmt.Sprintf("a file holding the mnemonic, - for the standard input (defaults to %v, or the mnemonic of the keystore account)", mnemonicEnv))
+	account := fs.Uint("accountIndex", 0, "the BIP44 account index")
+	from := fs.Uint("from", 1, "the first child index")
+	num := fs.Int("num", 1, "the number of children")
+	shardID := fs.Int("shardID", -1, "only derive children belonging to this shard")
+	_ = fs.Parse(args)
+
+	mnemonic, err := readSecret(*mnemonicFile, mnemonicEnv)
+	if err != nil {
+		return err
+	}
+	if mnemonic == "" {
+		if ctx.Account == "" {
+			return fmt.Errorf("no mnemonic provided (use the -mnemonicFile flag, the %v environment variable, or the -keystore and -account flags)", mnemonicEnv)
+		}
+		ks, err := ctx.getKeyStore()
+		if err != nil {
+			return err
+		}
+		password, err := ctx.getPassword()
+		if err != nil {
+			return err
+		}
+		mnemonic, err = ks.ExportMnemonic(ctx.Account, password)
+		if err != nil {
+			return err
+		}
+	}
+
+	master, err := wallet.NewMasterKeyFromMnemonic(mnemonic)
+	if err != nil {
+		return err
+	}
+
+	children := make([]*wallet.KeyWallet, 0)
+	getAccountInfo := incclient.GetAccountInfoFromPrivateKey
+	if *shardID >= 0 {
+		client, err := ctx.getClient()
+		if err != nil {
+			return err
+		}
+		getAccountInfo = client.GetAccountInfoFromPrivateKey
+		shardChildren, err := master.DeriveAccountChildrenForShard(client.GetNetworkParams(),
+			uint32(*account), byte(*shardID), uint32(*from), *num)
+		if err != nil {
+			return err
+		}
+		for _, child := range shardChildren {
+			children = append(children, child.KeyWallet)
+		}
+	} else {
+		for i := 0; i < *num; i++ {
+			child, err := master.DeriveAccountChild(uint32(*account), uint32(*from)+uint32(i))
+			if err != nil {
+				return err
+			}
+			children = append(children, child)
+		}
+	}
+
+	res := make([]*incclient.KeyInfo, 0)
+	for _, child := range children {
+		info, err := getAccountInfo(child.Base58CheckSerialize(wallet.PrivateKeyType))
+		if err != nil {
+			return err
+		}
+		res = append(res, info)
+	}
+
+	return ctx.output(res)
+}
+
+func keyStore(ctx *cliContext, args []string) error {
+	fs, _ := newFlagSet("keystore", false)
+	action := fs.String("action", "list", "the action to perform (list, import, export, delete)")
+	name := fs.String("name", "", "the name of the account to import")
+	privateKeyFile := fs.String("privateKeyFile", "", fmt.Sprintf("a file holding the private key to import, - for the standard input (or set %v)", privateKeyEnv))
+	mnemonicFile := fs.String("mnemonicFile", "", fmt.Sprintf("a file holding the mnemonic to import, - for the standard input (or set %v)", mnemonicEnv))
+	_ = fs.Parse(args)
+
+	ks, err := ctx.getKeyStore()
+	if err != nil {
+		return err
+	}
+
+	switch *action {
+	case "list":
+		entries, err := ks.List()
+		if err != nil {
+			return err
+		}
+		res := make([]map[string]string, 0)
+		for _, entry := range entries {
+			res = append(res, map[string]string{
+				"ID":             entry.ID,
+				"Name":           entry.Name,
+				"Type":           entry.Type,
+				"PaymentAddress": entry.PaymentAddress,
+			})
+		}
+		return ctx.output(res)
+	case "import":
+		password, err := ctx.getPassword()
+		if err != nil {
+			return err
+		}
+		privateKey, err := readSecret(*privateKeyFile, privateKeyEnv)
+		if err != nil {
+			return err
+		}
+		mnemonic, err := readSecret(*mnemonicFile, mnemonicEnv)
+		if err != nil {
+			return err
+		}
+		var entry *wallet.KeyStoreEntry
+		// the flags take precedence over the environment variables
+		switch {
+		case *privateKeyFile != "" || (*mnemonicFile == "" && privateKey != ""):
+			entry, err = ks.ImportPrivateKey(privateKey, *name, password)
+		case mnemonic != "":
+			entry, err = ks.ImportMnemonic(mnemonic, *name, password)
+		default:
+			return fmt.Errorf("either -privateKeyFile or -mnemonicFile (or the %v or %v environment variable) is required",
+				privateKeyEnv, mnemonicEnv)
+		}
+		if err != nil {
+			return err
+		}
+		return ctx.output(map[string]string{"ID": entry.ID, "Name": entry.Name, "PaymentAddress": entry.PaymentAddress})
+	case "export":
+		if ctx.Account == "" {
+			return fmt.Errorf("no account provided (use the -account flag)")
+		}
+		password, err := ctx.getPassword()
+		if err != nil {
+			return err
+		}
+		res, err := ks.ExportPrivateKey(ctx.Account, password)
+		if err != nil {
+			return err
+		}
+		return ctx.output(res)
+	case "delete":
+		if ctx.Account == "" {
+			return fmt.Errorf("no account provided (use the -account flag)")
+		}
+		password, err := ctx.getPassword()
+		if err != nil {
+			return err
+		}
+		return ks.Delete(ctx.Account, password)
+	default:
+		return fmt.Errorf("action %v not supported", *action)
+	}
+}
+
+func balance(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("balance", true)
+	tokenID := fs.String("tokenID", "", "the tokenID (defaults to all tokens)")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	if *tokenID == "" {
+		res, err := client.GetAllBalancesV2(key)
+		if err != nil {
+			return err
+		}
+		return ctx.output(res)
+	}
+	res, err := client.GetBalance(key, *tokenID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(map[string]uint64{*tokenID: res})
+}
+
+// utxoInfo is the output of the utxo command.
+type utxoInfo struct {
+	Index   uint64
+	Version uint8
+	Amount  uint64
+	Memo    string `json:",omitempty"`
+}
+
+func listUTXOs(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("utxo", true)
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the tokenID")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	coins, indices, memos, err := client.GetUnspentOutputCoinsWithMemos(key, *tokenID, 0)
+	if err != nil {
+		return err
+	}
+	res := make([]utxoInfo, 0)
+	for i, c := range coins {
+		info := utxoInfo{Version: c.GetVersion(), Amount: c.GetValue(), Memo: memos[i]}
+		if i < len(indices) && indices[i] != nil {
+			info.Index = indices[i].Uint64()
+		}
+		res = append(res, info)
+	}
+
+	return ctx.output(res)
+}
+
+func auditExport(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("auditexport", true)
+	tokenIDs := fs.String("tokenIDs", "", "a comma-separated list of tokenIDs to report (defaults to all tokens); the package keys still reveal all tokens")
+	fromHeight := fs.Uint64("fromHeight", 0, "only report coins received from this shard height; the package keys still reveal the full history")
+	toHeight := fs.Uint64("toHeight", 0, "only report coins received up to this shard height (0 for no limit)")
+	out := fs.String("out", "", "the output file of the audit package")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"out": *out}); err != nil {
+		return err
+	}
+
+	// the private key file may hold several keys, one per line
+	keys, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	keyList := strings.Fields(keys)
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	p, err := client.NewAuditPackage(keyList, splitList(*tokenIDs), *fromHeight, *toHeight)
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(*out, []byte(p.String()), 0600); err != nil {
+		return err
+	}
+
+	return ctx.output(fmt.Sprintf("saved the audit package of %v account(s) to %v", len(p.Accounts), *out))
+}
+
+func audit(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("audit", true)
+	packageFile := fs.String("package", "", "the audit package file")
+	out := fs.String("out", "", "the output file of the report (defaults to the standard output)")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"package": *packageFile}); err != nil {
+		return err
+	}
+
+	raw, err := ioutil.ReadFile(*packageFile)
+	if err != nil {
+		return err
+	}
+	p, err := incclient.ParseAuditPackage(raw)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	report, err := client.NewAuditReport(p, key)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return ctx.output(report)
+	}
+	if err = ioutil.WriteFile(*out, []byte(report.String()), 0600); err != nil {
+		return err
+	}
+
+	return ctx.output(fmt.Sprintf("saved the audit report to %v", *out))
+}
+
+func verifyAudit(ctx *cliContext, args []string) error {
+	fs, _ := newFlagSet("verifyaudit", false)
+	reportFile := fs.String("report", "", "the audit report file")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"report": *reportFile}); err != nil {
+		return err
+	}
+
+	raw, err := ioutil.ReadFile(*reportFile)
+	if err != nil {
+		return err
+	}
+	report, err := incclient.ParseAuditReport(raw)
+	if err != nil {
+		return err
+	}
+	if err = report.Verify(); err != nil {
+		return err
+	}
+
+	return ctx.output(fmt.Sprintf("the report is signed by %v", report.AuditorPublicKey))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	registerCommand(&command{name: "shield", usage: "shield a token from an EVM network", run: shield})
+	registerCommand(&command{name: "unshield", usage: "unshield a token to an EVM network", run: unshield})
+}
+
+func shield(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("shield", true)
+	evmTxHash := fs.String("evmTxHash", "", "the hash of the EVM deposit transaction")
+	tokenID := fs.String("tokenID", "", "the Incognito tokenID of the shielded token")
+	evm := fs.String("evm", "eth", "the EVM network (eth, bsc, plg, ftm, or the name or ID of a network of the config file)")
+	_ = fs.Parse(args)
+
+	if err := requireFlags(map[string]string{"evmTxHash": *evmTxHash, "tokenID": *tokenID}); err != nil {
+		return err
+	}
+	evmNetworkID, err := ctx.parseEVMNetwork(*evm)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	proof, _, err := client.GetEVMDepositProof(*evmTxHash, evmNetworkID)
+	if err != nil {
+		return fmt.Errorf("cannot get the deposit proof: %v", err)
+	}
+	txHash, err := client.CreateAndSendIssuingEVMRequestTransaction(key, *tokenID, *proof, evmNetworkID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func unshield(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("unshield", true)
+	tokenID := fs.String("tokenID", "", "the Incognito tokenID to unshield")
+	remoteAddress := fs.String("remoteAddress", "", "the receiving address on the EVM network")
+	amount := fs.Uint64("amount", 0, "the amount to unshield")
+	evm := fs.String("evm", "eth", "the EVM network (eth, bsc, plg, ftm, or the name or ID of a network of the config file)")
+	_ = fs.Parse(args)
+
+	if err := requireFlags(map[string]string{"tokenID": *tokenID, "remoteAddress": *remoteAddress}); err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	evmNetworkID, err := ctx.parseEVMNetwork(*evm)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendBurningRequestTransaction(key, *remoteAddress, *tokenID, *amount, evmNetworkID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
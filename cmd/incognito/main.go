@@ -0,0 +1,301 @@
+// Command incognito is a command-line tool wrapping the IncClient of the Incognito SDK.
+//
+// Usage:
+//
+//	incognito [global flags] <command> [command flags]
+//
+// Run `incognito help` to list all commands, and `incognito <command> -h` to list the flags of a command.
+//
+// The network must be selected with the global -network flag (or the config file); there is no default so that
+// mainnet is never used by accident.
+//
+// Secrets are never passed on the command line, where they would be visible to other users and kept in the shell
+// history. Commands which require a private key read it from the file given by their -privateKeyFile flag ("-" for the
+// standard input), or load it from a keystore (see wallet.KeyStore) with the global flags -keystore and -account, or
+// read it from the INCOGNITO_PRIVATE_KEY environment variable. The password of the keystore account is read from the
+// file given by the global -passwordFile flag, or from the INCOGNITO_PASSWORD environment variable.
+//
+// Besides the built-in EVM networks (eth, bsc, plg, ftm), the config file can list extra EVM networks, which are
+// registered on the client (see incclient.RegisterEVMNetwork) and can be selected by their name or ID:
+//
+//	{"network": "testnet", "evmNetworks": [{"id": <evmNetworkID>, "name": "avax", "host": "<EVM RPC endpoint>",
+//		"vaultAddress": "<vault address>", "issuingMetadataType": <type>, "burningMetadataType": <type>,
+//		"burnProofRPCMethod": "<RPC method>"}]}
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+const (
+	// passwordEnv is the environment variable holding the password of the keystore account.
+	passwordEnv = "INCOGNITO_PASSWORD"
+
+	// privateKeyEnv is the environment variable holding the private key, used when neither -privateKeyFile nor
+	// -account is set.
+	privateKeyEnv = "INCOGNITO_PRIVATE_KEY"
+
+	// mnemonicEnv is the environment variable holding the mnemonic, used when -mnemonicFile is not set.
+	mnemonicEnv = "INCOGNITO_MNEMONIC"
+)
+
+// command is a sub-command of the CLI.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx *cliContext, args []string) error
+}
+
+var commands = map[string]*command{}
+
+func registerCommand(cmd *command) {
+	commands[cmd.name] = cmd
+}
+
+// cliConfig holds the global settings of the CLI. It can be loaded from a JSON file with the -config flag; flags
+// explicitly set on the command line take precedence over the file.
+type cliConfig struct {
+	Network     string             `json:"network"`
+	Host        string             `json:"host"`
+	KeyStore    string             `json:"keystore"`
+	Account     string             `json:"account"`
+	EVMNetworks []evmNetworkConfig `json:"evmNetworks"`
+}
+
+// evmNetworkConfig is an extra EVM network to be registered on the client (see incclient.RegisterEVMNetwork).
+type evmNetworkConfig struct {
+	ID int `json:"id"`
+	incclient.EVMNetworkParams
+}
+
+// cliContext holds the global flags and the lazily-created client.
+type cliContext struct {
+	cliConfig
+	passwordFile string
+	jsonOutput   bool
+
+	client *incclient.IncClient
+}
+
+// loadConfig reads a config file, and fills the settings which have not been set by a flag.
+func (ctx *cliContext) loadConfig(path string, setFlags map[string]bool) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var cfg cliConfig
+	err = json.Unmarshal(raw, &cfg)
+	if err != nil {
+		return fmt.Errorf("cannot parse config %v: %v", path, err)
+	}
+
+	if !setFlags["network"] && cfg.Network != "" {
+		ctx.Network = cfg.Network
+	}
+	if !setFlags["host"] && cfg.Host != "" {
+		ctx.Host = cfg.Host
+	}
+	if !setFlags["keystore"] && cfg.KeyStore != "" {
+		ctx.KeyStore = cfg.KeyStore
+	}
+	if !setFlags["account"] && cfg.Account != "" {
+		ctx.Account = cfg.Account
+	}
+	ctx.EVMNetworks = cfg.EVMNetworks
+
+	return nil
+}
+
+// getClient returns the IncClient for the selected network, creating it on first use.
+func (ctx *cliContext) getClient() (*incclient.IncClient, error) {
+	if ctx.client != nil {
+		return ctx.client, nil
+	}
+
+	var client *incclient.IncClient
+	var err error
+	switch strings.ToLower(ctx.Network) {
+	case "mainnet":
+		if ctx.Host != "" {
+			client, err = incclient.NewIncClient(ctx.Host, incclient.MainNetETHHost, incclient.MainNetPrivacyVersion, "mainnet")
+		} else {
+			client, err = incclient.NewMainNetClient()
+		}
+	case "testnet":
+		if ctx.Host != "" {
+			client, err = incclient.NewIncClient(ctx.Host, incclient.TestNetETHHost, incclient.TestNetPrivacyVersion, "testnet")
+		} else {
+			client, err = incclient.NewTestNetClient()
+		}
+	case "testnet1":
+		if ctx.Host != "" {
+			client, err = incclient.NewIncClient(ctx.Host, incclient.TestNet1ETHHost, incclient.TestNet1PrivacyVersion, "testnet1")
+		} else {
+			client, err = incclient.NewTestNet1Client()
+		}
+	case "local":
+		host := ctx.Host
+		if host == "" {
+			host = incclient.LocalFullNode
+		}
+		client, err = incclient.NewIncClient(host, incclient.LocalETHHost, incclient.LocalPrivacyVersion, "local")
+	case "":
+		return nil, fmt.Errorf("no network provided (use the -network flag or the config file)")
+	default:
+		return nil, fmt.Errorf("network %v not supported", ctx.Network)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range ctx.EVMNetworks {
+		err = client.RegisterEVMNetwork(network.ID, network.EVMNetworkParams)
+		if err != nil {
+			return nil, fmt.Errorf("cannot register EVM network %v: %v", network.ID, err)
+		}
+	}
+	ctx.client = client
+
+	return client, nil
+}
+
+// getKeyStore opens the keystore given by the -keystore flag.
+func (ctx *cliContext) getKeyStore() (*wallet.KeyStore, error) {
+	if ctx.KeyStore == "" {
+		return nil, fmt.Errorf("no keystore provided (use the -keystore flag)")
+	}
+
+	return wallet.NewKeyStore(ctx.KeyStore, wallet.StandardScryptN, wallet.StandardScryptP)
+}
+
+// getPassword returns the password of the keystore account.
+func (ctx *cliContext) getPassword() (string, error) {
+	password, err := readSecret(ctx.passwordFile, passwordEnv)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("no password provided (use the -passwordFile flag or the %v environment variable)", passwordEnv)
+	}
+
+	return password, nil
+}
+
+// hasPrivateKey checks if getPrivateKey can find a private key without the -privateKeyFile flag.
+func (ctx *cliContext) hasPrivateKey() bool {
+	return ctx.Account != "" || os.Getenv(privateKeyEnv) != ""
+}
+
+// getPrivateKey returns the private key read from privateKeyFile if not empty. Otherwise, it loads the private key of
+// the keystore account, or falls back to the privateKeyEnv environment variable.
+func (ctx *cliContext) getPrivateKey(privateKeyFile string) (string, error) {
+	if privateKeyFile != "" || ctx.Account == "" {
+		privateKey, err := readSecret(privateKeyFile, privateKeyEnv)
+		if err != nil {
+			return "", err
+		}
+		if privateKey == "" {
+			return "", fmt.Errorf("no private key provided (use the -privateKeyFile flag, the -keystore and -account flags, or the %v environment variable)", privateKeyEnv)
+		}
+		return privateKey, nil
+	}
+
+	ks, err := ctx.getKeyStore()
+	if err != nil {
+		return "", err
+	}
+	password, err := ctx.getPassword()
+	if err != nil {
+		return "", err
+	}
+	w, err := ks.GetKeyWallet(ctx.Account, password)
+	if err != nil {
+		return "", err
+	}
+
+	return w.Base58CheckSerialize(wallet.PrivateKeyType), nil
+}
+
+// output prints a result, either as indented JSON or with its human-readable form.
+func (ctx *cliContext) output(res interface{}) error {
+	if !ctx.jsonOutput {
+		switch v := res.(type) {
+		case fmt.Stringer:
+			fmt.Println(strings.TrimRight(v.String(), "\n"))
+			return nil
+		case string:
+			fmt.Println(v)
+			return nil
+		}
+	}
+
+	jsb, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsb))
+
+	return nil
+}
+
+func printUsage(fs *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: incognito [global flags] <command> [command flags]\n\nGlobal flags:\n")
+	fs.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	names := make([]string, 0)
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16v %v\n", name, commands[name].usage)
+	}
+}
+
+func main() {
+	ctx := &cliContext{}
+	var configPath string
+	fs := flag.NewFlagSet("incognito", flag.ExitOnError)
+	fs.StringVar(&configPath, "config", "", "a JSON config file holding the network, host, keystore, account and extra EVM networks")
+	fs.StringVar(&ctx.Network, "network", "", "the network to interact with (mainnet, testnet, testnet1, local); required")
+	fs.StringVar(&ctx.Host, "host", "", "a custom full-node endpoint (defaults to the one of the network)")
+	fs.StringVar(&ctx.KeyStore, "keystore", "", "the keystore directory")
+	fs.StringVar(&ctx.Account, "account", "", "the name (or ID) of the keystore account used when -privateKeyFile is not set")
+	fs.StringVar(&ctx.passwordFile, "passwordFile", "", fmt.Sprintf("a file holding the password of the keystore account, - for the standard input (or set %v)", passwordEnv))
+	fs.BoolVar(&ctx.jsonOutput, "json", false, "print results as JSON")
+	fs.Usage = func() { printUsage(fs) }
+	_ = fs.Parse(os.Args[1:])
+
+	if configPath != "" {
+		setFlags := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+		if err := ctx.loadConfig(configPath, setFlags); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		printUsage(fs)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %v\n\n", fs.Arg(0))
+		printUsage(fs)
+		os.Exit(2)
+	}
+	if err := cmd.run(ctx, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+func TestParseReceivers(t *testing.T) {
+	addrList, amountList, err := parseReceivers("addr1, addr2,", "10,20")
+	if err != nil {
+		panic(err)
+	}
+	if len(addrList) != 2 || addrList[1] != "addr2" || amountList[0] != 10 || amountList[1] != 20 {
+		panic("unexpected receivers")
+	}
+
+	for _, tc := range [][2]string{{"", ""}, {"addr1,addr2", "10"}, {"addr1", "abc"}, {"addr1", "0"}} {
+		_, _, err = parseReceivers(tc[0], tc[1])
+		if err == nil {
+			panic("expected an error")
+		}
+	}
+}
+
+func TestRequireFlags(t *testing.T) {
+	err := requireFlags(map[string]string{"a": "x", "b": "y"})
+	if err != nil {
+		panic(err)
+	}
+
+	err = requireFlags(map[string]string{"b": "", "a": "", "c": "z"})
+	if err == nil || err.Error() != "missing required flags: -a, -b" {
+		panic(err)
+	}
+}
+
+func TestCLIContext_LoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "incognito-cli")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(path, []byte(`{"network": "testnet", "host": "http://localhost:9334", "account": "alice",
+		"evmNetworks": [{"id": 1000, "name": "avax", "host": "http://localhost:8545", "issuingMetadataType": 1001}]}`), 0600)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := &cliContext{cliConfig: cliConfig{Network: "mainnet", Account: "bob"}}
+	err = ctx.loadConfig(path, map[string]bool{"account": true})
+	if err != nil {
+		panic(err)
+	}
+	if ctx.Network != "testnet" || ctx.Host != "http://localhost:9334" || ctx.Account != "bob" {
+		panic("flags set on the command line must take precedence over the config file")
+	}
+	if len(ctx.EVMNetworks) != 1 || ctx.EVMNetworks[0].ID != 1000 || ctx.EVMNetworks[0].Name != "avax" ||
+		ctx.EVMNetworks[0].Host != "http://localhost:8545" || ctx.EVMNetworks[0].IssuingMetadataType != 1001 {
+		panic(fmt.Sprintf("unexpected EVM networks %v", ctx.EVMNetworks))
+	}
+}
+
+func TestParseMemos(t *testing.T) {
+	var memos listFlag
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&memos, "memo", "")
+	err := fs.Parse([]string{"-memo", "invoice 1, item 2", "-memo", ""})
+	if err != nil {
+		panic(err)
+	}
+
+	memoList, err := parseMemos(memos, 2)
+	if err != nil {
+		panic(err)
+	}
+	if len(memoList) != 2 || memoList[0] != "invoice 1, item 2" || memoList[1] != "" {
+		panic(fmt.Sprintf("unexpected memos %v", memoList))
+	}
+
+	_, err = parseMemos(memos, 3)
+	if err == nil {
+		panic("expected an error")
+	}
+	memoList, err = parseMemos(nil, 3)
+	if err != nil || memoList != nil {
+		panic("expected no memo")
+	}
+}
+
+func TestCLIContext_ParseEVMNetwork(t *testing.T) {
+	ctx := &cliContext{cliConfig: cliConfig{EVMNetworks: []evmNetworkConfig{{
+		ID: 1000,
+		EVMNetworkParams: incclient.EVMNetworkParams{
+			EVMNetworkInfo: rpc.EVMNetworkInfo{Name: "Avax"},
+		},
+	}}}}
+	for name, expected := range map[string]int{
+		"eth":                          rpc.ETHNetworkID,
+		"BSC":                          rpc.BSCNetworkID,
+		"avax":                         1000,
+		"1000":                         1000,
+		strconv.Itoa(rpc.PLGNetworkID): rpc.PLGNetworkID,
+	} {
+		id, err := ctx.parseEVMNetwork(name)
+		if err != nil {
+			panic(err)
+		}
+		if id != expected {
+			panic(fmt.Sprintf("%v: expected %v, got %v", name, expected, id))
+		}
+	}
+
+	for _, name := range []string{"aurora", "1001"} {
+		if _, err := ctx.parseEVMNetwork(name); err == nil {
+			panic(fmt.Sprintf("%v: expected an error", name))
+		}
+	}
+}
+
+func TestCLIContext_GetPrivateKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "incognito-cli")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(privateKeyEnv)
+
+	path := filepath.Join(dir, "key")
+	err = ioutil.WriteFile(path, []byte("fileKey\n"), 0600)
+	if err != nil {
+		panic(err)
+	}
+	err = os.Setenv(privateKeyEnv, "envKey")
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := &cliContext{}
+	key, err := ctx.getPrivateKey(path)
+	if err != nil || key != "fileKey" {
+		panic(fmt.Sprintf("expected the key of the file, got %v (%v)", key, err))
+	}
+	key, err = ctx.getPrivateKey("")
+	if err != nil || key != "envKey" {
+		panic(fmt.Sprintf("expected the key of the environment, got %v (%v)", key, err))
+	}
+
+	_ = os.Unsetenv(privateKeyEnv)
+	if ctx.hasPrivateKey() {
+		panic("expected no private key")
+	}
+	if _, err = ctx.getPrivateKey(""); err == nil {
+		panic("expected an error")
+	}
+	if _, err = ctx.getPrivateKey(filepath.Join(dir, "missing")); err == nil {
+		panic("expected an error")
+	}
+}
+
+func TestCLIContext_GetClient(t *testing.T) {
+	ctx := &cliContext{}
+	_, err := ctx.getClient()
+	if err == nil || !strings.Contains(err.Error(), "no network provided") {
+		panic(fmt.Sprintf("expected a missing network error, got %v", err))
+	}
+}
+
+func TestParseDecimals(t *testing.T) {
+	decimals, err := parseDecimals("token1:6, token2:18,")
+	if err != nil {
+		panic(err)
+	}
+	if len(decimals) != 2 || decimals["token1"] != 6 || decimals["token2"] != 18 {
+		panic(fmt.Sprintf("unexpected decimals %v", decimals))
+	}
+
+	for _, s := range []string{"token1", "token1:abc", "token1:256"} {
+		if _, err = parseDecimals(s); err == nil {
+			panic(fmt.Sprintf("%v: expected an error", s))
+		}
+	}
+}
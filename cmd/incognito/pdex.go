@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+func init() {
+	registerCommand(&command{name: "pdetrade", usage: "trade on the pDEX", run: pdexTrade})
+	registerCommand(&command{name: "pdeaddorder", usage: "add an order book on the pDEX", run: pdexAddOrder})
+	registerCommand(&command{name: "pdecontribute", usage: "contribute liquidity to a pDEX pool", run: pdexContribute})
+	registerCommand(&command{name: "pdestake", usage: "stake (or un-stake) a token in the pDEX", run: pdexStake})
+}
+
+func pdexTrade(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pdetrade", true)
+	tradePath := fs.String("tradingPath", "", "a comma-separated list of poolIDs")
+	sellToken := fs.String("sellTokenID", "", "the tokenID to sell")
+	buyToken := fs.String("buyTokenID", "", "the tokenID to buy")
+	amount := fs.Uint64("amount", 0, "the amount to sell")
+	expectedBuy := fs.Uint64("minAcceptableAmount", 0, "the minimum acceptable amount to buy")
+	tradingFee := fs.Uint64("tradingFee", 0, "the trading fee")
+	feeInPRV := fs.Bool("feeInPRV", false, "pay the trading fee in PRV")
+	_ = fs.Parse(args)
+
+	err := requireFlags(map[string]string{"tradingPath": *tradePath, "sellTokenID": *sellToken, "buyTokenID": *buyToken})
+	if err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendPdexv3TradeTransaction(key, splitList(*tradePath), *sellToken, *buyToken,
+		*amount, *expectedBuy, *tradingFee, *feeInPRV)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func pdexAddOrder(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pdeaddorder", true)
+	pairID := fs.String("pairID", "", "the poolID of the order")
+	sellToken := fs.String("sellTokenID", "", "the tokenID to sell")
+	buyToken := fs.String("buyTokenID", "", "the tokenID to buy")
+	nftID := fs.String("nftID", "", "the NFT identifying the order")
+	amount := fs.Uint64("sellAmount", 0, "the amount to sell")
+	expectedBuy := fs.Uint64("minAcceptableAmount", 0, "the minimum acceptable amount to buy")
+	_ = fs.Parse(args)
+
+	err := requireFlags(map[string]string{
+		"pairID": *pairID, "sellTokenID": *sellToken, "buyTokenID": *buyToken, "nftID": *nftID,
+	})
+	if err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("sellAmount must be greater than 0")
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendPdexv3AddOrderTransaction(key, *pairID, *sellToken, *buyToken, *nftID,
+		*amount, *expectedBuy)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func pdexContribute(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pdecontribute", true)
+	pairID := fs.String("pairID", "", "the poolID to contribute to (empty for a new pool)")
+	pairHash := fs.String("pairHash", "", "the hash identifying the two contributions of the pair")
+	tokenID := fs.String("tokenID", "", "the tokenID to contribute")
+	nftID := fs.String("nftID", "", "the NFT identifying the contributor")
+	amount := fs.Uint64("amount", 0, "the amount to contribute")
+	amplifier := fs.Uint64("amplifier", 10000, "the amplifier of the pool")
+	_ = fs.Parse(args)
+
+	err := requireFlags(map[string]string{"pairHash": *pairHash, "tokenID": *tokenID, "nftID": *nftID})
+	if err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendPdexv3ContributeTransaction(key, *pairID, *pairHash, *tokenID, *nftID,
+		*amount, *amplifier)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func pdexStake(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pdestake", true)
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the tokenID to stake")
+	nftID := fs.String("nftID", "", "the NFT identifying the staker")
+	amount := fs.Uint64("amount", 0, "the amount to stake (or un-stake)")
+	unstake := fs.Bool("unstake", false, "un-stake instead of staking")
+	_ = fs.Parse(args)
+
+	if err := requireFlags(map[string]string{"nftID": *nftID}); err != nil {
+		return err
+	}
+	if *amount == 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	var txHash string
+	if *unstake {
+		txHash, err = client.CreateAndSendPdexv3UnstakingTransaction(key, *tokenID, *nftID, *amount)
+	} else {
+		txHash, err = client.CreateAndSendPdexv3StakingTransaction(key, *tokenID, *nftID, *amount)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+)
+
+func init() {
+	registerCommand(&command{name: "stake", usage: "stake a shard validator", run: stake})
+	registerCommand(&command{name: "unstake", usage: "un-stake a shard validator", run: unstake})
+	registerCommand(&command{name: "reward", usage: "print the staking rewards of an address", run: reward})
+	registerCommand(&command{name: "withdrawreward", usage: "withdraw the staking rewards of an account", run: withdrawReward})
+}
+
+func stake(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("stake", true)
+	miningKey := fs.String("miningKey", "", "the mining key of the validator (defaults to the one of the private key)")
+	candidateAddr := fs.String("candidateAddress", "", "the payment address of the candidate (defaults to the account)")
+	rewardAddr := fs.String("rewardAddress", "", "the address receiving the rewards (defaults to the account)")
+	autoStake := fs.Bool("autoReStake", true, "re-stake automatically at the end of each term")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	if *miningKey == "" {
+		*miningKey = incclient.PrivateKeyToMiningKey(key)
+	}
+	if *candidateAddr == "" {
+		*candidateAddr = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	if *rewardAddr == "" {
+		*rewardAddr = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendShardStakingTransaction(key, *miningKey, *candidateAddr, *rewardAddr, *autoStake)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func unstake(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("unstake", true)
+	miningKey := fs.String("miningKey", "", "the mining key of the validator (defaults to the one of the private key)")
+	candidateAddr := fs.String("candidateAddress", "", "the payment address of the candidate (defaults to the account)")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	if *miningKey == "" {
+		*miningKey = incclient.PrivateKeyToMiningKey(key)
+	}
+	if *candidateAddr == "" {
+		*candidateAddr = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendUnStakingTransaction(key, *miningKey, *candidateAddr)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func reward(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("reward", true)
+	address := fs.String("address", "", "the payment address (defaults to the one of the account)")
+	_ = fs.Parse(args)
+
+	if *address == "" {
+		key, err := ctx.getPrivateKey(*privateKeyFile)
+		if err != nil {
+			return err
+		}
+		*address = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	res, err := client.GetRewardAmount(*address)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(res)
+}
+
+func withdrawReward(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("withdrawreward", true)
+	address := fs.String("address", "", "the reward receiver address (defaults to the one of the account)")
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the tokenID of the reward")
+	version := fs.Int("version", 2, "the transaction version")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	if *address == "" {
+		*address = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHash, err := client.CreateAndSendWithDrawRewardTransaction(key, *address, *tokenID, int8(*version))
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/paymenturi"
+)
+
+func init() {
+	registerCommand(&command{name: "describetx", usage: "decode a transaction into a human-readable summary", run: describeTx})
+	registerCommand(&command{name: "send", usage: "send PRV to one or more addresses", run: send})
+	registerCommand(&command{name: "sendtoken", usage: "send a token to one or more addresses", run: sendToken})
+	registerCommand(&command{name: "verifypayment", usage: "verify a payment proof against its transaction", run: verifyPayment})
+	registerCommand(&command{name: "payrequest", usage: "create an incognito: payment URI", run: payRequest})
+	registerCommand(&command{name: "pay", usage: "pay an incognito: payment URI", run: pay})
+	registerCommand(&command{name: "consolidate", usage: "consolidate the UTXOs of an account", run: consolidate})
+	registerCommand(&command{name: "convert", usage: "convert the UTXOs v1 of an account to UTXOs v2", run: convert})
+	registerCommand(&command{name: "maintainutxos", usage: "keep the UTXOs of an account in shape until interrupted", run: maintainUTXOs})
+	registerCommand(&command{name: "history", usage: "export the transaction history of an account (CSV, JSON-lines or OFX)", run: history})
+	registerCommand(&command{name: "pnl", usage: "compute the realized and unrealized P&L of the pDEX trades of an account", run: pnl})
+}
+
+func describeTx(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("describetx", true)
+	txHash := fs.String("txHash", "", "the hash of the transaction")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"txHash": *txHash}); err != nil {
+		return err
+	}
+
+	// the private key is optional here
+	key := ""
+	if *privateKeyFile != "" || ctx.hasPrivateKey() {
+		var err error
+		key, err = ctx.getPrivateKey(*privateKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+	res, err := client.DescribeTxByHash(*txHash, key)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(res)
+}
+
+func send(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("send", true)
+	addresses := fs.String("addresses", "", "a comma-separated list of receiver addresses")
+	amounts := fs.String("amounts", "", "a comma-separated list of amounts (in nano PRV)")
+	var memos listFlag
+	fs.Var(&memos, "memo", "the memo of a receiver, encrypted to it; repeat it once per receiver, in the order of -addresses (optional)")
+	version := fs.Int("version", 2, "the transaction version")
+	withProofs := fs.Bool("proofs", false, "output a payment proof for each receiver (version 2 only)")
+	_ = fs.Parse(args)
+
+	addrList, amountList, err := parseReceivers(*addresses, *amounts)
+	if err != nil {
+		return err
+	}
+	memoList, err := parseMemos(memos, len(addrList))
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	if *withProofs {
+		if *version != 2 {
+			return fmt.Errorf("payment proofs are only supported by transactions v2")
+		}
+		param := incclient.NewTxParam(key, addrList, amountList, 0, nil, nil, nil).SetMemos(memoList)
+		encodedTx, txHash, proofs, err := client.CreateRawTransactionWithPaymentProofs(param)
+		if err != nil {
+			return err
+		}
+		if err = client.SendRawTx(encodedTx); err != nil {
+			return err
+		}
+		return ctx.output(txProofsResult{TxHash: txHash, PaymentProofs: proofs})
+	}
+
+	txHash, err := client.CreateAndSendRawTransactionWithMemos(key, addrList, amountList, memoList, int8(*version), nil)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func sendToken(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("sendtoken", true)
+	tokenID := fs.String("tokenID", "", "the tokenID")
+	addresses := fs.String("addresses", "", "a comma-separated list of receiver addresses")
+	amounts := fs.String("amounts", "", "a comma-separated list of amounts")
+	var memos listFlag
+	fs.Var(&memos, "memo", "the memo of a receiver, encrypted to it; repeat it once per receiver, in the order of -addresses (optional)")
+	version := fs.Int("version", 2, "the transaction version")
+	withProofs := fs.Bool("proofs", false, "output a payment proof for each receiver (version 2 only)")
+	_ = fs.Parse(args)
+
+	if err := requireFlags(map[string]string{"tokenID": *tokenID}); err != nil {
+		return err
+	}
+	addrList, amountList, err := parseReceivers(*addresses, *amounts)
+	if err != nil {
+		return err
+	}
+	memoList, err := parseMemos(memos, len(addrList))
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	if *withProofs {
+		if *version != 2 {
+			return fmt.Errorf("payment proofs are only supported by transactions v2")
+		}
+		tokenParam := incclient.NewTxTokenParam(*tokenID, 1, addrList, amountList, false, 0, nil).SetMemos(memoList)
+		param := incclient.NewTxParam(key, []string{}, []uint64{}, incclient.DefaultPRVFee, tokenParam, nil, nil)
+		encodedTx, txHash, proofs, err := client.CreateRawTokenTransactionWithPaymentProofs(param)
+		if err != nil {
+			return err
+		}
+		if err = client.SendRawTokenTx(encodedTx); err != nil {
+			return err
+		}
+		return ctx.output(txProofsResult{TxHash: txHash, PaymentProofs: proofs})
+	}
+
+	txHash, err := client.CreateAndSendRawTokenTransactionWithMemos(key, addrList, amountList, memoList, *tokenID, int8(*version), nil)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+// txProofsResult is the output of the send commands when payment proofs are requested.
+type txProofsResult struct {
+	TxHash        string
+	PaymentProofs []*incclient.PaymentProof
+}
+
+func verifyPayment(ctx *cliContext, args []string) error {
+	fs, _ := newFlagSet("verifypayment", false)
+	proofStr := fs.String("proof", "", "the JSON-encoded payment proof")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"proof": *proofStr}); err != nil {
+		return err
+	}
+
+	p, err := incclient.ParsePaymentProof([]byte(*proofStr))
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+	if err = client.VerifyPaymentProof(p); err != nil {
+		return fmt.Errorf("invalid payment proof: %v", err)
+	}
+
+	return ctx.output(fmt.Sprintf("%v paid %v of token %v to %v", p.TxHash, p.Amount, p.TokenID, p.PaymentAddress))
+}
+
+func payRequest(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("payrequest", true)
+	address := fs.String("address", "", "the payment address to be paid (defaults to the address of the account)")
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the requested tokenID")
+	amount := fs.String("amount", "", "the requested amount, in human-readable units (optional)")
+	decimals := fs.Int("decimals", paymenturi.PRVDecimals, "the number of decimals of the token")
+	memo := fs.String("memo", "", "a payment reference attached to the payment (optional)")
+	label := fs.String("label", "", "a description of the payment (optional)")
+	_ = fs.Parse(args)
+
+	addr := *address
+	if addr == "" {
+		key, err := ctx.getPrivateKey(*privateKeyFile)
+		if err != nil {
+			return err
+		}
+		addr = incclient.PrivateKeyToPaymentAddress(key, -1)
+	}
+	if *decimals < 0 || *decimals > paymenturi.MaxDecimals {
+		return fmt.Errorf("invalid decimals %v", *decimals)
+	}
+	amountValue := uint64(0)
+	if *amount != "" {
+		var err error
+		amountValue, err = paymenturi.ParseAmount(*amount, uint8(*decimals))
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := paymenturi.NewPaymentRequest(addr, *tokenID, amountValue, uint8(*decimals), *memo)
+	if err != nil {
+		return err
+	}
+	req.Label = *label
+
+	return ctx.output(req.String())
+}
+
+func pay(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pay", true)
+	uri := fs.String("uri", "", "the incognito: payment URI")
+	_ = fs.Parse(args)
+
+	if err := requireFlags(map[string]string{"uri": *uri}); err != nil {
+		return err
+	}
+	req, err := paymenturi.Parse(*uri)
+	if err != nil {
+		return err
+	}
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	txParam, err := req.NewTxParam(key)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	var encodedTx []byte
+	var txHash string
+	if req.GetTokenID() == common.PRVIDStr {
+		encodedTx, txHash, err = client.CreateRawTransaction(txParam, 2)
+		if err == nil {
+			err = client.SendRawTx(encodedTx)
+		}
+	} else {
+		encodedTx, txHash, err = client.CreateRawTokenTransaction(txParam, 2)
+		if err == nil {
+			err = client.SendRawTokenTx(encodedTx)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHash))
+}
+
+func consolidate(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("consolidate", true)
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the tokenID")
+	version := fs.Int("version", 2, "the version of the UTXOs to consolidate")
+	numThreads := fs.Int("numThreads", 4, "the number of threads")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHashes, err := client.Consolidate(key, *tokenID, int8(*version), *numThreads)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHashes...))
+}
+
+func convert(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("convert", true)
+	tokenID := fs.String("tokenID", common.PRVIDStr, "the tokenID")
+	numThreads := fs.Int("numThreads", 4, "the number of threads")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	txHashes, err := client.ConvertAllUTXOs(key, *tokenID, *numThreads)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(newTxResult(txHashes...))
+}
+
+func maintainUTXOs(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("maintainutxos", true)
+	tokenIDs := fs.String("tokenIDs", "", "a comma-separated list of tokenIDs to maintain besides PRV")
+	minUTXOs := fs.Int("min", 0, "the minimum number of UTXOs of each token (0 to disable)")
+	maxUTXOs := fs.Int("max", 10, "the maximum number of UTXOs of each token (0 to disable)")
+	numFeeCoins := fs.Int("feeCoins", 0, "the number of fee-sized PRV UTXOs to keep")
+	convertV1 := fs.Bool("convert", true, "convert UTXOs v1 as soon as they appear")
+	maxFeePerKb := fs.Uint64("maxFeePerKb", 0, "only act when the estimated fee per kb is at most this value (0 to disable)")
+	interval := fs.Duration("interval", time.Minute, "the interval between two rounds")
+	_ = fs.Parse(args)
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	m, err := incclient.NewUTXOMaintainer(client, key, incclient.UTXOPolicy{
+		TokenIDs:    splitList(*tokenIDs),
+		MinUTXOs:    *minUTXOs,
+		MaxUTXOs:    *maxUTXOs,
+		NumFeeCoins: *numFeeCoins,
+		ConvertV1:   *convertV1,
+		MaxFeePerKb: *maxFeePerKb,
+	})
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		close(stop)
+	}()
+
+	m.Run(*interval, stop, func(events []*incclient.UTXOEvent) {
+		for _, event := range events {
+			if event.Type == incclient.UTXOEventIdle {
+				continue
+			}
+			_ = ctx.output(event)
+		}
+	})
+
+	return nil
+}
+
+func history(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("history", true)
+	tokenIDs := fs.String("tokenIDs", "", "a comma-separated list of tokenIDs (defaults to all tokens)")
+	out := fs.String("out", incclient.DefaultTxHistory, "the output file")
+	format := fs.String("format", "", "the output format (csv, jsonl, ofx; defaults to the file extension)")
+	from := fs.String("from", "", "only export transactions from this date (YYYY-MM-DD)")
+	to := fs.String("to", "", "only export transactions before this date (YYYY-MM-DD)")
+	decimals := fs.String("decimals", "", "a comma-separated list of tokenID:decimals, used to express OFX amounts in whole tokens (defaults to 9)")
+	_ = fs.Parse(args)
+
+	decimalsMap, err := parseDecimals(*decimals)
+	if err != nil {
+		return err
+	}
+
+	filter := &incclient.TxHistoryFilter{TokenIDs: splitList(*tokenIDs)}
+	if *from != "" {
+		filter.From, err = time.Parse(dateFormat, *from)
+		if err != nil {
+			return fmt.Errorf("invalid -from date %v: %v", *from, err)
+		}
+	}
+	if *to != "" {
+		filter.To, err = time.Parse(dateFormat, *to)
+		if err != nil {
+			return fmt.Errorf("invalid -to date %v: %v", *to, err)
+		}
+	}
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.ExportTxHistory(key, *out, *format, filter, decimalsMap)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(fmt.Sprintf("saved the transaction history to %v", *out))
+}
+
+func pnl(ctx *cliContext, args []string) error {
+	fs, privateKeyFile := newFlagSet("pnl", true)
+	quoteTokenID := fs.String("quoteTokenID", "", "the token in which values are expressed (e.g. a stable-coin)")
+	method := fs.String("method", incclient.CostBasisFIFO, "the cost-basis method (FIFO, LIFO, AVERAGE)")
+	year := fs.Int("year", 0, "report over a calendar year (overrides -from and -to)")
+	from := fs.String("from", "", "the start date of the report (YYYY-MM-DD)")
+	to := fs.String("to", "", "the end date of the report, exclusive (YYYY-MM-DD)")
+	beaconHeight := fs.Uint64("beaconHeight", 0, "the beacon height at which the remaining positions are valued (0 for the latest)")
+	_ = fs.Parse(args)
+	if err := requireFlags(map[string]string{"quoteTokenID": *quoteTokenID}); err != nil {
+		return err
+	}
+
+	params := incclient.PnLReportParams{
+		QuoteTokenID: *quoteTokenID,
+		Method:       *method,
+		BeaconHeight: *beaconHeight,
+	}
+	var err error
+	if *year != 0 {
+		params.From = time.Date(*year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		params.To = params.From.AddDate(1, 0, 0)
+	} else {
+		if *from != "" {
+			params.From, err = time.Parse(dateFormat, *from)
+			if err != nil {
+				return fmt.Errorf("invalid -from date %v: %v", *from, err)
+			}
+		}
+		if *to != "" {
+			params.To, err = time.Parse(dateFormat, *to)
+			if err != nil {
+				return fmt.Errorf("invalid -to date %v: %v", *to, err)
+			}
+		}
+	}
+
+	key, err := ctx.getPrivateKey(*privateKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := ctx.getClient()
+	if err != nil {
+		return err
+	}
+
+	report, err := client.GetPnLReport(key, params)
+	if err != nil {
+		return err
+	}
+
+	return ctx.output(report)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+// dateFormat is the format of the date flags.
+const dateFormat = "2006-01-02"
+
+// txResult is the output of the commands creating transactions.
+type txResult struct {
+	TxHashes []string
+}
+
+func (r txResult) String() string {
+	return strings.Join(r.TxHashes, "\n")
+}
+
+func newTxResult(txHashes ...string) txResult {
+	return txResult{TxHashes: txHashes}
+}
+
+// splitList splits a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	res := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// parseReceivers parses a comma-separated list of addresses and a comma-separated list of amounts.
+func parseReceivers(addresses, amounts string) ([]string, []uint64, error) {
+	addrList := splitList(addresses)
+	amountStrList := splitList(amounts)
+	if len(addrList) == 0 {
+		return nil, nil, fmt.Errorf("no receiver provided")
+	}
+	if len(addrList) != len(amountStrList) {
+		return nil, nil, fmt.Errorf("got %v receivers but %v amounts", len(addrList), len(amountStrList))
+	}
+
+	amountList := make([]uint64, 0)
+	for _, amountStr := range amountStrList {
+		amount, err := strconv.ParseUint(amountStr, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid amount %v: %v", amountStr, err)
+		}
+		if amount == 0 {
+			return nil, nil, fmt.Errorf("amount must be greater than 0")
+		}
+		amountList = append(amountList, amount)
+	}
+
+	return addrList, amountList, nil
+}
+
+// parseDecimals parses a comma-separated list of tokenID:decimals.
+func parseDecimals(s string) (map[string]uint8, error) {
+	res := make(map[string]uint8)
+	for _, item := range splitList(s) {
+		i := strings.LastIndex(item, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid decimals %v: expected tokenID:decimals", item)
+		}
+		decimals, err := strconv.ParseUint(item[i+1:], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimals %v: %v", item, err)
+		}
+		res[strings.TrimSpace(item[:i])] = uint8(decimals)
+	}
+
+	return res, nil
+}
+
+// listFlag is a string flag which can be repeated, e.g. -memo "first, memo" -memo "second memo".
+type listFlag []string
+
+func (l *listFlag) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseMemos checks the memos given by a repeated flag, one per receiver. Memos are kept as is, so they can contain
+// commas, and an empty memo leaves its receiver without a memo.
+func parseMemos(memos []string, numReceivers int) ([]string, error) {
+	if len(memos) == 0 {
+		return nil, nil
+	}
+	if len(memos) != numReceivers {
+		return nil, fmt.Errorf("got %v receivers but %v memos", numReceivers, len(memos))
+	}
+
+	return memos, nil
+}
+
+// builtinEVMNetworkIDs holds the names of the EVM networks supported by the SDK out of the box. Other networks are
+// listed in the config file.
+var builtinEVMNetworkIDs = map[string]int{
+	"eth": rpc.ETHNetworkID,
+	"bsc": rpc.BSCNetworkID,
+	"plg": rpc.PLGNetworkID,
+	"ftm": rpc.FTMNetworkID,
+}
+
+// parseEVMNetwork returns the EVM network ID given its name (eth, bsc, plg, ftm, or the name of an EVM network of the
+// config file) or its ID.
+func (ctx *cliContext) parseEVMNetwork(name string) (int, error) {
+	if id, ok := builtinEVMNetworkIDs[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+	for _, network := range ctx.EVMNetworks {
+		if network.Name != "" && strings.EqualFold(network.Name, name) {
+			return network.ID, nil
+		}
+	}
+	if id, err := strconv.Atoi(name); err == nil {
+		if rpc.IsEVMNetworkSupported(id) {
+			return id, nil
+		}
+		for _, network := range ctx.EVMNetworks {
+			if network.ID == id {
+				return id, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("EVM network %v not supported", name)
+}
+
+// readSecret reads a secret from the given file ("-" for the standard input), or from the given environment variable
+// if no file is given. It returns an empty string if none is set.
+func readSecret(file, env string) (string, error) {
+	if file == "" {
+		return strings.TrimSpace(os.Getenv(env)), nil
+	}
+
+	var raw []byte
+	var err error
+	if file == "-" {
+		raw, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		raw, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read %v: %v", file, err)
+	}
+
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// newFlagSet creates the flag set of a command, with the -privateKeyFile flag if needed.
+func newFlagSet(name string, withPrivateKey bool) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	var privateKeyFile *string
+	if withPrivateKey {
+		privateKeyFile = fs.String("privateKeyFile", "",
+			fmt.Sprintf("a file holding the private key of the account, - for the standard input (defaults to the keystore account, or %v)", privateKeyEnv))
+	}
+
+	return fs, privateKeyFile
+}
+
+// requireFlags checks that the given string flags are not empty.
+func requireFlags(values map[string]string) error {
+	missing := make([]string, 0)
+	for name, value := range values {
+		if value == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing required flags: %v", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
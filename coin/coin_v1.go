@@ -40,14 +40,19 @@ func (pc *PlainCoinV1) Init() *PlainCoinV1 {
 // GetVersion returns the version of a PlainCoinV1.
 func (*PlainCoinV1) GetVersion() uint8 { return 1 }
 
-// GetShardID returns the shardID in which a PlainCoinV1 belongs to.
+// GetShardID returns the shardID in which a PlainCoinV1 belongs to, w.r.t the default number of shards.
 func (pc *PlainCoinV1) GetShardID() (uint8, error) {
+	return pc.GetShardIDWithParams(nil)
+}
+
+// GetShardIDWithParams is the same as GetShardID, but uses the number of shards of the given NetworkParams.
+func (pc *PlainCoinV1) GetShardIDWithParams(params *common.NetworkParams) (uint8, error) {
 	if pc.publicKey == nil {
 		return 255, fmt.Errorf("cannot get ShardID because PublicKey of PlainCoinV1 is concealed")
 	}
 	pubKeyBytes := pc.publicKey.ToBytes()
 	lastByte := pubKeyBytes[crypto.Ed25519KeySize-1]
-	shardID := common.GetShardIDFromLastByte(lastByte)
+	shardID := params.GetShardIDFromLastByte(lastByte)
 	return shardID, nil
 }
 
@@ -180,10 +185,15 @@ func (pc *PlainCoinV1) HashH() *common.Hash {
 //	- The public key
 //	- The value
 //	- The serial number derivator
-//	- The shardID
+//	- The shardID (w.r.t the default number of shards)
 //	- The randomness.
 func (pc *PlainCoinV1) CommitAll() error {
-	shardID, err := pc.GetShardID()
+	return pc.CommitAllWithParams(nil)
+}
+
+// CommitAllWithParams is the same as CommitAll, but derives the shardID w.r.t the given NetworkParams.
+func (pc *PlainCoinV1) CommitAllWithParams(params *common.NetworkParams) error {
+	shardID, err := pc.GetShardIDWithParams(params)
 	if err != nil {
 		return err
 	}
@@ -356,9 +366,14 @@ func (c CoinV1) GetRandomness() *crypto.Scalar { return c.CoinDetails.GetRandomn
 // GetSNDerivator returns the serial number derivator of a CoinV1.
 func (c CoinV1) GetSNDerivator() *crypto.Scalar { return c.CoinDetails.GetSNDerivator() }
 
-// GetShardID returns the shardID in which a CoinV1 belongs to.
+// GetShardID returns the shardID in which a CoinV1 belongs to, w.r.t the default number of shards.
 func (c CoinV1) GetShardID() (uint8, error) { return c.CoinDetails.GetShardID() }
 
+// GetShardIDWithParams is the same as GetShardID, but uses the number of shards of the given NetworkParams.
+func (c CoinV1) GetShardIDWithParams(params *common.NetworkParams) (uint8, error) {
+	return c.CoinDetails.GetShardIDWithParams(params)
+}
+
 // GetValue returns the value of a CoinV1.
 func (c CoinV1) GetValue() uint64 { return c.CoinDetails.GetValue() }
 
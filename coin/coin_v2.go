@@ -324,14 +324,19 @@ func (c CoinV2) GetTxRandomDetail() (*crypto.Point, *crypto.Point, uint32, error
 	return txRandomConcealPoint, txRandomOTAPoint, index, nil
 }
 
-// GetShardID returns the shardID in which a CoinV2 belongs to.
+// GetShardID returns the shardID in which a CoinV2 belongs to, w.r.t the default number of shards.
 func (c CoinV2) GetShardID() (uint8, error) {
+	return c.GetShardIDWithParams(nil)
+}
+
+// GetShardIDWithParams is the same as GetShardID, but uses the number of shards of the given NetworkParams.
+func (c CoinV2) GetShardIDWithParams(params *common.NetworkParams) (uint8, error) {
 	if c.publicKey == nil {
 		return 255, fmt.Errorf("cannot get GetShardID because GetPublicKey of PlainCoin is concealed")
 	}
 	pubKeyBytes := c.publicKey.ToBytes()
 	lastByte := pubKeyBytes[crypto.Ed25519KeySize-1]
-	shardID := common.GetShardIDFromLastByte(lastByte)
+	shardID := params.GetShardIDFromLastByte(lastByte)
 	return shardID, nil
 }
 
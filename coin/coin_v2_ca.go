@@ -112,7 +112,7 @@ func NewCoinCA(p *CoinParams, tokenID *common.Hash) (*CoinV2, *crypto.Point, err
 		return nil, nil, fmt.Errorf(errStr)
 	}
 	receiverPublicKeyBytes := receiverPublicKey.ToBytesS()
-	targetShardID := common.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
+	targetShardID := p.NetworkParams.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
 
 	c := new(CoinV2).Init()
 	// Amount, Randomness, SharedRandom is transparency until we call concealData
@@ -152,7 +152,7 @@ func NewCoinCA(p *CoinParams, tokenID *common.Hash) (*CoinV2, *crypto.Point, err
 		publicKey := new(crypto.Point).Add(HrKG, publicSpend)
 		c.SetPublicKey(publicKey)
 
-		senderShardID, receivingShardID, coinPrivacyType, _ := DeriveShardInfoFromCoin(publicKey.ToBytesS())
+		senderShardID, receivingShardID, coinPrivacyType, _ := DeriveShardInfoFromCoinWithParams(publicKey.ToBytesS(), p.NetworkParams)
 		if receivingShardID == int(targetShardID) && senderShardID == p.SenderShardID && coinPrivacyType == p.CoinPrivacyType {
 			otaSharedRandomPoint := new(crypto.Point).ScalarMultBase(c.GetSharedRandom())
 			concealSharedRandomPoint := new(crypto.Point).ScalarMultBase(c.GetSharedConcealRandom())
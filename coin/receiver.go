@@ -34,8 +34,9 @@ func (receiver OTAReceiver) IsValid() bool {
 }
 
 // FromAddress generates an OTAReceiver from the given payment address.
-// Note: it does not generate an OTAReceiver matching the description of the new coin-grouping scheme.
-// Deprecated: FromCoinParams instead.
+// Note: it does not generate an OTAReceiver matching the description of the new coin-grouping scheme, and it derives
+// shards w.r.t the default number of shards only.
+// Deprecated: FromCoinParams (with CoinParams.NetworkParams) instead.
 func (receiver *OTAReceiver) FromAddress(addr key.PaymentAddress) error {
 	if receiver == nil {
 		return fmt.Errorf("OTAReceiver not initialized")
@@ -83,7 +84,7 @@ func (receiver *OTAReceiver) FromCoinParams(p *CoinParams) error {
 
 	addr := p.PaymentInfo.PaymentAddress
 
-	receiverShardID := common.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
+	receiverShardID := p.NetworkParams.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
 	otaRand := crypto.RandomScalar()
 	concealRand := crypto.RandomScalar()
 
@@ -101,7 +102,7 @@ func (receiver *OTAReceiver) FromCoinParams(p *CoinParams) error {
 		HrKG := (&crypto.Point{}).ScalarMultBase(hash)
 		publicKey := (&crypto.Point{}).Add(HrKG, publicSpend)
 
-		tmpSenderShardID, tmpReceiverShardID, tmpCoinType, _ := DeriveShardInfoFromCoin(publicKey.ToBytesS())
+		tmpSenderShardID, tmpReceiverShardID, tmpCoinType, _ := DeriveShardInfoFromCoinWithParams(publicKey.ToBytesS(), p.NetworkParams)
 		if tmpReceiverShardID == int(receiverShardID) && tmpSenderShardID == p.SenderShardID && tmpCoinType == p.CoinPrivacyType {
 			otaRandomPoint := (&crypto.Point{}).ScalarMultBase(otaRand)
 			concealRandomPoint := (&crypto.Point{}).ScalarMultBase(concealRand)
@@ -12,16 +12,19 @@ import (
 func TestOTAReceiver_FromCoinParams(t *testing.T) {
 	for i := 0; i < numTests; i++ {
 		prefix := fmt.Sprintf("[TEST %v]", i)
-		common.MaxShardNumber = 1 + common.RandInt()%7
-		senderShard := common.RandInt() % common.MaxShardNumber
-		receiverShard := common.RandInt() % common.MaxShardNumber
+		params, err := common.NewNetworkParams(1+common.RandInt()%7, 1)
+		if err != nil {
+			panic(fmt.Sprintf("%v %v", prefix, err))
+		}
+		senderShard := common.RandInt() % params.MaxShardNumber
+		receiverShard := common.RandInt() % params.MaxShardNumber
 		coinType := common.RandInt() % 2
 		if coinType == PrivacyTypeMint {
 			senderShard = receiverShard
 		}
 		fmt.Printf("%v STARTED\n", prefix)
 		fmt.Printf("%v numShards: %v, senderShard: %v, receiverShard: %v, coinType: %v\n", prefix,
-			common.MaxShardNumber, senderShard, receiverShard, coinType)
+			params.MaxShardNumber, senderShard, receiverShard, coinType)
 
 		w, err := wallet.GenRandomWalletForShardID(byte(receiverShard))
 		if err != nil {
@@ -35,9 +38,9 @@ func TestOTAReceiver_FromCoinParams(t *testing.T) {
 
 		var coinParam *CoinParams
 		if coinType == PrivacyTypeTransfer {
-			coinParam = NewTransferCoinParams(paymentInfo, byte(senderShard))
+			coinParam = NewTransferCoinParamsWithNetwork(params, paymentInfo, byte(senderShard))
 		} else {
-			coinParam = NewMintCoinParams(paymentInfo)
+			coinParam = NewMintCoinParamsWithNetwork(params, paymentInfo)
 		}
 
 		start := time.Now()
@@ -47,7 +50,7 @@ func TestOTAReceiver_FromCoinParams(t *testing.T) {
 			panic(fmt.Sprintf("%v %v", prefix, err))
 		}
 
-		tmpSenderShard, tmpReceiverShard, tmpCoinType, err := tmp.DeriveShardID()
+		tmpSenderShard, tmpReceiverShard, tmpCoinType, err := DeriveShardInfoFromCoinWithParams(tmp.PublicKey.ToBytesS(), params)
 		if err != nil {
 			panic(fmt.Sprintf("%v %v", prefix, err))
 		}
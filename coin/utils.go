@@ -103,7 +103,7 @@ func NewCoinFromPaymentInfo(p *CoinParams) (*CoinV2, error) {
 		return nil, fmt.Errorf(errStr)
 	}
 	receiverPublicKeyBytes := receiverPublicKey.ToBytesS()
-	targetShardID := common.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
+	targetShardID := p.NetworkParams.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
 
 	c := new(CoinV2).Init()
 	// Amount, Randomness, SharedRandom are transparency until we call concealData
@@ -140,7 +140,7 @@ func NewCoinFromPaymentInfo(p *CoinParams) (*CoinV2, error) {
 		publicKey := new(crypto.Point).Add(HrKG, publicSpend)
 		c.SetPublicKey(publicKey)
 
-		senderShardID, receivingShardID, coinPrivacyType, _ := DeriveShardInfoFromCoin(publicKey.ToBytesS())
+		senderShardID, receivingShardID, coinPrivacyType, _ := DeriveShardInfoFromCoinWithParams(publicKey.ToBytesS(), p.NetworkParams)
 		if receivingShardID == int(targetShardID) && senderShardID == p.SenderShardID && coinPrivacyType == p.CoinPrivacyType {
 			otaRandomPoint := new(crypto.Point).ScalarMultBase(c.GetSharedRandom())
 			concealRandomPoint := new(crypto.Point).ScalarMultBase(c.GetSharedConcealRandom())
@@ -160,10 +160,16 @@ const (
 // PublicKey on that coin (encoded inside its last byte).
 // Does not support MaxShardNumber > 8.
 func DeriveShardInfoFromCoin(coinPubKey []byte) (int, int, int, error) {
-	if common.MaxShardNumber > 8 {
-		return -1, -1, -1, fmt.Errorf("cannot derive shardID with MaxShardNumber = %v", common.MaxShardNumber)
+	return DeriveShardInfoFromCoinWithParams(coinPubKey, nil)
+}
+
+// DeriveShardInfoFromCoinWithParams is the same as DeriveShardInfoFromCoin, but uses the number of shards of the given
+// NetworkParams instead of the default one.
+func DeriveShardInfoFromCoinWithParams(coinPubKey []byte, params *common.NetworkParams) (int, int, int, error) {
+	numShards := params.GetMaxShardNumber()
+	if numShards <= 0 || numShards > common.MaxSupportedShardNumber {
+		return -1, -1, -1, fmt.Errorf("cannot derive shardID with MaxShardNumber = %v", numShards)
 	}
-	numShards := common.MaxShardNumber
 	n := int(coinPubKey[len(coinPubKey)-1]) % 128 // use 7 bits
 	receiverShardID := n % numShards
 	n /= numShards
@@ -181,6 +187,9 @@ type CoinParams struct {
 	key.PaymentInfo
 	SenderShardID   int
 	CoinPrivacyType int
+
+	// NetworkParams is used to derive the shards of the new coin. If nil, the default parameters are used.
+	NetworkParams *common.NetworkParams
 }
 
 // NewCoinParams returns an empty CoinParams.
@@ -190,30 +199,43 @@ func NewCoinParams() *CoinParams { return &CoinParams{} }
 // If `senderShardParams` is not given, `senderShard` will default to the shardID of the given payment info.
 // Otherwise, the first value of `senderShardParams` will be set as the `senderShard`.
 func NewTransferCoinParams(paymentInfo *key.PaymentInfo, senderShardParams ...byte) *CoinParams {
+	return NewTransferCoinParamsWithNetwork(nil, paymentInfo, senderShardParams...)
+}
+
+// NewTransferCoinParamsWithNetwork is the same as NewTransferCoinParams, but derives shards w.r.t the given
+// NetworkParams.
+func NewTransferCoinParamsWithNetwork(params *common.NetworkParams, paymentInfo *key.PaymentInfo, senderShardParams ...byte) *CoinParams {
 	var senderShard byte
 	if len(senderShardParams) > 0 {
 		senderShard = senderShardParams[0]
 	} else {
 		receiverPublicKeyBytes := paymentInfo.PaymentAddress.Pk
-		senderShard = common.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
+		senderShard = params.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
 	}
 
 	return &CoinParams{
 		PaymentInfo:     *paymentInfo,
 		SenderShardID:   int(senderShard),
 		CoinPrivacyType: PrivacyTypeTransfer,
+		NetworkParams:   params,
 	}
 }
 
 // NewMintCoinParams returns a new CoinParams for the minting purpose.
 func NewMintCoinParams(paymentInfo *key.PaymentInfo) *CoinParams {
+	return NewMintCoinParamsWithNetwork(nil, paymentInfo)
+}
+
+// NewMintCoinParamsWithNetwork is the same as NewMintCoinParams, but derives shards w.r.t the given NetworkParams.
+func NewMintCoinParamsWithNetwork(params *common.NetworkParams, paymentInfo *key.PaymentInfo) *CoinParams {
 	var senderShard byte
 	receiverPublicKeyBytes := paymentInfo.PaymentAddress.Pk
-	senderShard = common.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
+	senderShard = params.GetShardIDFromLastByte(receiverPublicKeyBytes[len(receiverPublicKeyBytes)-1])
 
 	return &CoinParams{
 		PaymentInfo:     *paymentInfo,
 		SenderShardID:   int(senderShard),
 		CoinPrivacyType: PrivacyTypeMint,
+		NetworkParams:   params,
 	}
 }
@@ -3,6 +3,7 @@ package coin
 import (
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
 	"github.com/incognitochain/go-incognito-sdk-v2/key"
 	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
 	"testing"
@@ -16,16 +17,19 @@ var (
 func TestNewCoinFromPaymentInfo(t *testing.T) {
 	for i := 0; i < numTests; i++ {
 		prefix := fmt.Sprintf("[TEST %v]", i)
-		common.MaxShardNumber = 1 + common.RandInt()%7
-		senderShard := common.RandInt() % common.MaxShardNumber
-		receiverShard := common.RandInt() % common.MaxShardNumber
+		params, err := common.NewNetworkParams(1+common.RandInt()%7, 1)
+		if err != nil {
+			panic(fmt.Sprintf("%v %v", prefix, err))
+		}
+		senderShard := common.RandInt() % params.MaxShardNumber
+		receiverShard := common.RandInt() % params.MaxShardNumber
 		coinType := common.RandInt() % 2
 		if coinType == PrivacyTypeMint {
 			senderShard = receiverShard
 		}
 		fmt.Printf("%v STARTED\n", prefix)
 		fmt.Printf("%v numShards: %v, senderShard: %v, receiverShard: %v, coinType: %v\n", prefix,
-			common.MaxShardNumber, senderShard, receiverShard, coinType)
+			params.MaxShardNumber, senderShard, receiverShard, coinType)
 
 		w, err := wallet.GenRandomWalletForShardID(byte(receiverShard))
 		if err != nil {
@@ -39,9 +43,9 @@ func TestNewCoinFromPaymentInfo(t *testing.T) {
 
 		var coinParam *CoinParams
 		if coinType == PrivacyTypeTransfer {
-			coinParam = NewTransferCoinParams(paymentInfo, byte(senderShard))
+			coinParam = NewTransferCoinParamsWithNetwork(params, paymentInfo, byte(senderShard))
 		} else {
-			coinParam = NewMintCoinParams(paymentInfo)
+			coinParam = NewMintCoinParamsWithNetwork(params, paymentInfo)
 		}
 
 		start := time.Now()
@@ -50,7 +54,7 @@ func TestNewCoinFromPaymentInfo(t *testing.T) {
 			panic(fmt.Sprintf("%v %v", prefix, err))
 		}
 
-		tmpSenderShard, tmpReceiverShard, tmpCoinType, err := DeriveShardInfoFromCoin(c.GetPublicKey().ToBytesS())
+		tmpSenderShard, tmpReceiverShard, tmpCoinType, err := DeriveShardInfoFromCoinWithParams(c.GetPublicKey().ToBytesS(), params)
 		if err != nil {
 			panic(fmt.Sprintf("%v %v", prefix, err))
 		}
@@ -70,3 +74,70 @@ func TestNewCoinFromPaymentInfo(t *testing.T) {
 		fmt.Printf("%v FINISHED: %v\n\n", prefix, time.Since(start).Seconds())
 	}
 }
+
+func TestNewCoinFromPaymentInfoWithNetwork(t *testing.T) {
+	defaultMaxShardNumber := common.MaxShardNumber
+	masterWallet, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < numTests; i++ {
+		prefix := fmt.Sprintf("[TEST %v]", i)
+		params, err := common.NewNetworkParams(1+common.RandInt()%7, 1)
+		if err != nil {
+			panic(fmt.Sprintf("%v %v", prefix, err))
+		}
+		receiverShard := common.RandInt() % params.MaxShardNumber
+		senderShard := common.RandInt() % params.MaxShardNumber
+
+		children, err := masterWallet.DeriveAccountChildrenForShard(params, 0, byte(receiverShard), uint32(i), 1)
+		if err != nil {
+			panic(fmt.Sprintf("%v %v", prefix, err))
+		}
+		paymentInfo := &key.PaymentInfo{PaymentAddress: children[0].KeyWallet.KeySet.PaymentAddress, Message: []byte{}}
+
+		for _, coinParam := range []*CoinParams{
+			NewTransferCoinParamsWithNetwork(params, paymentInfo, byte(senderShard)),
+			NewMintCoinParamsWithNetwork(params, paymentInfo),
+		} {
+			c, err := NewCoinFromPaymentInfo(coinParam)
+			if err != nil {
+				panic(fmt.Sprintf("%v %v", prefix, err))
+			}
+			tmpSenderShard, tmpReceiverShard, tmpCoinType, err := DeriveShardInfoFromCoinWithParams(c.GetPublicKey().ToBytesS(), params)
+			if err != nil {
+				panic(fmt.Sprintf("%v %v", prefix, err))
+			}
+			if tmpReceiverShard != receiverShard || tmpSenderShard != coinParam.SenderShardID || tmpCoinType != coinParam.CoinPrivacyType {
+				panic(fmt.Sprintf("%v expect (%v, %v, %v), got (%v, %v, %v)", prefix,
+					coinParam.SenderShardID, receiverShard, coinParam.CoinPrivacyType,
+					tmpSenderShard, tmpReceiverShard, tmpCoinType))
+			}
+			tmpShardID, err := c.GetShardIDWithParams(params)
+			if err != nil {
+				panic(fmt.Sprintf("%v %v", prefix, err))
+			}
+			pkBytes := c.GetPublicKey().ToBytesS()
+			if tmpShardID != params.GetShardIDFromLastByte(pkBytes[len(pkBytes)-1]) {
+				panic(fmt.Sprintf("%v invalid shardID %v", prefix, tmpShardID))
+			}
+		}
+
+		pk, err := new(crypto.Point).FromBytesS(children[0].KeyWallet.KeySet.PaymentAddress.Pk)
+		if err != nil {
+			panic(fmt.Sprintf("%v %v", prefix, err))
+		}
+		v1Coin := new(PlainCoinV1)
+		v1Coin.SetPublicKey(pk)
+		tmpShardID, err := v1Coin.GetShardIDWithParams(params)
+		if err != nil || int(tmpShardID) != receiverShard {
+			panic(fmt.Sprintf("%v expect v1 shardID %v, got %v (%v)", prefix, receiverShard, tmpShardID, err))
+		}
+	}
+
+	// the package-level defaults are left untouched
+	if common.MaxShardNumber != defaultMaxShardNumber {
+		panic(fmt.Sprintf("MaxShardNumber changed to %v", common.MaxShardNumber))
+	}
+}
@@ -31,8 +31,9 @@ func SliceExists(slice interface{}, item interface{}) (bool, error) {
 
 // GetShardIDFromLastByte returns the shardID from the last byte b of a public key.
 // The shardID is calculated by taking the remainder of b % MaxShardNumber.
+// To derive the shardID for a specific network, use NetworkParams.GetShardIDFromLastByte instead.
 func GetShardIDFromLastByte(b byte) byte {
-	return byte(int(b) % MaxShardNumber)
+	return (*NetworkParams)(nil).GetShardIDFromLastByte(b)
 }
 
 // IntToBytes converts an integer number to 2-byte array in big endian.
@@ -134,6 +135,10 @@ var (
 	PRVCoinID           = Hash{4}
 	ConfidentialAssetID = Hash{5}
 	PDEXCoinID          = Hash{6}
-	MaxShardNumber      = 8 //programmatically config based on networkID
-	AddressVersion      = 1
+
+	// MaxShardNumber is the default number of active shards, used when no NetworkParams is given.
+	MaxShardNumber = MaxSupportedShardNumber
+
+	// AddressVersion is the default address version, used when no NetworkParams is given.
+	AddressVersion = 1
 )
@@ -0,0 +1,72 @@
+package common
+
+import "fmt"
+
+// MaxSupportedShardNumber is the maximum number of shards supported by the coin-grouping scheme.
+const MaxSupportedShardNumber = 8
+
+// NetworkParams consists of the network-dependent parameters used to derive shards and encode addresses.
+//
+// Functions accepting a *NetworkParams treat a nil value as the package-level defaults MaxShardNumber and
+// AddressVersion, which the SDK never modifies. Callers working with several networks in the same process should
+// always pass their own NetworkParams.
+type NetworkParams struct {
+	// MaxShardNumber is the number of active shards of the network.
+	MaxShardNumber int
+
+	// AddressVersion is the version of encoded addresses: 0 for the old checksum, 1 for the new one (with OTA keys).
+	AddressVersion int
+}
+
+// NewNetworkParams creates a new NetworkParams with the given number of shards and address version.
+func NewNetworkParams(maxShardNumber, addressVersion int) (*NetworkParams, error) {
+	p := &NetworkParams{MaxShardNumber: maxShardNumber, AddressVersion: addressVersion}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// DefaultNetworkParams returns a NetworkParams with the package-level default values.
+func DefaultNetworkParams() *NetworkParams {
+	return &NetworkParams{MaxShardNumber: MaxShardNumber, AddressVersion: AddressVersion}
+}
+
+// Validate checks if a NetworkParams is valid.
+func (p *NetworkParams) Validate() error {
+	if p.MaxShardNumber <= 0 || p.MaxShardNumber > MaxSupportedShardNumber {
+		return fmt.Errorf("invalid MaxShardNumber %v", p.MaxShardNumber)
+	}
+	if p.AddressVersion != 0 && p.AddressVersion != 1 {
+		return fmt.Errorf("invalid AddressVersion %v", p.AddressVersion)
+	}
+
+	return nil
+}
+
+// GetMaxShardNumber returns the number of active shards. If p is nil, it returns MaxShardNumber.
+func (p *NetworkParams) GetMaxShardNumber() int {
+	if p == nil {
+		return MaxShardNumber
+	}
+	return p.MaxShardNumber
+}
+
+// GetAddressVersion returns the address version. If p is nil, it returns AddressVersion.
+func (p *NetworkParams) GetAddressVersion() int {
+	if p == nil {
+		return AddressVersion
+	}
+	return p.AddressVersion
+}
+
+// GetShardIDFromLastByte returns the shardID from the last byte b of a public key w.r.t the number of active shards.
+func (p *NetworkParams) GetShardIDFromLastByte(b byte) byte {
+	return byte(int(b) % p.GetMaxShardNumber())
+}
+
+// IsValidShardID checks if a shardID is one of the active shards.
+func (p *NetworkParams) IsValidShardID(shardID int) bool {
+	return shardID >= 0 && shardID < p.GetMaxShardNumber()
+}
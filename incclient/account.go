@@ -103,34 +103,23 @@ func (client *IncClient) GetMyNFTs(privateKey string) ([]string, error) {
 
 // ImportAccount imports a BIP39 mnemonic string and finds all child keys derived from the mnemonic. The first return KeyWallet
 // is the master wallet, which is used to derive the rest of child KeyWallet.
-// For child KeyWallets, we start with childIdx = 1 and stops at the index when there is no transaction found for the child key.
+// For child KeyWallets, we start with childIdx = 1 and stop after DefaultHDGapLimit consecutive child keys without any
+// transaction. To discover other BIP-44 accounts, or to use a different gap limit, use an HDWalletManager.
 func (client *IncClient) ImportAccount(mnemonic string) ([]*wallet.KeyWallet, error) {
-	res := make([]*wallet.KeyWallet, 0)
-
-	masterWallet, err := wallet.NewMasterKeyFromMnemonic(mnemonic)
+	manager, err := NewHDWalletManager(client, mnemonic)
 	if err != nil {
 		return nil, err
 	}
-	res = append(res, masterWallet)
-
-	childIdx := uint32(1)
-	for {
-		childWallet, err := masterWallet.DeriveChild(childIdx)
-		if err != nil {
-			return nil, fmt.Errorf("childIdx %v error: %v", childIdx, err)
-		}
 
-		addr, _ := childWallet.GetPaymentAddress()
-		receivedTxs, err := client.GetTransactionHashesByReceiver(addr)
-		if err != nil {
-			return nil, fmt.Errorf("childIdx %v error: %v", childIdx, err)
-		}
+	accounts, err := manager.DiscoverAll()
+	if err != nil {
+		return nil, err
+	}
 
-		if len(receivedTxs) > 0 {
-			res = append(res, childWallet)
-			childIdx++
-		} else {
-			return res, nil
-		}
+	res := make([]*wallet.KeyWallet, 0)
+	for _, account := range accounts {
+		res = append(res, account.KeyWallet)
 	}
+
+	return res, nil
 }
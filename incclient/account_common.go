@@ -33,8 +33,18 @@ func (k KeyInfo) String() string {
 	return string(jsb)
 }
 
-// GetAccountInfoFromPrivateKey returns all fields related to a private key.
+// GetAccountInfoFromPrivateKey returns all fields related to a private key, w.r.t the default NetworkParams.
+// Use IncClient.GetAccountInfoFromPrivateKey to get them w.r.t the network of a client.
 func GetAccountInfoFromPrivateKey(privateKey string) (*KeyInfo, error) {
+	return getAccountInfoFromPrivateKey(nil, privateKey)
+}
+
+// GetAccountInfoFromPrivateKey returns all fields related to a private key, w.r.t the NetworkParams of the client.
+func (client *IncClient) GetAccountInfoFromPrivateKey(privateKey string) (*KeyInfo, error) {
+	return getAccountInfoFromPrivateKey(client.networkParams, privateKey)
+}
+
+func getAccountInfoFromPrivateKey(params *common.NetworkParams, privateKey string) (*KeyInfo, error) {
 	w, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
 		return nil, err
@@ -45,12 +55,12 @@ func GetAccountInfoFromPrivateKey(privateKey string) (*KeyInfo, error) {
 	}
 
 	pubKey := PrivateKeyToPublicKey(privateKey)
-	addr := PrivateKeyToPaymentAddress(privateKey, -1)
-	addrV1 := PrivateKeyToPaymentAddress(privateKey, 0)
-	readonlyKey := PrivateKeyToReadonlyKey(privateKey)
-	otaKey := PrivateKeyToPrivateOTAKey(privateKey)
+	addr := privateKeyToPaymentAddress(params, privateKey, -1)
+	addrV1 := privateKeyToPaymentAddress(params, privateKey, 0)
+	readonlyKey := privateKeyToReadonlyKey(params, privateKey)
+	otaKey := privateKeyToPrivateOTAKey(params, privateKey)
 	miningKey := PrivateKeyToMiningKey(privateKey)
-	shardID := GetShardIDFromPrivateKey(privateKey)
+	shardID := getShardIDFromPrivateKey(params, privateKey)
 
 	miningKeyBytes, _, err := base58.Base58Check{}.Decode(miningKey)
 	if err != nil {
@@ -0,0 +1,665 @@
+package incclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// AuditAccount is the read-only material of an account disclosed to an auditor.
+type AuditAccount struct {
+	// PaymentAddress is the base58-encoded payment address of the account.
+	PaymentAddress string
+
+	// ReadonlyKey is the base58-encoded read-only key of the account, used to decrypt the amounts of its output coins.
+	ReadonlyKey string
+
+	// OTAKey is the base58-encoded OTA key of the account, used to find its output coins.
+	OTAKey string
+
+	// KeyImages maps the base58-encoded public key of each output coin of the account to its key image.
+	// A key image cannot be derived without the private key, so the owner computes them when exporting the package.
+	KeyImages map[string]string
+}
+
+// AuditPackage is a read-only package given to an auditor. It allows the auditor to recompute the incoming coins, the
+// spent coins and the balances of the selected accounts, without being able to spend them.
+//
+// Only v2 output coins are covered. A package has two limits the auditor and the owner must be aware of:
+//   - TokenIDs, FromHeight and ToHeight only limit what is reported. The package discloses the full read-only and OTA
+//     keys of the accounts, which reveal all their coins, past and future, of every token.
+//   - The key images are supplied by the owner and cannot be checked by the auditor, as they cannot be derived
+//     without the private keys. An owner omitting (or forging) key images can hide spends, and inflate the Unspent
+//     amount of the report. Output coins received after the package was exported have an unknown spending status.
+type AuditPackage struct {
+	Accounts []*AuditAccount
+
+	// TokenIDs limits the report to the given tokenIDs. An empty list means all tokens.
+	TokenIDs []string `json:",omitempty"`
+
+	// FromHeight and ToHeight limit the report to the coins received within the range of shard heights. A zero ToHeight
+	// means no upper limit.
+	FromHeight uint64
+	ToHeight   uint64
+
+	CreatedAt int64
+}
+
+// String returns the JSON-representation of an AuditPackage.
+func (p AuditPackage) String() string {
+	jsb, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(jsb)
+}
+
+// ParseAuditPackage parses and validates an AuditPackage from its JSON-representation.
+func ParseAuditPackage(data []byte) (*AuditPackage, error) {
+	var p AuditPackage
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("cannot parse audit package: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks if an AuditPackage is valid.
+func (p AuditPackage) Validate() error {
+	if len(p.Accounts) == 0 {
+		return fmt.Errorf("audit package has no account")
+	}
+	if p.ToHeight != 0 && p.FromHeight > p.ToHeight {
+		return fmt.Errorf("fromHeight (%v) is greater than toHeight (%v)", p.FromHeight, p.ToHeight)
+	}
+	for _, tokenIDStr := range p.TokenIDs {
+		if len(tokenIDStr) != 2*common.HashSize {
+			return fmt.Errorf("invalid tokenID %v: expected %v hex characters", tokenIDStr, 2*common.HashSize)
+		}
+		if _, err := new(common.Hash).NewHashFromStr(tokenIDStr); err != nil {
+			return fmt.Errorf("invalid tokenID %v: %v", tokenIDStr, err)
+		}
+	}
+	for _, account := range p.Accounts {
+		if _, err := account.getKeySet(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewAuditPackage exports an AuditPackage for the given private keys, whose report is limited to the given tokenIDs
+// (all tokens if empty) and the given range of heights (no upper limit if toHeight is 0). The private keys are not
+// included in the package; they are only used to derive the key images of the current output coins of the accounts.
+// Note that the read-only and OTA keys of the package still reveal the whole history of the accounts.
+func (client *IncClient) NewAuditPackage(privateKeys []string, tokenIDs []string, fromHeight, toHeight uint64) (*AuditPackage, error) {
+	p := &AuditPackage{
+		Accounts:   make([]*AuditAccount, 0),
+		TokenIDs:   tokenIDs,
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		CreatedAt:  time.Now().Unix(),
+	}
+	for _, privateKey := range privateKeys {
+		outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		outCoinKey.SetReadonlyKey("") // call this if you do not want the remote full-node to decrypt your coin
+
+		outCoins := make([]jsonresult.ICoinInfo, 0)
+		for _, tokenIDStr := range []string{common.PRVIDStr, common.ConfidentialAssetID.String()} {
+			tmpOutCoins, _, err := client.GetOutputCoins(outCoinKey, tokenIDStr, 0)
+			if err != nil {
+				return nil, err
+			}
+			outCoins = append(outCoins, tmpOutCoins...)
+		}
+
+		account, err := newAuditAccount(client.networkParams, privateKey, outCoins)
+		if err != nil {
+			return nil, err
+		}
+		p.Accounts = append(p.Accounts, account)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// AuditCoin is an output coin of an audited account.
+type AuditCoin struct {
+	PublicKey string
+	TokenID   string
+	Amount    uint64
+	Memo      string `json:",omitempty"`
+
+	// TxHash and Height are the transaction and the shard height at which the coin was received. They are only
+	// resolved if the audit is limited to a range of heights.
+	TxHash string `json:",omitempty"`
+	Height uint64 `json:",omitempty"`
+
+	// KeyImage is empty if the spending status of the coin is unknown.
+	KeyImage string `json:",omitempty"`
+	Spent    bool
+
+	// SpentTxHash and SpentHeight are the transaction and the shard height at which the coin was spent. They are only
+	// resolved if the audit has an upper height limit.
+	SpentTxHash string `json:",omitempty"`
+	SpentHeight uint64 `json:",omitempty"`
+}
+
+// AuditBalance summarizes the coins of a token in an audited account.
+type AuditBalance struct {
+	NumCoins int
+	Received uint64
+	Spent    uint64
+	Unspent  uint64
+
+	// Unknown is the amount of coins whose spending status is unknown.
+	Unknown uint64
+}
+
+// AuditAccountReport is the audit result of an account.
+type AuditAccountReport struct {
+	PaymentAddress string
+	Coins          []*AuditCoin
+	Balances       map[string]*AuditBalance
+}
+
+// AuditReport is the result of auditing an AuditPackage, signed by the auditor.
+type AuditReport struct {
+	TokenIDs   []string `json:",omitempty"`
+	FromHeight uint64
+	ToHeight   uint64
+	CreatedAt  int64
+	Accounts   []*AuditAccountReport
+
+	// AuditorPublicKey is the base58-encoded public key of the auditor.
+	AuditorPublicKey string
+
+	// Signature is the base58-encoded Schnorr signature of the auditor on the hash of the report, prefixed with a
+	// domain-separation tag (see signedMessage).
+	Signature string
+}
+
+// String returns the JSON-representation of an AuditReport.
+func (r AuditReport) String() string {
+	jsb, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(jsb)
+}
+
+// ParseAuditReport parses an AuditReport from its JSON-representation. It does not verify the signature.
+func ParseAuditReport(data []byte) (*AuditReport, error) {
+	var r AuditReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("cannot parse audit report: %v", err)
+	}
+	return &r, nil
+}
+
+// auditReportSignaturePrefix separates the signatures of audit reports from the other Schnorr signatures of a key,
+// so that the signature of a report cannot be replayed as (or forged from) another signed message.
+const auditReportSignaturePrefix = "incognito-audit-report-v1"
+
+// Hash returns the hash of an AuditReport, i.e, the hash of its JSON-representation without the signature.
+func (r AuditReport) Hash() (*common.Hash, error) {
+	r.Signature = ""
+	jsb, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	hash := common.HashH(jsb)
+	return &hash, nil
+}
+
+// signedMessage returns the message signed by the auditor: the hash of auditReportSignaturePrefix and Hash.
+func (r AuditReport) signedMessage() (*common.Hash, error) {
+	hash, err := r.Hash()
+	if err != nil {
+		return nil, err
+	}
+	msg := common.HashH(append([]byte(auditReportSignaturePrefix), hash[:]...))
+	return &msg, nil
+}
+
+// Sign signs an AuditReport with the private key of the auditor.
+func (r *AuditReport) Sign(privateKey string) error {
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return err
+	}
+	if len(keyWallet.KeySet.PrivateKey) != common.PrivateKeySize {
+		return fmt.Errorf("invalid private key")
+	}
+
+	sigKey := new(privacy.SchnorrPrivateKey)
+	sigKey.Set(new(crypto.Scalar).FromBytesS(keyWallet.KeySet.PrivateKey), new(crypto.Scalar).FromUint64(0))
+	r.AuditorPublicKey = base58.Base58Check{}.Encode(sigKey.GetPublicKey().GetPublicKey().ToBytesS(), common.ZeroByte)
+
+	msg, err := r.signedMessage()
+	if err != nil {
+		return err
+	}
+	signature, err := sigKey.Sign(msg[:])
+	if err != nil {
+		return err
+	}
+	r.Signature = base58.Base58Check{}.Encode(signature.Bytes(), common.ZeroByte)
+
+	return nil
+}
+
+// Verify checks the signature of an AuditReport against its AuditorPublicKey. It returns nil if the signature is valid.
+// Callers should also check that the AuditorPublicKey is the one of the expected auditor.
+func (r AuditReport) Verify() error {
+	pubKeyBytes, _, err := base58.Base58Check{}.Decode(r.AuditorPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid auditor public key: %v", err)
+	}
+	pubKeyPoint, err := new(crypto.Point).FromBytesS(pubKeyBytes)
+	if err != nil {
+		return fmt.Errorf("invalid auditor public key: %v", err)
+	}
+	sigBytes, _, err := base58.Base58Check{}.Decode(r.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	signature := new(privacy.SchnorrSignature)
+	if err = signature.SetBytes(sigBytes); err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	msg, err := r.signedMessage()
+	if err != nil {
+		return err
+	}
+	pubKey := new(privacy.SchnorrPublicKey)
+	pubKey.Set(pubKeyPoint)
+	if !pubKey.Verify(signature, msg[:]) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	return nil
+}
+
+// NewAuditReport audits an AuditPackage, and returns a report signed with the private key of the auditor.
+//
+// For each account, it retrieves and decrypts the output coins, checks their spending status using the key images of
+// the package, and computes the balance of each token. If the package is limited to a range of heights, the receiving
+// (and spending) transactions of the coins are resolved to filter them by height, which is notably slower.
+func (client *IncClient) NewAuditReport(p *AuditPackage, auditorPrivateKey string) (*AuditReport, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	var rawAssetTags map[string]*common.Hash
+	report := &AuditReport{
+		TokenIDs:   p.TokenIDs,
+		FromHeight: p.FromHeight,
+		ToHeight:   p.ToHeight,
+		CreatedAt:  time.Now().Unix(),
+		Accounts:   make([]*AuditAccountReport, 0),
+	}
+	for _, account := range p.Accounts {
+		keySet, err := account.getKeySet()
+		if err != nil {
+			return nil, err
+		}
+		pk := keySet.PaymentAddress.Pk
+		shardID := client.networkParams.GetShardIDFromLastByte(pk[len(pk)-1])
+		outCoinKey := rpc.NewOutCoinKey(account.PaymentAddress, account.OTAKey, "")
+
+		coins := make([]*AuditCoin, 0)
+		for _, tokenIDStr := range []string{common.PRVIDStr, common.ConfidentialAssetID.String()} {
+			outCoins, _, err := client.GetOutputCoins(outCoinKey, tokenIDStr, 0)
+			if err != nil {
+				return nil, err
+			}
+			// only get rawAssetTags when we have token coins to improve response time
+			if tokenIDStr != common.PRVIDStr && len(outCoins) > 0 && rawAssetTags == nil {
+				rawAssetTags, err = client.getAuditAssetTags(p.TokenIDs)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			tmpCoins, err := newAuditCoins(keySet, account.KeyImages, outCoins, rawAssetTags, p.TokenIDs)
+			if err != nil {
+				return nil, err
+			}
+			if p.FromHeight != 0 || p.ToHeight != 0 {
+				tmpCoins, err = client.filterAuditCoinsByHeight(tmpCoins, p.FromHeight, p.ToHeight)
+				if err != nil {
+					return nil, err
+				}
+			}
+			err = client.checkAuditCoinsSpent(shardID, tokenIDStr, tmpCoins, p.ToHeight)
+			if err != nil {
+				return nil, err
+			}
+			coins = append(coins, tmpCoins...)
+		}
+
+		report.Accounts = append(report.Accounts, newAuditAccountReport(account.PaymentAddress, coins))
+	}
+
+	if err := report.Sign(auditorPrivateKey); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// newAuditAccount creates the AuditAccount of a private key, with the key images of the given output coins. The keys
+// are encoded with the given network parameters (the default ones if nil).
+func newAuditAccount(params *common.NetworkParams, privateKey string, outCoins []jsonresult.ICoinInfo) (*AuditAccount, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := NewCoinDecryptor(privateKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keyImages := make(map[string]string)
+	for _, res := range decryptor.DecryptAll(outCoins) {
+		if res.Err != nil || res.Coin == nil || outCoins[res.Index].GetVersion() != 2 {
+			continue
+		}
+		pubKeyStr := base58.Base58Check{}.Encode(outCoins[res.Index].GetPublicKey().ToBytesS(), common.ZeroByte)
+		keyImages[pubKeyStr] = res.KeyImage
+	}
+
+	return &AuditAccount{
+		PaymentAddress: keyWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params),
+		ReadonlyKey:    keyWallet.Base58CheckSerializeWithParams(wallet.ReadonlyKeyType, params),
+		OTAKey:         keyWallet.Base58CheckSerializeWithParams(wallet.OTAKeyType, params),
+		KeyImages:      keyImages,
+	}, nil
+}
+
+// getKeySet returns the read-only key set of an AuditAccount, after checking that its keys belong to the same account.
+func (account AuditAccount) getKeySet() (*key.KeySet, error) {
+	addrWallet, err := wallet.Base58CheckDeserialize(account.PaymentAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment address %v: %v", account.PaymentAddress, err)
+	}
+	addr := addrWallet.KeySet.PaymentAddress
+	if len(addr.Pk) == 0 || addr.GetPublicView() == nil {
+		return nil, fmt.Errorf("%v is not a payment address", account.PaymentAddress)
+	}
+	readonlyWallet, err := wallet.Base58CheckDeserialize(account.ReadonlyKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read-only key of %v: %v", account.PaymentAddress, err)
+	}
+	readonlyKey := readonlyWallet.KeySet.ReadonlyKey
+	if readonlyKey.GetPrivateView() == nil {
+		return nil, fmt.Errorf("invalid read-only key of %v", account.PaymentAddress)
+	}
+	otaWallet, err := wallet.Base58CheckDeserialize(account.OTAKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTA key of %v: %v", account.PaymentAddress, err)
+	}
+	otaKey := otaWallet.KeySet.OTAKey
+	if otaKey.GetOTASecretKey() == nil || otaKey.GetPublicSpend() == nil {
+		return nil, fmt.Errorf("invalid OTA key of %v", account.PaymentAddress)
+	}
+	// a package must never disclose a private key
+	if len(addrWallet.KeySet.PrivateKey) != 0 || len(readonlyWallet.KeySet.PrivateKey) != 0 || len(otaWallet.KeySet.PrivateKey) != 0 {
+		return nil, fmt.Errorf("audit account %v holds a private key", account.PaymentAddress)
+	}
+
+	if !bytes.Equal(readonlyKey.Pk, addr.Pk) || !bytes.Equal(otaKey.GetPublicSpend().ToBytesS(), addr.Pk) ||
+		!crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(readonlyKey.GetPrivateView()), addr.GetPublicView()) {
+		return nil, fmt.Errorf("keys of %v do not belong to the same account", account.PaymentAddress)
+	}
+	if addr.GetOTAPublicKey() != nil &&
+		!crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(otaKey.GetOTASecretKey()), addr.GetOTAPublicKey()) {
+		return nil, fmt.Errorf("keys of %v do not belong to the same account", account.PaymentAddress)
+	}
+
+	return &key.KeySet{
+		PaymentAddress: addr,
+		ReadonlyKey:    readonlyKey,
+		OTAKey:         otaKey,
+	}, nil
+}
+
+// getAuditAssetTags returns the raw asset tags of the given tokenIDs, or of all tokens if none is given.
+func (client *IncClient) getAuditAssetTags(tokenIDs []string) (map[string]*common.Hash, error) {
+	if len(tokenIDs) == 0 {
+		return client.GetAllAssetTags()
+	}
+	return BuildAssetTags(tokenIDs)
+}
+
+// newAuditCoins decrypts the output coins belonging to a read-only key set, and returns those of the given tokenIDs
+// (all tokens if empty). Coins v1, and coins whose tokenID cannot be identified, are skipped.
+func newAuditCoins(keySet *key.KeySet, keyImages map[string]string, outCoins []jsonresult.ICoinInfo,
+	rawAssetTags map[string]*common.Hash, tokenIDs []string) ([]*AuditCoin, error) {
+	tokenIDMap := make(map[string]bool)
+	for _, tokenIDStr := range tokenIDs {
+		tokenIDMap[tokenIDStr] = true
+	}
+
+	res := make([]*AuditCoin, 0)
+	for _, outCoin := range outCoins {
+		c, ok := outCoin.(*coin.CoinV2)
+		if !ok {
+			continue
+		}
+		if belong, _ := c.DoesCoinBelongToKeySet(keySet); !belong {
+			continue
+		}
+		pubKeyStr := base58.Base58Check{}.Encode(c.GetPublicKey().ToBytesS(), common.ZeroByte)
+
+		plainCoin, err := c.Decrypt(keySet)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt coin %v: %v", pubKeyStr, err)
+		}
+		if plainCoin.GetValue() == 0 {
+			continue
+		}
+		tokenID, err := c.GetTokenId(keySet, rawAssetTags)
+		if err != nil || tokenID == nil {
+			if len(tokenIDMap) == 0 {
+				Logger.Printf("GetTokenId of coin %v error: %v\n", pubKeyStr, err)
+			}
+			continue
+		}
+		if len(tokenIDMap) != 0 && !tokenIDMap[tokenID.String()] {
+			continue
+		}
+
+		res = append(res, &AuditCoin{
+			PublicKey: pubKeyStr,
+			TokenID:   tokenID.String(),
+			Amount:    plainCoin.GetValue(),
+			Memo:      GetCoinMemo(plainCoin.GetInfo(), keySet),
+			KeyImage:  keyImages[pubKeyStr],
+		})
+	}
+
+	return res, nil
+}
+
+// filterAuditCoinsByHeight resolves the receiving transactions of the given coins, and returns the coins received
+// within [fromHeight, toHeight]. A zero toHeight means no upper limit.
+func (client *IncClient) filterAuditCoinsByHeight(coins []*AuditCoin, fromHeight, toHeight uint64) ([]*AuditCoin, error) {
+	pubKeys := make([]string, 0)
+	for _, c := range coins {
+		pubKeys = append(pubKeys, c.PublicKey)
+	}
+
+	txHashes := make(map[string][]string)
+	for current := 0; current < len(pubKeys); current += pageSize {
+		next := current + pageSize
+		if next > len(pubKeys) {
+			next = len(pubKeys)
+		}
+		tmpTxHashes, err := client.GetTxHashByPublicKeys(pubKeys[current:next])
+		if err != nil {
+			return nil, err
+		}
+		for pubKeyStr, txList := range tmpTxHashes {
+			txHashes[pubKeyStr] = txList
+		}
+	}
+
+	heights := make(map[string]uint64)
+	res := make([]*AuditCoin, 0)
+	for _, c := range coins {
+		txList := txHashes[c.PublicKey]
+		if len(txList) == 0 {
+			return nil, fmt.Errorf("cannot find the transaction of coin %v", c.PublicKey)
+		}
+		height, err := client.getTxHeight(txList[0], heights)
+		if err != nil {
+			return nil, err
+		}
+		if height < fromHeight || (toHeight != 0 && height > toHeight) {
+			continue
+		}
+		c.TxHash, c.Height = txList[0], height
+		res = append(res, c)
+	}
+
+	return res, nil
+}
+
+// checkAuditCoinsSpent sets the spending status of the given coins using their key images. If toHeight is not zero,
+// the spending transactions are resolved, and coins spent after toHeight are considered unspent.
+func (client *IncClient) checkAuditCoinsSpent(shardID byte, tokenIDStr string, coins []*AuditCoin, toHeight uint64) error {
+	keyImages := make([]string, 0)
+	knownCoins := make([]*AuditCoin, 0)
+	for _, c := range coins {
+		if c.KeyImage != "" {
+			keyImages = append(keyImages, c.KeyImage)
+			knownCoins = append(knownCoins, c)
+		}
+	}
+
+	spentKeyImages := make([]string, 0)
+	for current := 0; current < len(keyImages); current += pageSize {
+		next := current + pageSize
+		if next > len(keyImages) {
+			next = len(keyImages)
+		}
+		checkSpentList, err := client.CheckCoinsSpent(shardID, tokenIDStr, keyImages[current:next])
+		if err != nil {
+			return fmt.Errorf("cannot check spent coins: %v %v %v", tokenIDStr, len(keyImages), err)
+		}
+		for i, spent := range checkSpentList {
+			knownCoins[current+i].Spent = spent
+			if spent {
+				spentKeyImages = append(spentKeyImages, keyImages[current+i])
+			}
+		}
+	}
+	if toHeight == 0 || len(spentKeyImages) == 0 {
+		return nil
+	}
+
+	spentTxHashes, err := client.GetTxHashBySerialNumbers(spentKeyImages, tokenIDStr, shardID)
+	if err != nil {
+		return err
+	}
+	heights := make(map[string]uint64)
+	for _, c := range knownCoins {
+		if !c.Spent {
+			continue
+		}
+		txHash, ok := spentTxHashes[c.KeyImage]
+		if !ok || txHash == "" {
+			return fmt.Errorf("cannot find the spending transaction of coin %v", c.PublicKey)
+		}
+		height, err := client.getTxHeight(txHash, heights)
+		if err != nil {
+			return err
+		}
+		if height > toHeight {
+			c.Spent = false
+			continue
+		}
+		c.SpentTxHash, c.SpentHeight = txHash, height
+	}
+
+	return nil
+}
+
+// getTxHeight returns the shard height of a transaction, using (and updating) the given cache.
+func (client *IncClient) getTxHeight(txHash string, cache map[string]uint64) (uint64, error) {
+	if height, ok := cache[txHash]; ok {
+		return height, nil
+	}
+	txDetail, err := client.GetTxDetail(txHash)
+	if err != nil {
+		return 0, fmt.Errorf("cannot retrieve tx %v: %v", txHash, err)
+	}
+	if !txDetail.IsInBlock {
+		return 0, fmt.Errorf("tx %v is not in a block", txHash)
+	}
+	cache[txHash] = txDetail.BlockHeight
+
+	return txDetail.BlockHeight, nil
+}
+
+// newAuditAccountReport sorts the coins of an account, and computes the balance of each token.
+func newAuditAccountReport(paymentAddress string, coins []*AuditCoin) *AuditAccountReport {
+	sort.Slice(coins, func(i, j int) bool {
+		if coins[i].TokenID != coins[j].TokenID {
+			return coins[i].TokenID < coins[j].TokenID
+		}
+		if coins[i].Height != coins[j].Height {
+			return coins[i].Height < coins[j].Height
+		}
+		return coins[i].PublicKey < coins[j].PublicKey
+	})
+
+	balances := make(map[string]*AuditBalance)
+	for _, c := range coins {
+		balance, ok := balances[c.TokenID]
+		if !ok {
+			balance = new(AuditBalance)
+			balances[c.TokenID] = balance
+		}
+		balance.NumCoins++
+		balance.Received += c.Amount
+		switch {
+		case c.KeyImage == "":
+			balance.Unknown += c.Amount
+		case c.Spent:
+			balance.Spent += c.Amount
+		default:
+			balance.Unspent += c.Amount
+		}
+	}
+
+	return &AuditAccountReport{
+		PaymentAddress: paymentAddress,
+		Coins:          coins,
+		Balances:       balances,
+	}
+}
@@ -0,0 +1,178 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestAuditPackage(t *testing.T) {
+	w := newRandomWalletInShard(0)
+	privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+	rawCoins, amounts := genEncryptedCoins(w, 5)
+	otherRawCoins, _ := genEncryptedCoins(newRandomWalletInShard(0), 3)
+	outCoins := parseCoins(append(rawCoins, otherRawCoins...))
+
+	account, err := newAuditAccount(nil, privateKey, outCoins)
+	if err != nil {
+		panic(err)
+	}
+	_, expectedKeyImages := decryptCoinsSequentially(&w.KeySet, parseCoins(rawCoins))
+	if len(account.KeyImages) != len(expectedKeyImages) {
+		panic(fmt.Sprintf("expected %v key images, got %v", len(expectedKeyImages), len(account.KeyImages)))
+	}
+	for i, outCoin := range parseCoins(rawCoins) {
+		pubKeyStr := base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte)
+		if account.KeyImages[pubKeyStr] != expectedKeyImages[i] {
+			panic(fmt.Sprintf("wrong key image for coin %v", pubKeyStr))
+		}
+	}
+
+	p := &AuditPackage{Accounts: []*AuditAccount{account}, FromHeight: 10, ToHeight: 20}
+	parsed, err := ParseAuditPackage([]byte(p.String()))
+	if err != nil {
+		panic(err)
+	}
+	keySet, err := parsed.Accounts[0].getKeySet()
+	if err != nil {
+		panic(err)
+	}
+	if len(keySet.PrivateKey) != 0 {
+		panic("the key set of an audit account must not hold a private key")
+	}
+
+	// the keys of an account are encoded with the given network parameters
+	params, err := common.NewNetworkParams(common.MaxShardNumber, 0)
+	if err != nil {
+		panic(err)
+	}
+	oldAccount, err := newAuditAccount(params, privateKey, outCoins)
+	if err != nil {
+		panic(err)
+	}
+	if oldAccount.PaymentAddress != w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params) ||
+		oldAccount.PaymentAddress == account.PaymentAddress {
+		panic(fmt.Sprintf("unexpected payment address %v", oldAccount.PaymentAddress))
+	}
+	if _, err = oldAccount.getKeySet(); err != nil {
+		panic(err)
+	}
+
+	other := newRandomWalletInShard(0)
+	invalidPackages := map[string]*AuditPackage{
+		"no account":     {},
+		"invalid range":  {Accounts: []*AuditAccount{account}, FromHeight: 20, ToHeight: 10},
+		"invalid token":  {Accounts: []*AuditAccount{account}, TokenIDs: []string{"abc"}},
+		"wrong OTA key":  {Accounts: []*AuditAccount{{PaymentAddress: account.PaymentAddress, ReadonlyKey: account.ReadonlyKey, OTAKey: other.Base58CheckSerialize(wallet.OTAKeyType)}}},
+		"wrong view key": {Accounts: []*AuditAccount{{PaymentAddress: account.PaymentAddress, ReadonlyKey: other.Base58CheckSerialize(wallet.ReadonlyKeyType), OTAKey: account.OTAKey}}},
+		"private key":    {Accounts: []*AuditAccount{{PaymentAddress: account.PaymentAddress, ReadonlyKey: privateKey, OTAKey: account.OTAKey}}},
+	}
+	for name, invalidPackage := range invalidPackages {
+		if err = invalidPackage.Validate(); err == nil {
+			panic(fmt.Sprintf("expected an error for a package with %v", name))
+		}
+	}
+
+	// the auditor recomputes the coins of the account from the read-only keys
+	coins, err := newAuditCoins(keySet, account.KeyImages, outCoins, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	if len(coins) != len(amounts) {
+		panic(fmt.Sprintf("expected %v coins, got %v", len(amounts), len(coins)))
+	}
+	var total uint64
+	for i, c := range coins {
+		if c.TokenID != common.PRVIDStr || c.Amount != amounts[i] || c.KeyImage != expectedKeyImages[i] {
+			panic(fmt.Sprintf("unexpected coin %+v", c))
+		}
+		total += c.Amount
+	}
+	coins, err = newAuditCoins(keySet, account.KeyImages, outCoins, nil, []string{common.HashH([]byte("token")).String()})
+	if err != nil {
+		panic(err)
+	}
+	if len(coins) != 0 {
+		panic(fmt.Sprintf("expected no coins for another token, got %v", len(coins)))
+	}
+
+	coins, _ = newAuditCoins(keySet, account.KeyImages, outCoins, nil, nil)
+	coins[0].Spent = true
+	coins[1].KeyImage = ""
+	spent, unknown := coins[0].Amount, coins[1].Amount
+	report := newAuditAccountReport(account.PaymentAddress, coins)
+	balance := report.Balances[common.PRVIDStr]
+	if balance.NumCoins != len(amounts) || balance.Received != total || balance.Spent != spent ||
+		balance.Unknown != unknown || balance.Unspent != total-spent-unknown {
+		panic(fmt.Sprintf("unexpected balance %+v", balance))
+	}
+}
+
+func TestAuditReport_Sign(t *testing.T) {
+	w := newRandomWalletInShard(0)
+	auditor := newRandomWalletInShard(1)
+	rawCoins, _ := genEncryptedCoins(w, 2)
+	account, err := newAuditAccount(nil, w.Base58CheckSerialize(wallet.PrivateKeyType), parseCoins(rawCoins))
+	if err != nil {
+		panic(err)
+	}
+	keySet, err := account.getKeySet()
+	if err != nil {
+		panic(err)
+	}
+	coins, err := newAuditCoins(keySet, account.KeyImages, parseCoins(rawCoins), nil, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	report := &AuditReport{Accounts: []*AuditAccountReport{newAuditAccountReport(account.PaymentAddress, coins)}}
+	if err = report.Sign(auditor.Base58CheckSerialize(wallet.PrivateKeyType)); err != nil {
+		panic(err)
+	}
+	parsed, err := ParseAuditReport([]byte(report.String()))
+	if err != nil {
+		panic(err)
+	}
+	if err = parsed.Verify(); err != nil {
+		panic(err)
+	}
+	auditorPubKey := base58.Base58Check{}.Encode(auditor.KeySet.PaymentAddress.Pk, common.ZeroByte)
+	if parsed.AuditorPublicKey != auditorPubKey {
+		panic(fmt.Sprintf("unexpected auditor public key %v", parsed.AuditorPublicKey))
+	}
+
+	// a signature on the bare hash of the report is not valid
+	hash, err := parsed.Hash()
+	if err != nil {
+		panic(err)
+	}
+	sigKey := new(privacy.SchnorrPrivateKey)
+	sigKey.Set(new(crypto.Scalar).FromBytesS(auditor.KeySet.PrivateKey), new(crypto.Scalar).FromUint64(0))
+	signature, err := sigKey.Sign(hash[:])
+	if err != nil {
+		panic(err)
+	}
+	bareParsed := *parsed
+	bareParsed.Signature = base58.Base58Check{}.Encode(signature.Bytes(), common.ZeroByte)
+	if err = bareParsed.Verify(); err == nil {
+		panic("expected an error for a signature without the domain-separation prefix")
+	}
+
+	parsed.Accounts[0].Coins[0].Amount++
+	if err = parsed.Verify(); err == nil {
+		panic("expected an error for a tampered report")
+	}
+	parsed.Accounts[0].Coins[0].Amount--
+	parsed.AuditorPublicKey = base58.Base58Check{}.Encode(w.KeySet.PaymentAddress.Pk, common.ZeroByte)
+	if err = parsed.Verify(); err == nil {
+		panic("expected an error for another auditor")
+	}
+	if err = report.Sign(w.Base58CheckSerialize(wallet.PaymentAddressType)); err == nil {
+		panic("expected an error when signing without a private key")
+	}
+}
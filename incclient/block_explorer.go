@@ -0,0 +1,277 @@
+package incclient
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction"
+)
+
+// blockVerbosityWithTxs is the verbosity level at which the full-node returns the data of the transactions of a block.
+const blockVerbosityWithTxs = "2"
+
+// BlockTx is a decoded transaction of a shard block.
+type BlockTx struct {
+	// Hash is the hash of the transaction reported by the full-node.
+	Hash string
+
+	// LockTime is the lock time of the transaction.
+	LockTime int64
+
+	// Tx is the decoded transaction.
+	Tx metadata.Transaction
+
+	// Metadata is the parsed metadata of the transaction (if any).
+	Metadata metadata.Metadata
+}
+
+// GetShardBlockByHash returns the shard block with the given hash, including the data of its transactions.
+func (client *IncClient) GetShardBlockByHash(blockHash string) (*jsonresult.ShardBlock, error) {
+	responseInBytes, err := client.rpcServer.RetrieveBlock(blockHash, blockVerbosityWithTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	var res jsonresult.ShardBlock
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetShardBlocksByHeight returns all shard blocks of a shard at the given height, including the data of their
+// transactions. More than one block is returned if the height has not been finalized yet.
+func (client *IncClient) GetShardBlocksByHeight(shardID byte, height uint64) ([]*jsonresult.ShardBlock, error) {
+	if !client.networkParams.IsValidShardID(int(shardID)) {
+		return nil, fmt.Errorf("shardID out of range")
+	}
+
+	responseInBytes, err := client.rpcServer.RetrieveBlockByHeight(height, shardID, blockVerbosityWithTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*jsonresult.ShardBlock
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetShardBlockByHeight returns the first shard block of a shard at the given height. See GetShardBlocksByHeight.
+func (client *IncClient) GetShardBlockByHeight(shardID byte, height uint64) (*jsonresult.ShardBlock, error) {
+	blocks, err := client.GetShardBlocksByHeight(shardID, height)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("block %v of shard %v not found", height, shardID)
+	}
+
+	return blocks[0], nil
+}
+
+// GetBeaconBlockByHash returns the beacon block with the given hash.
+func (client *IncClient) GetBeaconBlockByHash(blockHash string) (*jsonresult.BeaconBlock, error) {
+	responseInBytes, err := client.rpcServer.RetrieveBeaconBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var res jsonresult.BeaconBlock
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetBeaconBlocksByHeight returns all beacon blocks at the given height.
+func (client *IncClient) GetBeaconBlocksByHeight(height uint64) ([]*jsonresult.BeaconBlock, error) {
+	responseInBytes, err := client.rpcServer.RetrieveBeaconBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*jsonresult.BeaconBlock
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetBeaconBlockByHeight returns the first beacon block at the given height. See GetBeaconBlocksByHeight.
+func (client *IncClient) GetBeaconBlockByHeight(height uint64) (*jsonresult.BeaconBlock, error) {
+	blocks, err := client.GetBeaconBlocksByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("beacon block %v not found", height)
+	}
+
+	return blocks[0], nil
+}
+
+// DecodeShardBlockTxs decodes all transactions of a shard block. The block must be retrieved with the data of its
+// transactions (e.g, by GetShardBlockByHash or GetShardBlockByHeight).
+func DecodeShardBlockTxs(block *jsonresult.ShardBlock) ([]*BlockTx, error) {
+	if block == nil {
+		return nil, fmt.Errorf("block is nil")
+	}
+	if len(block.Txs) == 0 && len(block.TxHashes) > 0 {
+		return nil, fmt.Errorf("block %v has no transaction data", block.Hash)
+	}
+
+	res := make([]*BlockTx, 0)
+	for _, blockTx := range block.Txs {
+		tx, err := DecodeBlockTx(blockTx)
+		if err != nil {
+			return nil, fmt.Errorf("block %v: %v", block.Hash, err)
+		}
+		res = append(res, tx)
+	}
+
+	return res, nil
+}
+
+// DecodeBlockTx decodes a transaction of a shard block.
+func DecodeBlockTx(blockTx jsonresult.BlockTx) (*BlockTx, error) {
+	txBytes, err := hex.DecodeString(blockTx.HexData)
+	if err != nil {
+		return nil, fmt.Errorf("cannot hex-decode tx %v: %v", blockTx.Hash, err)
+	}
+
+	txChoice, err := transaction.DeserializeTransactionJSON(txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode tx %v: %v", blockTx.Hash, err)
+	}
+	tx := txChoice.ToTx()
+	if tx == nil {
+		return nil, fmt.Errorf("cannot decode tx %v", blockTx.Hash)
+	}
+
+	// the metadata has been parsed with metadata.ParseMetadata during the deserialization
+	return &BlockTx{
+		Hash:     blockTx.Hash,
+		LockTime: blockTx.Locktime,
+		Tx:       tx,
+		Metadata: tx.GetMetadata(),
+	}, nil
+}
+
+// ShardBlockIterator walks through the blocks of a shard within a height range. When several blocks exist at the same
+// height, the one linked to the previously returned block is chosen.
+//
+// Example:
+//
+//	it, err := client.NewShardBlockIterator(0, 100, 200)
+//	for it.Next() {
+//		block := it.Block()
+//		...
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+type ShardBlockIterator struct {
+	client  *IncClient
+	shardID byte
+
+	nextHeight uint64
+	toHeight   uint64
+
+	block *jsonresult.ShardBlock
+	err   error
+}
+
+// NewShardBlockIterator creates a new ShardBlockIterator that walks through the blocks of a shard from fromHeight to
+// toHeight (inclusive). If toHeight is 0, it defaults to the current best height of the shard.
+func (client *IncClient) NewShardBlockIterator(shardID byte, fromHeight, toHeight uint64) (*ShardBlockIterator, error) {
+	if !client.networkParams.IsValidShardID(int(shardID)) {
+		return nil, fmt.Errorf("shardID out of range")
+	}
+	if fromHeight == 0 {
+		fromHeight = 1
+	}
+	if toHeight == 0 {
+		bestBlocks, err := client.GetBestBlock()
+		if err != nil {
+			return nil, err
+		}
+		toHeight = bestBlocks[int(shardID)]
+	}
+	if fromHeight > toHeight {
+		return nil, fmt.Errorf("fromHeight (%v) is greater than toHeight (%v)", fromHeight, toHeight)
+	}
+
+	return &ShardBlockIterator{
+		client:     client,
+		shardID:    shardID,
+		nextHeight: fromHeight,
+		toHeight:   toHeight,
+	}, nil
+}
+
+// Next retrieves the next block of the range. It returns false when the range has been walked through, or an error
+// occurred (see Err). Except for the first one, each block must link to the previous block of the iteration; if none
+// of the blocks at the next height does (e.g., the chain has been re-organized), the iteration stops with an error.
+func (it *ShardBlockIterator) Next() bool {
+	if it.err != nil || it.nextHeight > it.toHeight {
+		return false
+	}
+
+	blocks, err := it.client.GetShardBlocksByHeight(it.shardID, it.nextHeight)
+	if err != nil {
+		it.err = fmt.Errorf("cannot get block %v of shard %v: %v", it.nextHeight, it.shardID, err)
+		return false
+	}
+	if len(blocks) == 0 {
+		it.err = fmt.Errorf("block %v of shard %v not found", it.nextHeight, it.shardID)
+		return false
+	}
+
+	block := blocks[0]
+	if it.block != nil {
+		block = nil
+		for _, tmpBlock := range blocks {
+			if tmpBlock.PreviousBlockHash == it.block.Hash {
+				block = tmpBlock
+				break
+			}
+		}
+		if block == nil {
+			it.err = fmt.Errorf("no block %v of shard %v links to the previous block %v",
+				it.nextHeight, it.shardID, it.block.Hash)
+			return false
+		}
+	}
+	it.block = block
+	it.nextHeight++
+
+	return true
+}
+
+// Block returns the current block.
+func (it *ShardBlockIterator) Block() *jsonresult.ShardBlock {
+	return it.block
+}
+
+// Txs decodes and returns the transactions of the current block.
+func (it *ShardBlockIterator) Txs() ([]*BlockTx, error) {
+	return DecodeShardBlockTxs(it.block)
+}
+
+// Err returns the error (if any) that stopped the iteration.
+func (it *ShardBlockIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,253 @@
+package incclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+)
+
+// mockBlockNode serves the blocks of a single shard and the beacon chain via retrieveblock, retrieveblockbyheight,
+// retrievebeaconblockbyheight and getbestblock.
+type mockBlockNode struct {
+	mtx          sync.Mutex
+	shardID      byte
+	blocks       map[uint64][]*jsonresult.ShardBlock
+	best         uint64
+	beaconBlocks map[uint64][]*jsonresult.BeaconBlock
+	beaconBest   uint64
+}
+
+func (n *mockBlockNode) addBlock(height uint64, hash, prevHash string, txs ...jsonresult.BlockTx) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.blocks == nil {
+		n.blocks = make(map[uint64][]*jsonresult.ShardBlock)
+	}
+	block := &jsonresult.ShardBlock{
+		Hash:              hash,
+		ShardID:           n.shardID,
+		Height:            height,
+		PreviousBlockHash: prevHash,
+		Txs:               txs,
+	}
+	for _, tx := range txs {
+		block.TxHashes = append(block.TxHashes, tx.Hash)
+	}
+	n.blocks[height] = append(n.blocks[height], block)
+	if height > n.best {
+		n.best = height
+	}
+}
+
+// replaceBlock replaces all blocks at the given height, simulating a re-org.
+func (n *mockBlockNode) replaceBlock(height uint64, hash, prevHash string, txs ...jsonresult.BlockTx) {
+	n.mtx.Lock()
+	delete(n.blocks, height)
+	n.mtx.Unlock()
+	n.addBlock(height, hash, prevHash, txs...)
+}
+
+func (n *mockBlockNode) addBeaconBlock(height uint64, hash, prevHash string, instructions ...[]string) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.beaconBlocks == nil {
+		n.beaconBlocks = make(map[uint64][]*jsonresult.BeaconBlock)
+	}
+	n.beaconBlocks[height] = append(n.beaconBlocks[height], &jsonresult.BeaconBlock{
+		Hash:              hash,
+		Height:            height,
+		PreviousBlockHash: prevHash,
+		Instructions:      instructions,
+	})
+	if height > n.beaconBest {
+		n.beaconBest = height
+	}
+}
+
+func (n *mockBlockNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Method string
+		Params []interface{}
+	}
+	_ = json.Unmarshal(body, &req)
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var result interface{}
+	switch req.Method {
+	case "getbestblock":
+		result = jsonresult.BestBlockResult{BestBlocks: map[int]jsonresult.BestBlockItem{
+			-1:             {Height: n.beaconBest},
+			int(n.shardID): {Height: n.best},
+		}}
+	case "retrieveblockbyheight":
+		result = n.blocks[uint64(req.Params[0].(float64))]
+	case "retrievebeaconblockbyheight":
+		result = n.beaconBlocks[uint64(req.Params[0].(float64))]
+	case "retrieveblock":
+		for _, blocks := range n.blocks {
+			for _, block := range blocks {
+				if block.Hash == req.Params[0].(string) {
+					result = block
+				}
+			}
+		}
+	}
+	jsb, _ := json.Marshal(result)
+	resp, _ := json.Marshal(map[string]interface{}{"Result": json.RawMessage(jsb), "Error": nil})
+	_, _ = w.Write(resp)
+}
+
+func newTestBlockTx(amount uint64) (jsonresult.BlockTx, string) {
+	w := newRandomWalletInShard(0)
+	privateKey := w.KeySet.PrivateKey
+	otaCoin, err := coin.NewCoinFromPaymentInfo(coin.NewMintCoinParams(
+		key.InitPaymentInfo(w.KeySet.PaymentAddress, amount, []byte{})))
+	if err != nil {
+		panic(err)
+	}
+	md := metadata.NewPortalV4UnshieldResponse("accepted", common.Hash{}, "", "", amount,
+		common.PRVIDStr, metadata.PortalV4UnshieldingResponseMeta)
+
+	tx := new(tx_ver2.Tx)
+	err = tx.InitTxSalary(otaCoin, &privateKey, md)
+	if err != nil {
+		panic(err)
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		panic(err)
+	}
+
+	return jsonresult.BlockTx{
+		Hash:     tx.Hash().String(),
+		Locktime: tx.LockTime,
+		HexData:  hex.EncodeToString(txBytes),
+	}, tx.Hash().String()
+}
+
+func TestIncClient_GetShardBlockByHeight(t *testing.T) {
+	node := &mockBlockNode{shardID: 0}
+	blockTx, txHash := newTestBlockTx(1000)
+	node.addBlock(1, "h1", "h0", blockTx)
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL)}
+
+	block, err := client.GetShardBlockByHeight(0, 1)
+	if err != nil {
+		panic(err)
+	}
+	if block.Hash != "h1" || len(block.Txs) != 1 {
+		panic(fmt.Sprintf("unexpected block %v", block))
+	}
+
+	txs, err := DecodeShardBlockTxs(block)
+	if err != nil {
+		panic(err)
+	}
+	if len(txs) != 1 {
+		panic(fmt.Sprintf("expected 1 tx, got %v", len(txs)))
+	}
+	if txs[0].Tx.Hash().String() != txHash {
+		panic(fmt.Sprintf("expected tx %v, got %v", txHash, txs[0].Tx.Hash().String()))
+	}
+	if txs[0].Metadata == nil || txs[0].Metadata.GetType() != metadata.PortalV4UnshieldingResponseMeta {
+		panic(fmt.Sprintf("unexpected metadata %v", txs[0].Metadata))
+	}
+
+	_, err = client.GetShardBlockByHeight(0, 2)
+	if err == nil {
+		panic("expected an error for a missing block")
+	}
+	_, err = client.GetShardBlockByHeight(byte(common.MaxShardNumber), 1)
+	if err == nil {
+		panic("expected an error for an invalid shard")
+	}
+
+	block, err = client.GetShardBlockByHash("h1")
+	if err != nil {
+		panic(err)
+	}
+	if block.Height != 1 {
+		panic(fmt.Sprintf("expected height 1, got %v", block.Height))
+	}
+
+	block.Txs = nil
+	_, err = DecodeShardBlockTxs(block)
+	if err == nil {
+		panic("expected an error for a block without transaction data")
+	}
+}
+
+func TestShardBlockIterator(t *testing.T) {
+	node := &mockBlockNode{shardID: 0}
+	node.addBlock(1, "h1", "h0")
+	node.addBlock(2, "h2", "h1")
+	// an orphaned block is returned first at height 3
+	node.addBlock(3, "h3-orphan", "h2-orphan")
+	node.addBlock(3, "h3", "h2")
+	node.addBlock(4, "h4", "h3")
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL)}
+
+	it, err := client.NewShardBlockIterator(0, 0, 0)
+	if err != nil {
+		panic(err)
+	}
+	hashes := make([]string, 0)
+	for it.Next() {
+		hashes = append(hashes, it.Block().Hash)
+	}
+	if it.Err() != nil {
+		panic(it.Err())
+	}
+	expected := []string{"h1", "h2", "h3", "h4"}
+	if fmt.Sprintf("%v", hashes) != fmt.Sprintf("%v", expected) {
+		panic(fmt.Sprintf("expected %v, got %v", expected, hashes))
+	}
+
+	it, err = client.NewShardBlockIterator(0, 4, 5)
+	if err != nil {
+		panic(err)
+	}
+	for it.Next() {
+	}
+	if it.Err() == nil {
+		panic("expected an error for a missing block")
+	}
+
+	// the iteration stops when no block links to the previous one
+	node.addBlock(5, "h5-orphan", "h4-orphan")
+	it, err = client.NewShardBlockIterator(0, 4, 5)
+	if err != nil {
+		panic(err)
+	}
+	hashes = make([]string, 0)
+	for it.Next() {
+		hashes = append(hashes, it.Block().Hash)
+	}
+	if it.Err() == nil || len(hashes) != 1 {
+		panic(fmt.Sprintf("expected an error for a broken chain, got %v", hashes))
+	}
+
+	_, err = client.NewShardBlockIterator(0, 3, 2)
+	if err == nil {
+		panic("expected an error for an invalid range")
+	}
+}
@@ -78,6 +78,29 @@ func (client *IncClient) GetListToken() (map[string]CustomToken, error) {
 	return listTokens, nil
 }
 
+// GetTokenSymbols returns a map from tokenIDs to their symbols for all tokens currently on the Incognito network.
+func (client *IncClient) GetTokenSymbols() (map[string]string, error) {
+	responseInBytes, err := client.rpcServer.ListPrivacyCustomTokenByRPC()
+	if err != nil {
+		return nil, err
+	}
+	var res rpc.ListCustomToken
+	err = json.Unmarshal(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	symbols := map[string]string{common.PRVIDStr: "PRV"}
+	for _, token := range res.Result.ListCustomToken {
+		symbols[token.ID] = token.Symbol
+	}
+
+	return symbols, nil
+}
+
 // GetListTokenIDs returns all token IDs currently on the Incognito network.
 func (client *IncClient) GetListTokenIDs() ([]string, error) {
 	responseInBytes, err := client.rpcServer.ListPrivacyCustomTokenIDsByRPC()
@@ -133,7 +156,7 @@ func (client *IncClient) GetCommitteeStateByShard(shardID int, shardRootHash str
 
 // GetShardBestState returns the latest state of a shard chain.
 func (client *IncClient) GetShardBestState(shardID int) (*jsonresult.ShardBestState, error) {
-	if shardID < 0 || shardID >= common.MaxShardNumber {
+	if !client.networkParams.IsValidShardID(shardID) {
 		return nil, fmt.Errorf("shardID out of range")
 	}
 
@@ -153,7 +176,7 @@ func (client *IncClient) GetShardBestState(shardID int) (*jsonresult.ShardBestSt
 
 // GetBeaconBestState returns the latest state of the beacon chain.
 func (client *IncClient) GetBeaconBestState(shardID int) (*jsonresult.BeaconBestState, error) {
-	if shardID < 0 || shardID >= common.MaxShardNumber {
+	if !client.networkParams.IsValidShardID(shardID) {
 		return nil, fmt.Errorf("shardID out of range")
 	}
 
@@ -110,6 +110,7 @@ func (client *IncClient) CreateAndSendIssuingRequestTransaction(privateKey,
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) CreateIssuingEVMRequestTransaction(privateKey, tokenIDStr string, proof EVMDepositProof, evmNetworkID ...int) ([]byte, string, error) {
 	tokenID, err := new(common.Hash).NewHashFromStr(tokenIDStr)
@@ -121,10 +122,10 @@ func (client *IncClient) CreateIssuingEVMRequestTransaction(privateKey, tokenIDS
 	if len(evmNetworkID) > 0 {
 		networkID = evmNetworkID[0]
 	}
-	if _, ok := rpc.EVMIssuingMetadata[networkID]; !ok {
-		return nil, "", fmt.Errorf("networkID %v not found", networkID)
+	mdType, err := rpc.GetEVMIssuingMetadataType(networkID)
+	if err != nil {
+		return nil, "", err
 	}
-	mdType := rpc.EVMIssuingMetadata[networkID]
 
 	var issuingETHRequestMeta *metadata.IssuingEVMRequest
 	issuingETHRequestMeta, err = metadata.NewIssuingEVMRequest(proof.blockHash, proof.txIdx, proof.nodeList, *tokenID, mdType)
@@ -145,6 +146,7 @@ func (client *IncClient) CreateIssuingEVMRequestTransaction(privateKey, tokenIDS
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) CreateAndSendIssuingEVMRequestTransaction(privateKey, tokenIDStr string, proof EVMDepositProof, evmNetworkID ...int) (string, error) {
 	encodedTx, txHash, err := client.CreateIssuingEVMRequestTransaction(privateKey, tokenIDStr, proof, evmNetworkID...)
@@ -169,6 +171,7 @@ func (client *IncClient) CreateAndSendIssuingEVMRequestTransaction(privateKey, t
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) CreateBurningRequestTransaction(privateKey, remoteAddress, tokenIDStr string, burnedAmount uint64, evmNetworkID ...int) ([]byte, string, error) {
 	if tokenIDStr == common.PRVIDStr {
@@ -185,7 +188,7 @@ func (client *IncClient) CreateBurningRequestTransaction(privateKey, remoteAddre
 		return nil, "", fmt.Errorf("cannot deserialize the sender private key")
 	}
 	burnerAddress := senderWallet.KeySet.PaymentAddress
-	if common.AddressVersion == 0 {
+	if client.networkParams.GetAddressVersion() == 0 {
 		burnerAddress.OTAPublic = nil
 	}
 
@@ -197,10 +200,10 @@ func (client *IncClient) CreateBurningRequestTransaction(privateKey, remoteAddre
 	if len(evmNetworkID) > 0 {
 		networkID = evmNetworkID[0]
 	}
-	if _, ok := rpc.EVMBurningMetadata[networkID]; !ok {
-		return nil, "", fmt.Errorf("networkID %v not found", networkID)
+	mdType, err := rpc.GetEVMBurningMetadataType(networkID)
+	if err != nil {
+		return nil, "", err
 	}
-	mdType := rpc.EVMBurningMetadata[networkID]
 
 	var md *metadata.BurningRequest
 	md, err = metadata.NewBurningRequest(burnerAddress, burnedAmount, *tokenID, tokenIDStr, remoteAddress, mdType)
@@ -223,6 +226,7 @@ func (client *IncClient) CreateBurningRequestTransaction(privateKey, remoteAddre
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) CreateAndSendBurningRequestTransaction(privateKey, remoteAddress, tokenIDStr string, burnedAmount uint64, evmNetworkID ...int) (string, error) {
 	encodedTx, txHash, err := client.CreateBurningRequestTransaction(privateKey, remoteAddress, tokenIDStr, burnedAmount, evmNetworkID...)
@@ -245,6 +249,7 @@ func (client *IncClient) CreateAndSendBurningRequestTransaction(privateKey, remo
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetBurnProof(txHash string, evmNetworkID ...int) (*jsonresult.InstructionProof, error) {
 	responseInBytes, err := client.rpcServer.GetBurnProof(txHash, evmNetworkID...)
@@ -0,0 +1,82 @@
+package incclient
+
+import (
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+// EVMNetworkParams consists of all parameters needed to interact with an EVM-compatible network via the Incognito bridge.
+type EVMNetworkParams struct {
+	rpc.EVMNetworkInfo
+
+	// Host is the EVM-RPC endpoint of the network.
+	Host string
+
+	// VaultAddress is the address of the Incognito vault contract on the network.
+	VaultAddress string
+}
+
+// RegisterEVMNetwork adds a new EVM-compatible network (e.g. Avalanche, Aurora) to the client. After the registration,
+// evmNetworkID can be passed to GetEVMDepositProof, GetBurnProof, CreateIssuingEVMRequestTransaction,
+// CreateBurningRequestTransaction, etc.
+//
+// The metadata types and burn-proof RPC method are registered process-wide, so that other clients can use the same
+// evmNetworkID by calling this function with the same parameters (and their own Host). The function is not thread-safe
+// w.r.t other bridge functions of the same client; it should be called right after the client is created.
+func (client *IncClient) RegisterEVMNetwork(evmNetworkID int, params EVMNetworkParams) error {
+	if params.Host == "" {
+		return fmt.Errorf("EVM host must be specified")
+	}
+	if _, ok := client.evmServers[evmNetworkID]; ok {
+		return fmt.Errorf("EVMNetworkID %v already registered", evmNetworkID)
+	}
+
+	if rpc.IsEVMNetworkSupported(evmNetworkID) {
+		issuingType, err := rpc.GetEVMIssuingMetadataType(evmNetworkID)
+		if err != nil {
+			return err
+		}
+		burningType, err := rpc.GetEVMBurningMetadataType(evmNetworkID)
+		if err != nil {
+			return err
+		}
+		if issuingType != params.IssuingMetadataType || burningType != params.BurningMetadataType {
+			return fmt.Errorf("EVMNetworkID %v already registered with different metadata types (%v, %v)",
+				evmNetworkID, issuingType, burningType)
+		}
+	} else {
+		err := rpc.RegisterEVMNetwork(evmNetworkID, params.EVMNetworkInfo)
+		if err != nil {
+			return err
+		}
+	}
+
+	if client.evmServers == nil {
+		client.evmServers = make(map[int]*rpc.RPCServer)
+	}
+	client.evmServers[evmNetworkID] = rpc.NewRPCServer(params.Host)
+	if client.evmVaults == nil {
+		client.evmVaults = make(map[int]string)
+	}
+	client.evmVaults[evmNetworkID] = params.VaultAddress
+
+	return nil
+}
+
+// GetEVMVaultAddress returns the address of the Incognito vault contract on the given EVM network.
+//
+// If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
+func (client *IncClient) GetEVMVaultAddress(evmNetworkID ...int) (string, error) {
+	networkID := rpc.ETHNetworkID
+	if len(evmNetworkID) > 0 {
+		networkID = evmNetworkID[0]
+	}
+
+	vaultAddress, ok := client.evmVaults[networkID]
+	if !ok || vaultAddress == "" {
+		return "", rpc.EVMNetworkNotFoundError(networkID)
+	}
+
+	return vaultAddress, nil
+}
@@ -0,0 +1,90 @@
+package incclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	rCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+func TestIncClient_RegisterEVMNetwork(t *testing.T) {
+	avaxNetworkID := rpc.FTMNetworkID + 100
+	params := EVMNetworkParams{
+		EVMNetworkInfo: rpc.EVMNetworkInfo{
+			Name:                        "Avalanche",
+			IssuingMetadataType:         1000,
+			IssuingResponseMetadataType: 1001,
+			BurningMetadataType:         1002,
+			BurnProofRPCMethod:          "getavaxburnproof",
+		},
+		Host:         "https://api.avax-test.network/ext/bc/C/rpc",
+		VaultAddress: "0x0000000000000000000000000000000000000001",
+	}
+
+	client := &IncClient{}
+	err := client.RegisterEVMNetwork(avaxNetworkID, params)
+	if err != nil {
+		panic(err)
+	}
+	if err = client.RegisterEVMNetwork(avaxNetworkID, params); err == nil {
+		panic("expected an error when registering the same network twice")
+	}
+
+	// another client could re-use the same network, but not with different metadata types.
+	otherClient := &IncClient{}
+	if err = otherClient.RegisterEVMNetwork(avaxNetworkID, params); err != nil {
+		panic(err)
+	}
+	params.BurningMetadataType = 1003
+	if err = (&IncClient{}).RegisterEVMNetwork(avaxNetworkID, params); err == nil {
+		panic("expected an error when registering with different metadata types")
+	}
+
+	vault, err := client.GetEVMVaultAddress(avaxNetworkID)
+	if err != nil {
+		panic(err)
+	}
+	if vault != "0x0000000000000000000000000000000000000001" {
+		panic("invalid vault address")
+	}
+
+	mdType, err := rpc.GetEVMBurningMetadataType(avaxNetworkID)
+	if err != nil {
+		panic(err)
+	}
+	if mdType != 1002 {
+		panic("invalid burning metadata type")
+	}
+
+	mdBytes, _ := json.Marshal(map[string]interface{}{"Type": 1002, "BurningAmount": 100})
+	md, err := metadata.ParseMetadata(mdBytes)
+	if err != nil {
+		panic(err)
+	}
+	if _, ok := md.(*metadata.BurningRequest); !ok {
+		panic("expected a BurningRequest metadata")
+	}
+}
+
+func TestIncClient_checkEVMDepositReceipt(t *testing.T) {
+	vault := "0x0000000000000000000000000000000000000001"
+	client := &IncClient{evmVaults: map[int]string{rpc.ETHNetworkID: vault}}
+
+	receipt := &types.Receipt{Logs: []*types.Log{{Address: rCommon.HexToAddress(vault)}}}
+	if err := client.checkEVMDepositReceipt(receipt); err != nil {
+		panic(err)
+	}
+
+	receipt = &types.Receipt{Logs: []*types.Log{{Address: rCommon.HexToAddress(EVMZeroAddress)}}}
+	if err := client.checkEVMDepositReceipt(receipt); err == nil {
+		panic("expected an error for a receipt without vault logs")
+	}
+
+	// the check is skipped for networks without a known vault.
+	if err := client.checkEVMDepositReceipt(receipt, rpc.BSCNetworkID); err != nil {
+		panic(err)
+	}
+}
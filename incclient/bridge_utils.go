@@ -37,6 +37,7 @@ type BridgeTokenInfo struct {
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetEVMTxByHash(txHash string, evmNetworkID ...int) (map[string]interface{}, error) {
 	networkID := rpc.ETHNetworkID
@@ -117,6 +118,7 @@ func (client *IncClient) GetEVMBlockByHash(blockHash string, evmNetworkID ...int
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetEVMTxReceipt(txHash string, evmNetworkID ...int) (*types.Receipt, error) {
 	networkID := rpc.ETHNetworkID
@@ -153,13 +155,15 @@ func (client *IncClient) GetEVMTxReceipt(txHash string, evmNetworkID ...int) (*t
 	return &res, nil
 }
 
-// GetEVMDepositProof retrieves an EVM-depositing proof of a transaction hash.
+// GetEVMDepositProof retrieves an EVM-depositing proof of a transaction hash. It returns an error if the transaction
+// does not emit any log from the Incognito vault of the network (see GetEVMVaultAddress).
 //
 // An additional parameter `evmNetworkID` is introduced to specify the target EVM network. evmNetworkID can be one of the following:
 //	- rpc.ETHNetworkID: the Ethereum network
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetEVMDepositProof(txHash string, evmNetworkID ...int) (*EVMDepositProof, uint64, error) {
 	// Get tx content
@@ -269,6 +273,14 @@ func (client *IncClient) GetEVMDepositProof(txHash string, evmNetworkID ...int)
 		receipts = append(receipts, siblingReceipt)
 	}
 
+	if txIndex >= uint64(len(receipts)) {
+		return nil, 0, fmt.Errorf("receipt of tx %v not found in block %v", txHash, blockHashStr)
+	}
+	err = client.checkEVMDepositReceipt(receipts[txIndex], evmNetworkID...)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	receiptList := types.Receipts(receipts)
 	receiptTrie.Reset()
 
@@ -321,6 +333,24 @@ func (client *IncClient) GetEVMDepositProof(txHash string, evmNetworkID ...int)
 	return NewETHDepositProof(uint(blockNumber), blockHash, uint(txIndex), encNodeList), amount, nil
 }
 
+// checkEVMDepositReceipt checks if the given receipt contains a log emitted by the Incognito vault of the EVM network.
+// The check is skipped if the client does not know the vault address of the network.
+func (client *IncClient) checkEVMDepositReceipt(receipt *types.Receipt, evmNetworkID ...int) error {
+	vaultAddress, err := client.GetEVMVaultAddress(evmNetworkID...)
+	if err != nil {
+		return nil
+	}
+
+	vault := rCommon.HexToAddress(vaultAddress)
+	for _, log := range receipt.Logs {
+		if log != nil && log.Address == vault {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("tx %v is not a deposit to the vault %v", receipt.TxHash.String(), vaultAddress)
+}
+
 // GetMostRecentEVMBlockNumber retrieves the most recent EVM block number.
 //
 // An additional parameter `evmNetworkID` is introduced to specify the target EVM network. evmNetworkID can be one of the following:
@@ -328,6 +358,7 @@ func (client *IncClient) GetEVMDepositProof(txHash string, evmNetworkID ...int)
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetMostRecentEVMBlockNumber(evmNetworkID ...int) (uint64, error) {
 	networkID := rpc.ETHNetworkID
@@ -377,6 +408,7 @@ func (client *IncClient) GetMostRecentEVMBlockNumber(evmNetworkID ...int) (uint6
 //	- rpc.BSCNetworkID: the Binance Smart Chain network
 //	- rpc.PLGNetworkID: the Polygon network
 //	- rpc.FTMNetworkID: the Fantom network
+//	- any network registered via IncClient.RegisterEVMNetwork
 // If set empty, evmNetworkID defaults to rpc.ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (client *IncClient) GetEVMTransactionStatus(txHash string, evmNetworkID ...int) (int, error) {
 	receipt, err := client.GetEVMTxReceipt(txHash, evmNetworkID...)
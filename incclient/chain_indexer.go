@@ -0,0 +1,426 @@
+package incclient
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+)
+
+const (
+	// BeaconChainID is the chainID of the beacon chain, as used by GetBestBlock and the ChainIndexer.
+	BeaconChainID = -1
+
+	// DefaultIndexerMaxReorgDepth is the default maximum number of blocks a ChainIndexer rolls back on a re-org.
+	DefaultIndexerMaxReorgDepth = 10
+)
+
+// ChainIndexer follows the beacon chain and the shard chains, and writes normalized rows of their blocks into an
+// IndexSink. It resumes from the last block stored in the sink (or from a start height if the sink is empty), and
+// handles short re-orgs by checking the PreviousBlockHash of each new block against the last stored block.
+type ChainIndexer struct {
+	client *IncClient
+	sink   IndexSink
+
+	chainIDs      []int
+	startHeights  map[int]uint64
+	maxReorgDepth int
+}
+
+// NewChainIndexer creates a new ChainIndexer writing into the given sink. By default, it follows the beacon chain and
+// all shard chains from height 1.
+func NewChainIndexer(client *IncClient, sink IndexSink) (*ChainIndexer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not found")
+	}
+	if sink == nil {
+		return nil, fmt.Errorf("sink not found")
+	}
+
+	chainIDs := []int{BeaconChainID}
+	for shardID := 0; shardID < client.networkParams.GetMaxShardNumber(); shardID++ {
+		chainIDs = append(chainIDs, shardID)
+	}
+
+	return &ChainIndexer{
+		client:        client,
+		sink:          sink,
+		chainIDs:      chainIDs,
+		startHeights:  make(map[int]uint64),
+		maxReorgDepth: DefaultIndexerMaxReorgDepth,
+	}, nil
+}
+
+// SetChains sets the chains (BeaconChainID or shardIDs) followed by the indexer.
+func (idx *ChainIndexer) SetChains(chainIDs ...int) error {
+	if len(chainIDs) == 0 {
+		return fmt.Errorf("no chain provided")
+	}
+	for _, chainID := range chainIDs {
+		if chainID != BeaconChainID && !idx.client.networkParams.IsValidShardID(chainID) {
+			return fmt.Errorf("chainID %v out of range", chainID)
+		}
+	}
+	idx.chainIDs = chainIDs
+
+	return nil
+}
+
+// SetStartHeight sets the checkpoint from which a chain is indexed when the sink has no block of that chain.
+func (idx *ChainIndexer) SetStartHeight(chainID int, height uint64) {
+	idx.startHeights[chainID] = height
+}
+
+// SetMaxReorgDepth sets the maximum number of blocks the indexer rolls back on a re-org. A deeper re-org stops the
+// indexing of the chain with an error.
+func (idx *ChainIndexer) SetMaxReorgDepth(depth int) {
+	idx.maxReorgDepth = depth
+}
+
+// IndexOnce indexes each followed chain up to its current best height. It returns the latest indexed height of each
+// chain.
+func (idx *ChainIndexer) IndexOnce() (map[int]uint64, error) {
+	bestBlocks, err := idx.client.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int]uint64)
+	for _, chainID := range idx.chainIDs {
+		bestHeight, ok := bestBlocks[chainID]
+		if !ok {
+			return res, fmt.Errorf("best block of chain %v not found", chainID)
+		}
+		height, err := idx.IndexChain(chainID, bestHeight)
+		if err != nil {
+			return res, fmt.Errorf("chain %v: %v", chainID, err)
+		}
+		res[chainID] = height
+	}
+
+	return res, nil
+}
+
+// IndexChain indexes a chain up to toHeight and returns the latest indexed height. When the next block does not link
+// to the last stored block, the stored block is rolled back and the indexing resumes from the previous height. A rolled
+// back block is never picked again during the same call.
+func (idx *ChainIndexer) IndexChain(chainID int, toHeight uint64) (uint64, error) {
+	reorgDepth := 0
+	reorgHeight := uint64(0)                           // the height at which the current re-org has been detected
+	orphanedHashes := make(map[uint64]map[string]bool) // height => hashes of the rolled back blocks
+	for {
+		lastBlock, err := idx.sink.GetLastBlock(chainID)
+		if err != nil {
+			return 0, err
+		}
+
+		nextHeight := idx.startHeights[chainID]
+		if nextHeight == 0 {
+			nextHeight = 1
+		}
+		if lastBlock != nil {
+			nextHeight = lastBlock.Height + 1
+		}
+		if nextHeight > toHeight {
+			if lastBlock == nil {
+				return 0, nil
+			}
+			return lastBlock.Height, nil
+		}
+
+		blocks, err := idx.getBlocks(chainID, nextHeight)
+		if err != nil {
+			return 0, err
+		}
+		if len(blocks) == 0 {
+			return 0, fmt.Errorf("block %v not found", nextHeight)
+		}
+		candidates := make([]*IndexedBlockData, 0)
+		for _, block := range blocks {
+			if !orphanedHashes[nextHeight][block.Block.Hash] {
+				candidates = append(candidates, block)
+			}
+		}
+
+		var data *IndexedBlockData
+		if lastBlock == nil {
+			data, err = idx.pickFirstBlock(chainID, nextHeight, toHeight, candidates)
+			if err != nil {
+				return 0, err
+			}
+		} else {
+			for _, candidate := range candidates {
+				if candidate.Block.PreviousBlockHash == lastBlock.Hash {
+					data = candidate
+					break
+				}
+			}
+		}
+
+		if data == nil {
+			if lastBlock == nil {
+				return 0, fmt.Errorf("all blocks at height %v have been orphaned", nextHeight)
+			}
+			if reorgDepth >= idx.maxReorgDepth {
+				return 0, fmt.Errorf("re-org deeper than %v blocks at height %v", idx.maxReorgDepth, lastBlock.Height)
+			}
+			Logger.Printf("Chain %v: block %v (%v) has been orphaned, rolling back\n", chainID, lastBlock.Height, lastBlock.Hash)
+			err = idx.sink.DeleteBlock(chainID, lastBlock.Height)
+			if err != nil {
+				return 0, err
+			}
+			if orphanedHashes[lastBlock.Height] == nil {
+				orphanedHashes[lastBlock.Height] = make(map[string]bool)
+			}
+			orphanedHashes[lastBlock.Height][lastBlock.Hash] = true
+			if reorgDepth == 0 {
+				reorgHeight = nextHeight
+			}
+			reorgDepth++
+			continue
+		}
+
+		err = idx.sink.WriteBlock(data)
+		if err != nil {
+			return 0, err
+		}
+		if data.Block.Height > reorgHeight {
+			reorgDepth = 0
+		}
+	}
+}
+
+// pickFirstBlock picks the block to start a chain from, when the sink has no block of that chain. If there are several
+// candidates at the given height, it picks the one the blocks at the next height link to; it fails if the choice is
+// still ambiguous.
+func (idx *ChainIndexer) pickFirstBlock(chainID int, height, toHeight uint64, candidates []*IndexedBlockData) (*IndexedBlockData, error) {
+	if len(candidates) <= 1 {
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+		return candidates[0], nil
+	}
+	if height >= toHeight {
+		return nil, fmt.Errorf("%v blocks found at height %v", len(candidates), height)
+	}
+
+	nextBlocks, err := idx.getBlocks(chainID, height+1)
+	if err != nil {
+		return nil, err
+	}
+	var res *IndexedBlockData
+	for _, candidate := range candidates {
+		for _, nextBlock := range nextBlocks {
+			if nextBlock.Block.PreviousBlockHash == candidate.Block.Hash {
+				if res != nil && res != candidate {
+					return nil, fmt.Errorf("%v blocks found at height %v", len(candidates), height)
+				}
+				res = candidate
+			}
+		}
+	}
+	if res == nil {
+		return nil, fmt.Errorf("%v blocks found at height %v, none of which is linked to by height %v", len(candidates), height, height+1)
+	}
+
+	return res, nil
+}
+
+// Run calls IndexOnce every interval until the stop channel is closed. The results of each round are passed to the
+// given callback (if not nil).
+func (idx *ChainIndexer) Run(interval time.Duration, stop <-chan struct{}, callback func(map[int]uint64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		heights, err := idx.IndexOnce()
+		if err != nil {
+			Logger.Printf("IndexOnce error: %v\n", err)
+		}
+		if callback != nil {
+			callback(heights, err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// getBlocks retrieves and normalizes all blocks of a chain at the given height.
+func (idx *ChainIndexer) getBlocks(chainID int, height uint64) ([]*IndexedBlockData, error) {
+	res := make([]*IndexedBlockData, 0)
+	if chainID == BeaconChainID {
+		blocks, err := idx.client.GetBeaconBlocksByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			res = append(res, NewIndexedBeaconBlockData(block))
+		}
+		return res, nil
+	}
+
+	blocks, err := idx.client.GetShardBlocksByHeight(byte(chainID), height)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		data, err := NewIndexedShardBlockData(block)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+
+	return res, nil
+}
+
+// NewIndexedBeaconBlockData normalizes a beacon block into rows.
+func NewIndexedBeaconBlockData(block *jsonresult.BeaconBlock) *IndexedBlockData {
+	return &IndexedBlockData{
+		Block: IndexedBlock{
+			ChainID:           BeaconChainID,
+			Height:            block.Height,
+			Hash:              block.Hash,
+			PreviousBlockHash: block.PreviousBlockHash,
+			Time:              block.Time,
+			Epoch:             block.Epoch,
+			BeaconHeight:      block.Height,
+		},
+		PDEXInstructions: getIndexedPDEXInstructions(BeaconChainID, block.Height, block.Hash, block.Instructions),
+	}
+}
+
+// NewIndexedShardBlockData normalizes a shard block into rows. The block must be retrieved with the data of its
+// transactions.
+func NewIndexedShardBlockData(block *jsonresult.ShardBlock) (*IndexedBlockData, error) {
+	txs, err := DecodeShardBlockTxs(block)
+	if err != nil {
+		return nil, err
+	}
+
+	shardID := int(block.ShardID)
+	res := &IndexedBlockData{
+		Block: IndexedBlock{
+			ChainID:           shardID,
+			Height:            block.Height,
+			Hash:              block.Hash,
+			PreviousBlockHash: block.PreviousBlockHash,
+			Time:              block.Time,
+			Epoch:             block.Epoch,
+			BeaconHeight:      block.BeaconHeight,
+			NumTxs:            len(txs),
+		},
+		Txs:              make([]IndexedTx, 0),
+		SerialNumbers:    make([]IndexedSerialNumber, 0),
+		OTAPublicKeys:    make([]IndexedOTAPublicKey, 0),
+		PDEXInstructions: getIndexedPDEXInstructions(shardID, block.Height, block.Hash, block.Instruction),
+	}
+
+	for i, blockTx := range txs {
+		tx := blockTx.Tx
+		txHash := tx.Hash().String()
+		tokenID := common.PRVIDStr
+		if tx.GetTokenID() != nil {
+			tokenID = tx.GetTokenID().String()
+		}
+		res.Txs = append(res.Txs, IndexedTx{
+			ShardID:      shardID,
+			BlockHeight:  block.Height,
+			BlockHash:    block.Hash,
+			TxIndex:      i,
+			TxHash:       txHash,
+			Version:      tx.GetVersion(),
+			TxType:       tx.GetType(),
+			MetadataType: tx.GetMetadataType(),
+			TokenID:      tokenID,
+			Fee:          tx.GetTxFee(),
+			FeeToken:     tx.GetTxFeeToken(),
+			LockTime:     tx.GetLockTime(),
+		})
+
+		for _, proof := range getTxProofs(tx) {
+			for _, inCoin := range proof.GetInputCoins() {
+				if inCoin == nil || inCoin.GetKeyImage() == nil {
+					continue
+				}
+				res.SerialNumbers = append(res.SerialNumbers, IndexedSerialNumber{
+					ShardID:      shardID,
+					BlockHeight:  block.Height,
+					TxHash:       txHash,
+					SerialNumber: base58.Base58Check{}.Encode(inCoin.GetKeyImage().ToBytesS(), common.ZeroByte),
+				})
+			}
+			for _, outCoin := range proof.GetOutputCoins() {
+				if outCoin == nil || outCoin.GetPublicKey() == nil {
+					continue
+				}
+				res.OTAPublicKeys = append(res.OTAPublicKeys, IndexedOTAPublicKey{
+					ShardID:     shardID,
+					BlockHeight: block.Height,
+					TxHash:      txHash,
+					PublicKey:   base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte),
+				})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// getTxProofs returns the PRV proof and the token proof (if any) of a transaction.
+func getTxProofs(tx metadata.Transaction) []privacy.Proof {
+	res := make([]privacy.Proof, 0)
+	switch tx.GetType() {
+	case common.TxCustomTokenPrivacyType, common.TxTokenConversionType:
+		tmpTx, ok := tx.(tx_generic.TransactionToken)
+		if !ok {
+			return res
+		}
+		if tmpTx.GetTxBase() != nil && tmpTx.GetTxBase().GetProof() != nil {
+			res = append(res, tmpTx.GetTxBase().GetProof())
+		}
+		if tmpTx.GetTxNormal() != nil && tmpTx.GetTxNormal().GetProof() != nil {
+			res = append(res, tmpTx.GetTxNormal().GetProof())
+		}
+	default:
+		if tx.GetProof() != nil {
+			res = append(res, tx.GetProof())
+		}
+	}
+
+	return res
+}
+
+// getIndexedPDEXInstructions returns the pDEX instructions from a list of block instructions.
+func getIndexedPDEXInstructions(chainID int, height uint64, blockHash string, instructions [][]string) []IndexedPDEXInstruction {
+	res := make([]IndexedPDEXInstruction, 0)
+	for i, inst := range instructions {
+		if len(inst) == 0 {
+			continue
+		}
+		metaType, err := strconv.Atoi(inst[0])
+		if err != nil || !metadataCommon.IsPDEXMetaType(metaType) {
+			continue
+		}
+		res = append(res, IndexedPDEXInstruction{
+			ChainID:      chainID,
+			BlockHeight:  height,
+			BlockHash:    blockHash,
+			InstIndex:    i,
+			MetadataType: metaType,
+			Instruction:  inst,
+		})
+	}
+
+	return res
+}
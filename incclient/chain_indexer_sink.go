@@ -0,0 +1,414 @@
+package incclient
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// IndexedBlock is the row of an indexed block. ChainID is BeaconChainID for beacon blocks, or the shardID.
+type IndexedBlock struct {
+	ChainID           int
+	Height            uint64
+	Hash              string
+	PreviousBlockHash string
+	Time              int64
+	Epoch             uint64
+	BeaconHeight      uint64
+	NumTxs            int
+}
+
+// IndexedTx is the row of an indexed transaction.
+type IndexedTx struct {
+	ShardID      int
+	BlockHeight  uint64
+	BlockHash    string
+	TxIndex      int
+	TxHash       string
+	Version      int8
+	TxType       string
+	MetadataType int
+	TokenID      string
+	Fee          uint64
+	FeeToken     uint64
+	LockTime     int64
+}
+
+// IndexedSerialNumber is the row of a serial number (key image) spent by an indexed transaction.
+type IndexedSerialNumber struct {
+	ShardID      int
+	BlockHeight  uint64
+	TxHash       string
+	SerialNumber string
+}
+
+// IndexedOTAPublicKey is the row of the (one-time) public key of an output coin of an indexed transaction.
+type IndexedOTAPublicKey struct {
+	ShardID     int
+	BlockHeight uint64
+	TxHash      string
+	PublicKey   string
+}
+
+// IndexedPDEXInstruction is the row of a pDEX instruction included in an indexed block.
+type IndexedPDEXInstruction struct {
+	ChainID      int
+	BlockHeight  uint64
+	BlockHash    string
+	InstIndex    int
+	MetadataType int
+	Instruction  []string
+}
+
+// IndexedBlockData consists of all rows of an indexed block.
+type IndexedBlockData struct {
+	Block            IndexedBlock
+	Txs              []IndexedTx
+	SerialNumbers    []IndexedSerialNumber
+	OTAPublicKeys    []IndexedOTAPublicKey
+	PDEXInstructions []IndexedPDEXInstruction
+}
+
+// IndexSink stores the rows produced by a ChainIndexer.
+type IndexSink interface {
+	// GetLastBlock returns the latest stored block of a chain, or nil if the sink has no block of that chain.
+	GetLastBlock(chainID int) (*IndexedBlock, error)
+
+	// WriteBlock stores all rows of a block. Blocks of a chain are written in increasing heights.
+	WriteBlock(data *IndexedBlockData) error
+
+	// DeleteBlock removes all rows of the block of a chain at the given height. It is called on the latest stored
+	// block when that block has been orphaned by a re-org.
+	DeleteBlock(chainID int, height uint64) error
+
+	// Close releases the resources held by the sink.
+	Close() error
+}
+
+// MemoryIndexSink is an IndexSink that keeps all rows in memory, mostly for testing purposes.
+type MemoryIndexSink struct {
+	mtx    sync.RWMutex
+	blocks map[int]map[uint64]*IndexedBlockData
+}
+
+// NewMemoryIndexSink creates a new, empty MemoryIndexSink.
+func NewMemoryIndexSink() *MemoryIndexSink {
+	return &MemoryIndexSink{blocks: make(map[int]map[uint64]*IndexedBlockData)}
+}
+
+// GetLastBlock implements the IndexSink interface.
+func (s *MemoryIndexSink) GetLastBlock(chainID int) (*IndexedBlock, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var res *IndexedBlock
+	for _, data := range s.blocks[chainID] {
+		if res == nil || data.Block.Height > res.Height {
+			tmp := data.Block
+			res = &tmp
+		}
+	}
+
+	return res, nil
+}
+
+// WriteBlock implements the IndexSink interface.
+func (s *MemoryIndexSink) WriteBlock(data *IndexedBlockData) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	chainID := data.Block.ChainID
+	if s.blocks[chainID] == nil {
+		s.blocks[chainID] = make(map[uint64]*IndexedBlockData)
+	}
+	if _, ok := s.blocks[chainID][data.Block.Height]; ok {
+		return fmt.Errorf("block %v of chain %v already stored", data.Block.Height, chainID)
+	}
+	s.blocks[chainID][data.Block.Height] = data
+
+	return nil
+}
+
+// DeleteBlock implements the IndexSink interface.
+func (s *MemoryIndexSink) DeleteBlock(chainID int, height uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.blocks[chainID], height)
+
+	return nil
+}
+
+// Close implements the IndexSink interface.
+func (s *MemoryIndexSink) Close() error {
+	return nil
+}
+
+// GetBlockData returns the rows of the block of a chain at the given height, or nil if not stored.
+func (s *MemoryIndexSink) GetBlockData(chainID int, height uint64) *IndexedBlockData {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	return s.blocks[chainID][height]
+}
+
+// GetBlocks returns the stored blocks of a chain, sorted by height.
+func (s *MemoryIndexSink) GetBlocks(chainID int) []IndexedBlock {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	res := make([]IndexedBlock, 0)
+	for _, data := range s.blocks[chainID] {
+		res = append(res, data.Block)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Height < res[j].Height
+	})
+
+	return res
+}
+
+// GetTx returns the row of a stored transaction, or nil if not found.
+func (s *MemoryIndexSink) GetTx(txHash string) *IndexedTx {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for _, blocks := range s.blocks {
+		for _, data := range blocks {
+			for _, tx := range data.Txs {
+				if tx.TxHash == txHash {
+					tmp := tx
+					return &tmp
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetSerialNumber returns the row of a stored serial number, or nil if not found.
+func (s *MemoryIndexSink) GetSerialNumber(serialNumber string) *IndexedSerialNumber {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for _, blocks := range s.blocks {
+		for _, data := range blocks {
+			for _, sn := range data.SerialNumbers {
+				if sn.SerialNumber == serialNumber {
+					tmp := sn
+					return &tmp
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Tables of the JSONLinesIndexSink.
+const (
+	IndexTableBlocks           = "blocks"
+	IndexTableTxs              = "txs"
+	IndexTableSerialNumbers    = "serial_numbers"
+	IndexTableOTAPublicKeys    = "ota_public_keys"
+	IndexTablePDEXInstructions = "pdex_instructions"
+	IndexTableRollbacks        = "rollbacks"
+)
+
+// jsonLinesSinkTailSize is the number of latest blocks per chain a JSONLinesIndexSink keeps in memory to handle re-orgs.
+const jsonLinesSinkTailSize = 128
+
+// IndexLine is a line of a JSONLinesIndexSink file.
+type IndexLine struct {
+	Table string
+	Row   json.RawMessage
+}
+
+// IndexRollback is the row written by a JSONLinesIndexSink when a block is deleted. Readers must discard all rows of
+// the chain at heights greater than or equal to Height that appear before the rollback line.
+type IndexRollback struct {
+	ChainID int
+	Height  uint64
+}
+
+// JSONLinesIndexSink is an IndexSink that appends the rows to a file, one JSON-encoded IndexLine per line. Since the
+// file is append-only, deleted blocks are recorded as IndexRollback rows.
+type JSONLinesIndexSink struct {
+	mtx  sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+
+	// tails[chainID] holds the latest stored blocks of a chain, in increasing heights.
+	tails map[int][]IndexedBlock
+}
+
+// NewJSONLinesIndexSink opens (or creates) the file at the given path. The latest blocks recorded in an existing file
+// are loaded so that the indexing can resume from where it stopped.
+func NewJSONLinesIndexSink(path string) (*JSONLinesIndexSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &JSONLinesIndexSink{
+		file:  f,
+		w:     bufio.NewWriter(f),
+		tails: make(map[int][]IndexedBlock),
+	}
+	err = s.load(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("cannot load %v: %v", path, err)
+	}
+
+	return s, nil
+}
+
+// load replays the blocks and rollbacks of an existing file.
+func (s *JSONLinesIndexSink) load(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		lineBytes, err := reader.ReadBytes('\n')
+		if len(lineBytes) > 0 {
+			var line IndexLine
+			if jsonErr := json.Unmarshal(lineBytes, &line); jsonErr != nil {
+				return jsonErr
+			}
+			switch line.Table {
+			case IndexTableBlocks:
+				var block IndexedBlock
+				if jsonErr := json.Unmarshal(line.Row, &block); jsonErr != nil {
+					return jsonErr
+				}
+				s.pushBlock(block)
+			case IndexTableRollbacks:
+				var rollback IndexRollback
+				if jsonErr := json.Unmarshal(line.Row, &rollback); jsonErr != nil {
+					return jsonErr
+				}
+				s.popBlocks(rollback.ChainID, rollback.Height)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *JSONLinesIndexSink) pushBlock(block IndexedBlock) {
+	tail := append(s.tails[block.ChainID], block)
+	if len(tail) > jsonLinesSinkTailSize {
+		tail = tail[len(tail)-jsonLinesSinkTailSize:]
+	}
+	s.tails[block.ChainID] = tail
+}
+
+func (s *JSONLinesIndexSink) popBlocks(chainID int, fromHeight uint64) {
+	tail := s.tails[chainID]
+	for len(tail) > 0 && tail[len(tail)-1].Height >= fromHeight {
+		tail = tail[:len(tail)-1]
+	}
+	s.tails[chainID] = tail
+}
+
+func (s *JSONLinesIndexSink) writeLine(table string, row interface{}) error {
+	rowBytes, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	lineBytes, err := json.Marshal(IndexLine{Table: table, Row: rowBytes})
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(lineBytes, '\n'))
+
+	return err
+}
+
+// GetLastBlock implements the IndexSink interface.
+func (s *JSONLinesIndexSink) GetLastBlock(chainID int) (*IndexedBlock, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	tail := s.tails[chainID]
+	if len(tail) == 0 {
+		return nil, nil
+	}
+	res := tail[len(tail)-1]
+
+	return &res, nil
+}
+
+// WriteBlock implements the IndexSink interface.
+func (s *JSONLinesIndexSink) WriteBlock(data *IndexedBlockData) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.writeLine(IndexTableBlocks, data.Block)
+	if err != nil {
+		return err
+	}
+	for _, tx := range data.Txs {
+		if err = s.writeLine(IndexTableTxs, tx); err != nil {
+			return err
+		}
+	}
+	for _, sn := range data.SerialNumbers {
+		if err = s.writeLine(IndexTableSerialNumbers, sn); err != nil {
+			return err
+		}
+	}
+	for _, pk := range data.OTAPublicKeys {
+		if err = s.writeLine(IndexTableOTAPublicKeys, pk); err != nil {
+			return err
+		}
+	}
+	for _, inst := range data.PDEXInstructions {
+		if err = s.writeLine(IndexTablePDEXInstructions, inst); err != nil {
+			return err
+		}
+	}
+	if err = s.w.Flush(); err != nil {
+		return err
+	}
+	s.pushBlock(data.Block)
+
+	return nil
+}
+
+// DeleteBlock implements the IndexSink interface.
+func (s *JSONLinesIndexSink) DeleteBlock(chainID int, height uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := s.writeLine(IndexTableRollbacks, IndexRollback{ChainID: chainID, Height: height})
+	if err != nil {
+		return err
+	}
+	if err = s.w.Flush(); err != nil {
+		return err
+	}
+	s.popBlocks(chainID, height)
+
+	return nil
+}
+
+// Close implements the IndexSink interface.
+func (s *JSONLinesIndexSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+
+	return s.file.Close()
+}
@@ -0,0 +1,211 @@
+package incclient
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// sqliteIndexSchema creates the tables of a SQLiteIndexSink.
+var sqliteIndexSchema = []string{
+	`CREATE TABLE IF NOT EXISTS blocks (
+		chain_id INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		hash TEXT NOT NULL,
+		previous_block_hash TEXT NOT NULL,
+		time INTEGER NOT NULL,
+		epoch INTEGER NOT NULL,
+		beacon_height INTEGER NOT NULL,
+		num_txs INTEGER NOT NULL,
+		PRIMARY KEY (chain_id, height)
+	)`,
+	`CREATE TABLE IF NOT EXISTS txs (
+		tx_hash TEXT NOT NULL PRIMARY KEY,
+		shard_id INTEGER NOT NULL,
+		block_height INTEGER NOT NULL,
+		block_hash TEXT NOT NULL,
+		tx_index INTEGER NOT NULL,
+		version INTEGER NOT NULL,
+		tx_type TEXT NOT NULL,
+		metadata_type INTEGER NOT NULL,
+		token_id TEXT NOT NULL,
+		fee TEXT NOT NULL,
+		fee_token TEXT NOT NULL,
+		lock_time INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS txs_block ON txs (shard_id, block_height)`,
+	`CREATE INDEX IF NOT EXISTS txs_metadata_type ON txs (metadata_type)`,
+	`CREATE TABLE IF NOT EXISTS serial_numbers (
+		serial_number TEXT NOT NULL,
+		shard_id INTEGER NOT NULL,
+		block_height INTEGER NOT NULL,
+		tx_hash TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS serial_numbers_sn ON serial_numbers (serial_number)`,
+	`CREATE INDEX IF NOT EXISTS serial_numbers_block ON serial_numbers (shard_id, block_height)`,
+	`CREATE TABLE IF NOT EXISTS ota_public_keys (
+		public_key TEXT NOT NULL,
+		shard_id INTEGER NOT NULL,
+		block_height INTEGER NOT NULL,
+		tx_hash TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS ota_public_keys_pk ON ota_public_keys (public_key)`,
+	`CREATE INDEX IF NOT EXISTS ota_public_keys_block ON ota_public_keys (shard_id, block_height)`,
+	`CREATE TABLE IF NOT EXISTS pdex_instructions (
+		chain_id INTEGER NOT NULL,
+		block_height INTEGER NOT NULL,
+		block_hash TEXT NOT NULL,
+		inst_index INTEGER NOT NULL,
+		metadata_type INTEGER NOT NULL,
+		instruction TEXT NOT NULL,
+		PRIMARY KEY (chain_id, block_height, inst_index)
+	)`,
+	`CREATE INDEX IF NOT EXISTS pdex_instructions_metadata_type ON pdex_instructions (metadata_type)`,
+}
+
+// SQLiteIndexSink is an IndexSink that stores the rows in a SQLite database, one table per row type. The database
+// handle must be opened by the caller with a SQLite driver of their choice (e.g, github.com/mattn/go-sqlite3), so that
+// the SDK itself does not depend on cgo.
+//
+// SQLite integers are signed 64-bit; hence, amounts (i.e, fee and fee_token) are stored as decimal TEXT to keep their
+// full uint64 range, while heights and epochs are stored as INTEGER and must not exceed math.MaxInt64.
+type SQLiteIndexSink struct {
+	db *sql.DB
+}
+
+// NewSQLiteIndexSink creates the tables (if needed) in the given database and returns a new SQLiteIndexSink.
+func NewSQLiteIndexSink(db *sql.DB) (*SQLiteIndexSink, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db not found")
+	}
+	for _, stmt := range sqliteIndexSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("cannot create the index schema: %v", err)
+		}
+	}
+
+	return &SQLiteIndexSink{db: db}, nil
+}
+
+// GetLastBlock implements the IndexSink interface.
+func (s *SQLiteIndexSink) GetLastBlock(chainID int) (*IndexedBlock, error) {
+	var res IndexedBlock
+	err := s.db.QueryRow(`SELECT chain_id, height, hash, previous_block_hash, time, epoch, beacon_height, num_txs
+		FROM blocks WHERE chain_id = ? ORDER BY height DESC LIMIT 1`, chainID).Scan(
+		&res.ChainID, &res.Height, &res.Hash, &res.PreviousBlockHash, &res.Time, &res.Epoch, &res.BeaconHeight, &res.NumTxs,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// WriteBlock implements the IndexSink interface. All rows of the block are written in a single database transaction.
+func (s *SQLiteIndexSink) WriteBlock(data *IndexedBlockData) error {
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = writeSQLiteBlock(dbTx, data)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+func writeSQLiteBlock(dbTx *sql.Tx, data *IndexedBlockData) error {
+	b := data.Block
+	for _, v := range []uint64{b.Height, b.Epoch, b.BeaconHeight} {
+		if v > math.MaxInt64 {
+			return fmt.Errorf("block %v: value %v out of the SQLite INTEGER range", b.Hash, v)
+		}
+	}
+	_, err := dbTx.Exec(`INSERT INTO blocks (chain_id, height, hash, previous_block_hash, time, epoch, beacon_height, num_txs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.ChainID, int64(b.Height), b.Hash, b.PreviousBlockHash, b.Time, int64(b.Epoch), int64(b.BeaconHeight), b.NumTxs)
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range data.Txs {
+		_, err = dbTx.Exec(`INSERT INTO txs (tx_hash, shard_id, block_height, block_hash, tx_index, version, tx_type,
+			metadata_type, token_id, fee, fee_token, lock_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			tx.TxHash, tx.ShardID, int64(tx.BlockHeight), tx.BlockHash, tx.TxIndex, tx.Version, tx.TxType,
+			tx.MetadataType, tx.TokenID, strconv.FormatUint(tx.Fee, 10), strconv.FormatUint(tx.FeeToken, 10), tx.LockTime)
+		if err != nil {
+			return err
+		}
+	}
+	for _, sn := range data.SerialNumbers {
+		_, err = dbTx.Exec(`INSERT INTO serial_numbers (serial_number, shard_id, block_height, tx_hash) VALUES (?, ?, ?, ?)`,
+			sn.SerialNumber, sn.ShardID, int64(sn.BlockHeight), sn.TxHash)
+		if err != nil {
+			return err
+		}
+	}
+	for _, pk := range data.OTAPublicKeys {
+		_, err = dbTx.Exec(`INSERT INTO ota_public_keys (public_key, shard_id, block_height, tx_hash) VALUES (?, ?, ?, ?)`,
+			pk.PublicKey, pk.ShardID, int64(pk.BlockHeight), pk.TxHash)
+		if err != nil {
+			return err
+		}
+	}
+	for _, inst := range data.PDEXInstructions {
+		instBytes, err := json.Marshal(inst.Instruction)
+		if err != nil {
+			return err
+		}
+		_, err = dbTx.Exec(`INSERT INTO pdex_instructions (chain_id, block_height, block_hash, inst_index, metadata_type,
+			instruction) VALUES (?, ?, ?, ?, ?, ?)`,
+			inst.ChainID, int64(inst.BlockHeight), inst.BlockHash, inst.InstIndex, inst.MetadataType, string(instBytes))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteBlock implements the IndexSink interface.
+func (s *SQLiteIndexSink) DeleteBlock(chainID int, height uint64) error {
+	if height > math.MaxInt64 {
+		return fmt.Errorf("height %v out of the SQLite INTEGER range", height)
+	}
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmts := []string{
+		`DELETE FROM blocks WHERE chain_id = ? AND height = ?`,
+		`DELETE FROM pdex_instructions WHERE chain_id = ? AND block_height = ?`,
+	}
+	if chainID != BeaconChainID {
+		stmts = append(stmts,
+			`DELETE FROM txs WHERE shard_id = ? AND block_height = ?`,
+			`DELETE FROM serial_numbers WHERE shard_id = ? AND block_height = ?`,
+			`DELETE FROM ota_public_keys WHERE shard_id = ? AND block_height = ?`,
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err = dbTx.Exec(stmt, chainID, int64(height)); err != nil {
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+// Close implements the IndexSink interface. The underlying database is closed as well.
+func (s *SQLiteIndexSink) Close() error {
+	return s.db.Close()
+}
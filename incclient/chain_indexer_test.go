@@ -0,0 +1,357 @@
+package incclient
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+func newTestChainIndexer(node *mockBlockNode, sink IndexSink) *ChainIndexer {
+	server := httptest.NewServer(node)
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL)}
+	indexer, err := NewChainIndexer(client, sink)
+	if err != nil {
+		panic(err)
+	}
+	err = indexer.SetChains(BeaconChainID, int(node.shardID))
+	if err != nil {
+		panic(err)
+	}
+
+	return indexer
+}
+
+func checkIndexedHashes(sink *MemoryIndexSink, chainID int, expected []string) {
+	hashes := make([]string, 0)
+	for _, block := range sink.GetBlocks(chainID) {
+		hashes = append(hashes, block.Hash)
+	}
+	if fmt.Sprintf("%v", hashes) != fmt.Sprintf("%v", expected) {
+		panic(fmt.Sprintf("chain %v: expected %v, got %v", chainID, expected, hashes))
+	}
+}
+
+func TestChainIndexer_IndexOnce(t *testing.T) {
+	node := &mockBlockNode{shardID: 0}
+	blockTx, txHash := newTestBlockTx(1000)
+	node.addBlock(1, "h1", "h0")
+	node.addBlock(2, "h2", "h1", blockTx)
+	node.addBlock(3, "h3", "h2")
+	pdexInst := []string{strconv.Itoa(metadataCommon.Pdexv3TradeRequestMeta), "0", "accepted", "{}"}
+	node.addBeaconBlock(1, "b1", "b0", []string{"1", "0"}, pdexInst)
+
+	sink := NewMemoryIndexSink()
+	indexer := newTestChainIndexer(node, sink)
+	heights, err := indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	if heights[0] != 3 || heights[BeaconChainID] != 1 {
+		panic(fmt.Sprintf("unexpected heights %v", heights))
+	}
+	checkIndexedHashes(sink, 0, []string{"h1", "h2", "h3"})
+	checkIndexedHashes(sink, BeaconChainID, []string{"b1"})
+
+	tx := sink.GetTx(txHash)
+	if tx == nil || tx.BlockHeight != 2 || tx.MetadataType != metadataCommon.PortalV4UnshieldingResponseMeta {
+		panic(fmt.Sprintf("unexpected tx %v", tx))
+	}
+	data := sink.GetBlockData(0, 2)
+	if len(data.OTAPublicKeys) != 1 || data.OTAPublicKeys[0].TxHash != txHash {
+		panic(fmt.Sprintf("unexpected OTA public keys %v", data.OTAPublicKeys))
+	}
+	data = sink.GetBlockData(BeaconChainID, 1)
+	if len(data.PDEXInstructions) != 1 || data.PDEXInstructions[0].InstIndex != 1 {
+		panic(fmt.Sprintf("unexpected pDEX instructions %v", data.PDEXInstructions))
+	}
+
+	// block 3 is orphaned, and the chain continues from h2
+	node.replaceBlock(3, "h3'", "h2")
+	node.addBlock(4, "h4'", "h3'")
+	heights, err = indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	if heights[0] != 4 {
+		panic(fmt.Sprintf("unexpected heights %v", heights))
+	}
+	checkIndexedHashes(sink, 0, []string{"h1", "h2", "h3'", "h4'"})
+
+	// a re-org deeper than the limit is reported
+	node.replaceBlock(2, "h2''", "h1")
+	node.replaceBlock(3, "h3''", "h2''")
+	node.replaceBlock(4, "h4''", "h3''")
+	node.addBlock(5, "h5''", "h4''")
+	indexer.SetMaxReorgDepth(2)
+	_, err = indexer.IndexOnce()
+	if err == nil {
+		panic("expected an error for a deep re-org")
+	}
+	indexer.SetMaxReorgDepth(DefaultIndexerMaxReorgDepth)
+	_, err = indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	checkIndexedHashes(sink, 0, []string{"h1", "h2''", "h3''", "h4''", "h5''"})
+}
+
+func TestChainIndexer_ForkedHeights(t *testing.T) {
+	node := &mockBlockNode{shardID: 0}
+	// the node lists the orphaned block first at heights 1 and 3
+	node.addBlock(1, "h1-orphan", "h0")
+	node.addBlock(1, "h1", "h0")
+	node.addBlock(2, "h2", "h1")
+	node.addBlock(3, "h3-orphan", "h2")
+	node.addBlock(3, "h3", "h2")
+	node.addBlock(4, "h4", "h3")
+	node.addBeaconBlock(1, "b1", "b0")
+
+	sink := NewMemoryIndexSink()
+	indexer := newTestChainIndexer(node, sink)
+	indexer.SetMaxReorgDepth(1)
+	heights, err := indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	if heights[0] != 4 {
+		panic(fmt.Sprintf("unexpected heights %v", heights))
+	}
+	checkIndexedHashes(sink, 0, []string{"h1", "h2", "h3", "h4"})
+
+	// the first block of a chain is ambiguous if no later block tells the candidates apart
+	node.addBlock(5, "h5-a", "h4")
+	node.addBlock(5, "h5-b", "h4")
+	indexer.SetStartHeight(0, 5)
+	sink = NewMemoryIndexSink()
+	indexer.sink = sink
+	if _, err = indexer.IndexChain(0, 5); err == nil {
+		panic("expected an error for an ambiguous first block")
+	}
+}
+
+func TestChainIndexer_StartHeight(t *testing.T) {
+	node := &mockBlockNode{shardID: 1}
+	for i := uint64(1); i <= 5; i++ {
+		node.addBlock(i, fmt.Sprintf("h%v", i), fmt.Sprintf("h%v", i-1))
+	}
+	node.addBeaconBlock(1, "b1", "b0")
+
+	sink := NewMemoryIndexSink()
+	indexer := newTestChainIndexer(node, sink)
+	indexer.SetStartHeight(1, 4)
+	_, err := indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	checkIndexedHashes(sink, 1, []string{"h4", "h5"})
+}
+
+func TestJSONLinesIndexSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "indexer")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index.jsonl")
+
+	node := &mockBlockNode{shardID: 0}
+	blockTx, _ := newTestBlockTx(1000)
+	node.addBlock(1, "h1", "h0", blockTx)
+	node.addBlock(2, "h2", "h1")
+	node.addBeaconBlock(1, "b1", "b0")
+
+	sink, err := NewJSONLinesIndexSink(path)
+	if err != nil {
+		panic(err)
+	}
+	indexer := newTestChainIndexer(node, sink)
+	_, err = indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	// re-open the file, and resume after a re-org
+	node.replaceBlock(2, "h2'", "h1")
+	node.addBlock(3, "h3'", "h2'")
+	sink, err = NewJSONLinesIndexSink(path)
+	if err != nil {
+		panic(err)
+	}
+	lastBlock, err := sink.GetLastBlock(0)
+	if err != nil {
+		panic(err)
+	}
+	if lastBlock == nil || lastBlock.Hash != "h2" {
+		panic(fmt.Sprintf("unexpected last block %v", lastBlock))
+	}
+	indexer = newTestChainIndexer(node, sink)
+	_, err = indexer.IndexOnce()
+	if err != nil {
+		panic(err)
+	}
+	err = sink.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	sink, err = NewJSONLinesIndexSink(path)
+	if err != nil {
+		panic(err)
+	}
+	defer sink.Close()
+	lastBlock, err = sink.GetLastBlock(0)
+	if err != nil {
+		panic(err)
+	}
+	if lastBlock == nil || lastBlock.Hash != "h3'" || lastBlock.Height != 3 {
+		panic(fmt.Sprintf("unexpected last block %v", lastBlock))
+	}
+}
+
+// fakeSQLDriver is a minimal database/sql driver that records the arguments of every INSERT statement, and serves
+// the last inserted block row to queries. It lets the SQLiteIndexSink be tested without a cgo SQLite driver.
+type fakeSQLDriver struct {
+	mtx     sync.Mutex
+	inserts map[string][][]driver.Value
+}
+
+type fakeSQLConn struct{ d *fakeSQLDriver }
+
+type fakeSQLStmt struct {
+	d     *fakeSQLDriver
+	query string
+}
+
+type fakeSQLRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (d *fakeSQLDriver) Open(string) (driver.Conn, error) { return &fakeSQLConn{d: d}, nil }
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{d: c.d, query: query}, nil
+}
+func (c *fakeSQLConn) Close() error              { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeSQLConn) Commit() error             { return nil }
+func (c *fakeSQLConn) Rollback() error           { return nil }
+
+func (s *fakeSQLStmt) Close() error  { return nil }
+func (s *fakeSQLStmt) NumInput() int { return -1 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fields := strings.Fields(s.query)
+	if len(fields) > 2 && fields[0] == "INSERT" {
+		s.d.mtx.Lock()
+		s.d.inserts[fields[2]] = append(s.d.inserts[fields[2]], args)
+		s.d.mtx.Unlock()
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *fakeSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mtx.Lock()
+	defer s.d.mtx.Unlock()
+	blocks := s.d.inserts["blocks"]
+	if len(blocks) == 0 {
+		return &fakeSQLRows{done: true}, nil
+	}
+	// the columns of an inserted block row are (chain_id, height, hash, previous_block_hash, time, epoch,
+	// beacon_height, num_txs), the same as those selected by GetLastBlock.
+	return &fakeSQLRows{row: blocks[len(blocks)-1]}, nil
+}
+
+func (r *fakeSQLRows) Columns() []string {
+	return []string{"chain_id", "height", "hash", "previous_block_hash", "time", "epoch", "beacon_height", "num_txs"}
+}
+func (r *fakeSQLRows) Close() error { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.done = true
+	return nil
+}
+
+var fakeSQL = &fakeSQLDriver{inserts: make(map[string][][]driver.Value)}
+
+func init() {
+	sql.Register("incclient-fake-sql", fakeSQL)
+}
+
+func TestSQLiteIndexSink(t *testing.T) {
+	db, err := sql.Open("incclient-fake-sql", "")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+	sink, err := NewSQLiteIndexSink(db)
+	if err != nil {
+		panic(err)
+	}
+
+	data := &IndexedBlockData{
+		Block: IndexedBlock{ChainID: 0, Height: math.MaxInt64, Hash: "h1", PreviousBlockHash: "h0",
+			Epoch: 100, BeaconHeight: 200, NumTxs: 1},
+		Txs: []IndexedTx{{ShardID: 0, BlockHeight: math.MaxInt64, BlockHash: "h1", TxHash: "tx1",
+			Fee: math.MaxUint64, FeeToken: math.MaxInt64 + 1}},
+	}
+	err = sink.WriteBlock(data)
+	if err != nil {
+		panic(err)
+	}
+
+	// amounts above math.MaxInt64 must round-trip through their TEXT columns
+	txs := fakeSQL.inserts["txs"]
+	if len(txs) != 1 {
+		panic(fmt.Sprintf("expected 1 tx row, got %v", len(txs)))
+	}
+	for i, expected := range map[int]uint64{9: data.Txs[0].Fee, 10: data.Txs[0].FeeToken} {
+		str, ok := txs[0][i].(string)
+		if !ok {
+			panic(fmt.Sprintf("column %v: expected a TEXT value, got %T", i, txs[0][i]))
+		}
+		v, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		if v != expected {
+			panic(fmt.Sprintf("column %v: expected %v, got %v", i, expected, v))
+		}
+	}
+
+	lastBlock, err := sink.GetLastBlock(0)
+	if err != nil {
+		panic(err)
+	}
+	if lastBlock == nil || *lastBlock != data.Block {
+		panic(fmt.Sprintf("unexpected last block %v", lastBlock))
+	}
+
+	// heights beyond the SQLite INTEGER range are rejected rather than wrapped
+	data.Block.Height = math.MaxInt64 + 1
+	data.Block.Hash = "h2"
+	if err = sink.WriteBlock(data); err == nil {
+		panic("expected an error for a height above math.MaxInt64")
+	}
+	if err = sink.DeleteBlock(0, math.MaxInt64+1); err == nil {
+		panic("expected an error for a height above math.MaxInt64")
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
 	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
 	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
-	"log"
 	"math/big"
 	"time"
 )
@@ -72,7 +71,7 @@ func (client *IncClient) GetOutputCoinsV2(outCoinKey *rpc.OutCoinKey, tokenID st
 //	- a map from the serial number to the output coin;
 //	- error (if any).
 func (client *IncClient) GetListDecryptedOutCoin(privateKey string, tokenID string, height uint64) (map[string]coin.PlainCoin, error) {
-	outCoinKey, err := NewOutCoinKeyFromPrivateKey(privateKey)
+	outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +132,7 @@ func (client *IncClient) GetUnspentOutputCoins(privateKey, tokenID string, heigh
 		return nil, nil, err
 	}
 
-	outCoinKey, err := NewOutCoinKeyFromPrivateKey(privateKey)
+	outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -157,7 +156,7 @@ func (client *IncClient) GetUnspentOutputCoins(privateKey, tokenID string, heigh
 	}
 	var checkSpentList []bool
 
-	shardID := common.GetShardIDFromLastByte(keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1])
+	shardID := client.networkParams.GetShardIDFromLastByte(keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1])
 
 	batchSize := 100
 	numBatches := len(listKeyImages) / batchSize
@@ -252,7 +251,7 @@ func (client *IncClient) GetUnspentOutputCoinsFromCache(privateKey, tokenID stri
 		return client.GetUnspentOutputCoins(privateKey, tokenID, height)
 	}
 
-	outCoinKey, err := NewOutCoinKeyFromPrivateKey(privateKey)
+	outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
 	if len(reSync) > 0 && reSync[0] {
 		err = client.syncOutCoinV2(outCoinKey, tokenID)
 		if err != nil {
@@ -303,7 +302,7 @@ func (client *IncClient) GetUnspentOutputCoinsFromCache(privateKey, tokenID stri
 		return nil, nil, err
 	}
 
-	shardID := GetShardIDFromPrivateKey(privateKey)
+	shardID := client.GetShardIDFromPrivateKey(privateKey)
 	checkSpentList, err := client.CheckCoinsSpent(shardID, tokenID, listKeyImages)
 	if err != nil {
 		return nil, nil, err
@@ -328,7 +327,7 @@ func (client *IncClient) GetSpentOutputCoins(privateKey, tokenID string, height
 		return nil, nil, err
 	}
 
-	outCoinKey, err := NewOutCoinKeyFromPrivateKey(privateKey)
+	outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -350,7 +349,7 @@ func (client *IncClient) GetSpentOutputCoins(privateKey, tokenID string, height
 		return nil, nil, err
 	}
 
-	shardID := common.GetShardIDFromLastByte(keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1])
+	shardID := client.networkParams.GetShardIDFromLastByte(keyWallet.KeySet.PaymentAddress.Pk[len(keyWallet.KeySet.PaymentAddress.Pk)-1])
 	checkSpentList, err := client.CheckCoinsSpent(shardID, tokenID, listKeyImages)
 	if err != nil {
 		return nil, nil, err
@@ -424,7 +423,7 @@ func (client *IncClient) GetOTACoinLength() (map[string]map[byte]uint64, error)
 // Note that all tokens' output coins are grouped together (except PRV), there for it will return the same result for
 // two different tokenIDs (jus use the common.ConfidentialAssetID for token retrieval).
 func (client *IncClient) GetOTACoinLengthByShard(shardID byte, tokenID string) (uint64, error) {
-	if int(shardID) >= common.MaxShardNumber {
+	if !client.networkParams.IsValidShardID(int(shardID)) {
 		return 0, fmt.Errorf("invalid shardID %v", shardID)
 	}
 
@@ -514,8 +513,19 @@ func BuildAssetTags(tokenIDs []string) (map[string]*common.Hash, error) {
 	return res, nil
 }
 
-// NewOutCoinKeyFromPrivateKey creates a new rpc.OutCoinKey given the private key.
+// NewOutCoinKeyFromPrivateKey creates a new rpc.OutCoinKey given the private key. The keys are encoded w.r.t the
+// default NetworkParams; use IncClient.NewOutCoinKeyFromPrivateKey to encode them w.r.t the network of a client.
 func NewOutCoinKeyFromPrivateKey(privateKey string) (*rpc.OutCoinKey, error) {
+	return newOutCoinKeyFromPrivateKey(nil, privateKey)
+}
+
+// NewOutCoinKeyFromPrivateKey creates a new rpc.OutCoinKey given the private key, whose keys are encoded w.r.t the
+// NetworkParams of the client.
+func (client *IncClient) NewOutCoinKeyFromPrivateKey(privateKey string) (*rpc.OutCoinKey, error) {
+	return newOutCoinKeyFromPrivateKey(client.networkParams, privateKey)
+}
+
+func newOutCoinKeyFromPrivateKey(params *common.NetworkParams, privateKey string) (*rpc.OutCoinKey, error) {
 	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
 		return nil, err
@@ -525,9 +535,9 @@ func NewOutCoinKeyFromPrivateKey(privateKey string) (*rpc.OutCoinKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	paymentAddStr := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
-	otaSecretKey := keyWallet.Base58CheckSerialize(wallet.OTAKeyType)
-	viewingKeyStr := keyWallet.Base58CheckSerialize(wallet.ReadonlyKeyType)
+	paymentAddStr := keyWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params)
+	otaSecretKey := keyWallet.Base58CheckSerializeWithParams(wallet.OTAKeyType, params)
+	viewingKeyStr := keyWallet.Base58CheckSerializeWithParams(wallet.ReadonlyKeyType, params)
 
 	return rpc.NewOutCoinKey(paymentAddStr, otaSecretKey, viewingKeyStr), err
 }
@@ -559,79 +569,26 @@ func ParseCoinFromJsonResponse(b []byte) ([]jsonresult.ICoinInfo, []*big.Int, er
 }
 
 // GetListDecryptedCoins decrypts a list of ICoinInfo's using the given private key.
+// The coins are decrypted in parallel by a CoinDecryptor with MaxGetCoinThreads workers.
 func GetListDecryptedCoins(privateKey string, listOutputCoins []jsonresult.ICoinInfo) ([]coin.PlainCoin, []string, error) {
-	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	decryptor, err := NewCoinDecryptor(privateKey, MaxGetCoinThreads)
 	if err != nil {
 		return nil, nil, err
 	}
-	keySet := keyWallet.KeySet
-
-	listDecryptedOutCoins := make([]coin.PlainCoin, 0)
-	listKeyImages := make([]string, 0)
-	for _, outCoin := range listOutputCoins {
-		if outCoin.GetVersion() == 1 {
-			if outCoin.IsEncrypted() {
-				tmpCoin, ok := outCoin.(*coin.CoinV1)
-				if !ok {
-					return nil, nil, fmt.Errorf("invalid CoinV1")
-				}
-
-				decryptedCoin, err := tmpCoin.Decrypt(&keySet)
-				if err != nil {
-					return nil, nil, err
-				}
-				keyImage, err := decryptedCoin.ParseKeyImageWithPrivateKey(keyWallet.KeySet.PrivateKey)
-				if err != nil {
-					return nil, nil, err
-				}
-				decryptedCoin.SetKeyImage(keyImage)
-
-				keyImageString := base58.Base58Check{}.Encode(keyImage.ToBytesS(), common.ZeroByte)
-
-				listKeyImages = append(listKeyImages, keyImageString)
-				listDecryptedOutCoins = append(listDecryptedOutCoins, decryptedCoin)
-			} else {
-				tmpPlainCoinV1, ok := outCoin.(*coin.PlainCoinV1)
-				if !ok {
-					return nil, nil, fmt.Errorf("invalid PlaincoinV1")
-				}
-
-				keyImage, err := tmpPlainCoinV1.ParseKeyImageWithPrivateKey(keyWallet.KeySet.PrivateKey)
-				if err != nil {
-					return nil, nil, err
-				}
-				tmpPlainCoinV1.SetKeyImage(keyImage)
-
-				keyImageString := base58.Base58Check{}.Encode(keyImage.ToBytesS(), common.ZeroByte)
-
-				listKeyImages = append(listKeyImages, keyImageString)
-				listDecryptedOutCoins = append(listDecryptedOutCoins, tmpPlainCoinV1)
-			}
-		} else if outCoin.GetVersion() == 2 {
-			tmpCoinV2, ok := outCoin.(*coin.CoinV2)
-			if !ok {
-				return nil, nil, fmt.Errorf("invalid CoinV2")
-			}
-			decryptedCoin, err := tmpCoinV2.Decrypt(&keyWallet.KeySet)
-			if err != nil {
-				log.Printf("Decrypt %v error: %v\n", base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0), err)
-				continue
-			}
-			keyImage := decryptedCoin.GetKeyImage()
-			keyImageString := base58.Base58Check{}.Encode(keyImage.ToBytesS(), common.ZeroByte)
 
-			listKeyImages = append(listKeyImages, keyImageString)
-			listDecryptedOutCoins = append(listDecryptedOutCoins, decryptedCoin)
-		}
-	}
-
-	return listDecryptedOutCoins, listKeyImages, nil
+	return decryptor.Decrypt(listOutputCoins)
 }
 
 // GenerateOTAFromPaymentAddress generates a random one-time address, and TxRandom from a payment address.
 // If `senderShardParams` is not given, `senderShard` will default to the shardID of the given payment info.
 // Otherwise, the first value of `senderShardParams` will be set as the `senderShard`.
 func GenerateOTAFromPaymentAddress(paymentAddressStr string, coinType int, senderShardParams ...byte) (string, string, error) {
+	return GenerateOTAFromPaymentAddressWithParams(nil, paymentAddressStr, coinType, senderShardParams...)
+}
+
+// GenerateOTAFromPaymentAddressWithParams is the same as GenerateOTAFromPaymentAddress, but derives shards w.r.t the
+// given NetworkParams.
+func GenerateOTAFromPaymentAddressWithParams(params *common.NetworkParams, paymentAddressStr string, coinType int, senderShardParams ...byte) (string, string, error) {
 	keyWallet, err := wallet.Base58CheckDeserialize(paymentAddressStr)
 	if err != nil {
 		return "", "", err
@@ -641,9 +598,9 @@ func GenerateOTAFromPaymentAddress(paymentAddressStr string, coinType int, sende
 
 	var coinInitParams *coin.CoinParams
 	if coinType == coin.PrivacyTypeMint {
-		coinInitParams = coin.NewMintCoinParams(paymentInfo)
+		coinInitParams = coin.NewMintCoinParamsWithNetwork(params, paymentInfo)
 	} else {
-		coinInitParams = coin.NewTransferCoinParams(paymentInfo, senderShardParams...)
+		coinInitParams = coin.NewTransferCoinParamsWithNetwork(params, paymentInfo, senderShardParams...)
 	}
 
 	otaReceiver := new(coin.OTAReceiver)
@@ -212,7 +212,7 @@ func (client *IncClient) syncOutCoinV2(outCoinKey *rpc.OutCoinKey, tokenIDStr st
 		return err
 	}
 
-	shardID, err := GetShardIDFromPaymentAddress(outCoinKey.PaymentAddress())
+	shardID, err := client.GetShardIDFromPaymentAddress(outCoinKey.PaymentAddress())
 	if err != nil || shardID == 255 {
 		return fmt.Errorf("GetShardIDPaymentAddressKey failed: %v", err)
 	}
@@ -0,0 +1,182 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// genEncryptedCoins generates numCoins concealed v2 output coins sent to the given key wallet, and returns their raw bytes.
+func genEncryptedCoins(w *wallet.KeyWallet, numCoins int) ([][]byte, []uint64) {
+	res := make([][]byte, 0)
+	amounts := make([]uint64, 0)
+	for i := 0; i < numCoins; i++ {
+		amount := 1 + common.RandUint64()%1000000
+		c, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(key.InitPaymentInfo(w.KeySet.PaymentAddress, amount, []byte{})))
+		if err != nil {
+			panic(err)
+		}
+		err = c.ConcealOutputCoin(w.KeySet.PaymentAddress.GetPublicView())
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, c.Bytes())
+		amounts = append(amounts, amount)
+	}
+
+	return res, amounts
+}
+
+func parseCoins(rawCoins [][]byte) []jsonresult.ICoinInfo {
+	res := make([]jsonresult.ICoinInfo, 0)
+	for _, rawCoin := range rawCoins {
+		c, err := coin.NewCoinFromByte(rawCoin)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, c.(jsonresult.ICoinInfo))
+	}
+
+	return res
+}
+
+// decryptCoinsSequentially decrypts coins one by one with CoinV2.Decrypt (i.e, the previous GetListDecryptedCoins).
+func decryptCoinsSequentially(keySet *key.KeySet, outCoins []jsonresult.ICoinInfo) ([]coin.PlainCoin, []string) {
+	decryptedCoins := make([]coin.PlainCoin, 0)
+	keyImages := make([]string, 0)
+	for _, outCoin := range outCoins {
+		decryptedCoin, err := outCoin.(*coin.CoinV2).Decrypt(keySet)
+		if err != nil {
+			continue
+		}
+		decryptedCoins = append(decryptedCoins, decryptedCoin)
+		keyImages = append(keyImages, base58.Base58Check{}.Encode(decryptedCoin.GetKeyImage().ToBytesS(), common.ZeroByte))
+	}
+
+	return decryptedCoins, keyImages
+}
+
+func TestCoinDecryptor_Decrypt(t *testing.T) {
+	w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+	privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+	other, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+
+	rawCoins, amounts := genEncryptedCoins(w, 50)
+	otherRawCoins, _ := genEncryptedCoins(other, 5)
+
+	expectedCoins, expectedKeyImages := decryptCoinsSequentially(&w.KeySet, parseCoins(rawCoins))
+
+	for _, numWorkers := range []int{1, 4, 16} {
+		decryptor, err := NewCoinDecryptor(privateKey, numWorkers)
+		if err != nil {
+			panic(err)
+		}
+
+		// coins of another key cannot be decrypted, and are skipped
+		outCoins := append(parseCoins(rawCoins), parseCoins(otherRawCoins)...)
+		decryptedCoins, keyImages, err := decryptor.Decrypt(outCoins)
+		if err != nil {
+			panic(err)
+		}
+		if len(decryptedCoins) != len(rawCoins) || len(keyImages) != len(rawCoins) {
+			panic(fmt.Sprintf("expect %v decrypted coins, got %v", len(rawCoins), len(decryptedCoins)))
+		}
+		for i, decryptedCoin := range decryptedCoins {
+			if decryptedCoin.GetValue() != amounts[i] || decryptedCoin.GetValue() != expectedCoins[i].GetValue() {
+				panic(fmt.Sprintf("coin #%v: expect value %v, got %v", i, amounts[i], decryptedCoin.GetValue()))
+			}
+			if keyImages[i] != expectedKeyImages[i] {
+				panic(fmt.Sprintf("coin #%v: expect key image %v, got %v", i, expectedKeyImages[i], keyImages[i]))
+			}
+			if (base58.Base58Check{}).Encode(decryptedCoin.GetKeyImage().ToBytesS(), common.ZeroByte) != keyImages[i] {
+				panic(fmt.Sprintf("coin #%v: key image not set", i))
+			}
+		}
+
+		// the stream delivers each coin exactly once
+		seen := make(map[int]bool)
+		for res := range decryptor.DecryptStream(parseCoins(rawCoins), nil) {
+			if res.Err != nil {
+				panic(res.Err)
+			}
+			if seen[res.Index] {
+				panic(fmt.Sprintf("coin #%v delivered twice", res.Index))
+			}
+			seen[res.Index] = true
+			if res.KeyImage != expectedKeyImages[res.Index] {
+				panic(fmt.Sprintf("coin #%v: expect key image %v, got %v", res.Index, expectedKeyImages[res.Index], res.KeyImage))
+			}
+		}
+		if len(seen) != len(rawCoins) {
+			panic(fmt.Sprintf("expect %v results, got %v", len(rawCoins), len(seen)))
+		}
+
+		// the stream is closed when the consumer stops reading
+		done := make(chan struct{})
+		resChan := decryptor.DecryptStream(parseCoins(rawCoins), done)
+		<-resChan
+		close(done)
+		timeout := time.After(10 * time.Second)
+	drain:
+		for {
+			select {
+			case _, ok := <-resChan:
+				if !ok {
+					break drain
+				}
+			case <-timeout:
+				panic("the stream is not closed after cancellation")
+			}
+		}
+	}
+}
+
+func benchmarkDecryptCoins(b *testing.B, numCoins int, decrypt func(privateKey string, outCoins []jsonresult.ICoinInfo)) {
+	w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+	privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+	rawCoins, _ := genEncryptedCoins(w, numCoins)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		outCoins := parseCoins(rawCoins)
+		b.StartTimer()
+		decrypt(privateKey, outCoins)
+	}
+}
+
+func BenchmarkDecryptCoins_Sequential(b *testing.B) {
+	benchmarkDecryptCoins(b, 1000, func(privateKey string, outCoins []jsonresult.ICoinInfo) {
+		w, _ := wallet.Base58CheckDeserialize(privateKey)
+		decryptCoinsSequentially(&w.KeySet, outCoins)
+	})
+}
+
+func BenchmarkDecryptCoins_CoinDecryptor1Worker(b *testing.B) {
+	benchmarkDecryptCoins(b, 1000, func(privateKey string, outCoins []jsonresult.ICoinInfo) {
+		decryptor, _ := NewCoinDecryptor(privateKey, 1)
+		_, _, _ = decryptor.Decrypt(outCoins)
+	})
+}
+
+func BenchmarkDecryptCoins_CoinDecryptor(b *testing.B) {
+	benchmarkDecryptCoins(b, 1000, func(privateKey string, outCoins []jsonresult.ICoinInfo) {
+		_, _, _ = GetListDecryptedCoins(privateKey, outCoins)
+	})
+}
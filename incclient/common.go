@@ -20,6 +20,7 @@ type TxParam struct {
 	fee              uint64
 	txTokenParam     *TxTokenParam
 	md               metadata.Metadata
+	memoList         []string
 
 	// additional parameters for special functions
 	//	- "PRVInputCoins": a coinParams consisting of PRV input coins and indices used to create a transaction with given
@@ -37,6 +38,7 @@ type TxTokenParam struct {
 	amountList   []uint64
 	hasTokenFee  bool
 	tokenFee     uint64
+	memoList     []string
 	kArgs        map[string]interface{}
 }
 
@@ -80,18 +82,44 @@ func NewTxTokenParam(tokenID string, tokenType int, receiverList []string, amoun
 	}
 }
 
-// PrivateKeyToPaymentAddress returns the payment address for its private key corresponding to the key type.
+// SetMemos attaches a memo to each PRV receiver of a TxParam. Memos are encrypted to their receivers (see EncryptMemo);
+// an empty memo leaves the corresponding output without a memo.
+func (param *TxParam) SetMemos(memoList []string) *TxParam {
+	param.memoList = memoList
+	return param
+}
+
+// SetMemos attaches a memo to each token receiver of a TxTokenParam. Memos are encrypted to their receivers (see
+// EncryptMemo); an empty memo leaves the corresponding output without a memo.
+func (param *TxTokenParam) SetMemos(memoList []string) *TxTokenParam {
+	param.memoList = memoList
+	return param
+}
+
+// PrivateKeyToPaymentAddress returns the payment address for its private key corresponding to the key type,
+// encoded w.r.t the default NetworkParams. Use IncClient.PrivateKeyToPaymentAddress to encode it w.r.t the network
+// of a client.
 // KeyType should be -1, 0, 1 where
 //	- -1: payment address of version 2
 //	- 0: payment address of version 1 with old encoding
 //	- 1: payment address of version 1 with new encoding
 func PrivateKeyToPaymentAddress(privateKey string, keyType int) string {
+	return privateKeyToPaymentAddress(nil, privateKey, keyType)
+}
+
+// PrivateKeyToPaymentAddress returns the payment address for its private key corresponding to the key type,
+// encoded w.r.t the NetworkParams of the client. See PrivateKeyToPaymentAddress for the supported key types.
+func (client *IncClient) PrivateKeyToPaymentAddress(privateKey string, keyType int) string {
+	return privateKeyToPaymentAddress(client.networkParams, privateKey, keyType)
+}
+
+func privateKeyToPaymentAddress(params *common.NetworkParams, privateKey string, keyType int) string {
 	keyWallet, _ := wallet.Base58CheckDeserialize(privateKey)
 	err := keyWallet.KeySet.InitFromPrivateKey(&keyWallet.KeySet.PrivateKey)
 	if err != nil {
 		return ""
 	}
-	paymentAddStr := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	paymentAddStr := keyWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params)
 	switch keyType {
 	case 0: //Old address, old encoding
 		addr, _ := wallet.GetPaymentAddressV1(paymentAddStr, false)
@@ -120,10 +148,22 @@ func PrivateKeyToPublicKey(privateKey string) []byte {
 	return keyWallet.KeySet.PaymentAddress.Pk
 }
 
-// PrivateKeyToPrivateOTAKey returns the private OTA key of a private key.
+// PrivateKeyToPrivateOTAKey returns the private OTA key of a private key, encoded w.r.t the default NetworkParams.
 //
 // If the private key is invalid, it returns an empty string.
 func PrivateKeyToPrivateOTAKey(privateKey string) string {
+	return privateKeyToPrivateOTAKey(nil, privateKey)
+}
+
+// PrivateKeyToPrivateOTAKey returns the private OTA key of a private key, encoded w.r.t the NetworkParams of the
+// client.
+//
+// If the private key is invalid, it returns an empty string.
+func (client *IncClient) PrivateKeyToPrivateOTAKey(privateKey string) string {
+	return privateKeyToPrivateOTAKey(client.networkParams, privateKey)
+}
+
+func privateKeyToPrivateOTAKey(params *common.NetworkParams, privateKey string) string {
 	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
 		Logger.Println(err)
@@ -135,13 +175,24 @@ func PrivateKeyToPrivateOTAKey(privateKey string) string {
 		return ""
 	}
 
-	return keyWallet.Base58CheckSerialize(wallet.OTAKeyType)
+	return keyWallet.Base58CheckSerializeWithParams(wallet.OTAKeyType, params)
 }
 
-// PrivateKeyToReadonlyKey returns the readonly key of a private key.
+// PrivateKeyToReadonlyKey returns the readonly key of a private key, encoded w.r.t the default NetworkParams.
 //
 // If the private key is invalid, it returns an empty string.
 func PrivateKeyToReadonlyKey(privateKey string) string {
+	return privateKeyToReadonlyKey(nil, privateKey)
+}
+
+// PrivateKeyToReadonlyKey returns the readonly key of a private key, encoded w.r.t the NetworkParams of the client.
+//
+// If the private key is invalid, it returns an empty string.
+func (client *IncClient) PrivateKeyToReadonlyKey(privateKey string) string {
+	return privateKeyToReadonlyKey(client.networkParams, privateKey)
+}
+
+func privateKeyToReadonlyKey(params *common.NetworkParams, privateKey string) string {
 	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
 		Logger.Println(err)
@@ -154,7 +205,7 @@ func PrivateKeyToReadonlyKey(privateKey string) string {
 	}
 
 	err = keyWallet.KeySet.InitFromPrivateKey(&keyWallet.KeySet.PrivateKey)
-	return keyWallet.Base58CheckSerialize(wallet.ReadonlyKeyType)
+	return keyWallet.Base58CheckSerializeWithParams(wallet.ReadonlyKeyType, params)
 }
 
 // PrivateKeyToMiningKey returns the mining key of a private key.
@@ -174,31 +225,56 @@ func PrivateKeyToMiningKey(privateKey string) string {
 	return miningKey
 }
 
-// GetShardIDFromPrivateKey returns the shardID where the private key resides in.
+// GetShardIDFromPrivateKey returns the shardID where the private key resides in, w.r.t the default NetworkParams.
+// Use IncClient.GetShardIDFromPrivateKey to derive the shard w.r.t the network of a client.
 //
-// If the private key is invalid, it returns 255.
+// If the private key is invalid, it returns 0.
 func GetShardIDFromPrivateKey(privateKey string) byte {
+	return getShardIDFromPrivateKey(nil, privateKey)
+}
+
+// GetShardIDFromPaymentAddress returns the shardID where the payment address resides in, w.r.t the default
+// NetworkParams. Use IncClient.GetShardIDFromPaymentAddress to derive the shard w.r.t the network of a client.
+//
+// If the payment address is invalid, it returns 255.
+func GetShardIDFromPaymentAddress(addrStr string) (byte, error) {
+	return getShardIDFromPaymentAddress(nil, addrStr)
+}
+
+// GetShardIDFromPrivateKey returns the shardID where the private key resides in, w.r.t the NetworkParams of the client.
+//
+// If the private key is invalid, it returns 0.
+func (client *IncClient) GetShardIDFromPrivateKey(privateKey string) byte {
+	return getShardIDFromPrivateKey(client.networkParams, privateKey)
+}
+
+// GetShardIDFromPaymentAddress returns the shardID where the payment address resides in, w.r.t the NetworkParams of
+// the client.
+//
+// If the payment address is invalid, it returns 255.
+func (client *IncClient) GetShardIDFromPaymentAddress(addrStr string) (byte, error) {
+	return getShardIDFromPaymentAddress(client.networkParams, addrStr)
+}
+
+func getShardIDFromPrivateKey(params *common.NetworkParams, privateKey string) byte {
 	pubKey := PrivateKeyToPublicKey(privateKey)
 	if pubKey == nil {
 		return 0
 	}
-	return common.GetShardIDFromLastByte(pubKey[len(pubKey)-1])
+	return params.GetShardIDFromLastByte(pubKey[len(pubKey)-1])
 }
 
-// GetShardIDFromPaymentAddress returns the shardID where the payment address resides in.
-//
-// If the private key is invalid, it returns 255.
-func GetShardIDFromPaymentAddress(addrStr string) (byte, error) {
+func getShardIDFromPaymentAddress(params *common.NetworkParams, addrStr string) (byte, error) {
 	keyWallet, err := wallet.Base58CheckDeserialize(addrStr)
 	if err != nil {
 		return 255, err
 	}
 
 	pubKey := keyWallet.KeySet.PaymentAddress.Pk
-	if pubKey == nil {
+	if len(pubKey) == 0 {
 		return 255, fmt.Errorf("publicKey is nil")
 	}
-	return common.GetShardIDFromLastByte(pubKey[len(pubKey)-1]), nil
+	return params.GetShardIDFromLastByte(pubKey[len(pubKey)-1]), nil
 }
 
 // AssertPaymentAddressAndTxVersion checks if a string payment address is supported by the underlying transaction.
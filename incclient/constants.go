@@ -57,13 +57,14 @@ const (
 )
 
 const (
-	DefaultPRVFee            = uint64(100000000) // 0.1 PRV
-	defaultNftRequiredAmount = 100
-	MaxInputSize             = 30
-	MaxOutputSize            = 30
-	prvInCoinKey             = "PRVInputCoins"
-	tokenInCoinKey           = "TokenInputCoins"
-	defaultCacheDirectory    = ".cache"
+	DefaultPRVFee             = uint64(100000000)     // 0.1 PRV
+	DefaultShardStakingAmount = uint64(1750000000000) // 1750 PRV
+	defaultNftRequiredAmount  = 100
+	MaxInputSize              = 30
+	MaxOutputSize             = 30
+	prvInCoinKey              = "PRVInputCoins"
+	tokenInCoinKey            = "TokenInputCoins"
+	defaultCacheDirectory     = ".cache"
 
 	InscMinFeePerKB = uint64(1000000000)  // 1 PRV
 	InscMinFeePerTx = uint64(10000000000) // 10 PRV
@@ -0,0 +1,303 @@
+package incclient
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+const (
+	// DefaultHDGapLimit is the default number of consecutive unused children after which the account discovery stops.
+	DefaultHDGapLimit = 20
+
+	// defaultHDFirstChildIndex is the first child index scanned by the account discovery. Child 0 is skipped to be
+	// consistent with the previous ImportAccount, which treats the master wallet as the first account.
+	defaultHDFirstChildIndex = 1
+)
+
+// HDAccount is an account derived from an HD wallet, i.e, m/44'/587'/AccountIndex'/0/ChildIndex.
+type HDAccount struct {
+	// AccountIndex is the BIP-44 account index.
+	AccountIndex uint32
+
+	// ChildIndex is the index of the child in the BIP-44 account.
+	ChildIndex uint32
+
+	// IsMaster indicates whether the account is the master wallet itself (in which case, the indices are ignored).
+	IsMaster bool
+
+	// Label is a user-defined name of the account.
+	Label string
+
+	// Used indicates whether the account has on-chain activity.
+	Used bool
+
+	KeyWallet      *wallet.KeyWallet
+	PrivateKey     string
+	PaymentAddress string
+}
+
+// HDActivityChecker checks if an HDAccount has on-chain activity.
+type HDActivityChecker func(account *HDAccount) (bool, error)
+
+// HDWalletManager manages the accounts derived from an HD wallet. Accounts are derived lazily, and can be discovered
+// following the BIP-44 account discovery: children are scanned in order until GapLimit consecutive children have no
+// on-chain activity.
+type HDWalletManager struct {
+	client *IncClient
+	master *wallet.KeyWallet
+
+	// GapLimit is the number of consecutive unused children after which the discovery stops.
+	GapLimit uint32
+
+	// ActivityChecker checks if an account has on-chain activity. By default, an account is used if it has received at
+	// least one transaction.
+	ActivityChecker HDActivityChecker
+
+	mtx      sync.Mutex
+	accounts map[uint32]map[uint32]*HDAccount // accountIndex => childIndex => HDAccount
+}
+
+// NewHDWalletManager creates a new HDWalletManager from a BIP39 mnemonic.
+func NewHDWalletManager(client *IncClient, mnemonic string) (*HDWalletManager, error) {
+	masterWallet, err := wallet.NewMasterKeyFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHDWalletManagerFromMasterKey(client, masterWallet)
+}
+
+// NewHDWalletManagerFromMasterKey creates a new HDWalletManager from a master KeyWallet.
+func NewHDWalletManagerFromMasterKey(client *IncClient, masterWallet *wallet.KeyWallet) (*HDWalletManager, error) {
+	if masterWallet == nil || masterWallet.HDKey == nil {
+		return nil, fmt.Errorf("HDKey not found")
+	}
+
+	m := &HDWalletManager{
+		client:   client,
+		master:   masterWallet,
+		GapLimit: DefaultHDGapLimit,
+		accounts: make(map[uint32]map[uint32]*HDAccount),
+	}
+	m.ActivityChecker = m.hasReceivedTxs
+
+	return m, nil
+}
+
+// GetMasterAccount returns the master wallet as an HDAccount.
+func (m *HDWalletManager) GetMasterAccount() *HDAccount {
+	return newHDAccount(m.master, 0, 0, true, m.getNetworkParams())
+}
+
+// DeriveAccount returns the child childIdx of the BIP-44 account accountIdx. Derived accounts are cached.
+func (m *HDWalletManager) DeriveAccount(accountIdx, childIdx uint32) (*HDAccount, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if acc, ok := m.accounts[accountIdx][childIdx]; ok {
+		return acc, nil
+	}
+
+	childWallet, err := m.master.DeriveAccountChild(accountIdx, childIdx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive account %v, child %v: %v", accountIdx, childIdx, err)
+	}
+	acc := newHDAccount(childWallet, accountIdx, childIdx, false, m.getNetworkParams())
+	if m.accounts[accountIdx] == nil {
+		m.accounts[accountIdx] = make(map[uint32]*HDAccount)
+	}
+	m.accounts[accountIdx][childIdx] = acc
+
+	return acc, nil
+}
+
+// Discover scans the children of the BIP-44 account accountIdx, and returns those with on-chain activity. The scan
+// stops once GapLimit consecutive children have no activity.
+func (m *HDWalletManager) Discover(accountIdx uint32) ([]*HDAccount, error) {
+	if m.ActivityChecker == nil {
+		return nil, fmt.Errorf("activity checker not found")
+	}
+	gapLimit := m.GapLimit
+	if gapLimit == 0 {
+		gapLimit = DefaultHDGapLimit
+	}
+
+	res := make([]*HDAccount, 0)
+	gap := uint32(0)
+	for childIdx := uint32(defaultHDFirstChildIndex); gap < gapLimit; childIdx++ {
+		acc, err := m.DeriveAccount(accountIdx, childIdx)
+		if err != nil {
+			return nil, err
+		}
+		used, err := m.ActivityChecker(acc)
+		if err != nil {
+			return nil, fmt.Errorf("childIdx %v error: %v", childIdx, err)
+		}
+		m.mtx.Lock()
+		acc.Used = used
+		m.mtx.Unlock()
+		if used {
+			res = append(res, acc)
+			gap = 0
+		} else {
+			gap++
+		}
+	}
+
+	return res, nil
+}
+
+// DiscoverAll discovers the accounts of the given BIP-44 account indices (by default, only account 0), and returns the
+// master wallet followed by all used accounts.
+func (m *HDWalletManager) DiscoverAll(accountIndices ...uint32) ([]*HDAccount, error) {
+	if len(accountIndices) == 0 {
+		accountIndices = []uint32{0}
+	}
+
+	res := []*HDAccount{m.GetMasterAccount()}
+	for _, accountIdx := range accountIndices {
+		accounts, err := m.Discover(accountIdx)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, accounts...)
+	}
+
+	return res, nil
+}
+
+// GetUsedAccounts returns all derived accounts marked as used, sorted by their indices.
+func (m *HDWalletManager) GetUsedAccounts() []*HDAccount {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	res := make([]*HDAccount, 0)
+	for _, children := range m.accounts {
+		for _, acc := range children {
+			if acc.Used {
+				res = append(res, acc)
+			}
+		}
+	}
+	sortHDAccounts(res)
+
+	return res
+}
+
+// SetLabel sets the label of the child childIdx of the BIP-44 account accountIdx.
+func (m *HDWalletManager) SetLabel(accountIdx, childIdx uint32, label string) error {
+	if label != "" {
+		if acc, _ := m.GetAccountByLabel(label); acc != nil && (acc.AccountIndex != accountIdx || acc.ChildIndex != childIdx) {
+			return fmt.Errorf("label %v already exists", label)
+		}
+	}
+	acc, err := m.DeriveAccount(accountIdx, childIdx)
+	if err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	acc.Label = label
+	m.mtx.Unlock()
+
+	return nil
+}
+
+// GetAccountByLabel returns the derived account with the given label.
+func (m *HDWalletManager) GetAccountByLabel(label string) (*HDAccount, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, children := range m.accounts {
+		for _, acc := range children {
+			if acc.Label == label {
+				return acc, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("account with label %v not found", label)
+}
+
+// GetBalances returns the balances of the given tokenID for the master wallet and all used accounts, and their total.
+// The result is a mapping from payment addresses to balances.
+func (m *HDWalletManager) GetBalances(tokenID string) (map[string]uint64, uint64, error) {
+	accounts := append([]*HDAccount{m.GetMasterAccount()}, m.GetUsedAccounts()...)
+
+	res := make(map[string]uint64)
+	total := uint64(0)
+	for _, acc := range accounts {
+		balance, err := m.client.GetBalance(acc.PrivateKey, tokenID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cannot get balance of %v: %v", acc.PaymentAddress, err)
+		}
+		res[acc.PaymentAddress] = balance
+		total += balance
+	}
+
+	return res, total, nil
+}
+
+// GetAllBalances returns the aggregated non-zero v2 balances (see GetAllBalancesV2) of the master wallet and all used
+// accounts.
+func (m *HDWalletManager) GetAllBalances() (map[string]uint64, error) {
+	accounts := append([]*HDAccount{m.GetMasterAccount()}, m.GetUsedAccounts()...)
+
+	res := make(map[string]uint64)
+	for _, acc := range accounts {
+		balances, err := m.client.GetAllBalancesV2(acc.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get balances of %v: %v", acc.PaymentAddress, err)
+		}
+		for tokenID, balance := range balances {
+			res[tokenID] += balance
+		}
+	}
+
+	return res, nil
+}
+
+// hasReceivedTxs is the default HDActivityChecker.
+func (m *HDWalletManager) hasReceivedTxs(acc *HDAccount) (bool, error) {
+	if m.client == nil {
+		return false, fmt.Errorf("client not found")
+	}
+	receivedTxs, err := m.client.GetTransactionHashesByReceiver(acc.PaymentAddress)
+	if err != nil {
+		return false, err
+	}
+
+	return len(receivedTxs) > 0, nil
+}
+
+// getNetworkParams returns the NetworkParams of the underlying client (if any).
+func (m *HDWalletManager) getNetworkParams() *common.NetworkParams {
+	if m.client == nil {
+		return nil
+	}
+	return m.client.networkParams
+}
+
+func newHDAccount(w *wallet.KeyWallet, accountIdx, childIdx uint32, isMaster bool, params *common.NetworkParams) *HDAccount {
+	return &HDAccount{
+		AccountIndex:   accountIdx,
+		ChildIndex:     childIdx,
+		IsMaster:       isMaster,
+		KeyWallet:      w,
+		PrivateKey:     w.Base58CheckSerializeWithParams(wallet.PrivateKeyType, params),
+		PaymentAddress: w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params),
+	}
+}
+
+func sortHDAccounts(accounts []*HDAccount) {
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].AccountIndex != accounts[j].AccountIndex {
+			return accounts[i].AccountIndex < accounts[j].AccountIndex
+		}
+		return accounts[i].ChildIndex < accounts[j].ChildIndex
+	})
+}
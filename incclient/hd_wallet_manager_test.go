@@ -0,0 +1,129 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestHDWalletManager_Discover(t *testing.T) {
+	masterWallet, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		manager, err := NewHDWalletManagerFromMasterKey(nil, masterWallet)
+		if err != nil {
+			panic(err)
+		}
+		manager.GapLimit = uint32(1 + common.RandInt()%10)
+
+		// mark some random children as used, with gaps smaller than the gap limit
+		usedChildren := make(map[uint32]bool)
+		childIdx := uint32(defaultHDFirstChildIndex)
+		numUsed := 1 + common.RandInt()%5
+		for j := 0; j < numUsed; j++ {
+			childIdx += uint32(common.RandInt()) % manager.GapLimit
+			usedChildren[childIdx] = true
+			childIdx++
+		}
+		// this child is beyond the gap limit, and must not be discovered
+		usedChildren[childIdx+manager.GapLimit] = true
+
+		numChecked := 0
+		manager.ActivityChecker = func(account *HDAccount) (bool, error) {
+			if account.AccountIndex != 1 {
+				return false, fmt.Errorf("expect account 1, got %v", account.AccountIndex)
+			}
+			numChecked++
+			return usedChildren[account.ChildIndex], nil
+		}
+
+		accounts, err := manager.Discover(1)
+		if err != nil {
+			panic(err)
+		}
+		if len(accounts) != numUsed {
+			panic(fmt.Sprintf("expect %v accounts, got %v", numUsed, len(accounts)))
+		}
+		if numChecked != int(childIdx-defaultHDFirstChildIndex+manager.GapLimit) {
+			panic(fmt.Sprintf("expect %v checks, got %v", childIdx-defaultHDFirstChildIndex+manager.GapLimit, numChecked))
+		}
+		for _, account := range accounts {
+			if !usedChildren[account.ChildIndex] {
+				panic(fmt.Sprintf("child %v is not used", account.ChildIndex))
+			}
+			expectedWallet, err := masterWallet.DeriveAccountChild(1, account.ChildIndex)
+			if err != nil {
+				panic(err)
+			}
+			if account.PrivateKey != expectedWallet.Base58CheckSerialize(wallet.PrivateKeyType) {
+				panic(fmt.Sprintf("child %v: invalid private key", account.ChildIndex))
+			}
+		}
+		if len(manager.GetUsedAccounts()) != numUsed {
+			panic(fmt.Sprintf("expect %v used accounts, got %v", numUsed, len(manager.GetUsedAccounts())))
+		}
+
+		// labels are unique
+		err = manager.SetLabel(accounts[0].AccountIndex, accounts[0].ChildIndex, "savings")
+		if err != nil {
+			panic(err)
+		}
+		err = manager.SetLabel(0, 1, "savings")
+		if err == nil {
+			panic("expect an error")
+		}
+		account, err := manager.GetAccountByLabel("savings")
+		if err != nil {
+			panic(err)
+		}
+		if account.ChildIndex != accounts[0].ChildIndex {
+			panic(fmt.Sprintf("expect child %v, got %v", accounts[0].ChildIndex, account.ChildIndex))
+		}
+	}
+
+	// account 0 is the same as DeriveChild
+	child, err := masterWallet.DeriveChild(3)
+	if err != nil {
+		panic(err)
+	}
+	accountChild, err := masterWallet.DeriveAccountChild(0, 3)
+	if err != nil {
+		panic(err)
+	}
+	if child.Base58CheckSerialize(wallet.PrivateKeyType) != accountChild.Base58CheckSerialize(wallet.PrivateKeyType) {
+		panic("DeriveChild and DeriveAccountChild mismatch")
+	}
+}
+
+func TestHDWalletManager_GetAllBalances(t *testing.T) {
+	var err error
+	ic, err = NewTestNet1Client()
+	if err != nil {
+		panic(err)
+	}
+
+	mnemonic := "" // input the mnemonic
+	manager, err := NewHDWalletManager(ic, mnemonic)
+	if err != nil {
+		panic(err)
+	}
+
+	accounts, err := manager.DiscoverAll(0, 1)
+	if err != nil {
+		panic(err)
+	}
+	for _, account := range accounts {
+		fmt.Printf("Account %v, child %v: %v\n", account.AccountIndex, account.ChildIndex, account.PaymentAddress)
+	}
+
+	balances, err := manager.GetAllBalances()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Balances: %v\n", balances)
+}
@@ -17,14 +17,26 @@ type IncClient struct {
 	// the EVM-RPC servers
 	evmServers map[int]*rpc.RPCServer
 
+	// the addresses of the vault contracts on the EVM networks
+	evmVaults map[int]string
+
 	// the parameters used in the v4 portal for BTC
 	btcPortalParams *BTCPortalV4Params
 
 	// the version of the client
 	version int
 
+	// the name of the network the client is interacting with (e.g, mainnet, testnet)
+	network string
+
+	// the parameters of the Incognito network the client is interacting with
+	networkParams *common.NetworkParams
+
 	// the utxoCache of the client
 	cache *utxoCache
+
+	// the recorder of simulated transactions, if the client is in dry-run mode
+	dryRun *dryRunRecorder
 }
 
 // NewTestNetClient creates a new IncClient with the test-net environment.
@@ -36,12 +48,20 @@ func NewTestNetClient() (*IncClient, error) {
 		rpc.PLGNetworkID: rpc.NewRPCServer(TestNetPLGHost),
 		rpc.FTMNetworkID: rpc.NewRPCServer(TestNetFTMHost),
 	}
+	evmVaults := map[int]string{
+		rpc.ETHNetworkID: TestNetETHContractAddressStr,
+		rpc.BSCNetworkID: TestNetBSCContractAddressStr,
+		rpc.PLGNetworkID: TestNetPLGContractAddressStr,
+		rpc.FTMNetworkID: TestNetFTMContractAddressStr,
+	}
 
 	incClient := IncClient{
 		rpcServer:       rpcServer,
 		evmServers:      evmServers,
+		evmVaults:       evmVaults,
 		btcPortalParams: &testNetBTCPortalV4Params,
 		version:         TestNetPrivacyVersion,
+		network:         "testnet",
 	}
 
 	activeShards, err := incClient.GetActiveShard()
@@ -51,11 +71,9 @@ func NewTestNetClient() (*IncClient, error) {
 
 	Logger.Printf("Init to %v, activeShards: %v\n", TestNetFullNode, activeShards)
 
-	common.MaxShardNumber = activeShards
-	if incClient.version == 1 {
-		common.AddressVersion = 0
-	} else if incClient.version == 2 {
-		common.AddressVersion = 1
+	incClient.networkParams, err = newNetworkParams(activeShards, incClient.version)
+	if err != nil {
+		return nil, err
 	}
 
 	return &incClient, nil
@@ -87,12 +105,21 @@ func NewTestNet1Client() (*IncClient, error) {
 		rpc.PLGNetworkID: rpc.NewRPCServer(TestNet1PLGHost),
 		rpc.FTMNetworkID: rpc.NewRPCServer(TestNet1FTMHost),
 	}
+	evmVaults := map[int]string{
+		rpc.ETHNetworkID: TestNet1ETHContractAddressStr,
+		rpc.BSCNetworkID: TestNet1BSCContractAddressStr,
+		rpc.PLGNetworkID: TestNet1PLGContractAddressStr,
+		rpc.FTMNetworkID: TestNet1FTMContractAddressStr,
+	}
 
 	incClient := IncClient{
 		rpcServer:       rpcServer,
 		evmServers:      evmServers,
+		evmVaults:       evmVaults,
 		btcPortalParams: &testNet1BTCPortalV4Params,
-		version:         TestNet1PrivacyVersion}
+		version:         TestNet1PrivacyVersion,
+		network:         "testnet1",
+	}
 
 	activeShards, err := incClient.GetActiveShard()
 	if err != nil {
@@ -101,11 +128,9 @@ func NewTestNet1Client() (*IncClient, error) {
 
 	Logger.Printf("Init to %v, activeShards: %v\n", TestNet1FullNode, activeShards)
 
-	common.MaxShardNumber = activeShards
-	if incClient.version == 1 {
-		common.AddressVersion = 0
-	} else if incClient.version == 2 {
-		common.AddressVersion = 1
+	incClient.networkParams, err = newNetworkParams(activeShards, incClient.version)
+	if err != nil {
+		return nil, err
 	}
 
 	return &incClient, nil
@@ -137,12 +162,21 @@ func NewMainNetClient() (*IncClient, error) {
 		rpc.PLGNetworkID: rpc.NewRPCServer(MainNetPLGHost),
 		rpc.FTMNetworkID: rpc.NewRPCServer(MainNetFTMHost),
 	}
+	evmVaults := map[int]string{
+		rpc.ETHNetworkID: MainNetETHContractAddressStr,
+		rpc.BSCNetworkID: MainNetBSCContractAddressStr,
+		rpc.PLGNetworkID: MainNetPLGContractAddressStr,
+		rpc.FTMNetworkID: MainNetFTMContractAddressStr,
+	}
 
 	incClient := IncClient{
 		rpcServer:       rpcServer,
 		evmServers:      evmServers,
+		evmVaults:       evmVaults,
 		btcPortalParams: &mainNetBTCPortalV4Params,
-		version:         MainNetPrivacyVersion}
+		version:         MainNetPrivacyVersion,
+		network:         "mainnet",
+	}
 
 	activeShards, err := incClient.GetActiveShard()
 	if err != nil {
@@ -151,11 +185,9 @@ func NewMainNetClient() (*IncClient, error) {
 
 	Logger.Printf("Init to %v, activeShards: %v\n", MainNetFullNode, activeShards)
 
-	common.MaxShardNumber = activeShards
-	if incClient.version == 1 {
-		common.AddressVersion = 0
-	} else if incClient.version == 2 {
-		common.AddressVersion = 1
+	incClient.networkParams, err = newNetworkParams(activeShards, incClient.version)
+	if err != nil {
+		return nil, err
 	}
 
 	return &incClient, nil
@@ -184,12 +216,18 @@ func NewLocalClient(port string) (*IncClient, error) {
 	evmServers := map[int]*rpc.RPCServer{
 		rpc.ETHNetworkID: rpc.NewRPCServer(LocalETHHost),
 	}
+	evmVaults := map[int]string{
+		rpc.ETHNetworkID: LocalETHContractAddressStr,
+	}
 
 	incClient := IncClient{
 		rpcServer:       rpcServer,
 		evmServers:      evmServers,
+		evmVaults:       evmVaults,
 		btcPortalParams: &localBTCPortalV4Params,
-		version:         LocalPrivacyVersion}
+		version:         LocalPrivacyVersion,
+		network:         "local",
+	}
 	if port != "" {
 		incClient.rpcServer = rpc.NewRPCServer(fmt.Sprintf("http://127.0.0.1:%v", port))
 	}
@@ -201,11 +239,9 @@ func NewLocalClient(port string) (*IncClient, error) {
 
 	Logger.Printf("Init to %v, activeShards: %v\n", LocalFullNode, activeShards)
 
-	common.MaxShardNumber = activeShards
-	if incClient.version == 1 {
-		common.AddressVersion = 0
-	} else if incClient.version == 2 {
-		common.AddressVersion = 1
+	incClient.networkParams, err = newNetworkParams(activeShards, incClient.version)
+	if err != nil {
+		return nil, err
 	}
 
 	return &incClient, nil
@@ -241,25 +277,44 @@ func NewIncClient(fullNode, ethNode string, version int, networks ...string) (*I
 		rpc.BSCNetworkID: rpc.NewRPCServer(MainNetBSCHost),
 		rpc.PLGNetworkID: rpc.NewRPCServer(MainNetPLGHost),
 	}
+	evmVaults := map[int]string{
+		rpc.ETHNetworkID: MainNetETHContractAddressStr,
+		rpc.BSCNetworkID: MainNetBSCContractAddressStr,
+		rpc.PLGNetworkID: MainNetPLGContractAddressStr,
+	}
 
 	incClient := IncClient{
 		rpcServer:       rpcServer,
 		evmServers:      evmServers,
+		evmVaults:       evmVaults,
 		btcPortalParams: &mainNetBTCPortalV4Params,
 		version:         version,
+		network:         "mainnet",
 	}
 	if len(networks) > 0 {
-		switch strings.ToLower(networks[0]) {
+		incClient.network = strings.ToLower(networks[0])
+		switch incClient.network {
 		case "testnet":
 			incClient.btcPortalParams = &testNetBTCPortalV4Params
 			incClient.evmServers[rpc.BSCNetworkID] = rpc.NewRPCServer(TestNetBSCHost)
 			incClient.evmServers[rpc.PLGNetworkID] = rpc.NewRPCServer(TestNetPLGHost)
+			incClient.evmVaults = map[int]string{
+				rpc.ETHNetworkID: TestNetETHContractAddressStr,
+				rpc.BSCNetworkID: TestNetBSCContractAddressStr,
+				rpc.PLGNetworkID: TestNetPLGContractAddressStr,
+			}
 		case "testnet1":
 			incClient.btcPortalParams = &testNet1BTCPortalV4Params
 			incClient.evmServers[rpc.BSCNetworkID] = rpc.NewRPCServer(TestNet1BSCHost)
 			incClient.evmServers[rpc.PLGNetworkID] = rpc.NewRPCServer(TestNet1PLGHost)
+			incClient.evmVaults = map[int]string{
+				rpc.ETHNetworkID: TestNet1ETHContractAddressStr,
+				rpc.BSCNetworkID: TestNet1BSCContractAddressStr,
+				rpc.PLGNetworkID: TestNet1PLGContractAddressStr,
+			}
 		case "local":
 			incClient.btcPortalParams = &localBTCPortalV4Params
+			incClient.evmVaults = map[int]string{rpc.ETHNetworkID: LocalETHContractAddressStr}
 		case "mainnet":
 		default:
 			return nil, fmt.Errorf("network %v not valid", networks[0])
@@ -273,13 +328,9 @@ func NewIncClient(fullNode, ethNode string, version int, networks ...string) (*I
 
 	Logger.Printf("Init to %v, activeShards: %v\n", fullNode, activeShards)
 
-	common.MaxShardNumber = activeShards
-	if incClient.version == 1 {
-		common.AddressVersion = 0
-	} else if incClient.version == 2 {
-		common.AddressVersion = 1
-	} else {
-		return nil, fmt.Errorf("version %v not supported", version)
+	incClient.networkParams, err = newNetworkParams(activeShards, incClient.version)
+	if err != nil {
+		return nil, err
 	}
 
 	return &incClient, nil
@@ -306,3 +357,36 @@ func NewIncClientWithCache(fullNode, ethNode string, version int, networks ...st
 
 	return incClient, nil
 }
+
+// GetNetwork returns the name of the network the client is interacting with (e.g, mainnet, testnet, testnet1, local).
+// For a client created by NewIncClient, it is the given network name in lower case (mainnet by default).
+func (client *IncClient) GetNetwork() string {
+	return client.network
+}
+
+// GetNetworkParams returns a copy of the NetworkParams of the client. These parameters (not the package-level
+// defaults common.MaxShardNumber and common.AddressVersion) are used by the client to derive shards and encode
+// addresses, so that clients of different networks can co-exist in the same process.
+func (client *IncClient) GetNetworkParams() *common.NetworkParams {
+	if client.networkParams == nil {
+		return common.DefaultNetworkParams()
+	}
+	res := *client.networkParams
+
+	return &res
+}
+
+// newNetworkParams returns the NetworkParams of a network with the given number of active shards and privacy version.
+func newNetworkParams(activeShards, version int) (*common.NetworkParams, error) {
+	var addressVersion int
+	switch version {
+	case 1:
+		addressVersion = 0
+	case 2:
+		addressVersion = 1
+	default:
+		return nil, fmt.Errorf("version %v not supported", version)
+	}
+
+	return common.NewNetworkParams(activeShards, addressVersion)
+}
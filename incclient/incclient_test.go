@@ -0,0 +1,99 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestIncClient_NetworkParams(t *testing.T) {
+	defaultShards := common.MaxShardNumber
+	w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+
+	clients := make([]*IncClient, 0)
+	for _, version := range []int{1, 2} {
+		for numShards := 1; numShards <= common.MaxSupportedShardNumber; numShards++ {
+			params, err := newNetworkParams(numShards, version)
+			if err != nil {
+				panic(err)
+			}
+			clients = append(clients, &IncClient{version: version, networkParams: params})
+		}
+	}
+
+	for _, client := range clients {
+		params := client.GetNetworkParams()
+
+		// the client encodes addresses w.r.t its own address version
+		addr := w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params)
+		addrV2 := w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, &common.NetworkParams{MaxShardNumber: params.MaxShardNumber, AddressVersion: 1})
+		if (addr == addrV2) != (client.version == 2) {
+			panic(fmt.Sprintf("version %v: invalid address encoding", client.version))
+		}
+		if _, err = wallet.Base58CheckDeserialize(addr); err != nil {
+			panic(err)
+		}
+		privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+		if tmpAddr := client.PrivateKeyToPaymentAddress(privateKey, -1); tmpAddr != addr {
+			panic(fmt.Sprintf("version %v: expect address %v, got %v", client.version, addr, tmpAddr))
+		}
+		outCoinKey, err := client.NewOutCoinKeyFromPrivateKey(privateKey)
+		if err != nil {
+			panic(err)
+		}
+		if outCoinKey.PaymentAddress() != addr {
+			panic(fmt.Sprintf("version %v: expect out-coin key address %v, got %v", client.version, addr, outCoinKey.PaymentAddress()))
+		}
+
+		// shards are derived w.r.t the client's number of shards
+		pubKey := w.KeySet.PaymentAddress.Pk
+		if shardID := client.GetShardIDFromPrivateKey(privateKey); shardID != params.GetShardIDFromLastByte(pubKey[len(pubKey)-1]) {
+			panic(fmt.Sprintf("numShards %v: invalid shardID %v", params.MaxShardNumber, shardID))
+		}
+		info, err := client.GetAccountInfoFromPrivateKey(privateKey)
+		if err != nil {
+			panic(err)
+		}
+		if info.ShardID != client.GetShardIDFromPrivateKey(privateKey) || info.PaymentAddress != addr {
+			panic(fmt.Sprintf("numShards %v: invalid account info %v", params.MaxShardNumber, info))
+		}
+
+		// OTAs are generated for the shards of the client's network
+		pkStr, _, err := GenerateOTAFromPaymentAddressWithParams(params, addrV2, coin.PrivacyTypeMint)
+		if err != nil {
+			panic(err)
+		}
+		pk, _, err := base58.Base58Check{}.Decode(pkStr)
+		if err != nil {
+			panic(err)
+		}
+		_, receiverShard, _, err := coin.DeriveShardInfoFromCoinWithParams(pk, params)
+		if err != nil {
+			panic(err)
+		}
+		expectedShard := params.GetShardIDFromLastByte(w.KeySet.PaymentAddress.Pk[len(w.KeySet.PaymentAddress.Pk)-1])
+		if receiverShard != int(expectedShard) {
+			panic(fmt.Sprintf("numShards %v: expect receiverShard %v, got %v", params.MaxShardNumber, expectedShard, receiverShard))
+		}
+	}
+
+	if common.MaxShardNumber != defaultShards {
+		panic(fmt.Sprintf("MaxShardNumber changed from %v to %v", defaultShards, common.MaxShardNumber))
+	}
+
+	_, err = newNetworkParams(common.MaxSupportedShardNumber+1, 2)
+	if err == nil {
+		panic("expect an error")
+	}
+	_, err = newNetworkParams(8, 3)
+	if err == nil {
+		panic("expect an error")
+	}
+}
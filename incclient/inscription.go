@@ -27,7 +27,7 @@ func (client *IncClient) CreateInscribeRequestTx(
 
 	otaReceiver := coin.OTAReceiver{}
 	paymentInfo := &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Message: []byte{}}
-	err = otaReceiver.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+	err = otaReceiver.FromCoinParams(coin.NewMintCoinParamsWithNetwork(client.networkParams, paymentInfo))
 	if err != nil {
 		return nil, "", err
 	}
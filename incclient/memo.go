@@ -0,0 +1,161 @@
+package incclient
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+const (
+	// encryptedMemoPrefix marks the info of an output coin as an encrypted memo.
+	encryptedMemoPrefix = "\x00M\x01"
+	memoTagSize         = 16
+
+	// MaxMemoSize is the maximum size (in bytes) of a memo attached to an output coin.
+	// An encrypted memo must fit in the info field of a coin (coin.MaxSizeInfoCoin bytes) along with a
+	// prefix, an ephemeral public key, and an authentication tag.
+	MaxMemoSize = coin.MaxSizeInfoCoin - len(encryptedMemoPrefix) - crypto.Ed25519KeySize - memoTagSize
+)
+
+// getMemoCipher derives the AEAD cipher of a memo from the shared secret and the ephemeral public key.
+func getMemoCipher(sharedSecret, ephemeralPubKey *crypto.Point) (cipher.AEAD, error) {
+	aesKey := common.HashB(append(sharedSecret.ToBytesS(), ephemeralPubKey.ToBytesS()...))
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// EncryptMemo encrypts a memo to the public view key of a payment address, so that only holders of the
+// corresponding private (or read-only) key can read it. The result is meant to be used as the Message of a
+// key.PaymentInfo, which ends up in the info field of the output coin.
+//
+// Each memo is encrypted under a fresh ephemeral key; as a result, the sender cannot decrypt it afterwards.
+func EncryptMemo(addr key.PaymentAddress, memo []byte) ([]byte, error) {
+	if len(memo) == 0 {
+		return nil, fmt.Errorf("memo is empty")
+	}
+	if len(memo) > MaxMemoSize {
+		return nil, fmt.Errorf("memo size (%v) exceeds the maximum size (%v)", len(memo), MaxMemoSize)
+	}
+	publicView := addr.GetPublicView()
+	if publicView == nil {
+		return nil, fmt.Errorf("payment address does not have a public view key")
+	}
+
+	r := crypto.RandomScalar()
+	ephemeralPubKey := new(crypto.Point).ScalarMultBase(r)
+	aead, err := getMemoCipher(new(crypto.Point).ScalarMult(publicView, r), ephemeralPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the key is never re-used, so is a zero nonce
+	nonce := make([]byte, aead.NonceSize())
+	res := []byte(encryptedMemoPrefix)
+	res = append(res, ephemeralPubKey.ToBytesS()...)
+	return aead.Seal(res, nonce, memo, nil), nil
+}
+
+// IsEncryptedMemo checks if the info of a coin holds an encrypted memo.
+func IsEncryptedMemo(info []byte) bool {
+	return len(info) > len(encryptedMemoPrefix)+crypto.Ed25519KeySize+memoTagSize &&
+		bytes.HasPrefix(info, []byte(encryptedMemoPrefix))
+}
+
+// DecryptMemo decrypts a memo created by EncryptMemo using the private view key of the receiver.
+func DecryptMemo(info []byte, privateView *crypto.Scalar) ([]byte, error) {
+	if !IsEncryptedMemo(info) {
+		return nil, fmt.Errorf("info does not hold an encrypted memo")
+	}
+	if privateView == nil {
+		return nil, fmt.Errorf("private view key is required to decrypt a memo")
+	}
+
+	offset := len(encryptedMemoPrefix)
+	ephemeralPubKey, err := new(crypto.Point).FromBytesS(info[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %v", err)
+	}
+	aead, err := getMemoCipher(new(crypto.Point).ScalarMult(ephemeralPubKey, privateView), ephemeralPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	memo, err := aead.Open(nil, nonce, info[offset+crypto.Ed25519KeySize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt memo: %v", err)
+	}
+
+	return memo, nil
+}
+
+// GetCoinMemo returns the memo attached to a coin with the given info.
+// An encrypted memo is decrypted using the read-only key of the key set; a plain (legacy) info is returned as is.
+// It returns an empty string if the coin has no memo, or if the memo cannot be decrypted with the key set.
+func GetCoinMemo(info []byte, keySet *key.KeySet) string {
+	if len(info) == 0 {
+		return ""
+	}
+	if !IsEncryptedMemo(info) {
+		return string(info)
+	}
+	if keySet == nil || len(keySet.ReadonlyKey.Rk) == 0 {
+		return ""
+	}
+
+	memo, err := DecryptMemo(info, keySet.ReadonlyKey.GetPrivateView())
+	if err != nil {
+		return ""
+	}
+	return string(memo)
+}
+
+// GetUnspentOutputCoinsWithMemos is the same as GetUnspentOutputCoins, except that it also returns the memo
+// attached to each unspent output coin (an empty string if a coin has no memo).
+func (client *IncClient) GetUnspentOutputCoinsWithMemos(privateKey, tokenID string, height uint64) ([]coin.PlainCoin, []*big.Int, []string, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	coins, indices, err := client.GetUnspentOutputCoins(privateKey, tokenID, height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	memos := make([]string, 0)
+	for _, c := range coins {
+		memos = append(memos, GetCoinMemo(c.GetInfo(), &keyWallet.KeySet))
+	}
+
+	return coins, indices, memos, nil
+}
+
+// getTxInMemos returns the (sorted) memos attached to the output coins of a transaction that belong to an account.
+func getTxInMemos(outCoins map[string]coin.Coin, ownedCoins map[string]coin.PlainCoin, keySet *key.KeySet) []string {
+	var res []string
+	for cmtStr, outCoin := range outCoins {
+		if _, ok := ownedCoins[cmtStr]; !ok {
+			continue
+		}
+		if memo := GetCoinMemo(outCoin.GetInfo(), keySet); memo != "" {
+			res = append(res, memo)
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
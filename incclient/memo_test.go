@@ -0,0 +1,116 @@
+package incclient
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestEncryptMemo(t *testing.T) {
+	receiver := newRandomWalletInShard(0)
+	other := newRandomWalletInShard(0)
+
+	for _, memo := range []string{"a", "INV-2021-0042", strings.Repeat("x", MaxMemoSize)} {
+		info, err := EncryptMemo(receiver.KeySet.PaymentAddress, []byte(memo))
+		if err != nil {
+			panic(err)
+		}
+		if len(info) > coin.MaxSizeInfoCoin || !IsEncryptedMemo(info) {
+			panic(fmt.Sprintf("invalid encrypted memo %v", info))
+		}
+
+		decrypted, err := DecryptMemo(info, receiver.KeySet.ReadonlyKey.GetPrivateView())
+		if err != nil {
+			panic(err)
+		}
+		if string(decrypted) != memo {
+			panic(fmt.Sprintf("expected memo %v, got %v", memo, string(decrypted)))
+		}
+
+		if _, err = DecryptMemo(info, other.KeySet.ReadonlyKey.GetPrivateView()); err == nil {
+			panic("expected an error when decrypting with another key")
+		}
+		if GetCoinMemo(info, &other.KeySet) != "" {
+			panic("another key must not read the memo")
+		}
+	}
+
+	if _, err := EncryptMemo(receiver.KeySet.PaymentAddress, make([]byte, MaxMemoSize+1)); err == nil {
+		panic("expected an error for a too long memo")
+	}
+	if GetCoinMemo([]byte("legacy message"), &other.KeySet) != "legacy message" {
+		panic("a plain info must be returned as is")
+	}
+}
+
+func TestCoinMemo(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	receiver := newRandomWalletInShard(1)
+	receiverAddr := receiver.Base58CheckSerialize(wallet.PaymentAddressType)
+	otherAddr := newRandomWalletInShard(1).Base58CheckSerialize(wallet.PaymentAddressType)
+
+	_, err := createPaymentInfos([]string{receiverAddr, otherAddr}, []uint64{100, 200}, []string{"INV-1"})
+	if err == nil {
+		panic("expected an error for mismatched memos")
+	}
+	paymentInfos, err := createPaymentInfos([]string{receiverAddr, otherAddr}, []uint64{100, 200}, []string{"INV-1", ""})
+	if err != nil {
+		panic(err)
+	}
+	if len(paymentInfos[1].Message) != 0 {
+		panic("an empty memo must not be attached")
+	}
+
+	// the memo survives the creation, concealing and decryption of an output coin
+	outCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(paymentInfos[0], 0))
+	if err != nil {
+		panic(err)
+	}
+	err = outCoin.ConcealOutputCoin(receiver.KeySet.PaymentAddress.GetPublicView())
+	if err != nil {
+		panic(err)
+	}
+	plainCoin, err := outCoin.Decrypt(&receiver.KeySet)
+	if err != nil {
+		panic(err)
+	}
+	if plainCoin.GetValue() != 100 || GetCoinMemo(plainCoin.GetInfo(), &receiver.KeySet) != "INV-1" {
+		panic(fmt.Sprintf("unexpected coin %v, %v", plainCoin.GetValue(), plainCoin.GetInfo()))
+	}
+	if GetCoinMemo(outCoin.GetInfo(), &sender.KeySet) != "" {
+		panic("the sender must not read the memo")
+	}
+
+	cmtStr := base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), common.ZeroByte)
+	memos := getTxInMemos(map[string]coin.Coin{cmtStr: outCoin}, map[string]coin.PlainCoin{cmtStr: plainCoin}, &receiver.KeySet)
+	if fmt.Sprint(memos) != "[INV-1]" {
+		panic(fmt.Sprintf("unexpected memos %v", memos))
+	}
+}
+
+func TestDryRun_Memos(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+	receiver := newRandomWalletInShard(1).Base58CheckSerialize(wallet.PaymentAddressType)
+	coins, indices := newTestCoinsV2(sender.KeySet.PaymentAddress, 3*DefaultPRVFee)
+
+	client := (&IncClient{}).NewDryRunClient()
+	param := NewTxParam(privateKey, []string{receiver}, []uint64{DefaultPRVFee}, 0, nil, nil, nil).SetMemos([]string{"INV-1"})
+	_, _, err := client.CreateRawTransactionWithInputCoins(param, coins, indices)
+	if err != nil {
+		panic(err)
+	}
+	reports := client.GetDryRunReports()
+	if len(reports) != 1 || reports[0].Outputs[0].Memo != "INV-1" || reports[0].Outputs[1].Memo != "" {
+		panic(fmt.Sprintf("unexpected outputs %+v", reports[0].Outputs))
+	}
+
+	if _, _, err = client.CreateRawTransactionVer1(param); err == nil {
+		panic("expected an error for memos in a transaction v1")
+	}
+}
@@ -0,0 +1,582 @@
+package incclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// OTAScanner implements a trustless scanner for v2 output coins. Instead of submitting OTA keys to a full-node
+// (i.e, SubmitKey/AuthorizedSubmitKey), it downloads all output coins of a shard by their indices, and checks their
+// ownership locally. Therefore, the remote node learns nothing about which coins belong to the scanned keys.
+//
+// For each OTA key, the scanner persists a checkpoint (the next index to scan, and the coins found so far) so that
+// subsequent scans only download new output coins. Keys of the same shard are scanned together over a single download.
+//
+// Note that v1 output coins are not covered by the scanner, and that GetUnspentOutputCoins (and GetBalance) reveal the key
+// images of the found coins to the remote node.
+type OTAScanner struct {
+	client        *IncClient
+	checkpointDir string
+
+	// NumWorkers is the number of workers downloading and checking output coins simultaneously.
+	// By default, it is set to MaxGetCoinThreads.
+	NumWorkers int
+
+	// BatchSize is the number of output coins retrieved in each request.
+	BatchSize uint64
+
+	mtx         sync.Mutex
+	checkpoints map[string]*otaScanCheckpoint
+}
+
+// otaScanCheckpoint keeps track of the scanning progress of an OTA key.
+type otaScanCheckpoint struct {
+	// NextIndex is a mapping from a tokenID (PRV or common.ConfidentialAssetID) to the next output-coin index to scan.
+	NextIndex map[string]uint64 `json:"NextIndex"`
+
+	// OutCoins is a mapping from a tokenID (PRV or common.ConfidentialAssetID) to the output coins found so far.
+	OutCoins map[string]*cachedOutCoins `json:"OutCoins"`
+}
+
+func newOTAScanCheckpoint() *otaScanCheckpoint {
+	return &otaScanCheckpoint{
+		NextIndex: make(map[string]uint64),
+		OutCoins:  make(map[string]*cachedOutCoins),
+	}
+}
+
+// otaScanAccount is an OTA key being scanned.
+type otaScanAccount struct {
+	keyID      string
+	keySet     *key.KeySet
+	checkpoint *otaScanCheckpoint
+}
+
+// otaScanStatus is the result of scanning a batch of output coins.
+type otaScanStatus struct {
+	fromIndex uint64
+	toIndex   uint64
+	data      []map[uint64]jsonresult.ICoinInfo // data[i] is the list of coins belonging to the i-th account
+	err       error
+}
+
+// NewOTAScanner creates a new OTAScanner which stores its checkpoints in the given directory.
+func NewOTAScanner(client *IncClient, checkpointDir string) (*OTAScanner, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not found")
+	}
+	if checkpointDir == "" {
+		return nil, fmt.Errorf("checkpoint directory must not be empty")
+	}
+	if _, err := os.Stat(checkpointDir); os.IsNotExist(err) {
+		err = os.MkdirAll(checkpointDir, os.ModePerm)
+		if err != nil {
+			return nil, fmt.Errorf("make directory %v error: %v", checkpointDir, err)
+		}
+	}
+
+	return &OTAScanner{
+		client:        client,
+		checkpointDir: checkpointDir,
+		NumWorkers:    MaxGetCoinThreads,
+		BatchSize:     uint64(batchSize),
+		checkpoints:   make(map[string]*otaScanCheckpoint),
+	}, nil
+}
+
+// Scan scans all new output coins of the given tokenID for the given OTA keys (or private keys), and updates their
+// checkpoints. For tokens other than PRV, all tokens are scanned together (i.e, the common.ConfidentialAssetID).
+func (s *OTAScanner) Scan(otaKeys []string, tokenID string) error {
+	tokenID = getScanningTokenID(tokenID)
+
+	accountsByShard := make(map[byte][]*otaScanAccount)
+	for _, otaKey := range otaKeys {
+		account, shardID, err := s.newScanAccount(otaKey)
+		if err != nil {
+			return err
+		}
+		accountsByShard[shardID] = append(accountsByShard[shardID], account)
+	}
+
+	for shardID, accounts := range accountsByShard {
+		err := s.scanShard(shardID, tokenID, accounts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetScannedOutCoins returns the output coins (and their indices) found so far for an OTA key w.r.t the given tokenID.
+// For tokens other than PRV, output coins of all tokens are returned.
+func (s *OTAScanner) GetScannedOutCoins(otaKey, tokenID string) ([]jsonresult.ICoinInfo, []*big.Int, error) {
+	otaKey, err := getOTAKeyStr(otaKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	checkpoint, err := s.loadCheckpoint(otaKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	outCoins := make([]jsonresult.ICoinInfo, 0)
+	indices := make([]*big.Int, 0)
+	cached := checkpoint.OutCoins[getScanningTokenID(tokenID)]
+	if cached == nil {
+		return outCoins, indices, nil
+	}
+
+	idxList := make([]uint64, 0)
+	for idx := range cached.Data {
+		idxList = append(idxList, idx)
+	}
+	sort.Slice(idxList, func(i, j int) bool {
+		return idxList[i] < idxList[j]
+	})
+	for _, idx := range idxList {
+		outCoins = append(outCoins, cached.Data[idx])
+		indices = append(indices, new(big.Int).SetUint64(idx))
+	}
+
+	return outCoins, indices, nil
+}
+
+// GetNextIndex returns the next output-coin index to be scanned for an OTA key w.r.t the given tokenID.
+func (s *OTAScanner) GetNextIndex(otaKey, tokenID string) (uint64, error) {
+	otaKey, err := getOTAKeyStr(otaKey)
+	if err != nil {
+		return 0, err
+	}
+	checkpoint, err := s.loadCheckpoint(otaKey)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return checkpoint.NextIndex[getScanningTokenID(tokenID)], nil
+}
+
+// GetUnspentOutputCoins scans the output coins of a private key w.r.t the given tokenID, decrypts them locally, and
+// returns the unspent ones.
+//
+// NOTE: the spending status is checked by sending the key images of all found coins to the remote node (in batches of
+// 100), which lets the node link these coins to a single account. Callers who do not trust the node should use
+// GetScannedOutCoins instead, and check the key images via a different node or over separate connections.
+func (s *OTAScanner) GetUnspentOutputCoins(privateKey, tokenID string) ([]coin.PlainCoin, []*big.Int, error) {
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keyWallet.KeySet.PrivateKey) == 0 {
+		return nil, nil, fmt.Errorf("invalid private key")
+	}
+	otaKey := PrivateKeyToPrivateOTAKey(privateKey)
+
+	err = s.Scan([]string{otaKey}, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+	outCoins, indices, err := s.GetScannedOutCoins(otaKey, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(outCoins) == 0 {
+		return nil, nil, nil
+	}
+
+	// decrypt the coins locally; coins that cannot be decrypted are skipped
+	decryptor, err := NewCoinDecryptor(privateKey, s.NumWorkers)
+	if err != nil {
+		return nil, nil, err
+	}
+	decryptedCoins := make([]coin.PlainCoin, 0)
+	keyImages := make([]string, 0)
+	tmpOutCoins := make([]*coin.CoinV2, 0)
+	tmpIndices := make([]*big.Int, 0)
+	for _, res := range decryptor.DecryptAll(outCoins) {
+		if res.Err != nil {
+			Logger.Printf("Decrypt coin at index %v error: %v\n", indices[res.Index], res.Err)
+			continue
+		}
+		v2Coin, ok := outCoins[res.Index].(*coin.CoinV2)
+		if !ok {
+			continue
+		}
+		decryptedCoins = append(decryptedCoins, res.Coin)
+		keyImages = append(keyImages, res.KeyImage)
+		tmpOutCoins = append(tmpOutCoins, v2Coin)
+		tmpIndices = append(tmpIndices, indices[res.Index])
+	}
+
+	// filter out coins of other tokens (computing the asset tags locally)
+	var rawAssetTags map[string]*common.Hash
+	if tokenID != common.PRVIDStr {
+		rawAssetTags, err = BuildAssetTags([]string{tokenID})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	shardID := s.client.GetShardIDFromPrivateKey(privateKey)
+	spentCheckBatchSize := 100
+	res := make([]coin.PlainCoin, 0)
+	resIndices := make([]*big.Int, 0)
+	for start := 0; start < len(keyImages); start += spentCheckBatchSize {
+		end := start + spentCheckBatchSize
+		if end > len(keyImages) {
+			end = len(keyImages)
+		}
+		spentList, err := s.client.CheckCoinsSpent(shardID, tokenID, keyImages[start:end])
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot check spent coins: %v", err)
+		}
+		for i, spent := range spentList {
+			decryptedCoin := decryptedCoins[start+i]
+			if spent || decryptedCoin.GetValue() == 0 {
+				continue
+			}
+			if tokenID != common.PRVIDStr {
+				coinTokenID, err := tmpOutCoins[start+i].GetTokenId(&keyWallet.KeySet, rawAssetTags)
+				if err != nil || coinTokenID.String() != tokenID {
+					continue
+				}
+			}
+			res = append(res, decryptedCoin)
+			resIndices = append(resIndices, tmpIndices[start+i])
+		}
+	}
+
+	return res, resIndices, nil
+}
+
+// GetBalance returns the balance of a private key w.r.t the given tokenID, using locally-scanned output coins.
+func (s *OTAScanner) GetBalance(privateKey, tokenID string) (uint64, error) {
+	utxoList, _, err := s.GetUnspentOutputCoins(privateKey, tokenID)
+	if err != nil {
+		return 0, err
+	}
+
+	balance := uint64(0)
+	for _, utxo := range utxoList {
+		balance += utxo.GetValue()
+	}
+
+	return balance, nil
+}
+
+// scanShard scans output coins of a shard for the given accounts in parallel. Batches are committed to the checkpoints
+// in order, so that an interrupted scan resumes from the last contiguous scanned index.
+func (s *OTAScanner) scanShard(shardID byte, tokenID string, accounts []*otaScanAccount) error {
+	coinLength, err := s.client.GetOTACoinLengthByShard(shardID, tokenID)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	fromIndices := make([]uint64, len(accounts))
+	currentIndex := coinLength
+	for i, account := range accounts {
+		fromIndices[i] = account.checkpoint.NextIndex[tokenID]
+		if fromIndices[i] < currentIndex {
+			currentIndex = fromIndices[i]
+		}
+	}
+	s.mtx.Unlock()
+	if currentIndex >= coinLength {
+		return nil
+	}
+	Logger.Printf("Scanning output coins of token %v, shard %v from %v to %v\n", tokenID, shardID, currentIndex, coinLength)
+
+	numWorkers := s.NumWorkers
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+	batchLength := s.BatchSize
+	if batchLength == 0 {
+		batchLength = uint64(batchSize)
+	}
+
+	start := time.Now()
+	jobs := make(chan [2]uint64)
+	statusChan := make(chan otaScanStatus, numWorkers)
+	done := make(chan struct{})
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				status := s.scanBatch(shardID, tokenID, job[0], job[1], accounts, fromIndices)
+				select {
+				case statusChan <- status:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for idx := currentIndex; idx < coinLength; idx += batchLength {
+			toIndex := idx + batchLength - 1
+			if toIndex >= coinLength {
+				toIndex = coinLength - 1
+			}
+			select {
+			case jobs <- [2]uint64{idx, toIndex}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(statusChan)
+	}()
+
+	// commit batches in order
+	pending := make(map[uint64]otaScanStatus)
+	for status := range statusChan {
+		if status.err != nil {
+			return fmt.Errorf("scan FAILED at indices [%v-%v]: %v", status.fromIndex, status.toIndex, status.err)
+		}
+		pending[status.fromIndex] = status
+		committed := false
+		for {
+			tmpStatus, ok := pending[currentIndex]
+			if !ok {
+				break
+			}
+			delete(pending, currentIndex)
+			s.commitBatch(tokenID, accounts, tmpStatus)
+			currentIndex = tmpStatus.toIndex + 1
+			committed = true
+		}
+		if committed {
+			err = s.saveCheckpoints(accounts)
+			if err != nil {
+				return err
+			}
+			Logger.Printf("Scanned up to index %v/%v, timeElapsed: %v\n", currentIndex, coinLength, time.Since(start).Seconds())
+		}
+		if currentIndex >= coinLength {
+			break
+		}
+	}
+	if currentIndex < coinLength {
+		return fmt.Errorf("scan stopped at index %v/%v", currentIndex, coinLength)
+	}
+
+	return nil
+}
+
+// scanBatch retrieves output coins of indices [fromIndex, toIndex], and checks them against each account whose checkpoint
+// is not beyond the index of the coin.
+func (s *OTAScanner) scanBatch(
+	shardID byte, tokenID string, fromIndex, toIndex uint64, accounts []*otaScanAccount, accountFromIndices []uint64,
+) otaScanStatus {
+	status := otaScanStatus{fromIndex: fromIndex, toIndex: toIndex}
+
+	idxList := make([]uint64, 0)
+	for i := fromIndex; i <= toIndex; i++ {
+		idxList = append(idxList, i)
+	}
+	outCoins, err := s.client.GetOTACoinsByIndices(shardID, tokenID, idxList)
+	if err != nil {
+		status.err = err
+		return status
+	}
+
+	status.data = make([]map[uint64]jsonresult.ICoinInfo, len(accounts))
+	for i := range accounts {
+		status.data[i] = make(map[uint64]jsonresult.ICoinInfo)
+	}
+	burningPubKey := wallet.GetBurningPublicKey()
+	for idx, outCoin := range outCoins {
+		if outCoin == nil || bytes.Equal(outCoin.GetPublicKey().ToBytesS(), burningPubKey) {
+			continue
+		}
+		for i, account := range accounts {
+			if idx < accountFromIndices[i] {
+				continue
+			}
+			if belongs, _ := outCoin.DoesCoinBelongToKeySet(account.keySet); belongs {
+				status.data[i][idx] = outCoin
+			}
+		}
+	}
+
+	return status
+}
+
+// commitBatch adds the result of a batch to the checkpoints of the accounts.
+func (s *OTAScanner) commitBatch(tokenID string, accounts []*otaScanAccount, status otaScanStatus) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for i, account := range accounts {
+		checkpoint := account.checkpoint
+		if checkpoint.NextIndex[tokenID] > status.toIndex {
+			continue
+		}
+		if len(status.data[i]) > 0 {
+			if checkpoint.OutCoins[tokenID] == nil {
+				checkpoint.OutCoins[tokenID] = NewCachedOutCoins()
+			}
+			for idx, outCoin := range status.data[i] {
+				checkpoint.OutCoins[tokenID].Data[idx] = outCoin
+			}
+		}
+		checkpoint.NextIndex[tokenID] = status.toIndex + 1
+	}
+}
+
+// newScanAccount parses an OTA key (or a private key), and loads its checkpoint.
+func (s *OTAScanner) newScanAccount(keyStr string) (*otaScanAccount, byte, error) {
+	otaKey, err := getOTAKeyStr(keyStr)
+	if err != nil {
+		return nil, 0, err
+	}
+	w, err := wallet.Base58CheckDeserialize(otaKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	keySet := w.KeySet
+	if keySet.OTAKey.GetOTASecretKey() == nil || keySet.OTAKey.GetPublicSpend() == nil {
+		return nil, 0, fmt.Errorf("invalid OTAKey")
+	}
+	pk := keySet.OTAKey.GetPublicSpend().ToBytesS()
+	shardID := s.client.networkParams.GetShardIDFromLastByte(pk[len(pk)-1])
+
+	checkpoint, err := s.loadCheckpoint(otaKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &otaScanAccount{keyID: getOTAScanKeyID(pk), keySet: &keySet, checkpoint: checkpoint}, shardID, nil
+}
+
+// loadCheckpoint returns the checkpoint of an OTA key, loading it from the checkpoint directory if needed.
+func (s *OTAScanner) loadCheckpoint(otaKey string) (*otaScanCheckpoint, error) {
+	w, err := wallet.Base58CheckDeserialize(otaKey)
+	if err != nil {
+		return nil, err
+	}
+	if w.KeySet.OTAKey.GetPublicSpend() == nil {
+		return nil, fmt.Errorf("invalid OTAKey")
+	}
+	keyID := getOTAScanKeyID(w.KeySet.OTAKey.GetPublicSpend().ToBytesS())
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if checkpoint, ok := s.checkpoints[keyID]; ok {
+		return checkpoint, nil
+	}
+
+	checkpoint := newOTAScanCheckpoint()
+	data, err := ioutil.ReadFile(s.getCheckpointFile(keyID))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse checkpoint: %v", err)
+		}
+		if checkpoint.NextIndex == nil {
+			checkpoint.NextIndex = make(map[string]uint64)
+		}
+		if checkpoint.OutCoins == nil {
+			checkpoint.OutCoins = make(map[string]*cachedOutCoins)
+		}
+	}
+	s.checkpoints[keyID] = checkpoint
+
+	return checkpoint, nil
+}
+
+// saveCheckpoints stores the checkpoints of the given accounts. Each checkpoint is written to a temporary file, then
+// renamed, so that a checkpoint file is never partially written.
+func (s *OTAScanner) saveCheckpoints(accounts []*otaScanAccount) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, account := range accounts {
+		data, err := json.Marshal(account.checkpoint)
+		if err != nil {
+			return err
+		}
+		fileName := s.getCheckpointFile(account.keyID)
+		err = ioutil.WriteFile(fileName+".tmp", data, 0600)
+		if err != nil {
+			return err
+		}
+		err = os.Rename(fileName+".tmp", fileName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getCheckpointFile returns the checkpoint file of the OTA key with the given ID (see getOTAScanKeyID).
+func (s *OTAScanner) getCheckpointFile(keyID string) string {
+	return filepath.Join(s.checkpointDir, keyID)
+}
+
+// getOTAScanKeyID returns the ID under which the checkpoint of an OTA key is stored, given its public spend key. It is
+// the hash of the public key bytes, so that every encoding of the same key shares a checkpoint, and the key itself is not
+// exposed by the file name.
+func getOTAScanKeyID(publicSpend []byte) string {
+	h := sha256.Sum256(publicSpend)
+	return hex.EncodeToString(h[:])
+}
+
+// getOTAKeyStr returns the base58-encoded OTA key of the given key, which is either an OTA key or a private key.
+func getOTAKeyStr(keyStr string) (string, error) {
+	w, err := wallet.Base58CheckDeserialize(keyStr)
+	if err != nil {
+		return "", err
+	}
+	if len(w.KeySet.PrivateKey) != 0 {
+		return PrivateKeyToPrivateOTAKey(keyStr), nil
+	}
+	if w.KeySet.OTAKey.GetOTASecretKey() == nil {
+		return "", fmt.Errorf("expect an OTA key or a private key")
+	}
+
+	return keyStr, nil
+}
+
+// getScanningTokenID returns the tokenID under which output coins of the given tokenID are indexed by the full-node.
+func getScanningTokenID(tokenID string) string {
+	if tokenID != common.PRVIDStr {
+		return common.ConfidentialAssetID.String()
+	}
+	return tokenID
+}
@@ -0,0 +1,213 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// mockOTANode serves the output coins of a single shard via getotacoinlength and getotacoinsbyindices.
+type mockOTANode struct {
+	mtx        sync.Mutex
+	shardID    byte
+	coins      []jsonresult.OutCoin
+	minQueried uint64
+}
+
+func (n *mockOTANode) addCoin(addr key.PaymentAddress, amount uint64) {
+	c, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(key.InitPaymentInfo(addr, amount, []byte{})))
+	if err != nil {
+		panic(err)
+	}
+	n.mtx.Lock()
+	n.coins = append(n.coins, jsonresult.NewOutCoin(c))
+	n.mtx.Unlock()
+}
+
+func (n *mockOTANode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Method string
+		Params []map[string]interface{}
+	}
+	_ = json.Unmarshal(body, &req)
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var result interface{}
+	switch req.Method {
+	case "getotacoinlength":
+		result = map[string]map[byte]uint64{
+			common.PRVIDStr: {n.shardID: uint64(len(n.coins))},
+		}
+	case "getotacoinsbyindices":
+		res := make(map[uint64]jsonresult.OutCoin)
+		for _, tmpIdx := range req.Params[0]["Indices"].([]interface{}) {
+			idx := uint64(tmpIdx.(float64))
+			if idx < n.minQueried {
+				n.minQueried = idx
+			}
+			res[idx] = n.coins[idx]
+		}
+		result = res
+	}
+	jsb, _ := json.Marshal(result)
+	resp, _ := json.Marshal(map[string]interface{}{"Result": json.RawMessage(jsb), "Error": nil})
+	_, _ = w.Write(resp)
+}
+
+func newRandomWalletInShard(shardID byte) *wallet.KeyWallet {
+	for {
+		w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+		if err != nil {
+			panic(err)
+		}
+		pk := w.KeySet.PaymentAddress.Pk
+		if common.GetShardIDFromLastByte(pk[len(pk)-1]) == shardID {
+			return w
+		}
+	}
+}
+
+func TestOTAScanner_Scan(t *testing.T) {
+	shardID := byte(0)
+	first := newRandomWalletInShard(shardID)
+	second := newRandomWalletInShard(shardID)
+	other := newRandomWalletInShard(shardID)
+
+	node := &mockOTANode{shardID: shardID}
+	expectedIndices := make([]map[uint64]bool, 2)
+	expectedIndices[0] = make(map[uint64]bool)
+	expectedIndices[1] = make(map[uint64]bool)
+	addCoins := func(n int) {
+		for i := 0; i < n; i++ {
+			idx := uint64(len(node.coins))
+			switch common.RandInt() % 3 {
+			case 0:
+				node.addCoin(first.KeySet.PaymentAddress, 1000)
+				expectedIndices[0][idx] = true
+			case 1:
+				node.addCoin(second.KeySet.PaymentAddress, 2000)
+				expectedIndices[1][idx] = true
+			default:
+				node.addCoin(other.KeySet.PaymentAddress, 3000)
+			}
+		}
+	}
+	addCoins(50)
+
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL)}
+
+	checkpointDir, err := ioutil.TempDir("", "ota_scanner")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(checkpointDir)
+
+	otaKeys := []string{first.Base58CheckSerialize(wallet.OTAKeyType), second.Base58CheckSerialize(wallet.OTAKeyType)}
+	checkResult := func(s *OTAScanner) {
+		for i, otaKey := range otaKeys {
+			outCoins, indices, err := s.GetScannedOutCoins(otaKey, common.PRVIDStr)
+			if err != nil {
+				panic(err)
+			}
+			if len(outCoins) != len(expectedIndices[i]) {
+				panic(fmt.Sprintf("key #%v: expect %v coins, got %v", i, len(expectedIndices[i]), len(outCoins)))
+			}
+			for _, idx := range indices {
+				if !expectedIndices[i][idx.Uint64()] {
+					panic(fmt.Sprintf("key #%v: unexpected coin at index %v", i, idx.Uint64()))
+				}
+			}
+			nextIndex, err := s.GetNextIndex(otaKey, common.PRVIDStr)
+			if err != nil {
+				panic(err)
+			}
+			if nextIndex != uint64(len(node.coins)) {
+				panic(fmt.Sprintf("key #%v: expect next index %v, got %v", i, len(node.coins), nextIndex))
+			}
+		}
+	}
+
+	s, err := NewOTAScanner(client, checkpointDir)
+	if err != nil {
+		panic(err)
+	}
+	s.BatchSize = 7
+	s.NumWorkers = 3
+	err = s.Scan(otaKeys, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	checkResult(s)
+
+	// a new scanner resumes from the persisted checkpoints, and only downloads new coins
+	addCoins(20)
+	node.minQueried = uint64(len(node.coins))
+	s, err = NewOTAScanner(client, checkpointDir)
+	if err != nil {
+		panic(err)
+	}
+	s.BatchSize = 4
+	err = s.Scan(otaKeys, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if node.minQueried != 50 {
+		panic(fmt.Sprintf("expect the scan to resume from index 50, got %v", node.minQueried))
+	}
+	checkResult(s)
+
+	// checkpoints are named after the public keys, so that a private key shares the checkpoint of its OTA key
+	pk := first.KeySet.OTAKey.GetPublicSpend().ToBytesS()
+	if _, err = os.Stat(filepath.Join(checkpointDir, getOTAScanKeyID(pk))); err != nil {
+		panic(err)
+	}
+	s, err = NewOTAScanner(client, checkpointDir)
+	if err != nil {
+		panic(err)
+	}
+	nextIndex, err := s.GetNextIndex(first.Base58CheckSerialize(wallet.PrivateKeyType), common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if nextIndex != uint64(len(node.coins)) {
+		panic(fmt.Sprintf("expect next index %v for the private key, got %v", len(node.coins), nextIndex))
+	}
+}
+
+func TestOTAScanner_GetBalance(t *testing.T) {
+	var err error
+	ic, err = NewTestNet1Client()
+	if err != nil {
+		panic(err)
+	}
+
+	privateKey := "" // input the private key
+	tokenID := common.PRVIDStr
+
+	s, err := NewOTAScanner(ic, "ota_checkpoints")
+	if err != nil {
+		panic(err)
+	}
+	balance, err := s.GetBalance(privateKey, tokenID)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Balance: %v\n", balance)
+}
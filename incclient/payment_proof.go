@@ -0,0 +1,340 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// PaymentProof proves that an output coin of a transaction v2 pays an amount of a token to a payment address.
+//
+// Output coins are one-time addresses with hidden amounts, so a transaction hash alone does not tell who was paid
+// how much. Only the sender of a transaction knows the shared randoms used to create its output coins; revealing them
+// for a single output lets anyone verify the payment against the on-chain transaction, without learning anything about
+// the other outputs, or the keys of the receiver.
+type PaymentProof struct {
+	TxHash         string
+	TokenID        string
+	OutputIndex    int
+	PaymentAddress string
+	Amount         uint64
+
+	// SharedRandom is the base58-encoded random used to derive the one-time address of the output coin.
+	SharedRandom string
+
+	// SharedConcealRandom is the base58-encoded random used to conceal the amount of the output coin.
+	SharedConcealRandom string
+}
+
+// String returns the JSON-representation of a PaymentProof.
+func (p PaymentProof) String() string {
+	jsb, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(jsb)
+}
+
+// ParsePaymentProof parses a PaymentProof from its JSON-representation.
+func ParsePaymentProof(data []byte) (*PaymentProof, error) {
+	var p PaymentProof
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("cannot parse payment proof: %v", err)
+	}
+	return &p, nil
+}
+
+// CreateRawTransactionWithPaymentProofs is the same as CreateRawTransactionVer2, except that it also returns a
+// PaymentProof for each receiver (except burning addresses). The proofs cannot be re-created afterwards, so they
+// should be stored by the caller.
+//
+// In dry-run mode, no proof is returned.
+func (client *IncClient) CreateRawTransactionWithPaymentProofs(param *TxParam) ([]byte, string, []*PaymentProof, error) {
+	if client.dryRun != nil {
+		encodedTx, txHash, err := client.dryRunTransaction(param, 2)
+		return encodedTx, txHash, nil, err
+	}
+
+	tx, err := client.createTransactionVer2(param)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	proofs, err := newPaymentProofs(tx.Hash().String(), common.PRVIDStr, tx.GetProof(), param.receiverList, param.amountList)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("cannot marshal txver2: %v", err)
+	}
+
+	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+
+	return []byte(base58CheckData), tx.Hash().String(), proofs, nil
+}
+
+// CreateRawTokenTransactionWithPaymentProofs is the same as CreateRawTokenTransactionVer2, except that it also returns
+// a PaymentProof for each token and PRV receiver (except burning addresses). The proofs cannot be re-created
+// afterwards, so they should be stored by the caller.
+//
+// In dry-run mode, no proof is returned.
+func (client *IncClient) CreateRawTokenTransactionWithPaymentProofs(txParam *TxParam) ([]byte, string, []*PaymentProof, error) {
+	if txParam.txTokenParam == nil {
+		return nil, "", nil, fmt.Errorf("TxTokenParam must not be nil")
+	}
+	if client.dryRun != nil {
+		encodedTx, txHash, err := client.dryRunTransaction(txParam, 2)
+		return encodedTx, txHash, nil, err
+	}
+
+	tx, err := client.createTokenTransactionVer2(txParam)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	txHash := tx.Hash().String()
+	proofs, err := newPaymentProofs(txHash, txParam.txTokenParam.tokenID, tx.GetTxNormal().GetProof(),
+		txParam.txTokenParam.receiverList, txParam.txTokenParam.amountList)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	prvProofs, err := newPaymentProofs(txHash, common.PRVIDStr, tx.GetTxBase().GetProof(), txParam.receiverList, txParam.amountList)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	proofs = append(proofs, prvProofs...)
+
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("cannot marshal txtokenver2: %v", err)
+	}
+
+	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+
+	return []byte(base58CheckData), txHash, proofs, nil
+}
+
+// VerifyPaymentProof retrieves the transaction of a PaymentProof, and checks that the proof is valid for it.
+// It returns nil if the proof is valid.
+func (client *IncClient) VerifyPaymentProof(p *PaymentProof) error {
+	tx, err := client.GetTx(p.TxHash)
+	if err != nil {
+		return err
+	}
+
+	return p.VerifyWithTx(tx)
+}
+
+// VerifyWithTx checks that a PaymentProof is valid for the given transaction, i.e, the output coin at OutputIndex pays
+// Amount of TokenID to PaymentAddress. It returns nil if the proof is valid.
+func (p PaymentProof) VerifyWithTx(tx metadata.Transaction) error {
+	if tx.Hash().String() != p.TxHash {
+		return fmt.Errorf("expected transaction %v, got %v", p.TxHash, tx.Hash().String())
+	}
+	tokenID, err := new(common.Hash).NewHashFromStr(p.TokenID)
+	if err != nil {
+		return fmt.Errorf("invalid tokenID %v: %v", p.TokenID, err)
+	}
+	addrWallet, err := wallet.Base58CheckDeserialize(p.PaymentAddress)
+	if err != nil {
+		return fmt.Errorf("invalid payment address %v: %v", p.PaymentAddress, err)
+	}
+	addr := addrWallet.KeySet.PaymentAddress
+	if addr.GetOTAPublicKey() == nil || addr.GetPublicView() == nil {
+		return fmt.Errorf("%v is not a payment address v2", p.PaymentAddress)
+	}
+	sharedRandom, err := decodeScalar(p.SharedRandom)
+	if err != nil {
+		return fmt.Errorf("invalid shared random: %v", err)
+	}
+	sharedConcealRandom, err := decodeScalar(p.SharedConcealRandom)
+	if err != nil {
+		return fmt.Errorf("invalid shared conceal random: %v", err)
+	}
+
+	proof, err := getTxProofByTokenID(tx, p.TokenID)
+	if err != nil {
+		return err
+	}
+	if proof == nil || p.OutputIndex < 0 || p.OutputIndex >= len(proof.GetOutputCoins()) {
+		return fmt.Errorf("transaction %v has no output %v for token %v", p.TxHash, p.OutputIndex, p.TokenID)
+	}
+	outCoin, ok := proof.GetOutputCoins()[p.OutputIndex].(*coin.CoinV2)
+	if !ok {
+		return fmt.Errorf("output %v is not a CoinV2", p.OutputIndex)
+	}
+
+	plainCoin, err := decryptPaymentOutput(outCoin, addr, sharedConcealRandom)
+	if err != nil {
+		return fmt.Errorf("output %v: %v", p.OutputIndex, err)
+	}
+	if plainCoin.GetValue() != p.Amount {
+		return fmt.Errorf("output %v pays %v, not %v", p.OutputIndex, plainCoin.GetValue(), p.Amount)
+	}
+	if !plainCoin.CheckCoinValid(addr, sharedRandom.ToBytesS(), p.Amount) {
+		return fmt.Errorf("output %v does not pay %v", p.OutputIndex, p.PaymentAddress)
+	}
+
+	// the asset tag of a confidential-asset coin is blinded with the shared OTA secret
+	if plainCoin.GetAssetTag() != nil || *tokenID == common.PRVCoinID {
+		sharedSecret := new(crypto.Point).ScalarMult(addr.GetOTAPublicKey(), sharedRandom)
+		if valid, err := plainCoin.ValidateAssetTag(sharedSecret, tokenID); !valid {
+			return fmt.Errorf("output %v is not a %v coin: %v", p.OutputIndex, p.TokenID, err)
+		}
+	} else if tx.GetTokenID().String() != p.TokenID {
+		return fmt.Errorf("output %v is not a %v coin", p.OutputIndex, p.TokenID)
+	}
+
+	return nil
+}
+
+// newPaymentProofs creates the PaymentProofs of the output coins of a proof which pay the given receivers. Each
+// receiver is matched with an output coin paying it the corresponding amount.
+func newPaymentProofs(txHash, tokenIDStr string, proof privacy.Proof, receiverList []string, amountList []uint64) ([]*PaymentProof, error) {
+	res := make([]*PaymentProof, 0)
+	if len(receiverList) == 0 {
+		return res, nil
+	}
+	proofV2, ok := proof.(*privacy.ProofV2)
+	if !ok || proofV2 == nil {
+		return nil, fmt.Errorf("expected a proof v2, got %T", proof)
+	}
+	sharedRandoms, sharedConcealRandoms := proofV2.GetOutputSharedRandoms()
+
+	used := make(map[int]bool)
+	for i, receiver := range receiverList {
+		addrWallet, err := wallet.Base58CheckDeserialize(receiver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payment address %v: %v", receiver, err)
+		}
+		addr := addrWallet.KeySet.PaymentAddress
+
+		// burnt output coins are not hidden, and need no proof
+		if wallet.IsPublicKeyBurningAddress(addr.Pk) {
+			continue
+		}
+		if addr.GetOTAPublicKey() == nil || addr.GetPublicView() == nil {
+			return nil, fmt.Errorf("%v is not a payment address v2", receiver)
+		}
+
+		outputIndex := findPaymentOutput(proofV2.GetOutputCoins(), sharedRandoms, sharedConcealRandoms, addr, amountList[i], used)
+		if outputIndex < 0 {
+			return nil, fmt.Errorf("no output coin pays %v to %v", amountList[i], receiver)
+		}
+		used[outputIndex] = true
+
+		res = append(res, &PaymentProof{
+			TxHash:              txHash,
+			TokenID:             tokenIDStr,
+			OutputIndex:         outputIndex,
+			PaymentAddress:      receiver,
+			Amount:              amountList[i],
+			SharedRandom:        base58.Base58Check{}.Encode(sharedRandoms[outputIndex].ToBytesS(), common.ZeroByte),
+			SharedConcealRandom: base58.Base58Check{}.Encode(sharedConcealRandoms[outputIndex].ToBytesS(), common.ZeroByte),
+		})
+	}
+
+	return res, nil
+}
+
+// findPaymentOutput returns the index of the first unused output coin paying amount to addr, checked with the shared
+// randoms of the outputs. It returns -1 if there is none.
+func findPaymentOutput(outCoins []coin.Coin, sharedRandoms, sharedConcealRandoms []*crypto.Scalar,
+	addr key.PaymentAddress, amount uint64, used map[int]bool) int {
+	for i, c := range outCoins {
+		if used[i] || i >= len(sharedRandoms) || i >= len(sharedConcealRandoms) ||
+			sharedRandoms[i] == nil || sharedConcealRandoms[i] == nil {
+			continue
+		}
+		outCoin, ok := c.(*coin.CoinV2)
+		if !ok {
+			continue
+		}
+		plainCoin, err := decryptPaymentOutput(outCoin, addr, sharedConcealRandoms[i])
+		if err != nil {
+			continue
+		}
+		if plainCoin.CheckCoinValid(addr, sharedRandoms[i].ToBytesS(), amount) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// decryptPaymentOutput recovers the amount of an output coin paying addr with the shared conceal random of the output,
+// the same way the receiver does with its view key.
+func decryptPaymentOutput(outCoin *coin.CoinV2, addr key.PaymentAddress, sharedConcealRandom *crypto.Scalar) (*coin.CoinV2, error) {
+	plainCoin := new(coin.CoinV2)
+	if err := plainCoin.SetBytes(outCoin.Bytes()); err != nil {
+		return nil, err
+	}
+	if !plainCoin.IsEncrypted() {
+		return plainCoin, nil
+	}
+
+	txConcealRandomPoint, _, _, err := plainCoin.GetTxRandomDetail()
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(sharedConcealRandom), txConcealRandomPoint) {
+		return nil, fmt.Errorf("shared conceal random does not match")
+	}
+	rK := new(crypto.Point).ScalarMult(addr.GetPublicView(), sharedConcealRandom)
+	hash := crypto.HashToScalar(rK.ToBytesS())
+	hash = crypto.HashToScalar(hash.ToBytesS())
+	plainCoin.SetRandomness(new(crypto.Scalar).Sub(plainCoin.GetRandomness(), hash))
+	hash = crypto.HashToScalar(hash.ToBytesS())
+	plainCoin.SetAmount(new(crypto.Scalar).Sub(plainCoin.GetAmount(), hash))
+	if plainCoin.IsEncrypted() {
+		return nil, fmt.Errorf("cannot decrypt the output")
+	}
+
+	return plainCoin, nil
+}
+
+// getTxProofByTokenID returns the proof of a transaction holding the output coins of the given tokenID.
+func getTxProofByTokenID(tx metadata.Transaction, tokenIDStr string) (privacy.Proof, error) {
+	switch tx.GetType() {
+	case common.TxCustomTokenPrivacyType, common.TxTokenConversionType:
+		tmpTx, ok := tx.(tx_generic.TransactionToken)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse the transaction as a transaction token")
+		}
+		if tokenIDStr == common.PRVIDStr {
+			return tmpTx.GetTxBase().GetProof(), nil
+		}
+		return tmpTx.GetTxNormal().GetProof(), nil
+	default:
+		if tokenIDStr != common.PRVIDStr {
+			return nil, fmt.Errorf("transaction %v is not a token transaction", tx.Hash().String())
+		}
+		return tx.GetProof(), nil
+	}
+}
+
+// decodeScalar decodes a base58-encoded scalar.
+func decodeScalar(s string) (*crypto.Scalar, error) {
+	b, _, err := base58.Base58Check{}.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != crypto.Ed25519KeySize {
+		return nil, fmt.Errorf("expected %v bytes, got %v", crypto.Ed25519KeySize, len(b))
+	}
+	res := new(crypto.Scalar).FromBytesS(b)
+	if !res.ScalarValid() {
+		return nil, fmt.Errorf("invalid scalar")
+	}
+
+	return res, nil
+}
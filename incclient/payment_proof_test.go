@@ -0,0 +1,171 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestPaymentProof(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	receiver := newRandomWalletInShard(1)
+	receiverAddr := receiver.Base58CheckSerialize(wallet.PaymentAddressType)
+	otherAddr := newRandomWalletInShard(1).Base58CheckSerialize(wallet.PaymentAddressType)
+	inputCoins, _ := newTestCoinsV2(sender.KeySet.PaymentAddress, 1000)
+
+	// build the proof of a transaction paying 700 to the receiver, and 300 back to the sender
+	paymentInfos := []*key.PaymentInfo{
+		key.InitPaymentInfo(receiver.KeySet.PaymentAddress, 700, []byte{}),
+		key.InitPaymentInfo(sender.KeySet.PaymentAddress, 300, []byte{}),
+	}
+	outputCoins := make([]*coin.CoinV2, 0)
+	for _, paymentInfo := range paymentInfos {
+		outCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(paymentInfo))
+		if err != nil {
+			panic(err)
+		}
+		outputCoins = append(outputCoins, outCoin)
+	}
+	proof, err := privacy.ProveV2(inputCoins, outputCoins, nil, false, paymentInfos)
+	if err != nil {
+		panic(err)
+	}
+	tx := new(tx_ver2.Tx)
+	tx.Version = 2
+	tx.Type = common.TxNormalType
+	tx.Proof = proof
+
+	proofs, err := newPaymentProofs(tx.Hash().String(), common.PRVIDStr, tx.GetProof(), []string{receiverAddr}, []uint64{700})
+	if err != nil {
+		panic(err)
+	}
+	if len(proofs) != 1 || proofs[0].OutputIndex != 0 {
+		panic(fmt.Sprintf("unexpected proofs %v", proofs))
+	}
+
+	// receivers are matched with the outputs paying them, whatever their positions
+	senderAddr := sender.Base58CheckSerialize(wallet.PaymentAddressType)
+	changeProofs, err := newPaymentProofs(tx.Hash().String(), common.PRVIDStr, tx.GetProof(), []string{senderAddr}, []uint64{300})
+	if err != nil {
+		panic(err)
+	}
+	if len(changeProofs) != 1 || changeProofs[0].OutputIndex != 1 || changeProofs[0].VerifyWithTx(tx) != nil {
+		panic(fmt.Sprintf("unexpected proofs %v", changeProofs))
+	}
+	mismatches := map[string]struct {
+		receiver string
+		amount   uint64
+	}{
+		"wrong amount":  {receiverAddr, 300},
+		"wrong address": {otherAddr, 700},
+	}
+	for name, mismatch := range mismatches {
+		_, err = newPaymentProofs(tx.Hash().String(), common.PRVIDStr, tx.GetProof(), []string{mismatch.receiver}, []uint64{mismatch.amount})
+		if err == nil {
+			panic(fmt.Sprintf("expected an error for a receiver with %v", name))
+		}
+	}
+	p, err := ParsePaymentProof([]byte(proofs[0].String()))
+	if err != nil {
+		panic(err)
+	}
+	if err = p.VerifyWithTx(tx); err != nil {
+		panic(err)
+	}
+
+	randomStr := base58.Base58Check{}.Encode(crypto.RandomScalar().ToBytesS(), common.ZeroByte)
+	invalidProofs := map[string]func(p *PaymentProof){
+		"wrong amount":                func(p *PaymentProof) { p.Amount = 701 },
+		"wrong address":               func(p *PaymentProof) { p.PaymentAddress = otherAddr },
+		"wrong output":                func(p *PaymentProof) { p.OutputIndex = 1 },
+		"missing output":              func(p *PaymentProof) { p.OutputIndex = 2 },
+		"wrong token":                 func(p *PaymentProof) { p.TokenID = common.HashH([]byte("token")).String() },
+		"wrong transaction":           func(p *PaymentProof) { p.TxHash = common.HashH([]byte("tx")).String() },
+		"wrong shared random":         func(p *PaymentProof) { p.SharedRandom = randomStr },
+		"wrong shared conceal random": func(p *PaymentProof) { p.SharedConcealRandom = randomStr },
+	}
+	for name, update := range invalidProofs {
+		invalidProof := *p
+		update(&invalidProof)
+		if err = invalidProof.VerifyWithTx(tx); err == nil {
+			panic(fmt.Sprintf("expected an error for a proof with %v", name))
+		}
+	}
+}
+
+func TestPaymentProof_ConfidentialAsset(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	receiver := newRandomWalletInShard(1)
+	receiverAddr := receiver.Base58CheckSerialize(wallet.PaymentAddressType)
+	tokenID := common.HashH([]byte("token"))
+
+	// build the proof of a confidential-asset transaction paying 700 tokens to the receiver, and 300 back to the sender
+	inputCoin, _, err := coin.NewCoinCA(coin.NewTransferCoinParams(key.InitPaymentInfo(sender.KeySet.PaymentAddress, 1000, []byte{})), &tokenID)
+	if err != nil {
+		panic(err)
+	}
+	paymentInfos := []*key.PaymentInfo{
+		key.InitPaymentInfo(receiver.KeySet.PaymentAddress, 700, []byte{}),
+		key.InitPaymentInfo(sender.KeySet.PaymentAddress, 300, []byte{}),
+	}
+	outputCoins := make([]*coin.CoinV2, 0)
+	sharedSecrets := make([]*crypto.Point, 0)
+	for _, paymentInfo := range paymentInfos {
+		outCoin, sharedSecret, err := coin.NewCoinCA(coin.NewTransferCoinParams(paymentInfo), &tokenID)
+		if err != nil {
+			panic(err)
+		}
+		outputCoins = append(outputCoins, outCoin)
+		sharedSecrets = append(sharedSecrets, sharedSecret)
+	}
+	proof, err := privacy.ProveV2([]coin.PlainCoin{inputCoin}, outputCoins, sharedSecrets, true, paymentInfos)
+	if err != nil {
+		panic(err)
+	}
+	tx := new(tx_ver2.TxToken)
+	tx.Tx.Version = 2
+	tx.Tx.Type = common.TxCustomTokenPrivacyType
+	tx.TokenData.PropertyID = common.ConfidentialAssetID
+	tx.TokenData.Proof = proof
+	if proof.GetOutputCoins()[0].GetAssetTag() == nil {
+		panic("expected a blinded asset tag")
+	}
+
+	proofs, err := newPaymentProofs(tx.Hash().String(), tokenID.String(), proof, []string{receiverAddr}, []uint64{700})
+	if err != nil {
+		panic(err)
+	}
+	if len(proofs) != 1 {
+		panic(fmt.Sprintf("unexpected proofs %v", proofs))
+	}
+	p, err := ParsePaymentProof([]byte(proofs[0].String()))
+	if err != nil {
+		panic(err)
+	}
+	if err = p.VerifyWithTx(tx); err != nil {
+		panic(err)
+	}
+
+	// the asset tag must match the token of the proof, and the shared random must be the one of the output
+	sharedRandoms, _ := proof.GetOutputSharedRandoms()
+	otherSharedRandom := base58.Base58Check{}.Encode(sharedRandoms[1].ToBytesS(), common.ZeroByte)
+	invalidProofs := map[string]func(p *PaymentProof){
+		"wrong token":         func(p *PaymentProof) { p.TokenID = common.HashH([]byte("other token")).String() },
+		"wrong shared random": func(p *PaymentProof) { p.SharedRandom = otherSharedRandom },
+	}
+	for name, update := range invalidProofs {
+		invalidProof := *p
+		update(&invalidProof)
+		if err = invalidProof.VerifyWithTx(tx); err == nil {
+			panic(fmt.Sprintf("expected an error for a proof with %v", name))
+		}
+	}
+}
@@ -23,7 +23,7 @@ func (client *IncClient) CreatePdexv3MintNFT(privateKey string) ([]byte, string,
 	}
 	otaReceiver := coin.OTAReceiver{}
 	paymentInfo := &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Message: []byte{}}
-	err = otaReceiver.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+	err = otaReceiver.FromCoinParams(coin.NewMintCoinParamsWithNetwork(client.networkParams, paymentInfo))
 	if err != nil {
 		return nil, "", err
 	}
@@ -93,8 +93,8 @@ func (client *IncClient) CreatePdexv3Trade(privateKey string, tradePath []string
 	if feeInPRV && !isPRV && *tokenBuy != common.PRVCoinID {
 		tokenList = append(tokenList, common.PRVCoinID)
 	}
-	md.Receiver, err = GenerateOTAReceivers(
-		tokenList, senderWallet.KeySet.PaymentAddress)
+	md.Receiver, err = GenerateOTAReceiversWithParams(
+		client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -176,8 +176,8 @@ func (client *IncClient) CreatePdexv3AddOrder(privateKey, pairID string,
 	// create one-time receivers for response TX
 	isPRV := md.TokenToSell == common.PRVCoinID
 	tokenList := []common.Hash{md.TokenToSell, *tokenBuy}
-	md.Receiver, err = GenerateOTAReceivers(
-		tokenList, senderWallet.KeySet.PaymentAddress)
+	md.Receiver, err = GenerateOTAReceiversWithParams(
+		client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -249,7 +249,7 @@ func (client *IncClient) CreatePdexv3WithdrawOrder(privateKey, pairID, orderID s
 		tokenList = append(tokenList, *temp)
 	}
 
-	otaReceivers, err := GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+	otaReceivers, err := GenerateOTAReceiversWithParams(client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -298,7 +298,7 @@ func (client *IncClient) CreatePdexv3Contribute(privateKey, pairID, pairHash, to
 	// construct metadata for contribution
 	temp := coin.OTAReceiver{}
 	paymentInfo := &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Message: []byte{}}
-	err = temp.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+	err = temp.FromCoinParams(coin.NewMintCoinParamsWithNetwork(client.networkParams, paymentInfo))
 	if err != nil {
 		return nil, "", err
 	}
@@ -366,7 +366,7 @@ func (client *IncClient) CreatePdexv3WithdrawLiquidity(privateKey, pairID, token
 	}
 
 	tokenList := []common.Hash{*token0ID, *token1ID, *nftID}
-	otaReceivers, err := GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+	otaReceivers, err := GenerateOTAReceiversWithParams(client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -429,7 +429,7 @@ func (client *IncClient) CreatePdexv3WithdrawLPFee(privateKey, pairID string,
 		tokenList = append(tokenList, *temp)
 	}
 
-	otaReceivers, err := GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+	otaReceivers, err := GenerateOTAReceiversWithParams(client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -514,7 +514,7 @@ func (client *IncClient) CreatePdexv3Staking(privateKey, tokenIDStr, nftIDStr st
 	// construct metadata for contribution
 	temp := coin.OTAReceiver{}
 	paymentInfo := &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Message: []byte{}}
-	err = temp.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+	err = temp.FromCoinParams(coin.NewMintCoinParamsWithNetwork(client.networkParams, paymentInfo))
 	if err != nil {
 		return nil, "", err
 	}
@@ -576,7 +576,7 @@ func (client *IncClient) CreatePdexv3Unstaking(privateKey, tokenIDStr, nftIDStr
 	}
 
 	tokenList := []common.Hash{*nftID, *tokenID}
-	otaReceivers, err := GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+	otaReceivers, err := GenerateOTAReceiversWithParams(client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -646,7 +646,7 @@ func (client *IncClient) CreatePdexv3WithdrawStakeRewardTransaction(
 		}
 	}
 
-	otaReceivers, err := GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+	otaReceivers, err := GenerateOTAReceiversWithParams(client.networkParams, tokenList, senderWallet.KeySet.PaymentAddress)
 	if err != nil {
 		return nil, "", err
 	}
@@ -684,13 +684,20 @@ func (client *IncClient) CreateAndSendPdexv3WithdrawStakeRewardTransaction(
 
 func GenerateOTAReceivers(
 	tokens []common.Hash, addr key.PaymentAddress,
+) (map[common.Hash]coin.OTAReceiver, error) {
+	return GenerateOTAReceiversWithParams(nil, tokens, addr)
+}
+
+// GenerateOTAReceiversWithParams is the same as GenerateOTAReceivers, but derives shards w.r.t the given NetworkParams.
+func GenerateOTAReceiversWithParams(
+	params *common.NetworkParams, tokens []common.Hash, addr key.PaymentAddress,
 ) (map[common.Hash]coin.OTAReceiver, error) {
 	result := make(map[common.Hash]coin.OTAReceiver)
 	var err error
 	for _, tokenID := range tokens {
 		temp := coin.OTAReceiver{}
 		paymentInfo := &key.PaymentInfo{PaymentAddress: addr, Message: []byte{}}
-		err = temp.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+		err = temp.FromCoinParams(coin.NewMintCoinParamsWithNetwork(params, paymentInfo))
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,451 @@
+package incclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+const (
+	CostBasisFIFO    = "FIFO"
+	CostBasisLIFO    = "LIFO"
+	CostBasisAverage = "AVERAGE"
+)
+
+// PnLPriceOracle values token amounts in a quote token at a given beacon height.
+type PnLPriceOracle interface {
+	// GetValue returns the value of an amount of tokenID, expressed in the smallest unit of the quote token,
+	// at the given beacon height.
+	GetValue(tokenID string, amount uint64, beaconHeight uint64) (float64, error)
+}
+
+// PnLEntry is a single movement of funds fed to a PnLCalculator.
+//
+// A trade (IsTrade = true) disposes of SellAmount of SellTokenID and acquires BuyAmount of BuyTokenID; it realizes a
+// gain or a loss. Any other entry either acquires (BuyTokenID is set) funds at their market value, or disposes of
+// (SellTokenID is set) funds without realizing a gain, e.g. transfers and unshields. Fees are always disposals; for a
+// trade, the cost basis of the fees is deducted from the realized gain.
+type PnLEntry struct {
+	TxHash       string
+	LockTime     int64
+	BeaconHeight uint64
+	IsTrade      bool
+	SellTokenID  string
+	SellAmount   uint64
+	BuyTokenID   string
+	BuyAmount    uint64
+	Fees         map[string]uint64
+}
+
+// PnLRealizedGain is the gain (or loss, if negative) realized by a trade.
+// Values are expressed in the smallest unit of the quote token.
+type PnLRealizedGain struct {
+	TxHash     string
+	LockTime   int64
+	TokenID    string
+	Amount     uint64
+	BuyTokenID string
+	BuyAmount  uint64
+	Proceeds   float64
+	CostBasis  float64
+	Gain       float64
+
+	// UncoveredAmount is the part of Amount not covered by previous acquisitions (e.g., because the history is
+	// incomplete); its cost basis is taken as zero.
+	UncoveredAmount uint64
+
+	// Priced indicates whether the proceeds could be determined. Un-priced trades carry the cost basis over to the
+	// bought token and realize nothing.
+	Priced bool
+}
+
+// PnLPosition is the remaining (unrealized) position of a token.
+type PnLPosition struct {
+	TokenID        string
+	Amount         uint64
+	CostBasis      float64
+	MarketValue    float64
+	UnrealizedGain float64
+	Priced         bool
+}
+
+// costLot is an acquisition of a token that has not been fully disposed of.
+type costLot struct {
+	lockTime int64
+	amount   uint64
+	cost     float64
+}
+
+// PnLCalculator computes per-token cost bases and realized gains in a quote token.
+//
+// Entries must be processed in chronological order. The quote token itself is not tracked: disposing of it never
+// realizes a gain.
+type PnLCalculator struct {
+	quoteTokenID string
+	method       string
+	oracle       PnLPriceOracle
+	lots         map[string][]*costLot
+	realized     []PnLRealizedGain
+}
+
+// NewPnLCalculator creates a new PnLCalculator for the given quote token and cost-basis method
+// (CostBasisFIFO, CostBasisLIFO or CostBasisAverage).
+func NewPnLCalculator(quoteTokenID, method string, oracle PnLPriceOracle) (*PnLCalculator, error) {
+	method = strings.ToUpper(method)
+	switch method {
+	case CostBasisFIFO, CostBasisLIFO, CostBasisAverage:
+	default:
+		return nil, fmt.Errorf("cost-basis method %v not supported", method)
+	}
+	if quoteTokenID == "" {
+		return nil, fmt.Errorf("no quote token provided")
+	}
+	if oracle == nil {
+		return nil, fmt.Errorf("no price oracle provided")
+	}
+
+	return &PnLCalculator{
+		quoteTokenID: quoteTokenID,
+		method:       method,
+		oracle:       oracle,
+		lots:         make(map[string][]*costLot),
+		realized:     make([]PnLRealizedGain, 0),
+	}, nil
+}
+
+// Process applies an entry to the current positions.
+func (c *PnLCalculator) Process(entry PnLEntry) {
+	feeCost := float64(0)
+	for tokenIDStr, fee := range entry.Fees {
+		cost, _ := c.dispose(tokenIDStr, fee)
+		feeCost += cost
+	}
+
+	if !entry.IsTrade {
+		if entry.SellTokenID != "" && entry.SellAmount > 0 {
+			_, _ = c.dispose(entry.SellTokenID, entry.SellAmount)
+		}
+		if entry.BuyTokenID != "" && entry.BuyAmount > 0 {
+			value, err := c.getValue(entry.BuyTokenID, entry.BuyAmount, entry.BeaconHeight)
+			if err != nil {
+				Logger.Printf("cannot value %v of %v for tx %v: %v\n", entry.BuyAmount, entry.BuyTokenID, entry.TxHash, err)
+			}
+			c.acquire(entry.BuyTokenID, entry.BuyAmount, value, entry.LockTime)
+		}
+		return
+	}
+
+	gain := PnLRealizedGain{
+		TxHash:     entry.TxHash,
+		LockTime:   entry.LockTime,
+		TokenID:    entry.SellTokenID,
+		Amount:     entry.SellAmount,
+		BuyTokenID: entry.BuyTokenID,
+		BuyAmount:  entry.BuyAmount,
+		Priced:     true,
+	}
+	gain.CostBasis, gain.UncoveredAmount = c.dispose(entry.SellTokenID, entry.SellAmount)
+	gain.CostBasis += feeCost
+
+	// the proceeds are the value of what was received; if neither side is the quote token, the bought amount is
+	// valued by the oracle, falling back to the sold amount.
+	var err error
+	switch {
+	case entry.BuyTokenID == c.quoteTokenID:
+		gain.Proceeds = float64(entry.BuyAmount)
+	case entry.SellTokenID == c.quoteTokenID:
+		gain.Proceeds = float64(entry.SellAmount)
+	default:
+		gain.Proceeds, err = c.oracle.GetValue(entry.BuyTokenID, entry.BuyAmount, entry.BeaconHeight)
+		if err != nil {
+			gain.Proceeds, err = c.oracle.GetValue(entry.SellTokenID, entry.SellAmount, entry.BeaconHeight)
+		}
+		if err != nil {
+			Logger.Printf("cannot price trade %v: %v\n", entry.TxHash, err)
+			gain.Priced = false
+			gain.Proceeds = gain.CostBasis
+		}
+	}
+	c.acquire(entry.BuyTokenID, entry.BuyAmount, gain.Proceeds, entry.LockTime)
+
+	if entry.SellTokenID == c.quoteTokenID {
+		// buying with the quote token is an acquisition only
+		return
+	}
+	gain.Gain = gain.Proceeds - gain.CostBasis
+	c.realized = append(c.realized, gain)
+}
+
+// GetRealizedGains returns the gains realized by the trades processed so far.
+func (c *PnLCalculator) GetRealizedGains() []PnLRealizedGain {
+	return c.realized
+}
+
+// GetPositions returns the current positions of all tokens (but the quote token), valued at the given beacon height.
+func (c *PnLCalculator) GetPositions(beaconHeight uint64) []PnLPosition {
+	tokenIDs := make([]string, 0)
+	for tokenIDStr := range c.lots {
+		tokenIDs = append(tokenIDs, tokenIDStr)
+	}
+	sort.Strings(tokenIDs)
+
+	res := make([]PnLPosition, 0)
+	for _, tokenIDStr := range tokenIDs {
+		pos := PnLPosition{TokenID: tokenIDStr}
+		for _, lot := range c.lots[tokenIDStr] {
+			pos.Amount += lot.amount
+			pos.CostBasis += lot.cost
+		}
+		if pos.Amount == 0 {
+			continue
+		}
+
+		var err error
+		pos.MarketValue, err = c.oracle.GetValue(tokenIDStr, pos.Amount, beaconHeight)
+		if err != nil {
+			Logger.Printf("cannot value the position of %v: %v\n", tokenIDStr, err)
+		} else {
+			pos.Priced = true
+			pos.UnrealizedGain = pos.MarketValue - pos.CostBasis
+		}
+		res = append(res, pos)
+	}
+
+	return res
+}
+
+// getValue returns the value of an amount of a token in the quote token.
+func (c *PnLCalculator) getValue(tokenIDStr string, amount uint64, beaconHeight uint64) (float64, error) {
+	if tokenIDStr == c.quoteTokenID {
+		return float64(amount), nil
+	}
+	return c.oracle.GetValue(tokenIDStr, amount, beaconHeight)
+}
+
+// acquire adds a lot of a token.
+func (c *PnLCalculator) acquire(tokenIDStr string, amount uint64, cost float64, lockTime int64) {
+	if tokenIDStr == "" || tokenIDStr == c.quoteTokenID || amount == 0 {
+		return
+	}
+
+	if c.method == CostBasisAverage && len(c.lots[tokenIDStr]) > 0 {
+		lot := c.lots[tokenIDStr][0]
+		lot.amount += amount
+		lot.cost += cost
+		return
+	}
+	c.lots[tokenIDStr] = append(c.lots[tokenIDStr], &costLot{lockTime: lockTime, amount: amount, cost: cost})
+}
+
+// dispose removes an amount of a token from its lots according to the cost-basis method. It returns the cost basis
+// of the removed amount, and the amount that could not be covered by the existing lots.
+func (c *PnLCalculator) dispose(tokenIDStr string, amount uint64) (float64, uint64) {
+	if tokenIDStr == c.quoteTokenID {
+		return float64(amount), 0
+	}
+
+	lots := c.lots[tokenIDStr]
+	cost := float64(0)
+	for amount > 0 && len(lots) > 0 {
+		idx := 0
+		if c.method == CostBasisLIFO {
+			idx = len(lots) - 1
+		}
+		lot := lots[idx]
+
+		if lot.amount <= amount {
+			cost += lot.cost
+			amount -= lot.amount
+			lots = append(lots[:idx], lots[idx+1:]...)
+			continue
+		}
+
+		partCost := lot.cost * float64(amount) / float64(lot.amount)
+		cost += partCost
+		lot.cost -= partCost
+		lot.amount -= amount
+		amount = 0
+	}
+	c.lots[tokenIDStr] = lots
+
+	return cost, amount
+}
+
+// NewPnLEntries converts history events (see NewHistoryEvents) into PnLEntry's, in chronological order.
+//
+// Successful trades become trade entries. Other events are split into one entry per token, based on their balance
+// changes; refunded trades only cost their fees.
+func NewPnLEntries(events []HistoryEvent) []PnLEntry {
+	res := make([]PnLEntry, 0)
+	for _, event := range events {
+		hashes := event.GetTxHashes()
+		if len(hashes) == 0 {
+			continue
+		}
+
+		if trade, ok := event.(TradeEvent); ok && trade.BuyTokenID != "" && trade.Status == HistoryEventStatusSuccess {
+			entry := PnLEntry{
+				TxHash:      hashes[0],
+				LockTime:    trade.LockTime,
+				IsTrade:     true,
+				SellTokenID: trade.SellTokenID,
+				SellAmount:  trade.SellAmount,
+				BuyTokenID:  trade.BuyTokenID,
+				BuyAmount:   trade.BuyAmount,
+				Fees:        make(map[string]uint64),
+			}
+			// any other token spent by the trade (e.g. a trading fee paid in PRV) is a fee
+			for tokenIDStr, change := range trade.BalanceChanges {
+				switch tokenIDStr {
+				case trade.SellTokenID:
+					if change < -int64(trade.SellAmount) {
+						entry.Fees[tokenIDStr] = uint64(-change) - trade.SellAmount
+					}
+				case trade.BuyTokenID:
+				default:
+					if change < 0 {
+						entry.Fees[tokenIDStr] = uint64(-change)
+					}
+				}
+			}
+			res = append(res, entry)
+			continue
+		}
+
+		changes := event.GetBalanceChanges()
+		tokenIDs := make([]string, 0)
+		for tokenIDStr := range changes {
+			tokenIDs = append(tokenIDs, tokenIDStr)
+		}
+		sort.Strings(tokenIDs)
+		for _, tokenIDStr := range tokenIDs {
+			entry := PnLEntry{TxHash: hashes[0], LockTime: event.GetLockTime()}
+			if change := changes[tokenIDStr]; change < 0 {
+				entry.SellTokenID, entry.SellAmount = tokenIDStr, uint64(-change)
+			} else {
+				entry.BuyTokenID, entry.BuyAmount = tokenIDStr, uint64(change)
+			}
+			res = append(res, entry)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].LockTime < res[j].LockTime
+	})
+
+	return res
+}
+
+// PnLReportParams specifies how to build a PnLReport.
+type PnLReportParams struct {
+	// QuoteTokenID is the token in which values are expressed (e.g., a stable-coin).
+	QuoteTokenID string
+
+	// Method is the cost-basis method (CostBasisFIFO, CostBasisLIFO or CostBasisAverage).
+	Method string
+
+	// From and To delimit the reporting period [From, To). Realized gains are reported for trades within the period;
+	// events after To are ignored. A zero value means no bound.
+	From time.Time
+	To   time.Time
+
+	// BeaconHeight is the beacon height at which the remaining positions are valued (0 for the latest).
+	BeaconHeight uint64
+}
+
+// PnLReport is a profit-and-loss report over a period.
+type PnLReport struct {
+	QuoteTokenID    string
+	Method          string
+	From            int64
+	To              int64
+	BeaconHeight    uint64
+	Realized        []PnLRealizedGain
+	TotalRealized   float64
+	Positions       []PnLPosition
+	TotalUnrealized float64
+}
+
+// NewPnLReport runs a PnLCalculator over a list of entries (in chronological order) and builds the report.
+func NewPnLReport(entries []PnLEntry, params PnLReportParams, oracle PnLPriceOracle) (*PnLReport, error) {
+	calculator, err := NewPnLCalculator(params.QuoteTokenID, params.Method, oracle)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !params.To.IsZero() && !time.Unix(entry.LockTime, 0).Before(params.To) {
+			break
+		}
+		calculator.Process(entry)
+	}
+
+	res := &PnLReport{
+		QuoteTokenID: params.QuoteTokenID,
+		Method:       calculator.method,
+		BeaconHeight: params.BeaconHeight,
+		Realized:     make([]PnLRealizedGain, 0),
+	}
+	if !params.From.IsZero() {
+		res.From = params.From.Unix()
+	}
+	if !params.To.IsZero() {
+		res.To = params.To.Unix()
+	}
+	for _, gain := range calculator.GetRealizedGains() {
+		if !params.From.IsZero() && time.Unix(gain.LockTime, 0).Before(params.From) {
+			continue
+		}
+		res.Realized = append(res.Realized, gain)
+		res.TotalRealized += gain.Gain
+	}
+	res.Positions = calculator.GetPositions(params.BeaconHeight)
+	for _, pos := range res.Positions {
+		res.TotalUnrealized += pos.UnrealizedGain
+	}
+
+	return res, nil
+}
+
+// String returns the string-representation of a PnLReport.
+func (r PnLReport) String() string {
+	formatTime := func(t int64) string {
+		if t == 0 {
+			return "-"
+		}
+		return time.Unix(t, 0).Format(common.DateOutputFormat)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("P&L report (%v) in %v, from %v to %v\n", r.Method, r.QuoteTokenID, formatTime(r.From), formatTime(r.To)))
+	sb.WriteString("Realized gains:\n")
+	for _, gain := range r.Realized {
+		line := fmt.Sprintf("  %v %v: sold %v of %v for %v of %v, proceeds %.0f, cost %.0f, gain %.0f",
+			formatTime(gain.LockTime), gain.TxHash, gain.Amount, gain.TokenID, gain.BuyAmount, gain.BuyTokenID,
+			gain.Proceeds, gain.CostBasis, gain.Gain)
+		if gain.UncoveredAmount > 0 {
+			line += fmt.Sprintf(" (uncovered %v)", gain.UncoveredAmount)
+		}
+		if !gain.Priced {
+			line += " (un-priced)"
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("Total realized: %.0f\n", r.TotalRealized))
+	sb.WriteString(fmt.Sprintf("Positions at beacon height %v:\n", r.BeaconHeight))
+	for _, pos := range r.Positions {
+		line := fmt.Sprintf("  %v: amount %v, cost %.0f", pos.TokenID, pos.Amount, pos.CostBasis)
+		if pos.Priced {
+			line += fmt.Sprintf(", value %.0f, unrealized %.0f", pos.MarketValue, pos.UnrealizedGain)
+		} else {
+			line += " (un-priced)"
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("Total unrealized: %.0f\n", r.TotalUnrealized))
+
+	return sb.String()
+}
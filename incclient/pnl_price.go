@@ -0,0 +1,173 @@
+package incclient
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+)
+
+// PDEXPriceOracle is a PnLPriceOracle based on the pDEX pool states.
+//
+// A token is valued using the virtual reserves of its deepest pool with the quote token, or, if there is no such
+// pool, by routing through PRV. Pool states are cached per beacon height.
+type PDEXPriceOracle struct {
+	client       *IncClient
+	quoteTokenID string
+	poolPairs    map[uint64]map[string]*jsonresult.Pdexv3PoolPairState
+}
+
+// NewPDEXPriceOracle creates a new PDEXPriceOracle for the given quote token.
+func NewPDEXPriceOracle(client *IncClient, quoteTokenID string) *PDEXPriceOracle {
+	return &PDEXPriceOracle{
+		client:       client,
+		quoteTokenID: quoteTokenID,
+		poolPairs:    make(map[uint64]map[string]*jsonresult.Pdexv3PoolPairState),
+	}
+}
+
+// GetValue returns the value of an amount of tokenID in the quote token at the given beacon height
+// (0 for the latest).
+func (o *PDEXPriceOracle) GetValue(tokenIDStr string, amount uint64, beaconHeight uint64) (float64, error) {
+	if tokenIDStr == o.quoteTokenID {
+		return float64(amount), nil
+	}
+
+	poolPairs, ok := o.poolPairs[beaconHeight]
+	if !ok {
+		var err error
+		poolPairs, err = o.client.GetAllPdexPoolPairs(beaconHeight)
+		if err != nil {
+			return 0, fmt.Errorf("cannot get the pool pairs at beacon height %v: %v", beaconHeight, err)
+		}
+		o.poolPairs[beaconHeight] = poolPairs
+	}
+
+	value, err := getPoolValue(poolPairs, tokenIDStr, o.quoteTokenID, new(big.Float).SetUint64(amount))
+	if err == nil {
+		return value, nil
+	}
+	if tokenIDStr == common.PRVIDStr || o.quoteTokenID == common.PRVIDStr {
+		return 0, err
+	}
+
+	prvValue, err := getPoolValue(poolPairs, tokenIDStr, common.PRVIDStr, new(big.Float).SetUint64(amount))
+	if err != nil {
+		return 0, fmt.Errorf("no pool to price %v in %v at beacon height %v", tokenIDStr, o.quoteTokenID, beaconHeight)
+	}
+
+	return getPoolValue(poolPairs, common.PRVIDStr, o.quoteTokenID, new(big.Float).SetFloat64(prvValue))
+}
+
+// getPoolValue values an amount of tokenID in quoteTokenID using the virtual reserves of the pool with the most
+// quoteTokenID liquidity.
+func getPoolValue(poolPairs map[string]*jsonresult.Pdexv3PoolPairState, tokenIDStr, quoteTokenID string,
+	amount *big.Float) (float64, error) {
+	var tokenReserve, quoteReserve *big.Int
+	for _, poolPair := range poolPairs {
+		state := poolPair.State
+		if state.Token0VirtualAmount == nil || state.Token1VirtualAmount == nil {
+			continue
+		}
+
+		var tmpTokenReserve, tmpQuoteReserve *big.Int
+		switch {
+		case state.Token0ID.String() == tokenIDStr && state.Token1ID.String() == quoteTokenID:
+			tmpTokenReserve, tmpQuoteReserve = state.Token0VirtualAmount, state.Token1VirtualAmount
+		case state.Token1ID.String() == tokenIDStr && state.Token0ID.String() == quoteTokenID:
+			tmpTokenReserve, tmpQuoteReserve = state.Token1VirtualAmount, state.Token0VirtualAmount
+		default:
+			continue
+		}
+		if tmpTokenReserve.Sign() <= 0 {
+			continue
+		}
+		if quoteReserve == nil || tmpQuoteReserve.Cmp(quoteReserve) > 0 {
+			tokenReserve, quoteReserve = tmpTokenReserve, tmpQuoteReserve
+		}
+	}
+	if quoteReserve == nil {
+		return 0, fmt.Errorf("no pool found for %v-%v", tokenIDStr, quoteTokenID)
+	}
+
+	value := new(big.Float).Mul(amount, new(big.Float).SetInt(quoteReserve))
+	value.Quo(value, new(big.Float).SetInt(tokenReserve))
+	res, _ := value.Float64()
+
+	return res, nil
+}
+
+// GetPnLReport builds the profit-and-loss report of an account over a period, using the pDEX pool states as the
+// price source.
+//
+// Trades whose response has not been retrieved are completed using CheckTradeStatus. The beacon height of each
+// transaction needing a price is resolved from its shard block; an error is returned if it cannot be resolved, rather
+// than pricing the transaction at the latest pool state.
+func (client *IncClient) GetPnLReport(privateKey string, params PnLReportParams) (*PnLReport, error) {
+	histories, err := NewTxHistoryProcessor(client, DefaultTxHistoryWorkers).GetAllHistory(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	events := NewHistoryEvents(histories)
+	for i, event := range events {
+		trade, ok := event.(TradeEvent)
+		if !ok || trade.Status != HistoryEventStatusPending || trade.RequestTxHash == "" {
+			continue
+		}
+		status, err := client.CheckTradeStatus(trade.RequestTxHash)
+		if err != nil {
+			Logger.Printf("cannot check the status of trade %v: %v\n", trade.RequestTxHash, err)
+			continue
+		}
+		if status.Status == 1 && status.BuyAmount > 0 {
+			trade.Status = HistoryEventStatusSuccess
+			trade.BuyTokenID = status.TokenToBuy
+			trade.BuyAmount = status.BuyAmount
+			events[i] = trade
+		}
+	}
+
+	entries := NewPnLEntries(events)
+	beaconHeights := make(map[string]uint64)
+	for i, entry := range entries {
+		needPrice := entry.BuyTokenID != "" && entry.BuyTokenID != params.QuoteTokenID
+		if entry.IsTrade {
+			needPrice = needPrice && entry.SellTokenID != params.QuoteTokenID
+		}
+		if !needPrice {
+			continue
+		}
+
+		beaconHeight, ok := beaconHeights[entry.TxHash]
+		if !ok {
+			beaconHeight, err = client.getTxBeaconHeight(entry.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get the beacon height of tx %v: %v", entry.TxHash, err)
+			}
+			beaconHeights[entry.TxHash] = beaconHeight
+		}
+		entries[i].BeaconHeight = beaconHeight
+	}
+
+	return NewPnLReport(entries, params, NewPDEXPriceOracle(client, params.QuoteTokenID))
+}
+
+// getTxBeaconHeight returns the beacon height of the shard block containing a transaction.
+func (client *IncClient) getTxBeaconHeight(txHash string) (uint64, error) {
+	txDetail, err := client.GetTxDetail(txHash)
+	if err != nil {
+		return 0, err
+	}
+	if txDetail.BlockHash == "" {
+		return 0, fmt.Errorf("tx %v is not in a block", txHash)
+	}
+
+	block, err := client.GetShardBlockByHash(txDetail.BlockHash)
+	if err != nil {
+		return 0, err
+	}
+
+	return block.BeaconHeight, nil
+}
@@ -0,0 +1,175 @@
+package incclient
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+)
+
+// fakePriceOracle prices tokens with fixed unit prices per beacon height.
+type fakePriceOracle map[uint64]map[string]float64
+
+func (o fakePriceOracle) GetValue(tokenIDStr string, amount uint64, beaconHeight uint64) (float64, error) {
+	price, ok := o[beaconHeight][tokenIDStr]
+	if !ok {
+		return 0, fmt.Errorf("no price for %v at %v", tokenIDStr, beaconHeight)
+	}
+	return price * float64(amount), nil
+}
+
+func TestPnLCalculator(t *testing.T) {
+	quoteTokenID := common.HashH([]byte("quote")).String()
+	tokenID := testHistoryTokenID
+	oracle := fakePriceOracle{
+		1: {tokenID: 2},
+		2: {common.PRVIDStr: 0.5},
+		9: {tokenID: 4, common.PRVIDStr: 0.6},
+	}
+	entries := []PnLEntry{
+		{TxHash: "transfer", LockTime: 10, BeaconHeight: 1, BuyTokenID: tokenID, BuyAmount: 100},
+		{TxHash: "buy", LockTime: 20, IsTrade: true, SellTokenID: quoteTokenID, SellAmount: 300, BuyTokenID: tokenID, BuyAmount: 100},
+		{TxHash: "sell", LockTime: 30, IsTrade: true, SellTokenID: tokenID, SellAmount: 150, BuyTokenID: quoteTokenID, BuyAmount: 600},
+	}
+
+	expected := map[string]struct {
+		cost      float64
+		remaining float64
+	}{
+		CostBasisFIFO:    {cost: 350, remaining: 150},
+		CostBasisLIFO:    {cost: 400, remaining: 100},
+		CostBasisAverage: {cost: 375, remaining: 125},
+	}
+	for method, exp := range expected {
+		calculator, err := NewPnLCalculator(quoteTokenID, method, oracle)
+		if err != nil {
+			panic(err)
+		}
+		for _, entry := range entries {
+			calculator.Process(entry)
+		}
+
+		gains := calculator.GetRealizedGains()
+		if len(gains) != 1 {
+			panic(fmt.Sprintf("%v: expected 1 realized gain, got %v", method, len(gains)))
+		}
+		if gains[0].CostBasis != exp.cost || gains[0].Proceeds != 600 || gains[0].Gain != 600-exp.cost {
+			panic(fmt.Sprintf("%v: unexpected realized gain %+v", method, gains[0]))
+		}
+
+		positions := calculator.GetPositions(9)
+		if len(positions) != 1 || positions[0].Amount != 50 || positions[0].CostBasis != exp.remaining {
+			panic(fmt.Sprintf("%v: unexpected positions %+v", method, positions))
+		}
+		if positions[0].MarketValue != 200 || positions[0].UnrealizedGain != 200-exp.remaining {
+			panic(fmt.Sprintf("%v: unexpected unrealized gain %+v", method, positions[0]))
+		}
+	}
+
+	_, err := NewPnLCalculator(quoteTokenID, "HIFO", oracle)
+	if err == nil {
+		panic("expected an error for an unsupported method")
+	}
+
+	// selling more than acquired, for a token priced by the oracle, realized after From
+	entries = append(entries, PnLEntry{
+		TxHash: "swap", LockTime: 40, BeaconHeight: 2, IsTrade: true,
+		SellTokenID: tokenID, SellAmount: 100, BuyTokenID: common.PRVIDStr, BuyAmount: 1000,
+	})
+	report, err := NewPnLReport(entries, PnLReportParams{
+		QuoteTokenID: quoteTokenID,
+		Method:       CostBasisFIFO,
+		From:         time.Unix(35, 0),
+		BeaconHeight: 9,
+	}, oracle)
+	if err != nil {
+		panic(err)
+	}
+	if len(report.Realized) != 1 || report.Realized[0].TxHash != "swap" {
+		panic(fmt.Sprintf("unexpected realized gains %+v", report.Realized))
+	}
+	swap := report.Realized[0]
+	if swap.UncoveredAmount != 50 || swap.CostBasis != 150 || swap.Proceeds != 500 || report.TotalRealized != 350 {
+		panic(fmt.Sprintf("unexpected swap gain %+v", swap))
+	}
+	if len(report.Positions) != 1 || report.Positions[0].TokenID != common.PRVIDStr || math.Abs(report.TotalUnrealized-100) > 1e-6 {
+		panic(fmt.Sprintf("unexpected positions %+v", report.Positions))
+	}
+}
+
+func TestNewPnLEntries(t *testing.T) {
+	tokenID := testHistoryTokenID
+	events := []HistoryEvent{
+		TradeEvent{
+			HistoryEventBase: HistoryEventBase{
+				Type:             HistoryEventTrade,
+				LockTime:         20,
+				RequestTxHash:    "trade",
+				ResponseTxHashes: []string{"tradeResponse"},
+				BalanceChanges:   map[string]int64{common.PRVIDStr: -1110, tokenID: 320},
+				Status:           HistoryEventStatusSuccess,
+			},
+			SellTokenID: common.PRVIDStr,
+			SellAmount:  1010,
+			BuyTokenID:  tokenID,
+			BuyAmount:   320,
+		},
+		TransferEvent{
+			HistoryEventBase: HistoryEventBase{
+				Type:           HistoryEventTransfer,
+				LockTime:       10,
+				RequestTxHash:  "transfer",
+				BalanceChanges: map[string]int64{common.PRVIDStr: 5000},
+			},
+		},
+	}
+
+	entries := NewPnLEntries(events)
+	if len(entries) != 2 {
+		panic(fmt.Sprintf("expected 2 entries, got %v", len(entries)))
+	}
+	if entries[0].TxHash != "transfer" || entries[0].IsTrade || entries[0].BuyAmount != 5000 {
+		panic(fmt.Sprintf("unexpected transfer entry %+v", entries[0]))
+	}
+	trade := entries[1]
+	if !trade.IsTrade || trade.TxHash != "trade" || trade.SellAmount != 1010 || trade.BuyAmount != 320 {
+		panic(fmt.Sprintf("unexpected trade entry %+v", trade))
+	}
+	if len(trade.Fees) != 1 || trade.Fees[common.PRVIDStr] != 100 {
+		panic(fmt.Sprintf("unexpected trade fees %v", trade.Fees))
+	}
+}
+
+func TestGetPoolValue(t *testing.T) {
+	quoteTokenID := common.HashH([]byte("quote"))
+	tokenID := common.HashH([]byte("token"))
+	newPool := func(token0, token1 common.Hash, amount0, amount1 int64) *jsonresult.Pdexv3PoolPairState {
+		return &jsonresult.Pdexv3PoolPairState{State: jsonresult.Pdexv3PoolPair{
+			Token0ID:            token0,
+			Token1ID:            token1,
+			Token0VirtualAmount: big.NewInt(amount0),
+			Token1VirtualAmount: big.NewInt(amount1),
+		}}
+	}
+	poolPairs := map[string]*jsonresult.Pdexv3PoolPairState{
+		"shallow": newPool(tokenID, quoteTokenID, 100, 100),
+		"deep":    newPool(quoteTokenID, tokenID, 30000, 10000),
+	}
+
+	value, err := getPoolValue(poolPairs, tokenID.String(), quoteTokenID.String(), big.NewFloat(10))
+	if err != nil {
+		panic(err)
+	}
+	if value != 30 {
+		panic(fmt.Sprintf("expected 30, got %v", value))
+	}
+
+	_, err = getPoolValue(poolPairs, common.PRVIDStr, quoteTokenID.String(), big.NewFloat(10))
+	if err == nil {
+		panic("expected an error for a missing pool")
+	}
+}
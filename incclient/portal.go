@@ -185,8 +185,8 @@ func (client *IncClient) CreatePortalUnShieldTransaction(
 		return nil, "", err
 	}
 
-	addr := senderWallet.Base58CheckSerialize(wallet.PaymentAddressType)
-	pubKeyStr, txRandomStr, err := GenerateOTAFromPaymentAddress(addr, coin.PrivacyTypeMint)
+	addr := senderWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, client.networkParams)
+	pubKeyStr, txRandomStr, err := GenerateOTAFromPaymentAddressWithParams(client.networkParams, addr, coin.PrivacyTypeMint)
 	if err != nil {
 		return nil, "", err
 	}
@@ -0,0 +1,217 @@
+package incclient
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// maxPortalMultiSigPubKeys is the maximum number of public keys in a portal multi-sig redeem script (i.e, OP_16).
+const maxPortalMultiSigPubKeys = 16
+
+// NewBTCPortalV4Params creates a new BTCPortalV4Params for an M-of-N multi-sig wallet with the given master public keys.
+// The TokenID is optional, and is only needed when interacting with the Incognito network.
+func NewBTCPortalV4Params(masterPubKeys [][]byte, numRequiredSigs uint, chainParams *chaincfg.Params, tokenID string) (*BTCPortalV4Params, error) {
+	params := &BTCPortalV4Params{
+		MasterPubKeys:   masterPubKeys,
+		NumRequiredSigs: numRequiredSigs,
+		ChainParams:     chainParams,
+		TokenID:         tokenID,
+	}
+	err := ValidateBTCPortalV4Params(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// ValidateBTCPortalV4Params checks if the given BTCPortalV4Params can be used to derive shielding addresses.
+func ValidateBTCPortalV4Params(params *BTCPortalV4Params) error {
+	if params == nil {
+		return fmt.Errorf("v4 Portal params not found")
+	}
+	if params.ChainParams == nil {
+		return fmt.Errorf("chain params not found")
+	}
+	numPubKeys := len(params.MasterPubKeys)
+	if numPubKeys == 0 || numPubKeys > maxPortalMultiSigPubKeys {
+		return fmt.Errorf("expect 1 to %v master public keys, got %v", maxPortalMultiSigPubKeys, numPubKeys)
+	}
+	if params.NumRequiredSigs == 0 || params.NumRequiredSigs > uint(numPubKeys) {
+		return fmt.Errorf("invalid number of required signatures %v (out of %v)", params.NumRequiredSigs, numPubKeys)
+	}
+	for idx, masterPubKey := range params.MasterPubKeys {
+		if _, err := btcec.ParsePubKey(masterPubKey, btcec.S256()); err != nil {
+			return fmt.Errorf("master BTC Public Key (#%v) %x is invalid - Error %v", idx, masterPubKey, err)
+		}
+	}
+
+	return nil
+}
+
+// GetBTCPortalV4Params returns a copy of the v4 Portal parameters of the client.
+func (client *IncClient) GetBTCPortalV4Params() (*BTCPortalV4Params, error) {
+	if client.btcPortalParams == nil {
+		return nil, fmt.Errorf("v4 Portal params not found")
+	}
+
+	res := *client.btcPortalParams
+	return &res, nil
+}
+
+// DerivePortalChildPubKeys derives the BTC child public keys of a payment address from the given master public keys.
+// The chain code of the derivation is the hash of paymentAddressStr; hence, the payment address must be given in the
+// exact same encoding as the one used in shielding requests.
+func DerivePortalChildPubKeys(masterPubKeys [][]byte, paymentAddressStr string, chainParams *chaincfg.Params) ([][]byte, error) {
+	if chainParams == nil {
+		return nil, fmt.Errorf("chain params not found")
+	}
+	_, err := AssertPaymentAddressAndTxVersion(paymentAddressStr, 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment address: %v", err)
+	}
+
+	pubKeys := make([][]byte, 0)
+	chainCode := chainhash.HashB([]byte(paymentAddressStr))
+	for idx, masterPubKey := range masterPubKeys {
+		// generate BTC child public key for this Incognito address
+		extendedBTCPublicKey := hdkeychain.NewExtendedKey(chainParams.HDPublicKeyID[:], masterPubKey, chainCode, []byte{}, 0, 0, false)
+		extendedBTCChildPubKey, err := extendedBTCPublicKey.Child(0)
+		if err != nil {
+			return nil, err
+		}
+
+		childPubKey, err := extendedBTCChildPubKey.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("master BTC Public Key (#%v) %v is invalid - Error %v", idx, masterPubKey, err)
+		}
+		pubKeys = append(pubKeys, childPubKey.SerializeCompressed())
+	}
+
+	return pubKeys, nil
+}
+
+// BuildPortalMultiSigRedeemScript builds the M-of-N multi-sig redeem script from the given public keys.
+func BuildPortalMultiSigRedeemScript(pubKeys [][]byte, numRequiredSigs uint) ([]byte, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > maxPortalMultiSigPubKeys {
+		return nil, fmt.Errorf("expect 1 to %v public keys, got %v", maxPortalMultiSigPubKeys, len(pubKeys))
+	}
+	if numRequiredSigs == 0 || numRequiredSigs > uint(len(pubKeys)) {
+		return nil, fmt.Errorf("invalid number of required signatures %v (out of %v)", numRequiredSigs, len(pubKeys))
+	}
+
+	// create redeem script for m of n multi-sig
+	builder := txscript.NewScriptBuilder()
+	// add the minimum number of needed signatures
+	builder.AddOp(byte(txscript.OP_1 - 1 + numRequiredSigs))
+	// add the public key to redeem script
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	// add the total number of public keys in the multi-sig script
+	builder.AddOp(byte(txscript.OP_1 - 1 + len(pubKeys)))
+	// add the check-multi-sig op-code
+	builder.AddOp(txscript.OP_CHECKMULTISIG)
+
+	redeemScript, err := builder.Script()
+	if err != nil {
+		return nil, fmt.Errorf("could not build script - Error %v", err)
+	}
+
+	return redeemScript, nil
+}
+
+// GetP2WSHAddressFromRedeemScript returns the P2WSH address of a redeem script.
+func GetP2WSHAddressFromRedeemScript(redeemScript []byte, chainParams *chaincfg.Params) (string, error) {
+	scriptHash := sha256.Sum256(redeemScript)
+	addr, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], chainParams)
+	if err != nil {
+		return "", fmt.Errorf("could not generate address from script - Error %v", err)
+	}
+
+	return addr.EncodeAddress(), nil
+}
+
+// DerivePortalShieldingAddress derives, without connecting to any node, the P2WSH multi-sig shielding address and its
+// redeem script for a given payment address from the given v4 Portal parameters.
+// If the payment address is empty, the master public keys are used (i.e, the address of the master wallet).
+func DerivePortalShieldingAddress(params *BTCPortalV4Params, paymentAddressStr string) (string, []byte, error) {
+	err := ValidateBTCPortalV4Params(params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pubKeys := params.MasterPubKeys[:]
+	if paymentAddressStr != "" {
+		pubKeys, err = DerivePortalChildPubKeys(params.MasterPubKeys, paymentAddressStr, params.ChainParams)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	redeemScript, err := BuildPortalMultiSigRedeemScript(pubKeys, params.NumRequiredSigs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	addr, err := GetP2WSHAddressFromRedeemScript(redeemScript, params.ChainParams)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return addr, redeemScript, nil
+}
+
+// DerivePortalShieldingAddresses derives the shielding addresses of a list of payment addresses in bulk.
+// It returns a map from payment addresses to their shielding addresses.
+func DerivePortalShieldingAddresses(params *BTCPortalV4Params, paymentAddresses []string) (map[string]string, error) {
+	err := ValidateBTCPortalV4Params(params)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	for _, paymentAddressStr := range paymentAddresses {
+		if paymentAddressStr == "" {
+			return nil, fmt.Errorf("empty payment address")
+		}
+		addr, _, err := DerivePortalShieldingAddress(params, paymentAddressStr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot derive shielding address for %v: %v", paymentAddressStr, err)
+		}
+		res[paymentAddressStr] = addr
+	}
+
+	return res, nil
+}
+
+// IsPortalShieldingAddressOf checks if the given BTC address is the shielding address of the given payment address
+// w.r.t the given v4 Portal parameters.
+func IsPortalShieldingAddressOf(params *BTCPortalV4Params, btcAddress, paymentAddressStr string) (bool, error) {
+	if paymentAddressStr == "" {
+		return false, fmt.Errorf("empty payment address")
+	}
+	err := ValidateBTCPortalV4Params(params)
+	if err != nil {
+		return false, err
+	}
+
+	decodedAddr, err := btcutil.DecodeAddress(btcAddress, params.ChainParams)
+	if err != nil {
+		return false, fmt.Errorf("invalid BTC address %v: %v", btcAddress, err)
+	}
+
+	addr, _, err := DerivePortalShieldingAddress(params, paymentAddressStr)
+	if err != nil {
+		return false, err
+	}
+
+	return decodedAddr.EncodeAddress() == addr, nil
+}
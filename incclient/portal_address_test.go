@@ -0,0 +1,94 @@
+package incclient
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestDerivePortalShieldingAddress(t *testing.T) {
+	paymentAddress := "12sw5DqMY42zcVbnwxHpGWmVykQ1W89UJexJug62qUK7GWi9x88vtet1ZM4M5tmkWec4tXfeURV9WAFgtAwHs1AitaSW1iVUiX8m4HG3m3NnuV74SYcbJBNFFZPb6PrVvDk9wZmGzSUKSd2xRd4L"
+
+	// a shielding proof accepted by the test-net
+	shieldingProof := "eyJNZXJrbGVQcm9vZnMiOlt7IlByb29mSGFzaCI6WzE2MCwxODIsMTEyLDEzMywyMzcsODYsMjA3LDIxNSw1MiwyMDksMjEsMzQsMjE4LDU0LDEwOCwxNjcsMTc1LDMwLDE4MywxMTEsMTY1LDI0NCwxMjMsNTksODcsMjIzLDExMSwxMzUsMjI5LDEzMyw2MCwxNzJdLCJJc0xlZnQiOnRydWV9LHsiUHJvb2ZIYXNoIjpbNDQsMTk1LDIzMCwxNCwyMDgsMTcxLDIwLDQyLDcsNDksNjMsMTM3LDEyMywzMywxNywyMCwyMTgsNDcsMTY2LDExMiwxNzAsNjUsMiwxMzYsMTA4LDIwMywyMjksMTc4LDIxOCw0OSwzNiw4MV0sIklzTGVmdCI6ZmFsc2V9LHsiUHJvb2ZIYXNoIjpbMTEzLDIxNywyMDQsMTQzLDEwMSwxODIsMTA1LDI1NSw5OCw2NCwxNzYsOTUsMjUxLDY4LDI0NSwyMDEsMTE4LDE3NywxNTcsMTE5LDE5MSwyMTEsMjQzLDEzNywyMDMsMTg3LDEzMiwxNjIsMTg1LDY1LDEzNSwyMzZdLCJJc0xlZnQiOmZhbHNlfSx7IlByb29mSGFzaCI6WzIyNSwxNiwxMjcsMTQyLDIyNiw1OCwxMTYsNywxNDAsMjA4LDIwNiwxNjAsMTc3LDM2LDE2MCw5NSwxNjMsMjQsMjEsNDAsMTksMTMsMTQzLDExNiwxODMsMTQzLDIzNywxNjUsODQsNDQsOTMsMTNdLCJJc0xlZnQiOmZhbHNlfSx7IlByb29mSGFzaCI6WzQzLDIwMiwxNTEsNTcsMTMxLDIyMCwzOCw5MSw0MCwxNzYsMTkwLDIxNiwxNywxNjIsMTEzLDQ2LDE3NiwxNzAsNDQsMjI2LDE1OSw2MiwxODQsNTcsMTQ4LDU5LDE3Miw2OCwxMywxOTMsMiwxMjFdLCJJc0xlZnQiOmZhbHNlfSx7IlByb29mSGFzaCI6WzE1NCwxNDUsMjEzLDE4OSwxOCwzMSw5MSw1MCw3LDgwLDE3NSwxNzAsNjAsOTYsMTIwLDEyMywxNTYsMTI4LDE4Miw3LDEzLDE5MSw4NCwxOTQsMTM3LDE3MiwxMjYsMTgwLDAsMjM3LDE4OCw5MV0sIklzTGVmdCI6dHJ1ZX1dLCJCVENUeCI6eyJWZXJzaW9uIjoyLCJUeEluIjpbeyJQcmV2aW91c091dFBvaW50Ijp7Ikhhc2giOls1NywxMDEsOTQsODUsMzMsNzUsNTksMTgzLDIxLDc4LDcxLDEzNCwxNzcsMTU2LDIwLDE3MywxNTQsNDgsMTA4LDUsODgsMjE4LDE2NSwyMTEsMjUzLDgsMTg4LDk1LDE2MSw2MCw4MiwyMjFdLCJJbmRleCI6MH0sIlNpZ25hdHVyZVNjcmlwdCI6IiIsIldpdG5lc3MiOm51bGwsIlNlcXVlbmNlIjo0Mjk0OTY3MjkzfSx7IlByZXZpb3VzT3V0UG9pbnQiOnsiSGFzaCI6WzY3LDQ3LDI1LDc5LDE4OSwxMTksOCwxMjgsMjAwLDEwOCw2LDQ1LDMxLDQwLDIwOCwxNzcsMTYyLDE1OCwxMzQsMTQsMTM3LDM3LDM5LDE3MywxMDIsMjEyLDQ3LDYwLDIyMSw5OSwxMzMsMTRdLCJJbmRleCI6MH0sIlNpZ25hdHVyZVNjcmlwdCI6IiIsIldpdG5lc3MiOm51bGwsIlNlcXVlbmNlIjo0Mjk0OTY3MjkzfV0sIlR4T3V0IjpbeyJWYWx1ZSI6MTU3NjAsIlBrU2NyaXB0IjoiQUJRa3MyVVAvUlRqcjhOakFHZ0RjQktiMEYwdE9nPT0ifSx7IlZhbHVlIjoxNTAwMDAsIlBrU2NyaXB0IjoiQUNENitOeUZoOWNsWUt5ZmlHMTl1QjhodGVjRXltQWhlQ25CRmpHNGVwYnhYQT09In1dLCJMb2NrVGltZSI6MjA5NjY1OH0sIkJsb2NrSGFzaCI6WzEsOTUsNzAsNzksMTIzLDQxLDcyLDE0OSwxNTcsNTksMjIxLDE5OSw5LDExOCwxMDEsODMsMjQ2LDE3LDI1MiwxMzQsMjI1LDg5LDEwNCw4OSwxLDAsMCwwLDAsMCwwLDBdfQ=="
+	proof, err := DecodeBTCProof(shieldingProof)
+	if err != nil {
+		panic(err)
+	}
+
+	addrStr, redeemScript, err := DerivePortalShieldingAddress(&testNetBTCPortalV4Params, paymentAddress)
+	if err != nil {
+		panic(err)
+	}
+	if len(redeemScript) == 0 {
+		panic("empty redeem script")
+	}
+	addr, err := btcutil.DecodeAddress(addrStr, testNetBTCPortalV4Params.ChainParams)
+	if err != nil {
+		panic(err)
+	}
+	amount, err := getBTCPaidAmount(proof.BTCTx, addr)
+	if err != nil {
+		panic(err)
+	}
+	Logger.Printf("ShieldingAddress: %v, amount: %v\n", addrStr, amount)
+
+	isOwned, err := IsPortalShieldingAddressOf(&testNetBTCPortalV4Params, addrStr, paymentAddress)
+	if err != nil {
+		panic(err)
+	}
+	if !isOwned {
+		panic("expected the shielding address to belong to the payment address")
+	}
+
+	otherPaymentAddress := "12smNK6U7rRbxLConJrmjHGgYFhNVTmKNoqn8B8rJuk5J2ZY363yCsSdAmrbnhrMtNHuXzszRB1xX8VGe6FuxjVqJWwhMmxDKuoaGZfuUaLAC2qnozu2czneFyvTUVAh4kaqLft1yEe5jRydnh39"
+	isOwned, err = IsPortalShieldingAddressOf(&testNetBTCPortalV4Params, addrStr, otherPaymentAddress)
+	if err != nil {
+		panic(err)
+	}
+	if isOwned {
+		panic("expected the shielding address not to belong to another payment address")
+	}
+
+	// bulk derivation
+	addresses, err := DerivePortalShieldingAddresses(&testNetBTCPortalV4Params, []string{paymentAddress, otherPaymentAddress})
+	if err != nil {
+		panic(err)
+	}
+	if len(addresses) != 2 || addresses[paymentAddress] != addrStr {
+		panic("bulk derivation mismatch")
+	}
+}
+
+func TestNewBTCPortalV4Params(t *testing.T) {
+	masterPubKeys := testNetBTCPortalV4Params.MasterPubKeys
+
+	// 2-of-3 on the main-net
+	params, err := NewBTCPortalV4Params(masterPubKeys[:3], 2, &chaincfg.MainNetParams, "")
+	if err != nil {
+		panic(err)
+	}
+	addr, _, err := DerivePortalShieldingAddress(params, "")
+	if err != nil {
+		panic(err)
+	}
+	if _, err = btcutil.DecodeAddress(addr, &chaincfg.MainNetParams); err != nil {
+		panic(err)
+	}
+
+	invalidParams := []struct {
+		masterPubKeys   [][]byte
+		numRequiredSigs uint
+	}{
+		{masterPubKeys, 0},
+		{masterPubKeys, uint(len(masterPubKeys) + 1)},
+		{nil, 1},
+		{[][]byte{{0x2, 0x3}}, 1},
+	}
+	for _, tc := range invalidParams {
+		if _, err = NewBTCPortalV4Params(tc.masterPubKeys, tc.numRequiredSigs, &chaincfg.MainNetParams, ""); err == nil {
+			panic("expected an error")
+		}
+	}
+}
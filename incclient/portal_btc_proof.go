@@ -0,0 +1,358 @@
+package incclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+)
+
+// DefaultBTCMinConfirmations is the minimum number of BTC blocks (including the one containing the transaction) required
+// for a shielding proof to be accepted by the v4 Portal.
+const DefaultBTCMinConfirmations = 6
+
+// BTCMerkleProof is a node in the Merkle branch of a BTC transaction.
+type BTCMerkleProof struct {
+	ProofHash *chainhash.Hash
+	IsLeft    bool
+}
+
+// BTCProof is the proof of a BTC transaction being included in a BTC block. It is the format of the shielding proofs
+// accepted by the v4 Portal.
+type BTCProof struct {
+	MerkleProofs []*BTCMerkleProof
+	BTCTx        *wire.MsgTx
+	BlockHash    *chainhash.Hash
+}
+
+// Encode returns the base64-encoded string of a BTCProof, which can be used as the shieldingProof of
+// CreatePortalShieldTransaction.
+func (p BTCProof) Encode() (string, error) {
+	jsb, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(jsb), nil
+}
+
+// DecodeBTCProof decodes a base64-encoded BTCProof.
+func DecodeBTCProof(encodedProof string) (*BTCProof, error) {
+	jsb, err := base64.StdEncoding.DecodeString(encodedProof)
+	if err != nil {
+		return nil, err
+	}
+
+	var res BTCProof
+	err = json.Unmarshal(jsb, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.BTCTx == nil || res.BlockHash == nil {
+		return nil, fmt.Errorf("invalid BTC proof: missing transaction or block hash")
+	}
+
+	return &res, nil
+}
+
+// BTCShieldingData consists of the raw BTC data needed to build a shielding proof.
+type BTCShieldingData struct {
+	// Tx is the BTC transaction paying the shielding address.
+	Tx *wire.MsgTx
+
+	// BlockHeaders is the chain of block headers, starting from the block containing Tx, followed by its successors.
+	BlockHeaders []*wire.BlockHeader
+
+	// BlockHeight is the height of the block containing Tx. It is used to locate the difficulty retargets within
+	// BlockHeaders.
+	BlockHeight uint64
+
+	// TxHashes is the list of all transaction hashes of the block containing Tx, in order.
+	// It is only used when MerkleBranch is empty.
+	TxHashes []*chainhash.Hash
+
+	// MerkleBranch is the Merkle branch of Tx in its block.
+	MerkleBranch []*BTCMerkleProof
+}
+
+// BuildBTCMerkleProof builds the Merkle branch of the transaction txHash given the list of all transaction hashes of a block.
+func BuildBTCMerkleProof(txHashes []*chainhash.Hash, txHash *chainhash.Hash) ([]*BTCMerkleProof, error) {
+	pos := -1
+	for i, h := range txHashes {
+		if h != nil && h.IsEqual(txHash) {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return nil, fmt.Errorf("tx %v not found in the given list", txHash.String())
+	}
+
+	res := make([]*BTCMerkleProof, 0)
+	layer := txHashes
+	for len(layer) > 1 {
+		if len(layer)%2 == 1 {
+			layer = append(layer[:len(layer):len(layer)], layer[len(layer)-1])
+		}
+
+		if pos%2 == 0 {
+			res = append(res, &BTCMerkleProof{ProofHash: layer[pos+1], IsLeft: false})
+		} else {
+			res = append(res, &BTCMerkleProof{ProofHash: layer[pos-1], IsLeft: true})
+		}
+
+		nextLayer := make([]*chainhash.Hash, 0, len(layer)/2)
+		for i := 0; i < len(layer); i += 2 {
+			nextLayer = append(nextLayer, blockchain.HashMerkleBranches(layer[i], layer[i+1]))
+		}
+		layer = nextLayer
+		pos /= 2
+	}
+
+	return res, nil
+}
+
+// VerifyBTCMerkleProof checks if the given Merkle branch links txHash to merkleRoot.
+func VerifyBTCMerkleProof(txHash *chainhash.Hash, merkleProofs []*BTCMerkleProof, merkleRoot *chainhash.Hash) bool {
+	cur := txHash
+	for _, proof := range merkleProofs {
+		if proof == nil || proof.ProofHash == nil {
+			return false
+		}
+		if proof.IsLeft {
+			cur = blockchain.HashMerkleBranches(proof.ProofHash, cur)
+		} else {
+			cur = blockchain.HashMerkleBranches(cur, proof.ProofHash)
+		}
+	}
+
+	return cur.IsEqual(merkleRoot)
+}
+
+// verifyBTCHeaderChain checks that the given headers (the first one being at height firstHeight) are linked together,
+// and that each of them satisfies the PoW target expected by the difficulty rules of the given chain. The target
+// of the first header cannot be derived from its predecessors, so it is only checked against the PoW limit of the chain;
+// each following target must either be equal to the previous one, or stay within the allowed adjustment at a
+// retarget height (or use the minimum difficulty on networks allowing it). The check does not prove that the headers
+// belong to the best chain: the difficulty of the first header is still trusted from the data source.
+func verifyBTCHeaderChain(chainParams *chaincfg.Params, headers []*wire.BlockHeader, firstHeight uint64) error {
+	if chainParams == nil {
+		return fmt.Errorf("BTC chain params not found")
+	}
+	blocksPerRetarget := uint64(chainParams.TargetTimespan / chainParams.TargetTimePerBlock)
+
+	// the target of the last header not mined with the minimum-difficulty rule
+	var lastBits uint32
+	for i, header := range headers {
+		if header == nil {
+			return fmt.Errorf("block header #%v is nil", i)
+		}
+		blockHash := header.BlockHash()
+		target := blockchain.CompactToBig(header.Bits)
+		if target.Sign() <= 0 || target.Cmp(chainParams.PowLimit) > 0 {
+			return fmt.Errorf("block %v has an invalid PoW target %08x", blockHash.String(), header.Bits)
+		}
+		if blockchain.HashToBig(&blockHash).Cmp(target) > 0 {
+			return fmt.Errorf("block %v does not satisfy its PoW target", blockHash.String())
+		}
+
+		height := firstHeight + uint64(i)
+		if i > 0 {
+			prevHeader := headers[i-1]
+			prevHash := prevHeader.BlockHash()
+			if !header.PrevBlock.IsEqual(&prevHash) {
+				return fmt.Errorf("block %v does not follow block %v", blockHash.String(), prevHash.String())
+			}
+			if !isExpectedBTCDifficulty(chainParams, prevHeader, header, height%blocksPerRetarget == 0, lastBits) {
+				return fmt.Errorf("block %v at height %v has an unexpected PoW target %08x", blockHash.String(),
+					height, header.Bits)
+			}
+		}
+
+		if !chainParams.ReduceMinDifficulty || header.Bits != chainParams.PowLimitBits || height%blocksPerRetarget == 0 {
+			lastBits = header.Bits
+		}
+	}
+
+	return nil
+}
+
+// isExpectedBTCDifficulty checks if the PoW target of header is the one expected after prevHeader. lastBits is the
+// target of the last known block not mined with the minimum-difficulty rule (or 0 if unknown).
+func isExpectedBTCDifficulty(chainParams *chaincfg.Params, prevHeader, header *wire.BlockHeader, isRetarget bool, lastBits uint32) bool {
+	if isRetarget {
+		// the new target cannot move more than RetargetAdjustmentFactor times away from the previous one
+		prevTarget := blockchain.CompactToBig(prevHeader.Bits)
+		factor := big.NewInt(chainParams.RetargetAdjustmentFactor)
+		maxTarget := new(big.Int).Mul(prevTarget, factor)
+		if maxTarget.Cmp(chainParams.PowLimit) > 0 {
+			maxTarget.Set(chainParams.PowLimit)
+		}
+		minTarget := blockchain.CompactToBig(blockchain.BigToCompact(new(big.Int).Div(prevTarget, factor)))
+		target := blockchain.CompactToBig(header.Bits)
+
+		return target.Cmp(minTarget) >= 0 && target.Cmp(maxTarget) <= 0
+	}
+
+	if !chainParams.ReduceMinDifficulty {
+		return header.Bits == prevHeader.Bits
+	}
+
+	// the minimum difficulty is allowed once no block has been mined for MinDiffReductionTime
+	reductionTime := int64(chainParams.MinDiffReductionTime / time.Second)
+	if header.Bits == chainParams.PowLimitBits && header.Timestamp.Unix() > prevHeader.Timestamp.Unix()+reductionTime {
+		return true
+	}
+	if lastBits == 0 {
+		// the real difficulty is not known from the given headers
+		return true
+	}
+
+	return header.Bits == lastBits
+}
+
+// getBTCPaidAmount returns the total amount (in satoshi) the given transaction pays to the given address.
+func getBTCPaidAmount(tx *wire.MsgTx, address btcutil.Address) (uint64, error) {
+	outputs, err := getBTCPaidOutputs(tx, address)
+	if err != nil {
+		return 0, err
+	}
+
+	amount := uint64(0)
+	for _, value := range outputs {
+		amount += value
+	}
+
+	return amount, nil
+}
+
+// getBTCPaidOutputs returns the values (in satoshi) of the outputs of the given transaction paying to the given address.
+func getBTCPaidOutputs(tx *wire.MsgTx, address btcutil.Address) ([]uint64, error) {
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]uint64, 0)
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, pkScript) && out.Value > 0 {
+			res = append(res, uint64(out.Value))
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("tx %v does not pay to %v", tx.TxHash().String(), address.EncodeAddress())
+	}
+
+	return res, nil
+}
+
+// BuildPortalShieldingProof builds and validates a v4 Portal shielding proof for a BTC transaction paying the shielding
+// address of paymentAddressStr. The validation includes:
+//   - the transaction pays to the multi-sig shielding address of paymentAddressStr;
+//   - the transaction is included in the first block of data.BlockHeaders;
+//   - the block headers are linked together and satisfy the PoW targets expected by the difficulty rules of the BTC
+//     chain, starting from the (trusted) target of the first header;
+//   - the transaction has at least DefaultBTCMinConfirmations confirmations.
+//
+// It returns the encoded proof, the shielding amount (in satoshi), and an error (if any).
+func (client *IncClient) BuildPortalShieldingProof(paymentAddressStr string, data *BTCShieldingData) (string, uint64, error) {
+	if client.btcPortalParams == nil {
+		return "", 0, fmt.Errorf("v4 Portal params not found")
+	}
+	if data == nil || data.Tx == nil {
+		return "", 0, fmt.Errorf("BTC transaction not found")
+	}
+	if len(data.BlockHeaders) < DefaultBTCMinConfirmations {
+		return "", 0, fmt.Errorf("expect at least %v confirmations, got %v", DefaultBTCMinConfirmations, len(data.BlockHeaders))
+	}
+
+	shieldingAddressStr, _, err := DerivePortalShieldingAddress(client.btcPortalParams, paymentAddressStr)
+	if err != nil {
+		return "", 0, err
+	}
+	shieldingAddress, err := btcutil.DecodeAddress(shieldingAddressStr, client.btcPortalParams.ChainParams)
+	if err != nil {
+		return "", 0, err
+	}
+	amount, err := getBTCPaidAmount(data.Tx, shieldingAddress)
+	if err != nil {
+		return "", 0, err
+	}
+
+	txHash := data.Tx.TxHash()
+	merkleProofs := data.MerkleBranch
+	if len(merkleProofs) == 0 {
+		merkleProofs, err = BuildBTCMerkleProof(data.TxHashes, &txHash)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	err = verifyBTCHeaderChain(client.btcPortalParams.ChainParams, data.BlockHeaders, data.BlockHeight)
+	if err != nil {
+		return "", 0, err
+	}
+	blockHeader := data.BlockHeaders[0]
+	if !VerifyBTCMerkleProof(&txHash, merkleProofs, &blockHeader.MerkleRoot) {
+		return "", 0, fmt.Errorf("invalid merkle proof for tx %v", txHash.String())
+	}
+
+	blockHash := blockHeader.BlockHash()
+	proof := BTCProof{
+		MerkleProofs: merkleProofs,
+		BTCTx:        data.Tx,
+		BlockHash:    &blockHash,
+	}
+	encodedProof, err := proof.Encode()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return encodedProof, amount, nil
+}
+
+// BuildPortalShieldingProofFromSource retrieves the data of a BTC transaction from the given BTCDataSource, and builds
+// a v4 Portal shielding proof for it. See BuildPortalShieldingProof for the list of validations.
+//
+// It returns the encoded proof, the shielding amount (in satoshi), and an error (if any).
+func (client *IncClient) BuildPortalShieldingProofFromSource(
+	paymentAddressStr, btcTxHash string, source BTCDataSource,
+) (string, uint64, error) {
+	data, err := GetBTCShieldingData(source, btcTxHash, DefaultBTCMinConfirmations)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return client.BuildPortalShieldingProof(paymentAddressStr, data)
+}
+
+// CreateAndSendPortalShieldTransactionFromBTCTx builds a shielding proof for a BTC transaction paying the shielding address
+// of paymentAddressStr using the given BTCDataSource, creates a Portal V4 shielding transaction, and submits it to the
+// Incognito network.
+//
+// It returns the transaction's hash, and an error (if any).
+func (client *IncClient) CreateAndSendPortalShieldTransactionFromBTCTx(
+	privateKey, paymentAddressStr, btcTxHash string, source BTCDataSource, inputCoins []coin.PlainCoin, coinIndices []uint64,
+) (string, error) {
+	if client.btcPortalParams == nil {
+		return "", fmt.Errorf("v4 Portal params not found")
+	}
+
+	shieldingProof, _, err := client.BuildPortalShieldingProofFromSource(paymentAddressStr, btcTxHash, source)
+	if err != nil {
+		return "", err
+	}
+
+	return client.CreateAndSendPortalShieldTransaction(
+		privateKey, client.btcPortalParams.TokenID, paymentAddressStr, shieldingProof, inputCoins, coinIndices)
+}
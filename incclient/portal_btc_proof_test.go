@@ -0,0 +1,150 @@
+package incclient
+
+import (
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"testing"
+)
+
+func TestBuildBTCMerkleProof(t *testing.T) {
+	for numTxs := 1; numTxs <= 20; numTxs++ {
+		txHashes := make([]*chainhash.Hash, 0)
+		txs := make([]*btcutil.Tx, 0)
+		for i := 0; i < numTxs; i++ {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.LockTime = uint32(common.RandUint64())
+			txs = append(txs, btcutil.NewTx(tx))
+			txHashes = append(txHashes, txs[i].Hash())
+		}
+		merkles := blockchain.BuildMerkleTreeStore(txs, false)
+		merkleRoot := merkles[len(merkles)-1]
+
+		for i := 0; i < numTxs; i++ {
+			proofs, err := BuildBTCMerkleProof(txHashes, txHashes[i])
+			if err != nil {
+				panic(err)
+			}
+			if !VerifyBTCMerkleProof(txHashes[i], proofs, merkleRoot) {
+				panic("invalid merkle proof")
+			}
+			if VerifyBTCMerkleProof(txHashes[(i+1)%numTxs], proofs, merkleRoot) && numTxs > 1 {
+				panic("merkle proof should be invalid for another tx")
+			}
+		}
+	}
+}
+
+func TestIncClient_BuildPortalShieldingProof(t *testing.T) {
+	// a regression network without the minimum-difficulty rule, to be able to mine blocks
+	chainParams := chaincfg.RegressionNetParams
+	chainParams.ReduceMinDifficulty = false
+	portalParams := testNetBTCPortalV4Params
+	portalParams.ChainParams = &chainParams
+	client := &IncClient{btcPortalParams: &portalParams}
+	paymentAddress := "12smNK6U7rRbxLConJrmjHGgYFhNVTmKNoqn8B8rJuk5J2ZY363yCsSdAmrbnhrMtNHuXzszRB1xX8VGe6FuxjVqJWwhMmxDKuoaGZfuUaLAC2qnozu2czneFyvTUVAh4kaqLft1yEe5jRydnh39"
+	shieldingAddrStr, _, err := DerivePortalShieldingAddress(client.btcPortalParams, paymentAddress)
+	if err != nil {
+		panic(err)
+	}
+	shieldingAddr, err := btcutil.DecodeAddress(shieldingAddrStr, client.btcPortalParams.ChainParams)
+	if err != nil {
+		panic(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(shieldingAddr)
+	if err != nil {
+		panic(err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxOut(wire.NewTxOut(100000, pkScript))
+	tx.AddTxOut(wire.NewTxOut(5000, []byte{txscript.OP_RETURN}))
+	txs := []*btcutil.Tx{btcutil.NewTx(wire.NewMsgTx(wire.TxVersion)), btcutil.NewTx(tx)}
+	txHashes := []*chainhash.Hash{txs[0].Hash(), txs[1].Hash()}
+	merkles := blockchain.BuildMerkleTreeStore(txs, false)
+
+	// mine a few easy blocks
+	bits := make([]uint32, DefaultBTCMinConfirmations)
+	for i := range bits {
+		bits[i] = chainParams.PowLimitBits
+	}
+	headers := mineBTCHeaders(*merkles[len(merkles)-1], bits)
+
+	data := &BTCShieldingData{Tx: tx, BlockHeaders: headers, BlockHeight: 100, TxHashes: txHashes}
+	encodedProof, amount, err := client.BuildPortalShieldingProof(paymentAddress, data)
+	if err != nil {
+		panic(err)
+	}
+	if amount != 100000 {
+		panic("invalid shielding amount")
+	}
+
+	proof, err := DecodeBTCProof(encodedProof)
+	if err != nil {
+		panic(err)
+	}
+	if proof.BTCTx.TxHash() != tx.TxHash() || *proof.BlockHash != headers[0].BlockHash() {
+		panic("decoded proof mismatch")
+	}
+
+	// not enough confirmations
+	data.BlockHeaders = headers[:DefaultBTCMinConfirmations-1]
+	if _, _, err = client.BuildPortalShieldingProof(paymentAddress, data); err == nil {
+		panic("expected an error")
+	}
+
+	// broken header chain
+	data.BlockHeaders = append([]*wire.BlockHeader{headers[0]}, headers[2:]...)
+	data.BlockHeaders = append(data.BlockHeaders, headers[1])
+	if _, _, err = client.BuildPortalShieldingProof(paymentAddress, data); err == nil {
+		panic("expected an error")
+	}
+
+	// the difficulty can only change at a retarget height, within the adjustment factor
+	blocksPerRetarget := uint64(chainParams.TargetTimespan / chainParams.TargetTimePerBlock)
+	for i := 3; i < len(bits); i++ {
+		bits[i] = 0x201fffff
+	}
+	data.BlockHeaders = mineBTCHeaders(*merkles[len(merkles)-1], bits)
+	if _, _, err = client.BuildPortalShieldingProof(paymentAddress, data); err == nil {
+		panic("expected an error for a difficulty change outside a retarget")
+	}
+	data.BlockHeight = blocksPerRetarget - 3
+	if _, _, err = client.BuildPortalShieldingProof(paymentAddress, data); err != nil {
+		panic(err)
+	}
+	for i := 3; i < len(bits); i++ {
+		bits[i] = 0x1f7fffff
+	}
+	data.BlockHeaders = mineBTCHeaders(*merkles[len(merkles)-1], bits)
+	if _, _, err = client.BuildPortalShieldingProof(paymentAddress, data); err == nil {
+		panic("expected an error for a difficulty change beyond the adjustment factor")
+	}
+}
+
+// mineBTCHeaders mines a chain of block headers with the given targets, the first one committing to merkleRoot.
+func mineBTCHeaders(merkleRoot chainhash.Hash, bits []uint32) []*wire.BlockHeader {
+	headers := make([]*wire.BlockHeader, 0)
+	prevHash := chainhash.Hash{}
+	for i := range bits {
+		header := &wire.BlockHeader{PrevBlock: prevHash, Bits: bits[i]}
+		if i == 0 {
+			header.MerkleRoot = merkleRoot
+		}
+		for {
+			h := header.BlockHash()
+			if blockchain.HashToBig(&h).Cmp(blockchain.CompactToBig(header.Bits)) <= 0 {
+				break
+			}
+			header.Nonce++
+		}
+		prevHash = header.BlockHash()
+		headers = append(headers, header)
+	}
+
+	return headers
+}
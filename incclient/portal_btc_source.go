@@ -0,0 +1,225 @@
+package incclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BTCDataSource provides the BTC data needed to build v4 Portal shielding proofs.
+type BTCDataSource interface {
+	// GetRawTransaction returns the BTC transaction with the given hash.
+	GetRawTransaction(txHash string) (*wire.MsgTx, error)
+
+	// GetTxBlock returns the hash and height of the block containing the given transaction.
+	GetTxBlock(txHash string) (*chainhash.Hash, uint64, error)
+
+	// GetBlockHeader returns the header of the given block.
+	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
+
+	// GetBlockTxHashes returns the hashes of all transactions in the given block, in order.
+	GetBlockTxHashes(blockHash *chainhash.Hash) ([]*chainhash.Hash, error)
+
+	// GetBlockHashByHeight returns the hash of the block at the given height on the best chain.
+	GetBlockHashByHeight(height uint64) (*chainhash.Hash, error)
+}
+
+// GetBTCShieldingData retrieves from the given BTCDataSource all data needed to build a shielding proof for a BTC
+// transaction. The returned BTCShieldingData consists of numConfirmations block headers, starting from the block
+// containing the transaction.
+func GetBTCShieldingData(source BTCDataSource, txHash string, numConfirmations int) (*BTCShieldingData, error) {
+	if source == nil {
+		return nil, fmt.Errorf("BTC data source not found")
+	}
+
+	tx, err := source.GetRawTransaction(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get BTC tx %v: %v", txHash, err)
+	}
+	blockHash, blockHeight, err := source.GetTxBlock(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the block of BTC tx %v: %v", txHash, err)
+	}
+	txHashes, err := source.GetBlockTxHashes(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get txs of BTC block %v: %v", blockHash.String(), err)
+	}
+
+	headers := make([]*wire.BlockHeader, 0)
+	for i := 0; i < numConfirmations; i++ {
+		tmpHash := blockHash
+		if i > 0 {
+			tmpHash, err = source.GetBlockHashByHeight(blockHeight + uint64(i))
+			if err != nil {
+				return nil, fmt.Errorf("cannot get BTC block hash at height %v: %v", blockHeight+uint64(i), err)
+			}
+		}
+		header, err := source.GetBlockHeader(tmpHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get BTC block header %v: %v", tmpHash.String(), err)
+		}
+		headers = append(headers, header)
+	}
+
+	return &BTCShieldingData{
+		Tx:           tx,
+		BlockHeaders: headers,
+		BlockHeight:  blockHeight,
+		TxHashes:     txHashes,
+	}, nil
+}
+
+// EsploraBTCDataSource implements a BTCDataSource using an Esplora-compatible REST API
+// (e.g. https://blockstream.info/api, https://blockstream.info/testnet/api).
+type EsploraBTCDataSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewEsploraBTCDataSource creates a new EsploraBTCDataSource pointing to the given url.
+func NewEsploraBTCDataSource(url string) *EsploraBTCDataSource {
+	return &EsploraBTCDataSource{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: 1 * time.Minute},
+	}
+}
+
+// GetRawTransaction returns the BTC transaction with the given hash.
+func (s EsploraBTCDataSource) GetRawTransaction(txHash string) (*wire.MsgTx, error) {
+	rawTx, err := s.getHex(fmt.Sprintf("/tx/%v/hex", txHash))
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	err = tx.Deserialize(bytes.NewReader(rawTx))
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// GetTxBlock returns the hash and height of the block containing the given transaction.
+func (s EsploraBTCDataSource) GetTxBlock(txHash string) (*chainhash.Hash, uint64, error) {
+	resp, err := s.get(fmt.Sprintf("/tx/%v/status", txHash))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var status struct {
+		Confirmed   bool   `json:"confirmed"`
+		BlockHeight uint64 `json:"block_height"`
+		BlockHash   string `json:"block_hash"`
+	}
+	err = json.Unmarshal(resp, &status)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !status.Confirmed {
+		return nil, 0, fmt.Errorf("tx %v has not been confirmed", txHash)
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(status.BlockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return blockHash, status.BlockHeight, nil
+}
+
+// GetBlockHeader returns the header of the given block.
+func (s EsploraBTCDataSource) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	rawHeader, err := s.getHex(fmt.Sprintf("/block/%v/header", blockHash.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	header := new(wire.BlockHeader)
+	err = header.Deserialize(bytes.NewReader(rawHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// GetBlockTxHashes returns the hashes of all transactions in the given block, in order.
+func (s EsploraBTCDataSource) GetBlockTxHashes(blockHash *chainhash.Hash) ([]*chainhash.Hash, error) {
+	resp, err := s.get(fmt.Sprintf("/block/%v/txids", blockHash.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	var txIDs []string
+	err = json.Unmarshal(resp, &txIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*chainhash.Hash, 0)
+	for _, txID := range txIDs {
+		h, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, h)
+	}
+
+	return res, nil
+}
+
+// GetBlockHashByHeight returns the hash of the block at the given height on the best chain.
+func (s EsploraBTCDataSource) GetBlockHashByHeight(height uint64) (*chainhash.Hash, error) {
+	resp, err := s.get(fmt.Sprintf("/block-height/%v", height))
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(strings.TrimSpace(string(resp)))
+}
+
+func (s EsploraBTCDataSource) get(path string) ([]byte, error) {
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(s.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			Logger.Printf("BodyClose %v\n", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: %v", resp.Status, string(body))
+	}
+
+	return body, nil
+}
+
+func (s EsploraBTCDataSource) getHex(path string) ([]byte, error) {
+	resp, err := s.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(strings.TrimSpace(string(resp)))
+}
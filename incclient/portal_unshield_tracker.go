@@ -0,0 +1,500 @@
+package incclient
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+)
+
+// PortalUnshieldStage represents a stage in the lifecycle of a v4 Portal un-shielding request.
+type PortalUnshieldStage int
+
+const (
+	// PortalUnshieldStageWaiting indicates the request is waiting to be included in a batch.
+	PortalUnshieldStageWaiting PortalUnshieldStage = iota
+
+	// PortalUnshieldStageBatched indicates the request has been included in a batch, and the external transaction has been signed.
+	PortalUnshieldStageBatched
+
+	// PortalUnshieldStageBroadcast indicates the external transaction of the batch has been found on the BTC network.
+	PortalUnshieldStageBroadcast
+
+	// PortalUnshieldStageCompleted indicates the confirmation of the external transaction has been submitted to the Incognito network.
+	PortalUnshieldStageCompleted
+
+	// PortalUnshieldStageRefunded indicates the request has been refunded.
+	PortalUnshieldStageRefunded
+)
+
+// String returns the string representation of a PortalUnshieldStage.
+func (s PortalUnshieldStage) String() string {
+	switch s {
+	case PortalUnshieldStageWaiting:
+		return "waiting"
+	case PortalUnshieldStageBatched:
+		return "batched"
+	case PortalUnshieldStageBroadcast:
+		return "broadcast"
+	case PortalUnshieldStageCompleted:
+		return "completed"
+	case PortalUnshieldStageRefunded:
+		return "refunded"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s PortalUnshieldStage) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// PortalUnshieldFee describes the network fee of a batch at a beacon height. A non-empty RBFReqTxID indicates the fee
+// was set by a replace-by-fee request.
+type PortalUnshieldFee struct {
+	BeaconHeight uint64
+	NetworkFee   uint
+	RBFReqTxID   string
+}
+
+// PortalUnshieldReport describes the lifecycle of a v4 Portal un-shielding request.
+type PortalUnshieldReport struct {
+	UnshieldID     string
+	TokenID        string
+	RemoteAddress  string
+	UnshieldAmount uint64
+	Stage          PortalUnshieldStage
+
+	// BatchID is the ID of the batch including the request (if found).
+	BatchID string
+
+	// Fees is the fee history of the batch, sorted by beacon heights.
+	Fees []PortalUnshieldFee
+
+	// ExternalTxID is the hash of the latest external transaction paying for the request.
+	ExternalTxID string
+
+	// ExternalTx is the decoded external transaction (if available).
+	ExternalTx *wire.MsgTx `json:"-"`
+
+	// ExternalFee is the fee (in Incognito unit) charged for the request.
+	ExternalFee uint64
+
+	// NumConfirmations is the number of BTC confirmations of the external transaction, capped at DefaultBTCMinConfirmations
+	// (only when a BTCDataSource is set).
+	NumConfirmations uint64
+
+	// ExpectedAmount is the amount (in satoshi) the external transaction should pay to RemoteAddress.
+	ExpectedAmount uint64
+
+	// PaidAmount is the amount (in satoshi) of the output of the external transaction paying for the request.
+	PaidAmount uint64
+
+	// Verified indicates whether the external transaction has been verified against the request.
+	Verified bool
+
+	// VerificationError is the reason why the verification failed (if any).
+	VerificationError string
+}
+
+// ConvertIncPBTCAmountToExternalBTCAmount converts an amount of pBTC (9 decimals) into satoshi (8 decimals).
+func ConvertIncPBTCAmountToExternalBTCAmount(incAmount uint64) uint64 {
+	return incAmount / 10
+}
+
+// VerifyPortalUnshieldPayout checks if the given BTC transaction has an output paying at least expectedAmount (in satoshi)
+// to remoteAddress. A batch pays each of its requests with a separate output; so when other requests of the same batch
+// are paid to remoteAddress, their expected amounts must be given in otherExpectedAmounts, and each output is matched
+// to at most one request.
+//
+// It returns the amount of the output matched to the request.
+func VerifyPortalUnshieldPayout(
+	tx *wire.MsgTx, remoteAddress string, expectedAmount uint64, params *BTCPortalV4Params, otherExpectedAmounts ...uint64,
+) (uint64, error) {
+	if tx == nil {
+		return 0, fmt.Errorf("external tx not found")
+	}
+	if params == nil {
+		return 0, fmt.Errorf("v4 Portal params not found")
+	}
+
+	addr, err := btcutil.DecodeAddress(remoteAddress, params.ChainParams)
+	if err != nil {
+		return 0, fmt.Errorf("invalid remote address %v: %v", remoteAddress, err)
+	}
+	outputs, err := getBTCPaidOutputs(tx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	paidAmount, ok := matchBTCPayouts(outputs, expectedAmount, otherExpectedAmounts)
+	if !ok {
+		if len(otherExpectedAmounts) == 0 {
+			return paidAmount, fmt.Errorf("tx %v pays %v to %v, expected %v", tx.TxHash().String(), outputs, remoteAddress, expectedAmount)
+		}
+		return paidAmount, fmt.Errorf("tx %v pays %v to %v, expected %v for this request and %v for the other requests of the batch",
+			tx.TxHash().String(), outputs, remoteAddress, expectedAmount, otherExpectedAmounts)
+	}
+
+	return paidAmount, nil
+}
+
+// matchBTCPayouts matches each expected amount (expectedAmount first, followed by otherExpectedAmounts) to a distinct
+// output paying at least that amount. It returns the output matched to expectedAmount (or the largest output if none),
+// and whether every expected amount has been matched.
+func matchBTCPayouts(outputs []uint64, expectedAmount uint64, otherExpectedAmounts []uint64) (uint64, bool) {
+	sortedOutputs := append([]uint64{}, outputs...)
+	sort.Slice(sortedOutputs, func(i, j int) bool { return sortedOutputs[i] < sortedOutputs[j] })
+	expectedAmounts := append([]uint64{expectedAmount}, otherExpectedAmounts...)
+	order := make([]int, len(expectedAmounts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return expectedAmounts[order[i]] < expectedAmounts[order[j]] })
+
+	// assigning the smallest sufficient output to the smallest expected amount first matches as many requests as possible
+	used := make([]bool, len(sortedOutputs))
+	matched := make([]int, len(expectedAmounts))
+	allMatched := true
+	for _, idx := range order {
+		matched[idx] = -1
+		for i, value := range sortedOutputs {
+			if !used[i] && value >= expectedAmounts[idx] {
+				used[i] = true
+				matched[idx] = i
+				break
+			}
+		}
+		if matched[idx] == -1 {
+			allMatched = false
+		}
+	}
+
+	if matched[0] == -1 {
+		return sortedOutputs[len(sortedOutputs)-1], false
+	}
+	return sortedOutputs[matched[0]], allMatched
+}
+
+// PortalUnshieldTracker follows v4 Portal un-shielding requests through batching, replace-by-fee and the final BTC broadcast.
+type PortalUnshieldTracker struct {
+	client    *IncClient
+	btcSource BTCDataSource
+
+	mtx      *sync.Mutex
+	batchIDs map[string]string // unshieldID => batchID
+
+	// batchRequests holds the un-shielding requests of the batches found in the Portal state.
+	batchRequests map[string][]string // batchID => unshieldIDs
+}
+
+// NewPortalUnshieldTracker creates a new PortalUnshieldTracker. btcSource is optional; if set, it is used to retrieve
+// external transactions and their confirmations from the BTC network.
+func NewPortalUnshieldTracker(client *IncClient, btcSource BTCDataSource) (*PortalUnshieldTracker, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not found")
+	}
+	if client.btcPortalParams == nil {
+		return nil, fmt.Errorf("v4 Portal params not found")
+	}
+
+	return &PortalUnshieldTracker{
+		client:        client,
+		btcSource:     btcSource,
+		mtx:           new(sync.Mutex),
+		batchIDs:      make(map[string]string),
+		batchRequests: make(map[string][]string),
+	}, nil
+}
+
+// SetBatchID tells the tracker which batch an un-shielding request belongs to. It is useful for completed requests,
+// whose batches have been removed from the Portal state. The other requests of the batch are then retrieved via
+// GetPortalBatchUnShieldingRequestStatus, so a batch ID persisted from an earlier run is enough to verify the payout.
+func (t *PortalUnshieldTracker) SetBatchID(unshieldID, batchID string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.batchIDs[unshieldID] = batchID
+}
+
+// Track returns the current report of an un-shielding request.
+func (t *PortalUnshieldTracker) Track(unshieldID string) (*PortalUnshieldReport, error) {
+	status, err := t.client.GetPortalUnShieldingRequestStatus(unshieldID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, fmt.Errorf("unshield request %v not found", unshieldID)
+	}
+
+	res := &PortalUnshieldReport{
+		UnshieldID:     unshieldID,
+		TokenID:        status.TokenID,
+		RemoteAddress:  status.RemoteAddress,
+		UnshieldAmount: status.UnshieldAmount,
+		ExternalTxID:   status.ExternalTxID,
+		ExternalFee:    status.ExternalFee,
+		ExpectedAmount: getPortalUnshieldExpectedAmount(status),
+	}
+
+	switch status.Status {
+	case metadata.PortalUnshieldReqWaitingStatus:
+		res.Stage = PortalUnshieldStageWaiting
+		return res, nil
+	case metadata.PortalUnshieldReqRefundedStatus:
+		res.Stage = PortalUnshieldStageRefunded
+		return res, nil
+	case metadata.PortalUnshieldReqProcessedStatus:
+		res.Stage = PortalUnshieldStageBatched
+	case metadata.PortalUnshieldReqCompletedStatus:
+		res.Stage = PortalUnshieldStageCompleted
+	default:
+		return nil, fmt.Errorf("unknown status %v of unshield request %v", status.Status, unshieldID)
+	}
+
+	err = t.updateBatchInfo(res)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.updateExternalTx(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ExternalTx != nil {
+		otherExpectedAmounts, err := t.getOtherExpectedAmounts(res)
+		if err != nil {
+			return nil, err
+		}
+		res.PaidAmount, err = VerifyPortalUnshieldPayout(res.ExternalTx, res.RemoteAddress, res.ExpectedAmount,
+			t.client.btcPortalParams, otherExpectedAmounts...)
+		if err != nil {
+			res.VerificationError = err.Error()
+		} else {
+			res.Verified = true
+		}
+	}
+
+	return res, nil
+}
+
+// TrackMany returns the reports of a list of un-shielding requests. It stops at the first error.
+func (t *PortalUnshieldTracker) TrackMany(unshieldIDs []string) ([]*PortalUnshieldReport, error) {
+	res := make([]*PortalUnshieldReport, 0)
+	for _, unshieldID := range unshieldIDs {
+		report, err := t.Track(unshieldID)
+		if err != nil {
+			return nil, fmt.Errorf("track %v error: %v", unshieldID, err)
+		}
+		res = append(res, report)
+	}
+
+	return res, nil
+}
+
+// updateBatchInfo looks for the batch including the request in the current Portal state, and updates its fee history.
+func (t *PortalUnshieldTracker) updateBatchInfo(report *PortalUnshieldReport) error {
+	bestBlocks, err := t.client.GetBestBlock()
+	if err != nil {
+		return err
+	}
+	beaconHeight, ok := bestBlocks[-1]
+	if !ok {
+		return fmt.Errorf("beacon height not found")
+	}
+	state, err := t.client.GetPortalV4State(beaconHeight)
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		for batchID, batch := range state.ProcessedUnshieldRequests[report.TokenID] {
+			found := false
+			for _, unshieldID := range batch.UnshieldRequests {
+				if unshieldID == report.UnshieldID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+
+			report.BatchID = batchID
+			for height, fee := range batch.ExternalFees {
+				report.Fees = append(report.Fees, PortalUnshieldFee{
+					BeaconHeight: height,
+					NetworkFee:   fee.NetworkFee,
+					RBFReqTxID:   fee.RBFReqIncTxID,
+				})
+			}
+			sort.Slice(report.Fees, func(i, j int) bool {
+				return report.Fees[i].BeaconHeight < report.Fees[j].BeaconHeight
+			})
+			t.SetBatchID(report.UnshieldID, batchID)
+			t.mtx.Lock()
+			t.batchRequests[batchID] = append([]string{}, batch.UnshieldRequests...)
+			t.mtx.Unlock()
+			return nil
+		}
+	}
+
+	// the batch may have been completed and removed from the state
+	t.mtx.Lock()
+	report.BatchID = t.batchIDs[report.UnshieldID]
+	t.mtx.Unlock()
+
+	return t.loadBatchRequests(report.BatchID)
+}
+
+// loadBatchRequests retrieves the un-shielding requests of a batch from the network if they are not known yet.
+// It is used for batches which are no longer in the Portal state.
+func (t *PortalUnshieldTracker) loadBatchRequests(batchID string) error {
+	if batchID == "" {
+		return nil
+	}
+	t.mtx.Lock()
+	_, ok := t.batchRequests[batchID]
+	t.mtx.Unlock()
+	if ok {
+		return nil
+	}
+
+	batchStatus, err := t.client.GetPortalBatchUnShieldingRequestStatus(batchID)
+	if err != nil {
+		return fmt.Errorf("cannot get the status of batch %v: %v", batchID, err)
+	}
+	if batchStatus == nil {
+		return nil
+	}
+
+	t.mtx.Lock()
+	t.batchRequests[batchID] = append([]string{}, batchStatus.UnshieldIDs...)
+	for _, unshieldID := range batchStatus.UnshieldIDs {
+		t.batchIDs[unshieldID] = batchID
+	}
+	t.mtx.Unlock()
+
+	return nil
+}
+
+// getOtherExpectedAmounts returns the expected amounts of the other requests in the batch of the given report paying
+// to the same remote address. The requests of a batch are known if the batch has been seen in the Portal state, or
+// retrieved by loadBatchRequests.
+func (t *PortalUnshieldTracker) getOtherExpectedAmounts(report *PortalUnshieldReport) ([]uint64, error) {
+	t.mtx.Lock()
+	unshieldIDs := t.batchRequests[report.BatchID]
+	t.mtx.Unlock()
+
+	res := make([]uint64, 0)
+	for _, unshieldID := range unshieldIDs {
+		if unshieldID == report.UnshieldID {
+			continue
+		}
+		status, err := t.client.GetPortalUnShieldingRequestStatus(unshieldID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the status of unshield request %v: %v", unshieldID, err)
+		}
+		if status != nil && status.RemoteAddress == report.RemoteAddress {
+			res = append(res, getPortalUnshieldExpectedAmount(status))
+		}
+	}
+
+	return res, nil
+}
+
+// getPortalUnshieldExpectedAmount returns the amount (in satoshi) an un-shielding request should be paid.
+func getPortalUnshieldExpectedAmount(status *metadata.PortalUnshieldRequestStatus) uint64 {
+	if status.ExternalFee >= status.UnshieldAmount {
+		return 0
+	}
+	return ConvertIncPBTCAmountToExternalBTCAmount(status.UnshieldAmount - status.ExternalFee)
+}
+
+// updateExternalTx retrieves the latest external transaction of the request, either from the BTC network
+// (if a BTCDataSource is set) or from the signed transactions of the Portal.
+func (t *PortalUnshieldTracker) updateExternalTx(report *PortalUnshieldReport) error {
+	if t.btcSource != nil && report.ExternalTxID != "" {
+		tx, err := t.btcSource.GetRawTransaction(report.ExternalTxID)
+		if err == nil {
+			report.ExternalTx = tx
+			if report.Stage == PortalUnshieldStageBatched {
+				report.Stage = PortalUnshieldStageBroadcast
+			}
+			report.NumConfirmations = t.getNumConfirmations(report.ExternalTxID)
+			return nil
+		}
+		Logger.Printf("cannot get external tx %v from the BTC network: %v\n", report.ExternalTxID, err)
+	}
+
+	if report.BatchID == "" {
+		return nil
+	}
+
+	var rawTxHex string
+	if len(report.Fees) > 0 && report.Fees[len(report.Fees)-1].RBFReqTxID != "" {
+		signedTx, err := t.client.GetPortalSignedRawReplaceFeeTransaction(report.Fees[len(report.Fees)-1].RBFReqTxID)
+		if err != nil {
+			return err
+		}
+		rawTxHex = signedTx.SignedTx
+	} else {
+		signedTx, err := t.client.GetPortalSignedRawTransaction(report.BatchID)
+		if err != nil {
+			return err
+		}
+		rawTxHex = signedTx.SignedTx
+	}
+
+	tx, err := decodeBTCRawTx(rawTxHex)
+	if err != nil {
+		return fmt.Errorf("cannot decode external tx of batch %v: %v", report.BatchID, err)
+	}
+	report.ExternalTx = tx
+	if report.ExternalTxID == "" {
+		report.ExternalTxID = tx.TxHash().String()
+	}
+
+	return nil
+}
+
+// getNumConfirmations returns the number of confirmations of a BTC transaction; 0 if it is not confirmed or an error occurs.
+func (t *PortalUnshieldTracker) getNumConfirmations(txHash string) uint64 {
+	_, blockHeight, err := t.btcSource.GetTxBlock(txHash)
+	if err != nil {
+		return 0
+	}
+
+	numConfirmations := uint64(1)
+	for {
+		_, err = t.btcSource.GetBlockHashByHeight(blockHeight + numConfirmations)
+		if err != nil || numConfirmations >= DefaultBTCMinConfirmations {
+			break
+		}
+		numConfirmations++
+	}
+
+	return numConfirmations
+}
+
+// decodeBTCRawTx decodes a hex-encoded BTC transaction.
+func decodeBTCRawTx(rawTxHex string) (*wire.MsgTx, error) {
+	rawTx, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	err = tx.Deserialize(bytes.NewReader(rawTx))
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,146 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+)
+
+func TestVerifyPortalUnshieldPayout(t *testing.T) {
+	params := &testNetBTCPortalV4Params
+	remoteAddress := "tb1q0qjpqrgz54xsseymjrql6xs7p9qm0uj53v6rw9"
+	addr, err := btcutil.DecodeAddress(remoteAddress, params.ChainParams)
+	if err != nil {
+		panic(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		panic(err)
+	}
+
+	unshieldAmount := uint64(0.0005 * 1e9)
+	externalFee := uint64(12000)
+	expectedAmount := ConvertIncPBTCAmountToExternalBTCAmount(unshieldAmount - externalFee)
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxOut(wire.NewTxOut(int64(expectedAmount), pkScript))
+	tx.AddTxOut(wire.NewTxOut(1000000, []byte{txscript.OP_0}))
+	paidAmount, err := VerifyPortalUnshieldPayout(tx, remoteAddress, expectedAmount, params)
+	if err != nil {
+		panic(err)
+	}
+	if paidAmount != expectedAmount {
+		panic("invalid paid amount")
+	}
+
+	// pay less than expected
+	tx.TxOut[0].Value--
+	if _, err = VerifyPortalUnshieldPayout(tx, remoteAddress, expectedAmount, params); err == nil {
+		panic("expected an error")
+	}
+
+	// two requests of the batch paid to the same address are matched to distinct outputs
+	tx.TxOut[0].Value++
+	tx.AddTxOut(wire.NewTxOut(int64(expectedAmount)/2, pkScript))
+	paidAmount, err = VerifyPortalUnshieldPayout(tx, remoteAddress, expectedAmount/2, params, expectedAmount)
+	if err != nil {
+		panic(err)
+	}
+	if paidAmount != expectedAmount/2 {
+		panic(fmt.Sprintf("expect paid amount %v, got %v", expectedAmount/2, paidAmount))
+	}
+	if _, err = VerifyPortalUnshieldPayout(tx, remoteAddress, expectedAmount, params, expectedAmount); err == nil {
+		panic("expected an error when an output pays for two requests")
+	}
+
+	// pay to another address
+	if _, err = VerifyPortalUnshieldPayout(tx, "tb1qltudepv86ujkptyl3pkhmwqlyx67wpx2vqshs2wpzccms75k79wqwkvltc", 1, params); err == nil {
+		panic("expected an error")
+	}
+}
+
+func TestPortalUnshieldTracker_Track(t *testing.T) {
+	var err error
+	ic, err = NewTestNetClient()
+	if err != nil {
+		panic(err)
+	}
+
+	tracker, err := NewPortalUnshieldTracker(ic, NewEsploraBTCDataSource("https://blockstream.info/testnet/api"))
+	if err != nil {
+		panic(err)
+	}
+
+	unShieldID := "decc21f35ed8f9edc5167e1f7b3622e46f95216d0218fe2991d5cf1e4e491511"
+	report, err := tracker.Track(unShieldID)
+	if err != nil {
+		panic(err)
+	}
+
+	jsb, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	Logger.Println(string(jsb))
+}
+
+func TestPortalUnshieldTracker_loadBatchRequests(t *testing.T) {
+	batchID := "batch-1"
+	numQueries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string
+			Params []map[string]interface{}
+		}
+		_ = json.Unmarshal(body, &req)
+
+		var result interface{}
+		if req.Method == "getportalbatchunshieldrequeststatus" && req.Params[0]["BatchID"] == batchID {
+			numQueries++
+			result = metadata.PortalUnshieldRequestBatchStatus{
+				BatchID:     batchID,
+				UnshieldIDs: []string{"unshield-1", "unshield-2"},
+				Status:      metadata.PortalBatchUnshieldReqCompletedStatus,
+			}
+		}
+		jsb, _ := json.Marshal(result)
+		resp, _ := json.Marshal(map[string]interface{}{"Result": json.RawMessage(jsb), "Error": nil})
+		_, _ = w.Write(resp)
+	}))
+	defer server.Close()
+
+	tracker, err := NewPortalUnshieldTracker(&IncClient{
+		rpcServer:       rpc.NewRPCServer(server.URL),
+		btcPortalParams: &testNetBTCPortalV4Params,
+	}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// only the batch ID of one request is known (e.g. persisted by an earlier run).
+	tracker.SetBatchID("unshield-1", batchID)
+	for i := 0; i < 2; i++ {
+		if err = tracker.loadBatchRequests(batchID); err != nil {
+			panic(err)
+		}
+	}
+	if numQueries != 1 {
+		panic(fmt.Sprintf("expected 1 query, got %v", numQueries))
+	}
+	if len(tracker.batchRequests[batchID]) != 2 {
+		panic(fmt.Sprintf("expected 2 requests in batch %v, got %v", batchID, tracker.batchRequests[batchID]))
+	}
+	if tracker.batchIDs["unshield-2"] != batchID {
+		panic("batch ID of unshield-2 not recovered")
+	}
+}
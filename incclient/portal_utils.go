@@ -1,14 +1,10 @@
 package incclient
 
 import (
-	"crypto/sha256"
 	"fmt"
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/txscript"
-	"github.com/btcsuite/btcutil"
-	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
 	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
 )
 
 // GetPortalShieldingRequestStatus retrieves the status of a port shielding request.
@@ -37,67 +33,20 @@ func (client *IncClient) GeneratePortalShieldingAddress(paymentAddressStr, token
 			return "", fmt.Errorf("tokenID %v not supported by the v4 Portal", tokenIDStr)
 		}
 
-		pubKeys := make([][]byte, 0)
-		if paymentAddressStr == "" {
-			pubKeys = client.btcPortalParams.MasterPubKeys[:]
-		} else {
-			_, err = AssertPaymentAddressAndTxVersion(paymentAddressStr, 2)
-			if err != nil {
-				return "", fmt.Errorf("invalid payment address: %v", err)
-			}
-
-			chainCode := chainhash.HashB([]byte(paymentAddressStr))
-			for idx, masterPubKey := range client.btcPortalParams.MasterPubKeys {
-				// generate BTC child public key for this Incognito address
-				extendedBTCPublicKey := hdkeychain.NewExtendedKey(client.btcPortalParams.ChainParams.HDPublicKeyID[:], masterPubKey, chainCode, []byte{}, 0, 0, false)
-				extendedBTCChildPubKey, err := extendedBTCPublicKey.Child(0)
-				if err != nil {
-					return "", err
-				}
-
-				childPubKey, err := extendedBTCChildPubKey.ECPubKey()
-				if err != nil {
-					return "", fmt.Errorf("master BTC Public Key (#%v) %v is invalid - Error %v", idx, masterPubKey, err)
-				}
-				pubKeys = append(pubKeys, childPubKey.SerializeCompressed())
-			}
-
-			// create redeem script for m of n multi-sig
-			builder := txscript.NewScriptBuilder()
-			// add the minimum number of needed signatures
-			builder.AddOp(byte(txscript.OP_1 - 1 + client.btcPortalParams.NumRequiredSigs))
-			// add the public key to redeem script
-			for _, pubKey := range pubKeys {
-				builder.AddData(pubKey)
-			}
-			// add the total number of public keys in the multi-sig script
-			builder.AddOp(byte(txscript.OP_1 - 1 + len(pubKeys)))
-			// add the check-multi-sig op-code
-			builder.AddOp(txscript.OP_CHECKMULTISIG)
-
-			redeemScript, err := builder.Script()
-			if err != nil {
-				return "", fmt.Errorf("could not build script - Error %v", err)
-			}
-
-			// generate P2WSH address
-			scriptHash := sha256.Sum256(redeemScript)
-			addr, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], client.btcPortalParams.ChainParams)
-			if err != nil {
-				return "", fmt.Errorf("could not generate address from script - Error %v", err)
-			}
-			res = addr.EncodeAddress()
+		res, _, err = DerivePortalShieldingAddress(client.btcPortalParams, paymentAddressStr)
+		if err != nil {
+			return "", err
 		}
 
 		rpcRes, err := client.generatePortalShieldingAddressFromRPC(paymentAddressStr, tokenIDStr)
 		if err != nil {
 			return "", err
 		}
-		Logger.Println("Generated shielding addresses match!!")
 
 		if rpcRes != res {
 			return "", fmt.Errorf("rpc result (%v) and client result (%v) mismatch, please double check the v4 Portal configuration", rpcRes, res)
 		}
+		Logger.Println("Generated shielding addresses match!!")
 	}
 
 	res, err = client.generatePortalShieldingAddressFromRPC(paymentAddressStr, tokenIDStr)
@@ -124,6 +73,70 @@ func (client *IncClient) GetPortalUnShieldingRequestStatus(unShieldID string) (*
 	return res, nil
 }
 
+// GetPortalV4State retrieves the state of the v4 Portal at the given beacon height.
+func (client *IncClient) GetPortalV4State(beaconHeight uint64) (*jsonresult.PortalV4State, error) {
+	responseInBytes, err := client.rpcServer.GetPortalV4State(beaconHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *jsonresult.PortalV4State
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPortalBatchUnShieldingRequestStatus retrieves the status of a batch of portal un-shielding requests.
+func (client *IncClient) GetPortalBatchUnShieldingRequestStatus(batchID string) (*metadata.PortalUnshieldRequestBatchStatus, error) {
+	responseInBytes, err := client.rpcServer.GetPortalBatchUnShieldingRequestStatus(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *metadata.PortalUnshieldRequestBatchStatus
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPortalSignedRawTransaction retrieves the signed external transaction of a batch of portal un-shielding requests.
+func (client *IncClient) GetPortalSignedRawTransaction(batchID string) (*jsonresult.PortalSignedRawTransaction, error) {
+	responseInBytes, err := client.rpcServer.GetPortalSignedRawTransaction(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *jsonresult.PortalSignedRawTransaction
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetPortalSignedRawReplaceFeeTransaction retrieves the signed external transaction of a portal replace-by-fee request.
+func (client *IncClient) GetPortalSignedRawReplaceFeeTransaction(rbfTxID string) (*jsonresult.PortalSignedRawTransaction, error) {
+	responseInBytes, err := client.rpcServer.GetPortalSignedRawReplaceFeeTransaction(rbfTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *jsonresult.PortalSignedRawTransaction
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // generatePortalShieldingAddressFromRPC returns the multi-sig shielding address for a given payment address and a tokenID
 // via an RPC when using the Portal.
 func (client *IncClient) generatePortalShieldingAddressFromRPC(paymentAddressStr, tokenIDStr string) (string, error) {
@@ -80,7 +80,7 @@ func (client *IncClient) CreateBurningPRVPeggingRequestTransaction(
 		return nil, "", fmt.Errorf("cannot deserialize the sender private key")
 	}
 	burnerAddress := senderWallet.KeySet.PaymentAddress
-	if common.AddressVersion == 0 {
+	if client.networkParams.GetAddressVersion() == 0 {
 		burnerAddress.OTAPublic = nil
 	}
 
@@ -9,14 +9,22 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
 )
 
-// CreateShardStakingTransaction creates a raw staking transaction.
+// CreateShardStakingTransaction creates a raw staking transaction with the DefaultShardStakingAmount.
 func (client *IncClient) CreateShardStakingTransaction(privateKey, privateSeed, candidateAddr, rewardReceiverAddr string, autoStake bool) ([]byte, string, error) {
+	return client.CreateShardStakingTransactionWithAmount(privateKey, privateSeed, candidateAddr, rewardReceiverAddr, autoStake, DefaultShardStakingAmount)
+}
+
+// CreateShardStakingTransactionWithAmount creates a raw staking transaction with the given staking amount.
+// It is useful for networks whose staking amount is different from the DefaultShardStakingAmount.
+func (client *IncClient) CreateShardStakingTransactionWithAmount(
+	privateKey, privateSeed, candidateAddr, rewardReceiverAddr string, autoStake bool, stakingAmount uint64,
+) ([]byte, string, error) {
 	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
 		return nil, "", err
 	}
 
-	funderAddr := senderWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	funderAddr := senderWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, client.networkParams)
 
 	if len(candidateAddr) == 0 {
 		candidateAddr = funderAddr
@@ -34,25 +42,13 @@ func (client *IncClient) CreateShardStakingTransaction(privateKey, privateSeed,
 		return nil, "", fmt.Errorf("candidate payment address invalid: %v", candidateAddr)
 	}
 
-	seed, _, err := base58.Base58Check{}.Decode(privateSeed)
-	if err != nil {
-		return nil, "", fmt.Errorf("cannot decode private seed: %v", privateSeed)
-	}
-
-	committeePK, err := key.NewCommitteeKeyFromSeed(seed, pk)
+	committeeKeyStr, err := getCommitteeKeyStr(privateSeed, pk)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot create committee key from pk: %v, seed: %v. Error: %v", pk, seed, err)
-	}
-
-	committeePKBytes, err := committeePK.Bytes()
-	if err != nil {
-		return nil, "", fmt.Errorf("committee to bytes error: %v", err)
+		return nil, "", err
 	}
 
-	stakingAmount := uint64(1750000000000)
-
 	stakingMetadata, err := metadata.NewStakingMetadata(metadata.ShardStakingMeta, funderAddr, rewardReceiverAddr, stakingAmount,
-		base58.Base58Check{}.Encode(committeePKBytes, common.ZeroByte), autoStake)
+		committeeKeyStr, autoStake)
 
 	txParam := NewTxParam(privateKey, []string{common.BurningAddress2}, []uint64{stakingAmount}, 0, nil, stakingMetadata, nil)
 
@@ -81,7 +77,7 @@ func (client *IncClient) CreateUnStakingTransaction(privateKey, privateSeed, can
 		return nil, "", err
 	}
 
-	funderAddr := senderWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	funderAddr := senderWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, client.networkParams)
 
 	if len(candidateAddr) == 0 {
 		candidateAddr = funderAddr
@@ -96,21 +92,11 @@ func (client *IncClient) CreateUnStakingTransaction(privateKey, privateSeed, can
 		return nil, "", fmt.Errorf("candidate payment address invalid: %v", candidateAddr)
 	}
 
-	seed, _, err := base58.Base58Check{}.Decode(privateSeed)
+	committeeKeyStr, err := getCommitteeKeyStr(privateSeed, pk)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot decode private seed %v: %v", privateSeed, err)
-	}
-
-	committeePK, err := key.NewCommitteeKeyFromSeed(seed, pk)
-	if err != nil {
-		return nil, "", fmt.Errorf("cannot create committee key from pk: %v, seed: %v. Error: %v", pk, seed, err)
-	}
-
-	committeePKBytes, err := committeePK.Bytes()
-	if err != nil {
-		return nil, "", fmt.Errorf("committee to bytes error: %v", err)
+		return nil, "", err
 	}
-	unStakingMetadata, err := metadata.NewUnStakingMetadata(base58.Base58Check{}.Encode(committeePKBytes, common.ZeroByte))
+	unStakingMetadata, err := metadata.NewUnStakingMetadata(committeeKeyStr)
 
 	txParam := NewTxParam(privateKey, []string{common.BurningAddress2}, []uint64{0}, 0, nil, unStakingMetadata, nil)
 
@@ -144,7 +130,7 @@ func (client *IncClient) CreateWithDrawRewardTransaction(privateKey, addr, token
 		return nil, "", err
 	}
 
-	funderAddr := senderWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	funderAddr := senderWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, client.networkParams)
 	if len(addr) == 0 {
 		addr = funderAddr
 	}
@@ -182,3 +168,24 @@ func (client *IncClient) CreateAndSendWithDrawRewardTransaction(privateKey, addr
 
 	return txHash, nil
 }
+
+// getCommitteeKeyStr returns the base58-encoded committee public key of a validator given its private seed (a.k.a the
+// mining key) and the public key of its candidate address.
+func getCommitteeKeyStr(privateSeed string, pk []byte) (string, error) {
+	seed, _, err := base58.Base58Check{}.Decode(privateSeed)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode private seed %v: %v", privateSeed, err)
+	}
+
+	committeePK, err := key.NewCommitteeKeyFromSeed(seed, pk)
+	if err != nil {
+		return "", fmt.Errorf("cannot create committee key from pk: %v, seed: %v. Error: %v", pk, seed, err)
+	}
+
+	committeePKBytes, err := committeePK.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("committee to bytes error: %v", err)
+	}
+
+	return base58.Base58Check{}.Encode(committeePKBytes, common.ZeroByte), nil
+}
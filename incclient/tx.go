@@ -27,6 +27,9 @@ func (client *IncClient) CreateRawTransaction(param *TxParam, version int8) ([]b
 	if param.txTokenParam != nil {
 		return nil, "", fmt.Errorf("method supports PRV transaction only")
 	}
+	if version == -1 && client.dryRun != nil {
+		version = 2
+	}
 	if version == -1 { //Try either one of the version, if possible
 		encodedTx, txHash, err := client.CreateRawTransactionVer1(param)
 		if err != nil {
@@ -52,6 +55,12 @@ func (client *IncClient) CreateRawTransaction(param *TxParam, version int8) ([]b
 //
 // It returns the base58-encoded transaction, the transaction's hash, and an error (if any).
 func (client *IncClient) CreateRawTransactionVer1(param *TxParam) ([]byte, string, error) {
+	if len(param.memoList) != 0 {
+		return nil, "", fmt.Errorf("memos are only supported by transactions v2")
+	}
+	if client.dryRun != nil {
+		return client.dryRunTransaction(param, 1)
+	}
 	privateKey := param.senderPrivateKey
 	//Create sender private key from string
 	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
@@ -60,7 +69,7 @@ func (client *IncClient) CreateRawTransactionVer1(param *TxParam) ([]byte, strin
 	}
 
 	//Create list of payment infos
-	paymentInfos, err := createPaymentInfos(param.receiverList, param.amountList)
+	paymentInfos, err := createPaymentInfos(param.receiverList, param.amountList, param.memoList)
 	if err != nil {
 		return nil, "", err
 	}
@@ -85,6 +94,7 @@ func (client *IncClient) CreateRawTransactionVer1(param *TxParam) ([]byte, strin
 	}
 
 	txInitParam := tx_generic.NewTxPrivacyInitParams(&(senderWallet.KeySet.PrivateKey), paymentInfos, coinsToSpend, param.fee, hasPrivacy, &common.PRVCoinID, param.md, nil, kvArgs)
+	txInitParam.NetworkParams = client.networkParams
 
 	tx := new(tx_ver1.Tx)
 	err = tx.Init(txInitParam)
@@ -106,17 +116,37 @@ func (client *IncClient) CreateRawTransactionVer1(param *TxParam) ([]byte, strin
 //
 // It returns the base58-encoded transaction, the transaction's hash, and an error (if any).
 func (client *IncClient) CreateRawTransactionVer2(param *TxParam) ([]byte, string, error) {
+	if client.dryRun != nil {
+		return client.dryRunTransaction(param, 2)
+	}
+	tx, err := client.createTransactionVer2(param)
+	if err != nil {
+		return nil, "", err
+	}
+
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot marshal txver2: %v", err)
+	}
+
+	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+
+	return []byte(base58CheckData), tx.Hash().String(), nil
+}
+
+// createTransactionVer2 creates and signs a PRV transaction version 2.
+func (client *IncClient) createTransactionVer2(param *TxParam) (*tx_ver2.Tx, error) {
 	privateKey := param.senderPrivateKey
 	//Create sender private key from string
 	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot init private key %v: %v", privateKey, err)
+		return nil, fmt.Errorf("cannot init private key %v: %v", privateKey, err)
 	}
 
 	//Create list of payment infos
-	paymentInfos, err := createPaymentInfos(param.receiverList, param.amountList)
+	paymentInfos, err := createPaymentInfos(param.receiverList, param.amountList, param.memoList)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	txFee := param.fee
@@ -143,7 +173,7 @@ func (client *IncClient) CreateRawTransactionVer2(param *TxParam) ([]byte, strin
 
 	coinsToSpend, kArgs, err := client.initParamsV2(param, common.PRVIDStr, totalAmount)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	txParam := tx_generic.NewTxPrivacyInitParams(&(senderWallet.KeySet.PrivateKey), paymentInfos, coinsToSpend, txFee, hasPrivacy, &common.PRVCoinID, param.md, nil, kArgs)
@@ -161,28 +191,22 @@ func (client *IncClient) CreateRawTransactionVer2(param *TxParam) ([]byte, strin
 
 		coinsToSpend, kArgs, err := client.initParamsV2(param, common.PRVIDStr, totalAmount)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 
 		txParam = tx_generic.NewTxPrivacyInitParams(&(senderWallet.KeySet.PrivateKey), paymentInfos, coinsToSpend, estTxFee, hasPrivacy, &common.PRVCoinID, param.md, nil, kArgs)
 	}
+	txParam.NetworkParams = client.networkParams
 
 	tx := new(tx_ver2.Tx)
 	err = tx.Init(txParam)
 	if err != nil {
-		return nil, "", fmt.Errorf("init txver2 error: %v", err)
+		return nil, fmt.Errorf("init txver2 error: %v", err)
 	}
 
 	fmt.Println("Tx Fee: ", tx.Fee)
 
-	txBytes, err := json.Marshal(tx)
-	if err != nil {
-		return nil, "", fmt.Errorf("cannot marshal txver2: %v", err)
-	}
-
-	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
-
-	return []byte(base58CheckData), tx.Hash().String(), nil
+	return tx, nil
 }
 
 // CreateAndSendRawTransaction creates a PRV transaction with the provided version, and submits it to the Incognito network.
@@ -204,6 +228,25 @@ func (client *IncClient) CreateAndSendRawTransaction(privateKey string, addrList
 	return txHash, nil
 }
 
+// CreateAndSendRawTransactionWithMemos is the same as CreateAndSendRawTransaction, except that it attaches a memo to
+// each receiver. Memos are encrypted to their receivers, and only supported by transactions v2.
+//
+// It returns the transaction's hash, and an error (if any).
+func (client *IncClient) CreateAndSendRawTransactionWithMemos(privateKey string, addrList []string, amountList []uint64, memoList []string, version int8, md metadata.Metadata) (string, error) {
+	txParam := NewTxParam(privateKey, addrList, amountList, 0, nil, md, nil).SetMemos(memoList)
+	encodedTx, txHash, err := client.CreateRawTransaction(txParam, version)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.SendRawTx(encodedTx)
+	if err != nil {
+		return "", err
+	}
+
+	return txHash, nil
+}
+
 // CreateRawTransactionWithInputCoins creates a raw PRV transaction from the provided input coins.
 // Parameters:
 //   - param: a regular TxParam.
@@ -246,6 +289,9 @@ func (client *IncClient) CreateRawTransactionWithInputCoins(param *TxParam, inpu
 
 // SendRawTx sends submits a raw PRV transaction to the Incognito blockchain.
 func (client *IncClient) SendRawTx(encodedTx []byte) error {
+	if client.dryRun != nil {
+		return nil
+	}
 	responseInBytes, err := client.rpcServer.SendRawTx(string(encodedTx))
 	if err != nil {
 		return nil
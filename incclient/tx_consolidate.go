@@ -111,6 +111,10 @@ func (client *IncClient) ConsolidatePRVs(privateKey string, version int8, numThr
 				break
 			}
 		}
+		// the UTXOs of a dry-run client are never updated
+		if client.dryRun != nil {
+			break
+		}
 
 		utxoList, idxList, err = client.getUTXOsListByVersion(privateKey, common.PRVIDStr, uint8(version))
 		if err != nil {
@@ -205,6 +209,10 @@ func (client *IncClient) ConsolidateTokenV1s(privateKey, tokenIDStr string, numT
 				break
 			}
 		}
+		// the UTXOs of a dry-run client are never updated
+		if client.dryRun != nil {
+			break
+		}
 
 		utxoList, idxList, err = client.getUTXOsListByVersion(privateKey, tokenIDStr, 1)
 		if err != nil {
@@ -331,6 +339,10 @@ func (client *IncClient) ConsolidateTokenV2s(privateKey, tokenIDStr string, numT
 				break
 			}
 		}
+		// the UTXOs of a dry-run client are never updated
+		if client.dryRun != nil {
+			break
+		}
 
 		utxoList, idxList, err = client.getUTXOsListByVersion(privateKey, tokenIDStr, 2)
 		if err != nil {
@@ -358,7 +370,7 @@ func (client *IncClient) consolidatePRVs(id int, privateKey string,
 		return
 	}
 
-	addr := PrivateKeyToPaymentAddress(privateKey, -1)
+	addr := client.PrivateKeyToPaymentAddress(privateKey, -1)
 	txParam := NewTxParam(privateKey, []string{addr}, []uint64{totalAmount - DefaultPRVFee}, DefaultPRVFee, nil, nil, nil)
 
 	encodedTx, txHash, err := client.CreateRawTransactionWithInputCoins(txParam, inputCoins, indices)
@@ -397,7 +409,7 @@ func (client *IncClient) consolidateTokenV1s(id int, privateKey, tokenIDStr stri
 	}
 
 	// estimate token fee
-	shardID := GetShardIDFromPrivateKey(privateKey)
+	shardID := client.GetShardIDFromPrivateKey(privateKey)
 	tokenFee, err := client.GetTokenFee(shardID, tokenIDStr)
 	if err != nil {
 		errCh <- fmt.Errorf("[ID %v] cannot estimate token fee: %v", id, err)
@@ -409,7 +421,7 @@ func (client *IncClient) consolidateTokenV1s(id int, privateKey, tokenIDStr stri
 		return
 	}
 
-	addr := PrivateKeyToPaymentAddress(privateKey, -1)
+	addr := client.PrivateKeyToPaymentAddress(privateKey, -1)
 	txTokenParam := NewTxTokenParam(tokenIDStr, 1, []string{addr}, []uint64{totalAmount - tokenFee}, true, tokenFee, nil)
 	txParam := NewTxParam(privateKey, []string{}, []uint64{}, 0, txTokenParam, nil, nil)
 
@@ -460,7 +472,7 @@ func (client *IncClient) consolidateTokenV2s(id int, privateKey, tokenIDStr stri
 		return
 	}
 
-	addr := PrivateKeyToPaymentAddress(privateKey, -1)
+	addr := client.PrivateKeyToPaymentAddress(privateKey, -1)
 	txTokenParam := NewTxTokenParam(tokenIDStr, 1, []string{addr}, []uint64{totalAmount}, false, 0, nil)
 	txParam := NewTxParam(privateKey, []string{}, []uint64{}, DefaultPRVFee, txTokenParam, nil, nil)
 
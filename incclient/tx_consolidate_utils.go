@@ -32,7 +32,7 @@ func (client *IncClient) getUTXOsListByVersion(privateKey string,
 
 func (client *IncClient) splitPRVForFees(privateKey string, version uint8, numThreads int) (string, error) {
 	Logger.Printf("Splitting PRV for numThreads %v\n", numThreads)
-	addr := PrivateKeyToPaymentAddress(privateKey, -1)
+	addr := client.PrivateKeyToPaymentAddress(privateKey, -1)
 	if len(addr) == 0 {
 		return "", fmt.Errorf("private key is invalid")
 	}
@@ -74,6 +74,9 @@ func (client *IncClient) splitPRVForFees(privateKey string, version uint8, numTh
 	if err != nil {
 		return txHash, err
 	}
+	if client.dryRun != nil {
+		return txHash, nil
+	}
 
 	// check if we have enough PRV UTXOs
 	Logger.Printf("Checking UTXOs updated...\n")
@@ -102,8 +105,11 @@ func (client *IncClient) splitPRVForFees(privateKey string, version uint8, numTh
 
 // waitingCheckTxInBlock waits and checks until a transaction has been included in a block.
 //
-// In case the transaction is invalid, it stops.
+// In case the transaction is invalid, it stops. In dry-run mode, it returns immediately.
 func (client *IncClient) waitingCheckTxInBlock(txHash string) error {
+	if client.dryRun != nil {
+		return nil
+	}
 	timeOut := time.After(5 * time.Minute)
 	for {
 		isInBlock, err := client.CheckTxInBlock(txHash)
@@ -18,6 +18,10 @@ import (
 //
 // It returns the base58-encoded transaction, the transaction's hash, and an error (if any).
 func (client *IncClient) CreateRawConversionTransaction(privateKey string) ([]byte, string, error) {
+	if client.dryRun != nil {
+		return client.dryRunConversion(privateKey, common.PRVIDStr, nil, nil, nil)
+	}
+
 	//Create sender private key from string
 	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
@@ -56,6 +60,7 @@ func (client *IncClient) CreateRawConversionTransaction(privateKey string) ([]by
 	//Create tx conversion params
 	txParam := tx_ver2.NewTxConvertVer1ToVer2InitParams(&(senderWallet.KeySet.PrivateKey), []*key.PaymentInfo{&uniquePayment}, coinV1List,
 		DefaultPRVFee, nil, nil, nil, nil)
+	txParam.SetNetworkParams(client.networkParams)
 
 	tx := new(tx_ver2.Tx)
 	err = tx_ver2.InitConversion(tx, txParam)
@@ -81,6 +86,9 @@ func (client *IncClient) CreateRawTokenConversionTransaction(privateKey, tokenID
 	if tokenIDStr == common.PRVIDStr {
 		return nil, "", fmt.Errorf("try conversion transaction")
 	}
+	if client.dryRun != nil {
+		return client.dryRunConversion(privateKey, tokenIDStr, nil, nil, nil)
+	}
 
 	tokenID, err := new(common.Hash).NewHashFromStr(tokenIDStr)
 	if err != nil {
@@ -124,6 +132,7 @@ func (client *IncClient) CreateRawTokenConversionTransaction(privateKey, tokenID
 	txTokenParam := tx_ver2.NewTxTokenConvertVer1ToVer2InitParams(&(senderWallet.KeySet.PrivateKey), coinsToSpendPRV, []*key.PaymentInfo{}, coinV1ListToken,
 		[]*key.PaymentInfo{&uniquePayment}, prvFee, tokenID,
 		nil, nil, kvArgsPRV)
+	txTokenParam.SetNetworkParams(client.networkParams)
 
 	tx := new(tx_ver2.TxToken)
 	err = tx_ver2.InitTokenConversion(tx, txTokenParam)
@@ -208,6 +217,9 @@ func (client *IncClient) CreateConversionTransactionWithInputCoins(privateKey st
 	if len(coinV1List) == 0 {
 		return nil, txHash, fmt.Errorf("no CoinV1 to be converted")
 	}
+	if client.dryRun != nil {
+		return client.dryRunConversion(privateKey, common.PRVIDStr, coinV1List, nil, nil)
+	}
 
 	//Calculating the total amount being converted.
 	totalAmount := uint64(0)
@@ -225,6 +237,7 @@ func (client *IncClient) CreateConversionTransactionWithInputCoins(privateKey st
 	//Create tx conversion params
 	txParam := tx_ver2.NewTxConvertVer1ToVer2InitParams(&(senderWallet.KeySet.PrivateKey), []*key.PaymentInfo{&uniquePayment}, coinV1List,
 		DefaultPRVFee, nil, nil, nil, nil)
+	txParam.SetNetworkParams(client.networkParams)
 
 	tx := new(tx_ver2.Tx)
 	err = tx_ver2.InitConversion(tx, txParam)
@@ -267,7 +280,7 @@ func (client *IncClient) CreateTokenConversionTransactionWithInputCoins(privateK
 	if err != nil {
 		return nil, txHash, fmt.Errorf("cannot init private key %v: %v", privateKey, err)
 	}
-	shardID := GetShardIDFromPrivateKey(privateKey)
+	shardID := client.GetShardIDFromPrivateKey(privateKey)
 
 	// check number of token input coins
 	if len(tokenInCoins) > MaxInputSize {
@@ -285,6 +298,9 @@ func (client *IncClient) CreateTokenConversionTransactionWithInputCoins(privateK
 	if version != 1 {
 		return nil, txHash, fmt.Errorf("token input coins must be of version 1")
 	}
+	if client.dryRun != nil {
+		return client.dryRunConversion(privateKey, tokenIDStr, tokenInCoins, prvInCoins, prvIndices)
+	}
 
 	// check number of token input coins
 	if len(prvInCoins) > MaxInputSize {
@@ -345,6 +361,7 @@ func (client *IncClient) CreateTokenConversionTransactionWithInputCoins(privateK
 		prvInCoins, []*key.PaymentInfo{}, tokenInCoins,
 		[]*key.PaymentInfo{&uniquePayment}, prvFee, tokenID,
 		nil, nil, kvArgs)
+	txTokenParam.SetNetworkParams(client.networkParams)
 
 	tx := new(tx_ver2.TxToken)
 	err = tx_ver2.InitTokenConversion(tx, txTokenParam)
@@ -124,6 +124,10 @@ func (client *IncClient) convertAllPRVs(privateKey string, numThreads int) ([]st
 				break
 			}
 		}
+		// the UTXOs of a dry-run client are never updated
+		if client.dryRun != nil {
+			break
+		}
 
 		utxoV1List, _, err = client.getUTXOsListByVersion(privateKey, common.PRVIDStr, uint8(1))
 		if err != nil {
@@ -264,6 +268,10 @@ func (client *IncClient) convertAllTokens(privateKey, tokenIDStr string, numThre
 				break
 			}
 		}
+		// the UTXOs of a dry-run client are never updated
+		if client.dryRun != nil {
+			break
+		}
 
 		utxoV1List, _, err = client.getUTXOsListByVersion(privateKey, tokenIDStr, 1)
 		if err != nil {
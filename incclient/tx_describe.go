@@ -0,0 +1,421 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	privacyUtils "github.com/incognitochain/go-incognito-sdk-v2/privacy/utils"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy/v2/mlsag"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+var txTypeNames = map[string]string{
+	common.TxNormalType:             "Normal",
+	common.TxRewardType:             "Reward",
+	common.TxReturnStakingType:      "Return Staking",
+	common.TxConversionType:         "Conversion",
+	common.TxTokenConversionType:    "Token Conversion",
+	common.TxCustomTokenPrivacyType: "Token",
+}
+
+// TxCoinDescription describes an input or an output coin of a transaction.
+type TxCoinDescription struct {
+	Index int
+
+	// PublicKey is the base58-encoded (one-time) public key of an output coin.
+	PublicKey string `json:",omitempty"`
+
+	// KeyImage is the base58-encoded key image (or serial number) of an input coin.
+	KeyImage string `json:",omitempty"`
+
+	// Commitment is the base58-encoded commitment of an output coin.
+	Commitment string `json:",omitempty"`
+
+	// IsOwned indicates whether the coin belongs to the key set given to DescribeTx.
+	IsOwned bool
+
+	// Amount is the value of the coin. It is only available for owned or non-encrypted coins.
+	Amount uint64
+
+	// IsAmountUnknown indicates that the coin is owned but its amount could not be decrypted (e.g, a coin of version 1
+	// described with only the OTA key).
+	IsAmountUnknown bool `json:",omitempty"`
+
+	// Receiver is the payment address of the owner of the coin (if known).
+	Receiver string `json:",omitempty"`
+}
+
+// TxTransferDescription describes the transfer of a token (or PRV) within a transaction.
+type TxTransferDescription struct {
+	// TokenID is the ID of the transferred token. For token transactions of version 2, it is the
+	// common.ConfidentialAssetID unless it could be recovered from an owned output coin.
+	TokenID    string
+	IsPrivacy  bool
+	Fee        uint64
+	RingSize   int
+	NumInputs  int
+	NumOutputs int
+	Inputs     []TxCoinDescription
+	Outputs    []TxCoinDescription
+}
+
+// TxDescription is a human-readable summary of a transaction.
+type TxDescription struct {
+	TxHash   string
+	Version  int8
+	Type     string
+	TypeName string
+	LockTime int64
+	ShardID  byte
+	Size     uint64
+
+	// The following fields are only filled by IncClient.DescribeTxByHash.
+	BlockHash   string `json:",omitempty"`
+	BlockHeight uint64 `json:",omitempty"`
+	IsInBlock   bool
+	IsInMempool bool
+
+	// PRV describes the PRV transfer of the transaction.
+	PRV *TxTransferDescription
+
+	// Token describes the token transfer of a token transaction (if any).
+	Token *TxTransferDescription `json:",omitempty"`
+
+	MetadataType int
+	MetadataNote string            `json:",omitempty"`
+	Metadata     metadata.Metadata `json:",omitempty"`
+}
+
+// DescribeTx decodes a transaction into a TxDescription. If a key set is given, the output coins belonging to that key
+// set are marked as owned, and their amounts are decrypted. The given key set must contain at least the OTA key;
+// amounts that cannot be decrypted with it are reported as unknown.
+func DescribeTx(tx metadata.Transaction, keySet *key.KeySet) (*TxDescription, error) {
+	return describeTx(tx, keySet, nil, nil)
+}
+
+// DescribeTxByHash retrieves a transaction and decodes it into a TxDescription. If a private key is given, the input
+// and output coins belonging to it are resolved with their amounts, and the token of a token transaction of version 2
+// is recovered (if possible).
+func (client *IncClient) DescribeTxByHash(txHash string, privateKey string) (*TxDescription, error) {
+	txDetail, err := client.GetTxDetail(txHash)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := jsonresult.ParseTxDetail(*txDetail)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet *key.KeySet
+	var rawAssetTags map[string]*common.Hash
+	if privateKey != "" {
+		w, err := wallet.Base58CheckDeserialize(privateKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(w.KeySet.PrivateKey) == 0 {
+			return nil, fmt.Errorf("invalid private key")
+		}
+		err = w.KeySet.InitFromPrivateKey(&w.KeySet.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		keySet = &w.KeySet
+
+		if tx.GetVersion() == 2 && tx.GetType() == common.TxCustomTokenPrivacyType {
+			rawAssetTags, err = client.GetAllAssetTags()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res, err := describeTx(tx, keySet, rawAssetTags, client.networkParams)
+	if err != nil {
+		return nil, err
+	}
+	res.ShardID = txDetail.ShardID
+	res.Size = txDetail.TxSize
+	res.BlockHash = txDetail.BlockHash
+	res.BlockHeight = txDetail.BlockHeight
+	res.IsInBlock = txDetail.IsInBlock
+	res.IsInMempool = txDetail.IsInMempool
+
+	if keySet != nil {
+		for _, transfer := range []*TxTransferDescription{res.PRV, res.Token} {
+			if transfer == nil || len(transfer.Inputs) == 0 || transfer.TokenID == common.ConfidentialAssetID.String() {
+				continue
+			}
+			err = client.resolveOwnedInputs(privateKey, keySet, transfer)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// resolveOwnedInputs marks the input coins of a transfer that have been spent by the given private key.
+func (client *IncClient) resolveOwnedInputs(privateKey string, keySet *key.KeySet, transfer *TxTransferDescription) error {
+	spentCoins, _, err := client.GetSpentOutputCoins(privateKey, transfer.TokenID, 0)
+	if err != nil {
+		return err
+	}
+
+	spentValues := make(map[string]uint64)
+	for _, spentCoin := range spentCoins {
+		if spentCoin.GetKeyImage() == nil {
+			continue
+		}
+		keyImageStr := base58.Base58Check{}.Encode(spentCoin.GetKeyImage().ToBytesS(), common.ZeroByte)
+		spentValues[keyImageStr] = spentCoin.GetValue()
+	}
+
+	receiver := getKeySetPaymentAddress(keySet, client.networkParams)
+	for i, input := range transfer.Inputs {
+		if value, ok := spentValues[input.KeyImage]; ok {
+			transfer.Inputs[i].IsOwned = true
+			transfer.Inputs[i].Amount = value
+			transfer.Inputs[i].Receiver = receiver
+		}
+	}
+
+	return nil
+}
+
+func describeTx(
+	tx metadata.Transaction, keySet *key.KeySet,
+	rawAssetTags map[string]*common.Hash, params *common.NetworkParams,
+) (*TxDescription, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("tx is nil")
+	}
+
+	res := &TxDescription{
+		TxHash:       tx.Hash().String(),
+		Version:      tx.GetVersion(),
+		Type:         tx.GetType(),
+		TypeName:     txTypeNames[tx.GetType()],
+		LockTime:     tx.GetLockTime(),
+		ShardID:      params.GetShardIDFromLastByte(tx.GetSenderAddrLastByte()),
+		Size:         tx.GetTxActualSize(),
+		MetadataType: tx.GetMetadataType(),
+		MetadataNote: txMetadataNote[tx.GetMetadataType()],
+		Metadata:     tx.GetMetadata(),
+	}
+	if res.TypeName == "" {
+		res.TypeName = fmt.Sprintf("Unknown(%v)", tx.GetType())
+	}
+
+	var err error
+	switch tx.GetType() {
+	case common.TxCustomTokenPrivacyType, common.TxTokenConversionType:
+		tmpTx, ok := tx.(tx_generic.TransactionToken)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse the transaction as a transaction token")
+		}
+		if tmpTx.GetTxBase() != nil {
+			res.PRV, err = describeTransfer(tmpTx.GetTxBase(), common.PRVIDStr, keySet, nil, params)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tokenID := common.PRVIDStr
+		if tx.GetTokenID() != nil {
+			tokenID = tx.GetTokenID().String()
+		}
+		res.Token, err = describeTransfer(tmpTx.GetTxNormal(), tokenID, keySet, rawAssetTags, params)
+		if err != nil {
+			return nil, err
+		}
+		res.Token.Fee = tx.GetTxFeeToken()
+	default:
+		res.PRV, err = describeTransfer(tx, common.PRVIDStr, keySet, nil, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// describeTransfer describes the proof of a (sub-)transaction.
+func describeTransfer(
+	tx metadata.Transaction, tokenID string, keySet *key.KeySet,
+	rawAssetTags map[string]*common.Hash, params *common.NetworkParams,
+) (*TxTransferDescription, error) {
+	res := &TxTransferDescription{
+		TokenID:   tokenID,
+		IsPrivacy: tx.IsPrivacy(),
+		Fee:       tx.GetTxFee(),
+		Inputs:    make([]TxCoinDescription, 0),
+		Outputs:   make([]TxCoinDescription, 0),
+	}
+
+	proof := tx.GetProof()
+	if proof == nil {
+		return res, nil
+	}
+
+	for i, inCoin := range proof.GetInputCoins() {
+		input := TxCoinDescription{Index: i}
+		if inCoin.GetKeyImage() != nil {
+			input.KeyImage = base58.Base58Check{}.Encode(inCoin.GetKeyImage().ToBytesS(), common.ZeroByte)
+		}
+		res.Inputs = append(res.Inputs, input)
+	}
+	res.NumInputs = len(res.Inputs)
+	res.RingSize = getTxRingSize(tx, proof)
+
+	receiver := ""
+	if keySet != nil {
+		receiver = getKeySetPaymentAddress(keySet, params)
+	}
+	for i, outCoin := range proof.GetOutputCoins() {
+		output := TxCoinDescription{Index: i}
+		if outCoin.GetPublicKey() != nil {
+			output.PublicKey = base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte)
+		}
+		if outCoin.GetCommitment() != nil {
+			output.Commitment = base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), common.ZeroByte)
+		}
+		if !outCoin.IsEncrypted() {
+			output.Amount = outCoin.GetValue()
+		}
+
+		if keySet != nil {
+			isOwned, _ := outCoin.DoesCoinBelongToKeySet(keySet)
+			if isOwned {
+				output.IsOwned = true
+				output.Receiver = receiver
+				// decrypt a copy of the coin, so that the transaction (and its hash) is left untouched
+				tmpCoin, err := coin.NewCoinFromByte(outCoin.Bytes())
+				if err != nil {
+					return nil, fmt.Errorf("cannot copy output coin %v: %v", i, err)
+				}
+				plainCoin, err := tmpCoin.Decrypt(keySet)
+				if err != nil {
+					// e.g, a coin of version 1 cannot be decrypted without the read-only key
+					output.IsAmountUnknown = true
+				} else {
+					output.Amount = plainCoin.GetValue()
+				}
+
+				if res.TokenID == common.ConfidentialAssetID.String() && rawAssetTags != nil {
+					if coinV2, ok := outCoin.(*coin.CoinV2); ok {
+						if assetID, err := coinV2.GetTokenId(keySet, rawAssetTags); err == nil {
+							res.TokenID = assetID.String()
+						}
+					}
+				}
+			}
+		}
+		res.Outputs = append(res.Outputs, output)
+	}
+	res.NumOutputs = len(res.Outputs)
+
+	return res, nil
+}
+
+// getTxRingSize returns the number of ring members of each input of a (sub-)transaction.
+func getTxRingSize(tx metadata.Transaction, proof privacy.Proof) int {
+	if len(proof.GetInputCoins()) == 0 {
+		return 0
+	}
+
+	if tx.GetVersion() == 2 {
+		switch tx.GetType() {
+		case common.TxConversionType, common.TxTokenConversionType:
+			// conversion transactions spend non-hidden coins of version 1, and are signed with Schnorr
+			return 1
+		}
+		sig, err := new(mlsag.Sig).FromBytes(tx.GetSig())
+		if err != nil {
+			// unknown signature
+			return 0
+		}
+		return len(sig.GetR())
+	}
+
+	if proofV1, ok := proof.(*privacy.ProofV1); ok && len(proofV1.GetOneOfManyProof()) > 0 {
+		return privacyUtils.CommitmentRingSize
+	}
+
+	return 1
+}
+
+// getKeySetPaymentAddress returns the base58-encoded payment address of a key set.
+func getKeySetPaymentAddress(keySet *key.KeySet, params *common.NetworkParams) string {
+	w := wallet.KeyWallet{KeySet: key.KeySet{PaymentAddress: keySet.PaymentAddress}}
+	return w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params)
+}
+
+// String returns the human-readable form of a TxDescription.
+func (d TxDescription) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("TxHash: %v\n", d.TxHash))
+	sb.WriteString(fmt.Sprintf("Version: %v, Type: %v (%v), LockTime: %v, ShardID: %v, Size: %v\n",
+		d.Version, d.TypeName, d.Type, d.LockTime, d.ShardID, d.Size))
+	if d.IsInBlock {
+		sb.WriteString(fmt.Sprintf("Block: %v (height %v)\n", d.BlockHash, d.BlockHeight))
+	} else if d.IsInMempool {
+		sb.WriteString("Block: in mempool\n")
+	}
+	if d.PRV != nil {
+		sb.WriteString(d.PRV.describe("PRV"))
+	}
+	if d.Token != nil {
+		sb.WriteString(d.Token.describe("Token"))
+	}
+	if d.Metadata != nil {
+		note := d.MetadataNote
+		if note == "" {
+			note = "Unknown"
+		}
+		sb.WriteString(fmt.Sprintf("Metadata: %v (type %v)\n", note, d.MetadataType))
+		mdBytes, err := json.MarshalIndent(d.Metadata, "  ", "  ")
+		if err == nil {
+			sb.WriteString(fmt.Sprintf("  %v\n", string(mdBytes)))
+		}
+	}
+
+	return sb.String()
+}
+
+func (t TxTransferDescription) describe(name string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%v transfer: TokenID: %v, Privacy: %v, Fee: %v, Inputs: %v, Outputs: %v, RingSize: %v\n",
+		name, t.TokenID, t.IsPrivacy, t.Fee, t.NumInputs, t.NumOutputs, t.RingSize))
+	for _, input := range t.Inputs {
+		if input.IsOwned {
+			sb.WriteString(fmt.Sprintf("  input #%v (owned): %v, amount %v\n", input.Index, input.KeyImage, input.Amount))
+		} else {
+			sb.WriteString(fmt.Sprintf("  input #%v: %v\n", input.Index, input.KeyImage))
+		}
+	}
+	for _, output := range t.Outputs {
+		if output.IsOwned && output.IsAmountUnknown {
+			sb.WriteString(fmt.Sprintf("  output #%v (owned): %v, amount unknown, receiver %v\n",
+				output.Index, output.PublicKey, output.Receiver))
+		} else if output.IsOwned {
+			sb.WriteString(fmt.Sprintf("  output #%v (owned): %v, amount %v, receiver %v\n",
+				output.Index, output.PublicKey, output.Amount, output.Receiver))
+		} else if output.Amount > 0 {
+			sb.WriteString(fmt.Sprintf("  output #%v: %v, amount %v\n", output.Index, output.PublicKey, output.Amount))
+		} else {
+			sb.WriteString(fmt.Sprintf("  output #%v: %v\n", output.Index, output.PublicKey))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package incclient
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver1"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestDescribeTx(t *testing.T) {
+	receiver := newRandomWalletInShard(0)
+	other := newRandomWalletInShard(0)
+	amount := uint64(common.RandInt()%1000000 + 1)
+
+	otaCoin, err := coin.NewCoinFromPaymentInfo(coin.NewMintCoinParams(
+		key.InitPaymentInfo(receiver.KeySet.PaymentAddress, amount, []byte{})))
+	if err != nil {
+		panic(err)
+	}
+	md := metadata.NewPortalV4UnshieldResponse("accepted", common.Hash{}, "", "", amount,
+		common.PRVIDStr, metadata.PortalV4UnshieldingResponseMeta)
+	tx := new(tx_ver2.Tx)
+	err = tx.InitTxSalary(otaCoin, &other.KeySet.PrivateKey, md)
+	if err != nil {
+		panic(err)
+	}
+
+	desc, err := DescribeTx(tx, &receiver.KeySet)
+	if err != nil {
+		panic(err)
+	}
+	if desc.TxHash != tx.Hash().String() || desc.Type != common.TxRewardType || desc.TypeName != "Reward" {
+		panic(fmt.Sprintf("unexpected description %v", desc))
+	}
+	if desc.MetadataType != metadata.PortalV4UnshieldingResponseMeta || desc.MetadataNote != "[Portal] Unshield Response" {
+		panic(fmt.Sprintf("unexpected metadata %v (%v)", desc.MetadataType, desc.MetadataNote))
+	}
+	if _, ok := desc.Metadata.(*metadata.PortalUnshieldResponse); !ok {
+		panic(fmt.Sprintf("unexpected metadata type %T", desc.Metadata))
+	}
+	if desc.Token != nil || desc.PRV == nil {
+		panic("expected a PRV transfer only")
+	}
+	if desc.PRV.NumInputs != 0 || desc.PRV.RingSize != 0 || desc.PRV.NumOutputs != 1 {
+		panic(fmt.Sprintf("unexpected transfer %v", desc.PRV))
+	}
+	output := desc.PRV.Outputs[0]
+	expectedReceiver := receiver.Base58CheckSerialize(wallet.PaymentAddressType)
+	if !output.IsOwned || output.Amount != amount || output.Receiver != expectedReceiver {
+		panic(fmt.Sprintf("unexpected output %v", output))
+	}
+	if !strings.Contains(desc.String(), "[Portal] Unshield Response") {
+		panic(fmt.Sprintf("unexpected string %v", desc.String()))
+	}
+
+	desc, err = DescribeTx(tx, &other.KeySet)
+	if err != nil {
+		panic(err)
+	}
+	// minted coins are not encrypted
+	if desc.PRV.Outputs[0].IsOwned || desc.PRV.Outputs[0].Amount != amount || desc.PRV.Outputs[0].Receiver != "" {
+		panic(fmt.Sprintf("unexpected output %v", desc.PRV.Outputs[0]))
+	}
+
+	desc, err = DescribeTx(tx, nil)
+	if err != nil {
+		panic(err)
+	}
+	if desc.PRV.Outputs[0].IsOwned || desc.PRV.Outputs[0].PublicKey == "" {
+		panic(fmt.Sprintf("unexpected output %v", desc.PRV.Outputs[0]))
+	}
+}
+
+func TestDescribeTx_OTAKeyOnly(t *testing.T) {
+	receiver := newRandomWalletInShard(0)
+	amount := uint64(common.RandInt()%1000000 + 1)
+
+	// an encrypted output coin of version 1 cannot be decrypted with the OTA key only
+	plainCoin := new(coin.PlainCoinV1).Init()
+	pk, err := new(crypto.Point).FromBytesS(receiver.KeySet.PaymentAddress.Pk)
+	if err != nil {
+		panic(err)
+	}
+	plainCoin.SetPublicKey(pk)
+	plainCoin.SetValue(amount)
+	plainCoin.SetSNDerivator(crypto.RandomScalar())
+	plainCoin.SetRandomness(crypto.RandomScalar())
+	err = plainCoin.CommitAll()
+	if err != nil {
+		panic(err)
+	}
+	outCoin := &coin.CoinV1{CoinDetails: plainCoin}
+	err = outCoin.Encrypt(receiver.KeySet.PaymentAddress.Tk)
+	if err != nil {
+		panic(err)
+	}
+	proof := new(privacy.ProofV1)
+	proof.Init()
+	err = proof.SetOutputCoins([]coin.Coin{outCoin})
+	if err != nil {
+		panic(err)
+	}
+	tx := new(tx_ver1.Tx)
+	tx.Version = 1
+	tx.Type = common.TxNormalType
+	tx.Proof = proof
+
+	otaKeySet := &key.KeySet{PaymentAddress: receiver.KeySet.PaymentAddress, OTAKey: receiver.KeySet.OTAKey}
+	desc, err := DescribeTx(tx, otaKeySet)
+	if err != nil {
+		panic(err)
+	}
+	output := desc.PRV.Outputs[0]
+	if !output.IsOwned || !output.IsAmountUnknown || output.Amount != 0 {
+		panic(fmt.Sprintf("unexpected output %v", output))
+	}
+	if !strings.Contains(desc.String(), "amount unknown") {
+		panic(fmt.Sprintf("unexpected string %v", desc.String()))
+	}
+
+	desc, err = DescribeTx(tx, &receiver.KeySet)
+	if err != nil {
+		panic(err)
+	}
+	output = desc.PRV.Outputs[0]
+	if !output.IsOwned || output.IsAmountUnknown || output.Amount != amount {
+		panic(fmt.Sprintf("unexpected output %v", output))
+	}
+}
+
+func TestDescribeTx_getTxRingSize(t *testing.T) {
+	proof := new(privacy.ProofV2)
+	proof.Init()
+	err := proof.SetInputCoins([]coin.PlainCoin{new(coin.CoinV2).Init()})
+	if err != nil {
+		panic(err)
+	}
+
+	tx := new(tx_ver2.Tx)
+	tx.Version = 2
+	tx.Sig = common.RandBytes(64)
+	for txType, expected := range map[string]int{
+		common.TxConversionType:      1,
+		common.TxTokenConversionType: 1,
+		common.TxNormalType:          0, // not a valid MLSAG signature
+	} {
+		tx.Type = txType
+		if ringSize := getTxRingSize(tx, proof); ringSize != expected {
+			panic(fmt.Sprintf("type %v: expected ring size %v, got %v", txType, expected, ringSize))
+		}
+	}
+}
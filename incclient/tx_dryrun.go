@@ -0,0 +1,456 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/utils"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// TxDryRunCoin is an input coin chosen by a simulated transaction.
+type TxDryRunCoin struct {
+	TokenID   string
+	Version   int
+	PublicKey string
+	Value     uint64
+	Index     uint64
+}
+
+// TxDryRunOutput is an output of a simulated transaction.
+type TxDryRunOutput struct {
+	TokenID  string
+	Address  string
+	Amount   uint64
+	Memo     string `json:",omitempty"`
+	IsChange bool
+}
+
+// TxDryRunReport describes a transaction simulated by a dry-run IncClient (see NewDryRunClient).
+type TxDryRunReport struct {
+	// TxHash is a placeholder hash identifying the simulated transaction; no transaction has this hash on-chain.
+	TxHash string
+
+	Version int8
+
+	// TokenID is the token being transferred (PRV for a PRV transaction).
+	TokenID string
+
+	Inputs  []TxDryRunCoin
+	Outputs []TxDryRunOutput
+
+	// Fee is the PRV fee, TokenFee is the fee paid in the token (for transactions v1 only).
+	Fee      uint64
+	TokenFee uint64
+
+	MetadataType int
+	Metadata     json.RawMessage
+
+	// EstimatedSize is the estimated size (in bytes) of the transaction. It is 0 for transactions v1.
+	EstimatedSize uint64
+
+	// Sufficient indicates whether the balances (of the given version) cover the required amounts. If not, Inputs
+	// and Outputs are left empty.
+	Sufficient bool
+	Balances   map[string]uint64
+	Required   map[string]uint64
+}
+
+// dryRunRecorder keeps the reports of the transactions simulated by a dry-run IncClient.
+type dryRunRecorder struct {
+	mtx     sync.Mutex
+	reports []*TxDryRunReport
+}
+
+// record adds a report, and returns its placeholder hash: the hash of the report and its position in the recorder.
+func (r *dryRunRecorder) record(report *TxDryRunReport) (string, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	jsb, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal the dry-run report: %v", err)
+	}
+	report.TxHash = common.HashH(append(jsb, common.IntToBytes(len(r.reports))...)).String()
+	r.reports = append(r.reports, report)
+
+	return report.TxHash, nil
+}
+
+// NewDryRunClient returns a copy of the client in dry-run mode.
+//
+// In dry-run mode, the transaction builders run coin selection and fee estimation, then record a TxDryRunReport
+// instead of signing: they return an empty encoded transaction and the placeholder hash of the report. Broadcasting
+// (SendRawTx, SendRawTokenTx) is a no-op, and the builders sending several transactions (e.g. Consolidate,
+// ConvertAllUTXOs) neither wait for the transactions to be confirmed nor for the UTXOs to be updated, so they
+// simulate a single round. Read-only RPCs are still sent to the remote node. A version of -1 is simulated as
+// version 2.
+func (client *IncClient) NewDryRunClient() *IncClient {
+	res := *client
+	res.dryRun = new(dryRunRecorder)
+	return &res
+}
+
+// IsDryRun checks if the client is in dry-run mode.
+func (client *IncClient) IsDryRun() bool {
+	return client.dryRun != nil
+}
+
+// GetDryRunReports returns the reports of the transactions simulated so far by a dry-run client.
+func (client *IncClient) GetDryRunReports() []*TxDryRunReport {
+	if client.dryRun == nil {
+		return nil
+	}
+	client.dryRun.mtx.Lock()
+	defer client.dryRun.mtx.Unlock()
+
+	res := make([]*TxDryRunReport, len(client.dryRun.reports))
+	copy(res, client.dryRun.reports)
+	return res
+}
+
+// DryRun calls fn with a dry-run copy of the client (see NewDryRunClient), and returns the reports of the
+// transactions it has built. For example,
+//
+//	reports, err := client.DryRun(func(c *IncClient) error {
+//		_, err := c.CreateAndSendRawTransaction(privateKey, addrList, amountList, 2, nil)
+//		return err
+//	})
+func (client *IncClient) DryRun(fn func(dryRunClient *IncClient) error) ([]*TxDryRunReport, error) {
+	dryRunClient := client.NewDryRunClient()
+	err := fn(dryRunClient)
+	return dryRunClient.GetDryRunReports(), err
+}
+
+// dryRunTransaction simulates a PRV or token transaction and records its report.
+func (client *IncClient) dryRunTransaction(param *TxParam, version int8) ([]byte, string, error) {
+	if version != 1 && version != 2 {
+		return nil, "", fmt.Errorf("transaction version is invalid")
+	}
+	senderWallet, err := wallet.Base58CheckDeserialize(param.senderPrivateKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot init private key %v: %v", param.senderPrivateKey, err)
+	}
+	senderAddr := client.PrivateKeyToPaymentAddress(param.senderPrivateKey, -1)
+
+	report := &TxDryRunReport{
+		Version:    version,
+		TokenID:    common.PRVIDStr,
+		Inputs:     make([]TxDryRunCoin, 0),
+		Outputs:    make([]TxDryRunOutput, 0),
+		Sufficient: true,
+		Balances:   make(map[string]uint64),
+		Required:   make(map[string]uint64),
+	}
+	if param.md != nil {
+		report.MetadataType = param.md.GetType()
+		report.Metadata, err = json.Marshal(param.md)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot marshal metadata: %v", err)
+		}
+	}
+	hasPrivacy := param.md == nil
+
+	prvPayments, err := createPaymentInfos(param.receiverList, param.amountList, param.memoList)
+	if err != nil {
+		return nil, "", err
+	}
+	report.Fee = param.fee
+	if report.Fee == 0 {
+		report.Fee = DefaultPRVFee
+	}
+	isInscribeTx := param.txTokenParam == nil && param.md != nil && param.md.GetType() == metadata.InscribeRequestMeta
+	if version == 2 && isInscribeTx {
+		report.Fee = InscMinFeePerTx
+	}
+
+	// the token part
+	var tokenParam *tx_generic.TokenParam
+	var tokenCoins []coin.PlainCoin
+	var tokenIndices []uint64
+	tokenChange := uint64(0)
+	if param.txTokenParam != nil {
+		tokenIDStr := param.txTokenParam.tokenID
+		if _, err = new(common.Hash).NewHashFromStr(tokenIDStr); err != nil {
+			return nil, "", err
+		}
+		report.TokenID = tokenIDStr
+
+		tokenAmount := uint64(0)
+		for _, amount := range param.txTokenParam.amountList {
+			tokenAmount += amount
+		}
+		var tokenPayments []*key.PaymentInfo
+		if param.txTokenParam.tokenType == utils.CustomTokenInit {
+			tokenPayments = []*key.PaymentInfo{{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: tokenAmount}}
+		} else {
+			tokenPayments, err = createPaymentInfos(param.txTokenParam.receiverList, param.txTokenParam.amountList, param.txTokenParam.memoList)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		if version == 1 && param.txTokenParam.hasTokenFee {
+			report.Fee = 0
+			report.TokenFee = param.txTokenParam.tokenFee
+			if report.TokenFee == 0 {
+				report.TokenFee, err = client.GetTokenFee(client.GetShardIDFromPrivateKey(param.senderPrivateKey), tokenIDStr)
+				if err != nil {
+					return nil, "", err
+				}
+			}
+		}
+
+		if param.txTokenParam.tokenType != utils.CustomTokenInit {
+			required := tokenAmount + report.TokenFee
+			var sufficient bool
+			tokenCoins, tokenIndices, sufficient, err = client.dryRunSelectCoins(param, tokenIDStr, version, required, report)
+			if err != nil {
+				return nil, "", err
+			}
+			if sufficient {
+				tokenChange = sumCoinValues(tokenCoins) - required
+			}
+		}
+
+		for i, payment := range tokenPayments {
+			report.Outputs = append(report.Outputs, newTxDryRunOutput(client.networkParams, tokenIDStr, payment, getMemo(param.txTokenParam.memoList, i)))
+		}
+		if tokenChange > 0 {
+			tokenPayments = append(tokenPayments, &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: tokenChange})
+			report.Outputs = append(report.Outputs, TxDryRunOutput{TokenID: tokenIDStr, Address: senderAddr, Amount: tokenChange, IsChange: true})
+		}
+		tokenParam = &tx_generic.TokenParam{PropertyID: tokenIDStr, Receiver: tokenPayments, TokenInput: tokenCoins}
+	}
+
+	// the PRV part
+	prvAmount := uint64(0)
+	for _, amount := range param.amountList {
+		prvAmount += amount
+	}
+	var prvCoins []coin.PlainCoin
+	var prvIndices []uint64
+	prvChange := uint64(0)
+	if prvAmount+report.Fee > 0 {
+		var sufficient bool
+		prvCoins, prvIndices, sufficient, err = client.dryRunSelectCoins(param, common.PRVIDStr, version, prvAmount+report.Fee, report)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// re-estimate the fee of an inscription with the actual number of input coins
+		if sufficient && version == 2 && isInscribeTx {
+			sizeParam := tx_generic.NewEstimateTxSizeParam(2, len(prvCoins), len(prvPayments)+1, hasPrivacy, param.md, nil, 0)
+			estTxFee := uint64(math.Ceil(float64(tx_generic.EstimateTxSizeV2(sizeParam))/1024.0)) * InscMinFeePerKB
+			if estTxFee > report.Fee {
+				report.Fee = estTxFee
+				prvCoins, prvIndices, sufficient, err = client.dryRunSelectCoins(param, common.PRVIDStr, version, prvAmount+report.Fee, report)
+				if err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		if sufficient {
+			prvChange = sumCoinValues(prvCoins) - prvAmount - report.Fee
+		}
+	}
+	for i, payment := range prvPayments {
+		report.Outputs = append(report.Outputs, newTxDryRunOutput(client.networkParams, common.PRVIDStr, payment, getMemo(param.memoList, i)))
+	}
+	if prvChange > 0 {
+		prvPayments = append(prvPayments, &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: prvChange})
+		report.Outputs = append(report.Outputs, TxDryRunOutput{TokenID: common.PRVIDStr, Address: senderAddr, Amount: prvChange, IsChange: true})
+	}
+
+	if !report.Sufficient {
+		report.Inputs = make([]TxDryRunCoin, 0)
+		report.Outputs = make([]TxDryRunOutput, 0)
+	} else {
+		report.Inputs = append(newTxDryRunCoins(common.PRVIDStr, prvCoins, prvIndices), newTxDryRunCoins(report.TokenID, tokenCoins, tokenIndices)...)
+		if version == 2 {
+			sizeParam := tx_generic.NewEstimateTxSizeParam(2, len(prvCoins), len(prvPayments), hasPrivacy, param.md, tokenParam, 0)
+			report.EstimatedSize = tx_generic.EstimateTxSizeV2(sizeParam)
+		}
+	}
+	txHash, err := client.dryRun.record(report)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return nil, txHash, nil
+}
+
+// dryRunConversion simulates a transaction converting UTXOs v1 of a token into UTXOs v2, and records its report.
+// If coinV1List is nil, all UTXOs v1 are converted. For a token, the fee is paid with the given PRV coins, or with
+// PRV UTXOs v2 chosen by the client if prvCoins is nil.
+func (client *IncClient) dryRunConversion(privateKey, tokenIDStr string, coinV1List []coin.PlainCoin,
+	prvCoins []coin.PlainCoin, prvIndices []uint64) ([]byte, string, error) {
+	if _, err := wallet.Base58CheckDeserialize(privateKey); err != nil {
+		return nil, "", fmt.Errorf("cannot init private key %v: %v", privateKey, err)
+	}
+	senderAddr := client.PrivateKeyToPaymentAddress(privateKey, -1)
+
+	if coinV1List == nil {
+		utxoList, _, err := client.GetUnspentOutputCoins(privateKey, tokenIDStr, 0)
+		if err != nil {
+			return nil, "", err
+		}
+		coinV1List, _, _, err = divideCoins(utxoList, nil, true)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot divide coin: %v", err)
+		}
+	}
+	if len(coinV1List) == 0 {
+		return nil, "", fmt.Errorf("no CoinV1 left to be converted")
+	}
+
+	report := &TxDryRunReport{
+		Version:    2,
+		TokenID:    tokenIDStr,
+		Inputs:     newTxDryRunCoins(tokenIDStr, coinV1List, nil),
+		Outputs:    make([]TxDryRunOutput, 0),
+		Fee:        DefaultPRVFee,
+		Sufficient: true,
+		Balances:   map[string]uint64{tokenIDStr: sumCoinValues(coinV1List)},
+		Required:   make(map[string]uint64),
+	}
+	totalAmount := sumCoinValues(coinV1List)
+	if tokenIDStr == common.PRVIDStr {
+		report.Required[common.PRVIDStr] = DefaultPRVFee
+		if totalAmount < DefaultPRVFee {
+			report.Sufficient = false
+		} else {
+			report.Outputs = append(report.Outputs, TxDryRunOutput{TokenID: tokenIDStr, Address: senderAddr, Amount: totalAmount - DefaultPRVFee})
+		}
+	} else {
+		// the fee is paid with PRV UTXOs v2
+		param := &TxParam{senderPrivateKey: privateKey}
+		if prvCoins != nil {
+			param.kArgs = map[string]interface{}{prvInCoinKey: coinParams{coinList: prvCoins, idxList: prvIndices}}
+		}
+		prvCoins, prvIndices, sufficient, err := client.dryRunSelectCoins(param, common.PRVIDStr, 2, DefaultPRVFee, report)
+		if err != nil {
+			return nil, "", err
+		}
+		if sufficient {
+			report.Inputs = append(report.Inputs, newTxDryRunCoins(common.PRVIDStr, prvCoins, prvIndices)...)
+			report.Outputs = append(report.Outputs, TxDryRunOutput{TokenID: tokenIDStr, Address: senderAddr, Amount: totalAmount})
+			if change := sumCoinValues(prvCoins) - DefaultPRVFee; change > 0 {
+				report.Outputs = append(report.Outputs, TxDryRunOutput{TokenID: common.PRVIDStr, Address: senderAddr, Amount: change, IsChange: true})
+			}
+		}
+	}
+	if !report.Sufficient {
+		report.Inputs = make([]TxDryRunCoin, 0)
+		report.Outputs = make([]TxDryRunOutput, 0)
+	}
+	txHash, err := client.dryRun.record(report)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return nil, txHash, nil
+}
+
+// dryRunSelectCoins chooses the coins of a token to spend for the required amount, the same way the transaction
+// builders do (without retrieving decoys). It updates the balance and the requirement of the token in the report,
+// and returns false if the balance is insufficient.
+func (client *IncClient) dryRunSelectCoins(param *TxParam, tokenIDStr string, version int8, required uint64,
+	report *TxDryRunReport) ([]coin.PlainCoin, []uint64, bool, error) {
+	report.Required[tokenIDStr] = required
+
+	// input coins provided by the caller are all spent
+	if param.kArgs != nil {
+		kArgKey := tokenInCoinKey
+		if tokenIDStr == common.PRVIDStr {
+			kArgKey = prvInCoinKey
+		}
+		if cp, ok := param.kArgs[kArgKey].(coinParams); ok && len(cp.coinList) > 0 {
+			v, _ := getVersionFromInputCoins(cp.coinList)
+			if int8(v) == version {
+				balance := sumCoinValues(cp.coinList)
+				report.Balances[tokenIDStr] = balance
+				if balance < required {
+					report.Sufficient = false
+					return nil, nil, false, nil
+				}
+				return cp.coinList, cp.idxList, true, nil
+			}
+		}
+	}
+
+	utxoList, idxList, err := client.GetUnspentOutputCoins(param.senderPrivateKey, tokenIDStr, 0)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	coinV1List, coinV2List, idxV2List, err := divideCoins(utxoList, idxList, true)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("cannot divide coin: %v", err)
+	}
+	coinList := coinV2List
+	if version == 1 {
+		coinList = coinV1List
+	}
+	report.Balances[tokenIDStr] = sumCoinValues(coinList)
+
+	coinsToSpend, chosenIdxList, err := chooseBestCoinsByAmount(coinList, required)
+	if err != nil {
+		report.Sufficient = false
+		return nil, nil, false, nil
+	}
+	var indices []uint64
+	if version == 2 {
+		indices = make([]uint64, 0)
+		for _, idx := range chosenIdxList {
+			indices = append(indices, idxV2List[idx])
+		}
+	}
+
+	return coinsToSpend, indices, true, nil
+}
+
+func newTxDryRunCoins(tokenIDStr string, coins []coin.PlainCoin, indices []uint64) []TxDryRunCoin {
+	res := make([]TxDryRunCoin, 0)
+	for i, c := range coins {
+		dryRunCoin := TxDryRunCoin{
+			TokenID: tokenIDStr,
+			Version: int(c.GetVersion()),
+			Value:   c.GetValue(),
+		}
+		if c.GetPublicKey() != nil {
+			dryRunCoin.PublicKey = base58.Base58Check{}.Encode(c.GetPublicKey().ToBytesS(), common.ZeroByte)
+		}
+		if i < len(indices) {
+			dryRunCoin.Index = indices[i]
+		}
+		res = append(res, dryRunCoin)
+	}
+	return res
+}
+
+func newTxDryRunOutput(params *common.NetworkParams, tokenIDStr string, payment *key.PaymentInfo, memo string) TxDryRunOutput {
+	w := new(wallet.KeyWallet)
+	w.KeySet.PaymentAddress = payment.PaymentAddress
+	return TxDryRunOutput{
+		TokenID: tokenIDStr,
+		Address: w.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params),
+		Amount:  payment.Amount,
+		Memo:    memo,
+	}
+}
+
+// getMemo returns the i-th memo of a (possibly empty) memo list.
+func getMemo(memoList []string, i int) string {
+	if i < len(memoList) {
+		return memoList[i]
+	}
+	return ""
+}
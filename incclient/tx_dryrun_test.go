@@ -0,0 +1,190 @@
+package incclient
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func newTestCoinsV2(addr key.PaymentAddress, values ...uint64) ([]coin.PlainCoin, []uint64) {
+	coins := make([]coin.PlainCoin, 0)
+	indices := make([]uint64, 0)
+	for i, v := range values {
+		c, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(key.InitPaymentInfo(addr, v, []byte{})))
+		if err != nil {
+			panic(err)
+		}
+		coins = append(coins, c)
+		indices = append(indices, uint64(i+1))
+	}
+	return coins, indices
+}
+
+func TestDryRun_PRVTransaction(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+	receiver := newRandomWalletInShard(1).Base58CheckSerialize(wallet.PaymentAddressType)
+	coins, indices := newTestCoinsV2(sender.KeySet.PaymentAddress, 3*DefaultPRVFee, 2*DefaultPRVFee)
+
+	client := &IncClient{}
+	if client.IsDryRun() || client.GetDryRunReports() != nil {
+		panic("a regular client is not in dry-run mode")
+	}
+
+	reports, err := client.DryRun(func(c *IncClient) error {
+		param := NewTxParam(privateKey, []string{receiver}, []uint64{DefaultPRVFee}, 0, nil, nil, nil)
+		encodedTx, txHash, err := c.CreateRawTransactionWithInputCoins(param, coins, indices)
+		if err != nil {
+			return err
+		}
+		if encodedTx != nil || txHash == "" {
+			return fmt.Errorf("expected no transaction and a placeholder hash, got %v", txHash)
+		}
+		if err = c.SendRawTx(encodedTx); err != nil {
+			return err
+		}
+
+		// not enough PRV
+		param = NewTxParam(privateKey, []string{receiver}, []uint64{5 * DefaultPRVFee}, 0, nil, nil, nil)
+		_, _, err = c.CreateRawTransactionWithInputCoins(param, coins, indices)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	if len(reports) != 2 {
+		panic(fmt.Sprintf("expected 2 reports, got %v", len(reports)))
+	}
+
+	report := reports[0]
+	if report.TxHash == "" || report.TxHash == reports[1].TxHash {
+		panic(fmt.Sprintf("expected distinct placeholder hashes, got %v and %v", report.TxHash, reports[1].TxHash))
+	}
+	if !report.Sufficient || report.Version != 2 || report.TokenID != common.PRVIDStr || report.Fee != DefaultPRVFee {
+		panic(fmt.Sprintf("unexpected report %+v", report))
+	}
+	if len(report.Inputs) != 2 || report.Inputs[1].Index != 2 || report.Inputs[0].PublicKey == "" {
+		panic(fmt.Sprintf("unexpected inputs %+v", report.Inputs))
+	}
+	if len(report.Outputs) != 2 || report.Outputs[0].Address != receiver || report.Outputs[0].Amount != DefaultPRVFee {
+		panic(fmt.Sprintf("unexpected outputs %+v", report.Outputs))
+	}
+	if change := report.Outputs[1]; !change.IsChange || change.Amount != 3*DefaultPRVFee {
+		panic(fmt.Sprintf("unexpected change %+v", change))
+	}
+	if report.EstimatedSize == 0 || report.Required[common.PRVIDStr] != 2*DefaultPRVFee {
+		panic(fmt.Sprintf("unexpected estimation %+v", report))
+	}
+
+	report = reports[1]
+	if report.Sufficient || len(report.Inputs) != 0 || report.Balances[common.PRVIDStr] != 5*DefaultPRVFee {
+		panic(fmt.Sprintf("expected an insufficient balance, got %+v", report))
+	}
+}
+
+func TestDryRun_TokenTransaction(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+	tokenIDStr := testHistoryTokenID
+	tokenCoins, tokenIndices := newTestCoinsV2(sender.KeySet.PaymentAddress, 700, 300)
+	prvCoins, prvIndices := newTestCoinsV2(sender.KeySet.PaymentAddress, DefaultPRVFee)
+
+	md := &metadata.BurningRequest{
+		BurningAmount: 600,
+		TokenID:       common.Hash{},
+		RemoteAddress: "0x15B9419e738393Dbc8448272b18CdE970a07864D",
+		MetadataBase:  metadata.MetadataBase{Type: metadata.BurningRequestMetaV2},
+	}
+	client := (&IncClient{}).NewDryRunClient()
+	txTokenParam := NewTxTokenParam(tokenIDStr, 1, []string{common.BurningAddress2}, []uint64{600}, false, 0, nil)
+	txParam := NewTxParam(privateKey, []string{}, []uint64{}, 0, txTokenParam, md, nil)
+	_, _, err := client.CreateRawTokenTransactionWithInputCoins(txParam, tokenCoins, tokenIndices, prvCoins, prvIndices)
+	if err != nil {
+		panic(err)
+	}
+
+	reports := client.GetDryRunReports()
+	if len(reports) != 1 {
+		panic(fmt.Sprintf("expected 1 report, got %v", len(reports)))
+	}
+	report := reports[0]
+	if !report.Sufficient || report.TokenID != tokenIDStr || report.MetadataType != metadata.BurningRequestMetaV2 || len(report.Metadata) == 0 {
+		panic(fmt.Sprintf("unexpected report %+v", report))
+	}
+	if len(report.Inputs) != 3 {
+		panic(fmt.Sprintf("unexpected inputs %+v", report.Inputs))
+	}
+	// the burnt amount, and the token change; the PRV coin pays the exact fee
+	if len(report.Outputs) != 2 || report.Outputs[0].Amount != 600 || !report.Outputs[1].IsChange || report.Outputs[1].Amount != 400 {
+		panic(fmt.Sprintf("unexpected outputs %+v", report.Outputs))
+	}
+}
+
+func TestDryRun_NetworkParams(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+	receiver := newRandomWalletInShard(1)
+	coins, indices := newTestCoinsV2(sender.KeySet.PaymentAddress, 3*DefaultPRVFee)
+
+	// output addresses are encoded with the address version of the client
+	params, err := newNetworkParams(common.MaxShardNumber, 1)
+	if err != nil {
+		panic(err)
+	}
+	client := &IncClient{version: 1, networkParams: params}
+	reports, err := client.DryRun(func(c *IncClient) error {
+		param := NewTxParam(privateKey, []string{receiver.Base58CheckSerialize(wallet.PaymentAddressType)},
+			[]uint64{DefaultPRVFee}, 0, nil, nil, nil)
+		_, _, err := c.CreateRawTransactionWithInputCoins(param, coins, indices)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	expectedAddr := receiver.Base58CheckSerializeWithParams(wallet.PaymentAddressType, params)
+	if len(reports) != 1 || reports[0].Outputs[0].Address != expectedAddr ||
+		expectedAddr == receiver.Base58CheckSerialize(wallet.PaymentAddressType) {
+		panic(fmt.Sprintf("expected the output address %v, got %+v", expectedAddr, reports))
+	}
+}
+
+func TestDryRun_ConsolidatePRVs(t *testing.T) {
+	sender := newRandomWalletInShard(0)
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+
+	node := &mockHistoryNode{
+		shardID: 0,
+		txs:     make(map[string]string),
+		pkTxs:   make(map[string][]string),
+	}
+	for i := 0; i < maxUTXOsAfterConsolidated+2; i++ {
+		node.addSalaryTx(sender, DefaultPRVFee, true)
+	}
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL), version: 2}
+
+	// a dry run neither waits for the transactions nor for the UTXOs to be updated
+	var txList []string
+	reports, err := client.DryRun(func(c *IncClient) error {
+		var err error
+		txList, err = c.ConsolidatePRVs(privateKey, 2, 1)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	if len(reports) != 1 || len(txList) != 1 || txList[0] != reports[0].TxHash {
+		panic(fmt.Sprintf("expected a single simulated transaction, got %v, %v", txList, len(reports)))
+	}
+	if len(reports[0].Inputs) != maxUTXOsAfterConsolidated+2 {
+		panic(fmt.Sprintf("expected %v inputs, got %v", maxUTXOsAfterConsolidated+2, len(reports[0].Inputs)))
+	}
+}
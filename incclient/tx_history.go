@@ -37,6 +37,7 @@ type TxIn struct {
 	Metadata metadata.Metadata
 	OutCoins map[string]uint64
 	Note     string
+	Memos    []string `json:",omitempty"`
 }
 
 // GetLockTime returns the lock-time.
@@ -76,6 +77,23 @@ func (txIn TxIn) GetTxHash() string {
 	return txIn.TxHash
 }
 
+// UnmarshalJSON does the JSON-unmarshalling operation for a TxIn, parsing its Metadata into the right type.
+func (txIn *TxIn) UnmarshalJSON(data []byte) error {
+	type txInAlias TxIn
+	var tmp struct {
+		txInAlias
+		Metadata json.RawMessage
+	}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*txIn = TxIn(tmp.txInAlias)
+	txIn.Metadata, err = parseHistoryMetadata(tmp.Metadata)
+	return err
+}
+
 // TxOut is an out-going transaction.
 // A transaction is considered to be a TxOut if it spends input coins.
 type TxOut struct {
@@ -129,6 +147,36 @@ func (txOut TxOut) GetTxHash() string {
 	return txOut.TxHash
 }
 
+// UnmarshalJSON does the JSON-unmarshalling operation for a TxOut, parsing its Metadata into the right type.
+func (txOut *TxOut) UnmarshalJSON(data []byte) error {
+	type txOutAlias TxOut
+	var tmp struct {
+		txOutAlias
+		Metadata json.RawMessage
+	}
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+
+	*txOut = TxOut(tmp.txOutAlias)
+	txOut.Metadata, err = parseHistoryMetadata(tmp.Metadata)
+	return err
+}
+
+// parseHistoryMetadata parses the JSON-encoded metadata of a history action.
+func parseHistoryMetadata(raw json.RawMessage) (metadata.Metadata, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	md, err := metadata.ParseMetadata(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse metadata %v: %v", string(raw), err)
+	}
+	return md, nil
+}
+
 // TxHistory consists of a list of TxIn's and a list of TxOut's.
 type TxHistory struct {
 	TxInList  []TxIn
@@ -142,7 +190,7 @@ func (client *IncClient) GetListTxsInV1(privateKey string, tokenIDStr string) ([
 		return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
 	}
 
-	addrStr := PrivateKeyToPaymentAddress(privateKey, -1)
+	addrStr := client.PrivateKeyToPaymentAddress(privateKey, -1)
 	if addrStr == "" {
 		return nil, fmt.Errorf("cannot get payment address")
 	}
@@ -185,6 +233,7 @@ func (client *IncClient) GetListTxsInV1(privateKey string, tokenIDStr string) ([
 				TxHash:   txHash,
 				TokenID:  tx.GetTokenID().String(),
 				Metadata: tx.GetMetadata(),
+				Memos:    getTxInMemos(outCoins, mapCmt, &kWallet.KeySet),
 			}
 			newTxIn.Amount = amount
 			res = append(res, newTxIn)
@@ -269,6 +318,7 @@ func (client *IncClient) GetListTxsInV2(privateKey string, tokenIDStr string) ([
 					Metadata: tx.GetMetadata(),
 					Amount:   amount,
 					Note:     txMetadataNote[tx.GetMetadataType()],
+					Memos:    getTxInMemos(outCoins, mapCmt, &kWallet.KeySet),
 				}
 				mapRes[txHash] = txIn
 			}
@@ -318,7 +368,7 @@ func (client *IncClient) GetListTxsOutV1(privateKey string, tokenIDStr string) (
 	}
 
 	addr := kWallet.KeySet.PaymentAddress
-	shardID := common.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
+	shardID := client.networkParams.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
 
 	listSpentCoins, _, err := client.GetSpentOutputCoins(privateKey, tokenIDStr, 0)
 	if err != nil {
@@ -432,7 +482,7 @@ func (client *IncClient) GetListTxsOutV2(privateKey string, tokenIDStr string) (
 	}
 
 	addr := kWallet.KeySet.PaymentAddress
-	shardID := common.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
+	shardID := client.networkParams.GetShardIDFromLastByte(addr.Pk[len(addr.Pk)-1])
 
 	listSpentCoins, _, err := client.GetSpentOutputCoins(privateKey, tokenIDStr, 0)
 	if err != nil {
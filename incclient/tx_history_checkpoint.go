@@ -0,0 +1,188 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+)
+
+// TxHistoryCheckpoint records the progress of the history retrieval of an account w.r.t a tokenID on a network.
+//
+// Besides the history retrieved so far, it keeps track of the items already processed so that the next retrieval
+// only looks at newer ones:
+//   - ProcessedTxs: the v1 in-coming transaction hashes;
+//   - ProcessedOTAs: the (base58-encoded) public keys of the output coins;
+//   - ProcessedKeyImages: the (base58-encoded) key images of the spent coins;
+//   - LatestIndex: the highest index of the v2 output coins processed so far, -1 if none;
+//   - CoinKeyImages: a mapping from the ID of each processed output coin (see getHistoryCoinID) to its key image;
+//   - Coins: the version and value of each processed output coin, keyed by its key image.
+//
+// The next retrieval only downloads the v2 output coins after LatestIndex. CoinKeyImages and Coins allow it to check
+// the spending status of the processed coins, and to account for their values, without downloading or decrypting them
+// again.
+type TxHistoryCheckpoint struct {
+	Network            string
+	TokenID            string
+	History            TxHistory
+	ProcessedTxs       map[string]bool
+	ProcessedOTAs      map[string]bool
+	ProcessedKeyImages map[string]bool
+	LatestIndex        int64
+	CoinKeyImages      map[string]string
+	Coins              map[string]TxHistoryCheckpointCoin
+	UpdatedAt          int64
+}
+
+// TxHistoryCheckpointCoin is an output coin processed by a TxHistoryCheckpoint.
+type TxHistoryCheckpointCoin struct {
+	Version uint8
+	Value   uint64
+}
+
+// newTxHistoryCheckpoint creates an empty TxHistoryCheckpoint for a tokenID on a network.
+func newTxHistoryCheckpoint(network, tokenIDStr string) *TxHistoryCheckpoint {
+	return &TxHistoryCheckpoint{
+		Network:            network,
+		TokenID:            tokenIDStr,
+		History:            TxHistory{TxInList: make([]TxIn, 0), TxOutList: make([]TxOut, 0)},
+		ProcessedTxs:       make(map[string]bool),
+		ProcessedOTAs:      make(map[string]bool),
+		ProcessedKeyImages: make(map[string]bool),
+		LatestIndex:        -1,
+		CoinKeyImages:      make(map[string]string),
+		Coins:              make(map[string]TxHistoryCheckpointCoin),
+	}
+}
+
+// getTxHistoryCheckpointPath returns the path of the checkpoint of a private key w.r.t a tokenID on a network.
+// Checkpoints are grouped by the network, then by the (base58-encoded) public key of the account.
+func getTxHistoryCheckpointPath(dir, network, privateKey, tokenIDStr string) (string, error) {
+	if network == "" {
+		return "", fmt.Errorf("network must not be empty")
+	}
+	pubKey := PrivateKeyToPublicKey(privateKey)
+	if len(pubKey) == 0 {
+		return "", fmt.Errorf("cannot get the public key of the private key")
+	}
+	accountID := base58.Base58Check{}.Encode(pubKey, 0)
+
+	return filepath.Join(dir, network, accountID, fmt.Sprintf("%v.json", tokenIDStr)), nil
+}
+
+// loadTxHistoryCheckpoint loads the checkpoint of a private key w.r.t a tokenID on a network.
+// It returns an empty checkpoint if none has been stored.
+func loadTxHistoryCheckpoint(dir, network, privateKey, tokenIDStr string) (*TxHistoryCheckpoint, error) {
+	filePath, err := getTxHistoryCheckpointPath(dir, network, privateKey, tokenIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newTxHistoryCheckpoint(network, tokenIDStr), nil
+		}
+		return nil, err
+	}
+
+	res := newTxHistoryCheckpoint(network, tokenIDStr)
+	err = json.Unmarshal(raw, res)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint %v: %v", filePath, err)
+	}
+	if res.Network != network {
+		return nil, fmt.Errorf("checkpoint %v is for network %v, expected %v", filePath, res.Network, network)
+	}
+	if res.TokenID != tokenIDStr {
+		return nil, fmt.Errorf("checkpoint %v is for token %v, expected %v", filePath, res.TokenID, tokenIDStr)
+	}
+	if res.ProcessedTxs == nil {
+		res.ProcessedTxs = make(map[string]bool)
+	}
+	if res.ProcessedOTAs == nil {
+		res.ProcessedOTAs = make(map[string]bool)
+	}
+	if res.ProcessedKeyImages == nil {
+		res.ProcessedKeyImages = make(map[string]bool)
+	}
+	if res.CoinKeyImages == nil {
+		res.CoinKeyImages = make(map[string]string)
+	}
+	if res.Coins == nil {
+		res.Coins = make(map[string]TxHistoryCheckpointCoin)
+	}
+
+	return res, nil
+}
+
+// store saves the checkpoint for a private key under its network. The file is replaced atomically so that an interrupted
+// write does not corrupt the previous checkpoint.
+func (cp *TxHistoryCheckpoint) store(dir, privateKey string) error {
+	filePath, err := getTxHistoryCheckpointPath(dir, cp.Network, privateKey, cp.TokenID)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	cp.UpdatedAt = time.Now().Unix()
+	raw, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filePath + ".tmp"
+	err = ioutil.WriteFile(tmpPath, raw, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filePath)
+}
+
+// historyCoin is the common interface of raw and decrypted output coins used to identify them in a checkpoint.
+type historyCoin interface {
+	GetVersion() uint8
+	GetPublicKey() *crypto.Point
+	GetCommitment() *crypto.Point
+}
+
+// getHistoryCoinID returns the ID of an output coin in a checkpoint: the (base58-encoded) public key of a v2 coin,
+// or the (base58-encoded) commitment of a v1 coin since v1 coins of an account share the same public key.
+func getHistoryCoinID(c historyCoin) string {
+	if c.GetVersion() == 2 {
+		return base58.Base58Check{}.Encode(c.GetPublicKey().ToBytesS(), 0)
+	}
+
+	return base58.Base58Check{}.Encode(c.GetCommitment().ToBytesS(), 0)
+}
+
+// filterUnprocessed returns the items of a list that are not in the processed set.
+func filterUnprocessed(processed map[string]bool, items []string) []string {
+	res := make([]string, 0)
+	for _, item := range items {
+		if !processed[item] {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// markProcessed adds a list of items to a processed set. It is a no-op if the set is nil.
+func markProcessed(processed map[string]bool, items []string) {
+	if processed == nil {
+		return
+	}
+	for _, item := range items {
+		processed[item] = true
+	}
+}
@@ -0,0 +1,309 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestTxHistoryCheckpoint_StoreAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tx-history-checkpoint")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	privateKey := newRandomWalletInShard(0).Base58CheckSerialize(wallet.PrivateKeyType)
+
+	// loading a non-existing checkpoint returns an empty one
+	cp, err := loadTxHistoryCheckpoint(dir, "mainnet", privateKey, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if len(cp.History.TxInList) != 0 || len(cp.ProcessedOTAs) != 0 || cp.LatestIndex != -1 || cp.Network != "mainnet" {
+		panic("expected an empty checkpoint")
+	}
+
+	burnReq := &metadata.BurningRequest{
+		BurningAmount: 100,
+		RemoteAddress: "0x15B9419e738393Dbc8448272b18CdE970a07864D",
+		MetadataBase:  metadata.MetadataBase{Type: metadata.BurningRequestMetaV2},
+	}
+	cp.History.TxInList = append(cp.History.TxInList, TxIn{LockTime: 1, TxHash: "tx1", Amount: 1000, TokenID: common.PRVIDStr})
+	cp.History.TxOutList = append(cp.History.TxOutList, TxOut{LockTime: 2, TxHash: "tx2", Amount: 100, TokenID: common.PRVIDStr, Metadata: burnReq})
+	markProcessed(cp.ProcessedOTAs, []string{"ota1", "ota2"})
+	markProcessed(cp.ProcessedKeyImages, []string{"ki1"})
+	cp.LatestIndex = 10
+	cp.CoinKeyImages["coin1"] = "ki1"
+	cp.Coins["ki1"] = TxHistoryCheckpointCoin{Version: 2, Value: 1000}
+	err = cp.store(dir, privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	loaded, err := loadTxHistoryCheckpoint(dir, "mainnet", privateKey, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if len(loaded.History.TxInList) != 1 || len(loaded.History.TxOutList) != 1 || loaded.UpdatedAt == 0 ||
+		loaded.LatestIndex != 10 || loaded.CoinKeyImages["coin1"] != "ki1" || loaded.Coins["ki1"].Value != 1000 {
+		panic(fmt.Sprintf("unexpected checkpoint %+v", loaded))
+	}
+	md, ok := loaded.History.TxOutList[0].Metadata.(*metadata.BurningRequest)
+	if !ok || md.RemoteAddress != burnReq.RemoteAddress || md.BurningAmount != burnReq.BurningAmount {
+		panic(fmt.Sprintf("metadata not restored: %v", loaded.History.TxOutList[0].Metadata))
+	}
+	if loaded.History.TxInList[0].Metadata != nil {
+		panic("expected nil metadata")
+	}
+
+	newItems := filterUnprocessed(loaded.ProcessedOTAs, []string{"ota1", "ota3", "ota2", "ota4"})
+	if len(newItems) != 2 || newItems[0] != "ota3" || newItems[1] != "ota4" {
+		panic(fmt.Sprintf("unexpected unprocessed items %v", newItems))
+	}
+	if len(filterUnprocessed(loaded.ProcessedKeyImages, []string{"ki1"})) != 0 {
+		panic("ki1 has been processed")
+	}
+
+	// checkpoints are per token
+	_, err = loadTxHistoryCheckpoint(dir, "mainnet", privateKey, common.ConfidentialAssetID.String())
+	if err != nil {
+		panic(err)
+	}
+
+	// checkpoints are per network
+	other, err := loadTxHistoryCheckpoint(dir, "testnet", privateKey, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if len(other.History.TxInList) != 0 || len(other.CoinKeyImages) != 0 || other.Network != "testnet" {
+		panic(fmt.Sprintf("expected an empty testnet checkpoint, got %+v", other))
+	}
+	_, err = loadTxHistoryCheckpoint(dir, "", privateKey, common.PRVIDStr)
+	if err == nil {
+		panic("expected an error for an empty network")
+	}
+}
+
+func TestTxHistoryCheckpoint_GetHistoryCoinID(t *testing.T) {
+	w := newRandomWalletInShard(0)
+	rawCoins, _ := genEncryptedCoins(w, 3)
+	outCoins := parseCoins(rawCoins)
+	decryptedCoins, _ := decryptCoinsSequentially(&w.KeySet, outCoins)
+
+	ids := make(map[string]bool)
+	for i, outCoin := range outCoins {
+		id := getHistoryCoinID(outCoin)
+		if id != getHistoryCoinID(decryptedCoins[i]) {
+			panic(fmt.Sprintf("the IDs of coin %v before and after decryption mismatch", i))
+		}
+		ids[id] = true
+	}
+	if len(ids) != len(outCoins) {
+		panic(fmt.Sprintf("expected %v different IDs, got %v", len(outCoins), len(ids)))
+	}
+}
+
+// mockHistoryNode serves the PRV output coins of a single shard, and the salary transactions paying them.
+type mockHistoryNode struct {
+	mtx          sync.Mutex
+	shardID      byte
+	coins        []jsonresult.OutCoin
+	ownedIndices []uint64
+	txs          map[string]string
+	pkTxs        map[string][]string
+
+	minQueried  uint64
+	queriedPKs  []string
+	numListings int
+}
+
+// addSalaryTx creates a salary transaction paying amount to receiver, and appends its output coin to the shard.
+// Owned coins are listed by listoutputcoinsfromcache.
+func (n *mockHistoryNode) addSalaryTx(receiver *wallet.KeyWallet, amount uint64, owned bool) {
+	otaCoin, err := coin.NewCoinFromPaymentInfo(coin.NewMintCoinParams(
+		key.InitPaymentInfo(receiver.KeySet.PaymentAddress, amount, []byte{})))
+	if err != nil {
+		panic(err)
+	}
+	tx := new(tx_ver2.Tx)
+	err = tx.InitTxSalary(otaCoin, &newRandomWalletInShard(n.shardID).KeySet.PrivateKey, nil)
+	if err != nil {
+		panic(err)
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		panic(err)
+	}
+	outCoin := tx.GetProof().GetOutputCoins()[0].(*coin.CoinV2)
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	txHash := tx.Hash().String()
+	n.txs[txHash] = base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+	pkStr := base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte)
+	n.pkTxs[pkStr] = append(n.pkTxs[pkStr], txHash)
+	if owned {
+		n.ownedIndices = append(n.ownedIndices, uint64(len(n.coins)))
+	}
+	n.coins = append(n.coins, jsonresult.NewOutCoin(outCoin))
+}
+
+func (n *mockHistoryNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Method string
+		Params []json.RawMessage
+	}
+	_ = json.Unmarshal(body, &req)
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	var result interface{}
+	switch req.Method {
+	case "getotacoinlength":
+		result = map[string]map[byte]uint64{
+			common.PRVIDStr: {n.shardID: uint64(len(n.coins))},
+		}
+	case "getotacoinsbyindices":
+		var params struct{ Indices []uint64 }
+		_ = json.Unmarshal(req.Params[0], &params)
+		res := make(map[uint64]jsonresult.OutCoin)
+		for _, idx := range params.Indices {
+			if idx < n.minQueried {
+				n.minQueried = idx
+			}
+			res[idx] = n.coins[idx]
+		}
+		result = res
+	case "listoutputcoinsfromcache":
+		n.numListings++
+		outCoins := make([]jsonresult.OutCoin, 0)
+		for _, idx := range n.ownedIndices {
+			outCoin := n.coins[idx]
+			outCoin.Index = base58.Base58Check{}.Encode(new(big.Int).SetUint64(idx).Bytes(), common.ZeroByte)
+			outCoins = append(outCoins, outCoin)
+		}
+		result = jsonresult.ListOutputCoins{Outputs: map[string][]jsonresult.OutCoin{"": outCoins}}
+	case "listoutputcoins":
+		result = jsonresult.ListOutputCoins{Outputs: map[string][]jsonresult.OutCoin{}}
+	case "hasserialnumbers":
+		var snList []string
+		_ = json.Unmarshal(req.Params[1], &snList)
+		result = make([]bool, len(snList))
+	case "gettransactionhashbyreceiver":
+		result = map[string][]string{}
+	case "gettransactionbypublickey":
+		var params struct{ PublicKeys []string }
+		_ = json.Unmarshal(req.Params[0], &params)
+		res := make(map[string]map[byte][]string)
+		for _, pkStr := range params.PublicKeys {
+			n.queriedPKs = append(n.queriedPKs, pkStr)
+			res[pkStr] = map[byte][]string{n.shardID: n.pkTxs[pkStr]}
+		}
+		result = res
+	case "getencodedtransactionsbyhashes":
+		var params struct{ TxHashList []string }
+		_ = json.Unmarshal(req.Params[0], &params)
+		res := make(map[string]string)
+		for _, txHash := range params.TxHashList {
+			res[txHash] = n.txs[txHash]
+		}
+		result = res
+	}
+	jsb, _ := json.Marshal(result)
+	resp, _ := json.Marshal(map[string]interface{}{"Result": json.RawMessage(jsb), "Error": nil})
+	_, _ = w.Write(resp)
+}
+
+func TestTxHistoryCheckpoint_IncrementalRetrieval(t *testing.T) {
+	shardID := byte(0)
+	receiver := newRandomWalletInShard(shardID)
+	other := newRandomWalletInShard(shardID)
+	privateKey := receiver.Base58CheckSerialize(wallet.PrivateKeyType)
+
+	node := &mockHistoryNode{
+		shardID: shardID,
+		txs:     make(map[string]string),
+		pkTxs:   make(map[string][]string),
+	}
+	node.addSalaryTx(receiver, 1000, true)
+	node.addSalaryTx(other, 2000, false)
+	node.addSalaryTx(receiver, 3000, true)
+
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL), version: 2, network: "testnet"}
+
+	dir, err := ioutil.TempDir("", "tx-history-checkpoint")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewTxHistoryProcessor(client, 2)
+	err = p.SetCheckpointDirectory(dir)
+	if err != nil {
+		panic(err)
+	}
+	history, err := p.GetTokenHistory(privateKey, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if len(history.TxInList) != 2 || node.numListings != 1 {
+		panic(fmt.Sprintf("expect 2 TxIns from 1 listing, got %v from %v", len(history.TxInList), node.numListings))
+	}
+
+	// the second retrieval only downloads and processes the coins after the latest index
+	prevLength := uint64(len(node.coins))
+	node.addSalaryTx(other, 4000, false)
+	node.addSalaryTx(receiver, 5000, true)
+	node.minQueried = uint64(len(node.coins))
+	node.queriedPKs = nil
+
+	p = NewTxHistoryProcessor(client, 2)
+	err = p.SetCheckpointDirectory(dir)
+	if err != nil {
+		panic(err)
+	}
+	history, err = p.GetTokenHistory(privateKey, common.PRVIDStr)
+	if err != nil {
+		panic(err)
+	}
+	if node.numListings != 1 {
+		panic("expect the second retrieval not to list all output coins")
+	}
+	if node.minQueried != prevLength {
+		panic(fmt.Sprintf("expect coins to be downloaded from index %v, got %v", prevLength, node.minQueried))
+	}
+	newPK := node.coins[len(node.coins)-1].PublicKey
+	if len(node.queriedPKs) != 1 || node.queriedPKs[0] != newPK {
+		panic(fmt.Sprintf("expect only the new coin to be processed, got %v", node.queriedPKs))
+	}
+	if len(history.TxInList) != 3 {
+		panic(fmt.Sprintf("expect 3 TxIns, got %v", len(history.TxInList)))
+	}
+	total := uint64(0)
+	for _, txIn := range history.TxInList {
+		total += txIn.Amount
+	}
+	if total != 9000 {
+		panic(fmt.Sprintf("expect a total amount of 9000, got %v", total))
+	}
+}
@@ -0,0 +1,600 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+)
+
+const (
+	HistoryEventTransfer          = "Transfer"
+	HistoryEventTrade             = "Trade"
+	HistoryEventShield            = "Shield"
+	HistoryEventUnshield          = "Unshield"
+	HistoryEventLiquidityAdd      = "LiquidityAdd"
+	HistoryEventLiquidityWithdraw = "LiquidityWithdraw"
+	HistoryEventStaking           = "Staking"
+	HistoryEventStakingReward     = "StakingReward"
+	HistoryEventOther             = "Other"
+)
+
+const (
+	// HistoryEventStatusPending indicates that the response of a request has not been found (yet).
+	HistoryEventStatusPending = "pending"
+
+	// HistoryEventStatusSuccess indicates that a request has been accepted.
+	HistoryEventStatusSuccess = "success"
+
+	// HistoryEventStatusRefunded indicates that a request has been rejected and its funds returned.
+	HistoryEventStatusRefunded = "refunded"
+)
+
+// historyEventTypes maps metadata types (of both requests and responses) to history event types.
+// Metadata types not listed here are classified as HistoryEventOther.
+var historyEventTypes = map[int]string{
+	metadata.InvalidMeta: HistoryEventTransfer,
+
+	// Trade
+	metadata.PDETradeRequestMeta:                   HistoryEventTrade,
+	metadata.PDETradeResponseMeta:                  HistoryEventTrade,
+	metadata.PDECrossPoolTradeRequestMeta:          HistoryEventTrade,
+	metadata.PDECrossPoolTradeResponseMeta:         HistoryEventTrade,
+	metadataCommon.Pdexv3TradeRequestMeta:          HistoryEventTrade,
+	metadataCommon.Pdexv3TradeResponseMeta:         HistoryEventTrade,
+	metadataCommon.Pdexv3AddOrderRequestMeta:       HistoryEventTrade,
+	metadataCommon.Pdexv3AddOrderResponseMeta:      HistoryEventTrade,
+	metadataCommon.Pdexv3WithdrawOrderRequestMeta:  HistoryEventTrade,
+	metadataCommon.Pdexv3WithdrawOrderResponseMeta: HistoryEventTrade,
+
+	// Shield
+	metadata.IssuingRequestMeta:            HistoryEventShield,
+	metadata.IssuingResponseMeta:           HistoryEventShield,
+	metadata.IssuingETHRequestMeta:         HistoryEventShield,
+	metadata.IssuingETHResponseMeta:        HistoryEventShield,
+	metadata.IssuingBSCRequestMeta:         HistoryEventShield,
+	metadata.IssuingBSCResponseMeta:        HistoryEventShield,
+	metadata.IssuingPLGRequestMeta:         HistoryEventShield,
+	metadata.IssuingPLGResponseMeta:        HistoryEventShield,
+	metadata.IssuingPRVERC20RequestMeta:    HistoryEventShield,
+	metadata.IssuingPRVERC20ResponseMeta:   HistoryEventShield,
+	metadata.IssuingPRVBEP20RequestMeta:    HistoryEventShield,
+	metadata.IssuingPRVBEP20ResponseMeta:   HistoryEventShield,
+	metadata.PortalV4ShieldingRequestMeta:  HistoryEventShield,
+	metadata.PortalV4ShieldingResponseMeta: HistoryEventShield,
+
+	// Unshield
+	metadata.BurningRequestMeta:                   HistoryEventUnshield,
+	metadata.BurningRequestMetaV2:                 HistoryEventUnshield,
+	metadata.ContractingRequestMeta:               HistoryEventUnshield,
+	metadata.BurningPBSCRequestMeta:               HistoryEventUnshield,
+	metadata.BurningPLGRequestMeta:                HistoryEventUnshield,
+	metadata.BurningForDepositToSCRequestMeta:     HistoryEventUnshield,
+	metadata.BurningForDepositToSCRequestMetaV2:   HistoryEventUnshield,
+	metadata.BurningPBSCForDepositToSCRequestMeta: HistoryEventUnshield,
+	metadata.BurningPLGForDepositToSCRequestMeta:  HistoryEventUnshield,
+	metadata.PortalV4UnshieldingRequestMeta:       HistoryEventUnshield,
+	metadata.PortalV4UnshieldingResponseMeta:      HistoryEventUnshield,
+
+	// Liquidity
+	metadata.PDEContributionMeta:                       HistoryEventLiquidityAdd,
+	metadata.PDEPRVRequiredContributionRequestMeta:     HistoryEventLiquidityAdd,
+	metadata.PDEContributionResponseMeta:               HistoryEventLiquidityAdd,
+	metadataCommon.Pdexv3AddLiquidityRequestMeta:       HistoryEventLiquidityAdd,
+	metadataCommon.Pdexv3AddLiquidityResponseMeta:      HistoryEventLiquidityAdd,
+	metadata.PDEWithdrawalRequestMeta:                  HistoryEventLiquidityWithdraw,
+	metadata.PDEWithdrawalResponseMeta:                 HistoryEventLiquidityWithdraw,
+	metadataCommon.Pdexv3WithdrawLiquidityRequestMeta:  HistoryEventLiquidityWithdraw,
+	metadataCommon.Pdexv3WithdrawLiquidityResponseMeta: HistoryEventLiquidityWithdraw,
+
+	// Staking
+	metadata.ShardStakingMeta:                  HistoryEventStaking,
+	metadata.BeaconStakingMeta:                 HistoryEventStaking,
+	metadata.UnStakingMeta:                     HistoryEventStaking,
+	metadata.StopAutoStakingMeta:               HistoryEventStaking,
+	metadataCommon.Pdexv3StakingRequestMeta:    HistoryEventStaking,
+	metadataCommon.Pdexv3StakingResponseMeta:   HistoryEventStaking,
+	metadataCommon.Pdexv3UnstakingRequestMeta:  HistoryEventStaking,
+	metadataCommon.Pdexv3UnstakingResponseMeta: HistoryEventStaking,
+
+	// Rewards
+	metadata.WithDrawRewardRequestMeta:                     HistoryEventStakingReward,
+	metadata.WithDrawRewardResponseMeta:                    HistoryEventStakingReward,
+	metadata.PDEFeeWithdrawalRequestMeta:                   HistoryEventStakingReward,
+	metadata.PDEFeeWithdrawalResponseMeta:                  HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawLPFeeRequestMeta:          HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawLPFeeResponseMeta:         HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawStakingRewardRequestMeta:  HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawStakingRewardResponseMeta: HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawProtocolFeeRequestMeta:    HistoryEventStakingReward,
+	metadataCommon.Pdexv3WithdrawProtocolFeeResponseMeta:   HistoryEventStakingReward,
+}
+
+// historyRequestTxIDKeys are the JSON keys under which response metadata reference their request transactions.
+var historyRequestTxIDKeys = []string{"RequestedTxID", "RequestTxID", "TxReqID", "ReqTxID", "TxRequest"}
+
+// HistoryEvent is a typed action of an account, built from one request transaction and its responses.
+type HistoryEvent interface {
+	// GetType returns the type of the event (HistoryEventTrade, HistoryEventShield, ...).
+	GetType() string
+
+	// GetLockTime returns the lock-time of the transaction initiating the event.
+	GetLockTime() int64
+
+	// GetTxHashes returns the hashes of all transactions making up the event, the request first.
+	GetTxHashes() []string
+
+	// GetBalanceChanges returns the net effect of the event on the balance of each token, fees included.
+	GetBalanceChanges() map[string]int64
+
+	String() string
+}
+
+// HistoryEventBase holds the fields common to all HistoryEvent's.
+type HistoryEventBase struct {
+	Type             string
+	LockTime         int64
+	RequestTxHash    string
+	ResponseTxHashes []string
+	MetadataType     int
+	Fee              uint64
+	FeeTokenID       string
+	BalanceChanges   map[string]int64
+	Status           string
+	Note             string
+}
+
+// GetType returns the type of the event.
+func (e HistoryEventBase) GetType() string {
+	return e.Type
+}
+
+// GetLockTime returns the lock-time of the event.
+func (e HistoryEventBase) GetLockTime() int64 {
+	return e.LockTime
+}
+
+// GetTxHashes returns the hashes of all transactions making up the event.
+func (e HistoryEventBase) GetTxHashes() []string {
+	res := make([]string, 0)
+	if e.RequestTxHash != "" {
+		res = append(res, e.RequestTxHash)
+	}
+	return append(res, e.ResponseTxHashes...)
+}
+
+// GetBalanceChanges returns the net effect of the event on the balance of each token.
+func (e HistoryEventBase) GetBalanceChanges() map[string]int64 {
+	return e.BalanceChanges
+}
+
+// String returns the string-representation of the event.
+func (e HistoryEventBase) String() string {
+	return e.format(describeBalanceChanges(e.BalanceChanges))
+}
+
+func (e HistoryEventBase) format(detail string) string {
+	lockTimeStr := time.Unix(e.LockTime, 0).Format(common.DateOutputFormat)
+	res := fmt.Sprintf("[%v] Timestamp: %v, TxHash: %v, %v", e.Type, lockTimeStr, e.GetTxHashes()[0], detail)
+	if e.Status != "" {
+		res += fmt.Sprintf(", Status: %v", e.Status)
+	}
+	if e.Note != "" {
+		res += fmt.Sprintf(", Note: %v", e.Note)
+	}
+	return res
+}
+
+// TransferEvent is a plain transfer of a token, without metadata.
+type TransferEvent struct {
+	HistoryEventBase
+	Direction string
+	TokenID   string
+	Amount    uint64
+}
+
+// String returns the string-representation of the event.
+func (e TransferEvent) String() string {
+	verb := "received"
+	if e.Direction == TxDirectionOut {
+		verb = "sent"
+	}
+	return e.format(fmt.Sprintf("%v %v of %v", verb, e.Amount, e.TokenID))
+}
+
+// TradeEvent is a pDEX trade (or order), paired with its response(s).
+type TradeEvent struct {
+	HistoryEventBase
+	SellTokenID string
+	SellAmount  uint64
+	BuyTokenID  string
+	BuyAmount   uint64
+}
+
+// String returns the string-representation of the event.
+func (e TradeEvent) String() string {
+	if e.BuyTokenID == "" {
+		return e.format(fmt.Sprintf("sold %v of %v", e.SellAmount, e.SellTokenID))
+	}
+	return e.format(fmt.Sprintf("swapped %v of %v for %v of %v", e.SellAmount, e.SellTokenID, e.BuyAmount, e.BuyTokenID))
+}
+
+// ShieldEvent is a shielding request paired with the minting response.
+type ShieldEvent struct {
+	HistoryEventBase
+	TokenID string
+	Amount  uint64
+}
+
+// String returns the string-representation of the event.
+func (e ShieldEvent) String() string {
+	return e.format(fmt.Sprintf("shielded %v of %v", e.Amount, e.TokenID))
+}
+
+// UnshieldEvent is an unshielding (burning) request, paired with its response if any.
+type UnshieldEvent struct {
+	HistoryEventBase
+	TokenID       string
+	Amount        uint64
+	RemoteAddress string
+}
+
+// String returns the string-representation of the event.
+func (e UnshieldEvent) String() string {
+	detail := fmt.Sprintf("unshielded %v of %v", e.Amount, e.TokenID)
+	if e.RemoteAddress != "" {
+		detail += fmt.Sprintf(" to %v", e.RemoteAddress)
+	}
+	return e.format(detail)
+}
+
+// LiquidityEvent is a pDEX contribution or withdrawal, paired with its response(s).
+// Contributed and Withdrawn hold the net amounts that left or entered the account for each token.
+type LiquidityEvent struct {
+	HistoryEventBase
+	Contributed map[string]uint64
+	Withdrawn   map[string]uint64
+}
+
+// String returns the string-representation of the event.
+func (e LiquidityEvent) String() string {
+	detail := make([]string, 0)
+	if len(e.Contributed) > 0 {
+		detail = append(detail, fmt.Sprintf("contributed %v", describeAmounts(e.Contributed)))
+	}
+	if len(e.Withdrawn) > 0 {
+		detail = append(detail, fmt.Sprintf("withdrew %v", describeAmounts(e.Withdrawn)))
+	}
+	return e.format(strings.Join(detail, ", "))
+}
+
+// StakingEvent is a (pDEX or committee) staking or un-staking action.
+type StakingEvent struct {
+	HistoryEventBase
+}
+
+// StakingRewardEvent is the withdrawal of staking rewards, LP fees or pDEX staking rewards.
+type StakingRewardEvent struct {
+	HistoryEventBase
+	Rewards map[string]uint64
+}
+
+// String returns the string-representation of the event.
+func (e StakingRewardEvent) String() string {
+	return e.format(fmt.Sprintf("received rewards %v", describeAmounts(e.Rewards)))
+}
+
+// historyTxGroup gathers the history entries of all tokens belonging to the same transaction.
+type historyTxGroup struct {
+	txHash   string
+	lockTime int64
+	metadata metadata.Metadata
+	note     string
+	ins      map[string]TxIn
+	outs     map[string]TxOut
+
+	// the request transaction hash if this group is a response
+	requestTxHash string
+	responses     []*historyTxGroup
+}
+
+// getMetadataType returns the metadata type of the group.
+func (g *historyTxGroup) getMetadataType() int {
+	if g.metadata == nil {
+		return metadata.InvalidMeta
+	}
+	return g.metadata.GetType()
+}
+
+// NewHistoryEvents builds typed events from the histories of several tokens (indexed by tokenIDs), e.g. the output
+// of TxHistoryProcessor.GetAllHistory. Response transactions are paired with the request transactions they refer to,
+// so that a trade shows up as a single TradeEvent instead of an out-going and an in-coming transfer. Responses whose
+// request is not part of the histories make up events on their own.
+//
+// The histories of all tokens involved must be provided for the balance changes to be complete. Events are sorted by
+// lock-time, latest first.
+func NewHistoryEvents(histories map[string]*TxHistory) []HistoryEvent {
+	groups := make(map[string]*historyTxGroup)
+	getGroup := func(txHash string, lockTime int64, md metadata.Metadata, note string) *historyTxGroup {
+		g, ok := groups[txHash]
+		if !ok {
+			g = &historyTxGroup{
+				txHash:   txHash,
+				lockTime: lockTime,
+				ins:      make(map[string]TxIn),
+				outs:     make(map[string]TxOut),
+			}
+			groups[txHash] = g
+		}
+		if g.metadata == nil && md != nil {
+			g.metadata = md
+		}
+		if g.note == "" {
+			g.note = strings.TrimSpace(strings.TrimSuffix(note, "(Tx Fee)"))
+		}
+		return g
+	}
+
+	for tokenIDStr, txHistory := range histories {
+		if txHistory == nil {
+			continue
+		}
+		for _, txIn := range txHistory.TxInList {
+			getGroup(txIn.TxHash, txIn.LockTime, txIn.Metadata, txIn.Note).ins[tokenIDStr] = txIn
+		}
+		for _, txOut := range txHistory.TxOutList {
+			getGroup(txOut.TxHash, txOut.LockTime, txOut.Metadata, txOut.Note).outs[tokenIDStr] = txOut
+		}
+	}
+
+	// pair responses with their requests
+	for _, g := range groups {
+		if len(g.outs) != 0 {
+			continue
+		}
+		g.requestTxHash = getHistoryRequestTxHash(g.metadata)
+		if req, ok := groups[g.requestTxHash]; ok && req != g {
+			req.responses = append(req.responses, g)
+		}
+	}
+
+	res := make([]HistoryEvent, 0)
+	for _, g := range groups {
+		if g.requestTxHash != "" {
+			if req, ok := groups[g.requestTxHash]; ok && req != g {
+				continue // already part of its request
+			}
+		}
+		res = append(res, newHistoryEvent(g))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].GetLockTime() != res[j].GetLockTime() {
+			return res[i].GetLockTime() > res[j].GetLockTime()
+		}
+		return res[i].GetTxHashes()[0] < res[j].GetTxHashes()[0]
+	})
+
+	return res
+}
+
+// newHistoryEvent creates the typed event of a transaction group (with its responses).
+func newHistoryEvent(g *historyTxGroup) HistoryEvent {
+	sort.Slice(g.responses, func(i, j int) bool {
+		if g.responses[i].lockTime != g.responses[j].lockTime {
+			return g.responses[i].lockTime < g.responses[j].lockTime
+		}
+		return g.responses[i].txHash < g.responses[j].txHash
+	})
+
+	base := HistoryEventBase{
+		Type:             getHistoryEventType(g.getMetadataType()),
+		LockTime:         g.lockTime,
+		MetadataType:     g.getMetadataType(),
+		BalanceChanges:   make(map[string]int64),
+		ResponseTxHashes: make([]string, 0),
+		Note:             g.note,
+	}
+	if g.requestTxHash != "" {
+		// a stand-alone response
+		base.ResponseTxHashes = append(base.ResponseTxHashes, g.txHash)
+	} else {
+		base.RequestTxHash = g.txHash
+	}
+
+	// the amounts spent by the request, and received from the responses (or the transaction itself)
+	spent := make(map[string]uint64)
+	received := make(map[string]uint64)
+	for tokenIDStr, txOut := range g.outs {
+		spent[tokenIDStr] += txOut.Amount
+		base.BalanceChanges[tokenIDStr] -= int64(txOut.Amount)
+		if txOut.TokenFee > 0 {
+			base.Fee, base.FeeTokenID = txOut.TokenFee, tokenIDStr
+		} else if base.FeeTokenID == "" || tokenIDStr == common.PRVIDStr {
+			base.Fee, base.FeeTokenID = txOut.PRVFee, common.PRVIDStr
+		}
+	}
+	if base.Fee > 0 {
+		base.BalanceChanges[base.FeeTokenID] -= int64(base.Fee)
+	}
+	addReceived := func(ins map[string]TxIn) {
+		for tokenIDStr, txIn := range ins {
+			received[tokenIDStr] += txIn.Amount
+			base.BalanceChanges[tokenIDStr] += int64(txIn.Amount)
+		}
+	}
+	addReceived(g.ins)
+	for _, resp := range g.responses {
+		base.ResponseTxHashes = append(base.ResponseTxHashes, resp.txHash)
+		addReceived(resp.ins)
+	}
+	for tokenIDStr, change := range base.BalanceChanges {
+		if change == 0 {
+			delete(base.BalanceChanges, tokenIDStr)
+		}
+	}
+
+	hasResponse := len(g.responses) > 0 || g.requestTxHash != ""
+	if base.Type != HistoryEventTransfer && base.Type != HistoryEventOther {
+		base.Status = HistoryEventStatusPending
+		if hasResponse {
+			base.Status = HistoryEventStatusSuccess
+		}
+	}
+
+	switch base.Type {
+	case HistoryEventTransfer:
+		event := TransferEvent{HistoryEventBase: base, Direction: TxDirectionIn}
+		event.TokenID, event.Amount = getMainToken(received)
+		if len(g.outs) > 0 {
+			event.Direction = TxDirectionOut
+			event.TokenID, event.Amount = getMainToken(spent)
+		}
+		return event
+
+	case HistoryEventTrade:
+		event := TradeEvent{HistoryEventBase: base}
+		event.SellTokenID, event.SellAmount = getMainToken(spent)
+		for tokenIDStr, amount := range received {
+			if tokenIDStr != event.SellTokenID && amount > event.BuyAmount {
+				event.BuyTokenID, event.BuyAmount = tokenIDStr, amount
+			}
+		}
+		if hasResponse && event.BuyTokenID == "" && received[event.SellTokenID] > 0 {
+			event.Status = HistoryEventStatusRefunded
+		}
+		return event
+
+	case HistoryEventShield:
+		event := ShieldEvent{HistoryEventBase: base}
+		event.TokenID, event.Amount = getMainToken(received)
+		return event
+
+	case HistoryEventUnshield:
+		event := UnshieldEvent{HistoryEventBase: base, RemoteAddress: getHistoryCounterparty(g.metadata)}
+		event.TokenID, event.Amount = getMainToken(spent)
+		if !hasResponse && event.MetadataType != metadata.PortalV4UnshieldingRequestMeta {
+			// bridge unshields have no response transaction; they are confirmed on the other chain.
+			event.Status = ""
+		}
+		if event.TokenID == "" {
+			event.TokenID, event.Amount = getMainToken(received)
+		}
+		return event
+
+	case HistoryEventLiquidityAdd, HistoryEventLiquidityWithdraw:
+		event := LiquidityEvent{HistoryEventBase: base, Contributed: make(map[string]uint64), Withdrawn: make(map[string]uint64)}
+		for tokenIDStr, amount := range spent {
+			if amount > received[tokenIDStr] {
+				event.Contributed[tokenIDStr] = amount - received[tokenIDStr]
+			}
+		}
+		for tokenIDStr, amount := range received {
+			if amount > spent[tokenIDStr] {
+				event.Withdrawn[tokenIDStr] = amount - spent[tokenIDStr]
+			}
+		}
+		if base.Type == HistoryEventLiquidityAdd && hasResponse && len(event.Withdrawn) > 0 {
+			event.Status = HistoryEventStatusRefunded
+		}
+		return event
+
+	case HistoryEventStaking:
+		return StakingEvent{HistoryEventBase: base}
+
+	case HistoryEventStakingReward:
+		return StakingRewardEvent{HistoryEventBase: base, Rewards: received}
+
+	default:
+		return base
+	}
+}
+
+// getHistoryEventType returns the event type of a metadata type.
+func getHistoryEventType(metaType int) string {
+	if res, ok := historyEventTypes[metaType]; ok {
+		return res
+	}
+	return HistoryEventOther
+}
+
+// getHistoryRequestTxHash returns the hash of the request transaction a response metadata refers to, if any.
+func getHistoryRequestTxHash(md metadata.Metadata) string {
+	if md == nil {
+		return ""
+	}
+	jsb, err := json.Marshal(md)
+	if err != nil {
+		return ""
+	}
+	fields := make(map[string]interface{})
+	if err = json.Unmarshal(jsb, &fields); err != nil {
+		return ""
+	}
+
+	for _, key := range historyRequestTxIDKeys {
+		if txHash, ok := fields[key].(string); ok && txHash != "" && txHash != (common.Hash{}).String() {
+			return txHash
+		}
+	}
+	return ""
+}
+
+// getMainToken returns the token with the largest amount (PRV last, as it is usually only spent on fees).
+func getMainToken(amounts map[string]uint64) (string, uint64) {
+	tokenIDs := make([]string, 0)
+	for tokenIDStr, amount := range amounts {
+		if amount > 0 {
+			tokenIDs = append(tokenIDs, tokenIDStr)
+		}
+	}
+	if len(tokenIDs) == 0 {
+		return "", 0
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool {
+		if (tokenIDs[i] == common.PRVIDStr) != (tokenIDs[j] == common.PRVIDStr) {
+			return tokenIDs[j] == common.PRVIDStr
+		}
+		if amounts[tokenIDs[i]] != amounts[tokenIDs[j]] {
+			return amounts[tokenIDs[i]] > amounts[tokenIDs[j]]
+		}
+		return tokenIDs[i] < tokenIDs[j]
+	})
+
+	return tokenIDs[0], amounts[tokenIDs[0]]
+}
+
+// describeAmounts returns a deterministic string-representation of a map of amounts.
+func describeAmounts(amounts map[string]uint64) string {
+	tokenIDs := make([]string, 0)
+	for tokenIDStr := range amounts {
+		tokenIDs = append(tokenIDs, tokenIDStr)
+	}
+	sort.Strings(tokenIDs)
+
+	parts := make([]string, 0)
+	for _, tokenIDStr := range tokenIDs {
+		parts = append(parts, fmt.Sprintf("%v of %v", amounts[tokenIDStr], tokenIDStr))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeBalanceChanges returns a deterministic string-representation of balance changes.
+func describeBalanceChanges(changes map[string]int64) string {
+	tokenIDs := make([]string, 0)
+	for tokenIDStr := range changes {
+		tokenIDs = append(tokenIDs, tokenIDStr)
+	}
+	sort.Strings(tokenIDs)
+
+	parts := make([]string, 0)
+	for _, tokenIDStr := range tokenIDs {
+		parts = append(parts, fmt.Sprintf("%+d of %v", changes[tokenIDStr], tokenIDStr))
+	}
+	return fmt.Sprintf("balance changes: [%v]", strings.Join(parts, ", "))
+}
@@ -0,0 +1,111 @@
+package incclient
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata/pdexv3"
+)
+
+func TestNewHistoryEvents(t *testing.T) {
+	tokenID := testHistoryTokenID
+	tradeReqHash := common.HashH([]byte("trade"))
+	refundedReqHash := common.HashH([]byte("refunded"))
+	shieldReqHash := common.HashH([]byte("shield"))
+
+	tradeReq := &pdexv3.TradeRequest{
+		TokenToSell:  common.PRVCoinID,
+		SellAmount:   1000,
+		TradingFee:   10,
+		MetadataBase: metadataCommon.MetadataBase{Type: metadataCommon.Pdexv3TradeRequestMeta},
+	}
+	histories := map[string]*TxHistory{
+		common.PRVIDStr: {
+			TxInList: []TxIn{
+				{LockTime: 10, TxHash: "transfer", Amount: 5000},
+				// the refund of a trade
+				{LockTime: 35, TxHash: "refund", Amount: 1010, Metadata: &pdexv3.TradeResponse{
+					Status:       1,
+					RequestTxID:  refundedReqHash,
+					MetadataBase: metadataCommon.MetadataBase{Type: metadataCommon.Pdexv3TradeResponseMeta},
+				}},
+			},
+			TxOutList: []TxOut{
+				{LockTime: 20, TxHash: tradeReqHash.String(), Amount: 1010, PRVFee: 100, Metadata: tradeReq},
+				{LockTime: 30, TxHash: refundedReqHash.String(), Amount: 1010, PRVFee: 100, Metadata: tradeReq},
+				{LockTime: 40, TxHash: "burn", Amount: 0, PRVFee: 100, Note: "(Tx Fee)", Metadata: &metadata.BurningRequest{
+					RemoteAddress: "0xabc",
+					MetadataBase:  metadata.MetadataBase{Type: metadata.BurningRequestMetaV2},
+				}},
+			},
+		},
+		tokenID: {
+			TxInList: []TxIn{
+				{LockTime: 25, TxHash: "tradeResponse", Amount: 320, Metadata: &pdexv3.TradeResponse{
+					Status:       0,
+					RequestTxID:  tradeReqHash,
+					MetadataBase: metadataCommon.MetadataBase{Type: metadataCommon.Pdexv3TradeResponseMeta},
+				}},
+				// a shield whose request was submitted by someone else
+				{LockTime: 50, TxHash: "shieldResponse", Amount: 77, Metadata: &metadata.IssuingEVMResponse{
+					RequestedTxID: shieldReqHash,
+					MetadataBase:  metadata.MetadataBase{Type: metadata.IssuingETHResponseMeta},
+				}},
+			},
+			TxOutList: []TxOut{
+				{LockTime: 40, TxHash: "burn", Amount: 60, PRVFee: 100, Metadata: &metadata.BurningRequest{
+					RemoteAddress: "0xabc",
+					MetadataBase:  metadata.MetadataBase{Type: metadata.BurningRequestMetaV2},
+				}},
+			},
+		},
+	}
+
+	events := NewHistoryEvents(histories)
+	if len(events) != 5 {
+		panic(fmt.Sprintf("expected 5 events, got %v", len(events)))
+	}
+
+	shield, ok := events[0].(ShieldEvent)
+	if !ok || shield.TokenID != tokenID || shield.Amount != 77 || shield.Status != HistoryEventStatusSuccess {
+		panic(fmt.Sprintf("unexpected shield event %+v", events[0]))
+	}
+
+	unshield, ok := events[1].(UnshieldEvent)
+	if !ok || unshield.TokenID != tokenID || unshield.Amount != 60 || unshield.RemoteAddress != "0xabc" {
+		panic(fmt.Sprintf("unexpected unshield event %+v", events[1]))
+	}
+	if unshield.BalanceChanges[tokenID] != -60 || unshield.BalanceChanges[common.PRVIDStr] != -100 {
+		panic(fmt.Sprintf("unexpected unshield balance changes %v", unshield.BalanceChanges))
+	}
+
+	refunded, ok := events[2].(TradeEvent)
+	if !ok || refunded.Status != HistoryEventStatusRefunded || len(refunded.GetTxHashes()) != 2 {
+		panic(fmt.Sprintf("unexpected refunded trade %+v", events[2]))
+	}
+	if refunded.BalanceChanges[common.PRVIDStr] != -100 {
+		panic(fmt.Sprintf("a refunded trade should only cost the fee, got %v", refunded.BalanceChanges))
+	}
+
+	trade, ok := events[3].(TradeEvent)
+	if !ok || trade.Status != HistoryEventStatusSuccess {
+		panic(fmt.Sprintf("unexpected trade %+v", events[3]))
+	}
+	if trade.SellTokenID != common.PRVIDStr || trade.SellAmount != 1010 || trade.BuyTokenID != tokenID || trade.BuyAmount != 320 {
+		panic(fmt.Sprintf("unexpected trade amounts %+v", trade))
+	}
+	if hashes := trade.GetTxHashes(); len(hashes) != 2 || hashes[0] != tradeReqHash.String() || hashes[1] != "tradeResponse" {
+		panic(fmt.Sprintf("unexpected trade hashes %v", hashes))
+	}
+	if trade.Fee != 100 || trade.BalanceChanges[common.PRVIDStr] != -1110 || trade.BalanceChanges[tokenID] != 320 {
+		panic(fmt.Sprintf("unexpected trade balance changes %v", trade.BalanceChanges))
+	}
+
+	transfer, ok := events[4].(TransferEvent)
+	if !ok || transfer.Direction != TxDirectionIn || transfer.Amount != 5000 || transfer.TokenID != common.PRVIDStr {
+		panic(fmt.Sprintf("unexpected transfer %+v", events[4]))
+	}
+}
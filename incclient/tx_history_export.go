@@ -0,0 +1,420 @@
+package incclient
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+)
+
+const (
+	TxHistoryFormatCSV       = "csv"
+	TxHistoryFormatJSONLines = "jsonl"
+	TxHistoryFormatOFX       = "ofx"
+)
+
+// DefaultTxHistoryWorkers is the number of workers used to retrieve the history of all tokens when exporting.
+const DefaultTxHistoryWorkers = 10
+
+// DefaultTokenDecimals is the number of decimals of PRV, and of most tokens on the Incognito network. It is used when the
+// decimals of a token are not given.
+const DefaultTokenDecimals = 9
+
+// maxOFXNameLength is the maximum length of the NAME field of an OFX transaction.
+const maxOFXNameLength = 32
+
+const (
+	TxDirectionIn  = "in"
+	TxDirectionOut = "out"
+)
+
+// txHistoryCSVHeader is the header line of an exported CSV history.
+var txHistoryCSVHeader = []string{
+	"Timestamp", "TxHash", "Direction", "TokenID", "TokenSymbol", "Amount", "Fee", "FeeTokenID", "Counterparty", "Note", "Balance",
+}
+
+// TxHistoryRecord is a single line of an exported transaction history.
+//
+// Amounts are expressed in the smallest unit of the token; Decimals is the number of decimals of TokenID, used to
+// express them in whole tokens where needed (e.g, OFX). The Fee is paid in FeeTokenID and is only deducted from
+// the running Balance when FeeTokenID equals TokenID. The PRV fee of a token transaction is carried by the PRV record
+// of the transaction only, so that it is not counted twice. The Balance is the running balance of TokenID right after the
+// record; it is signed so that an incomplete history shows up as a negative balance instead of wrapping around.
+type TxHistoryRecord struct {
+	Timestamp    int64
+	TxHash       string
+	Direction    string
+	TokenID      string
+	TokenSymbol  string
+	Amount       uint64
+	Fee          uint64
+	FeeTokenID   string
+	Counterparty string
+	Note         string
+	Balance      int64
+	Decimals     uint8
+}
+
+// TxHistoryFilter specifies which records to keep when exporting a transaction history.
+// Zero values mean no filtering.
+type TxHistoryFilter struct {
+	// TokenIDs is the list of tokenIDs to export.
+	TokenIDs []string
+
+	// From is the (inclusive) lower bound on the time of a record.
+	From time.Time
+
+	// To is the (exclusive) upper bound on the time of a record.
+	To time.Time
+}
+
+// isTokenIncluded checks if a tokenID passes the filter.
+func (f *TxHistoryFilter) isTokenIncluded(tokenIDStr string) bool {
+	if f == nil || len(f.TokenIDs) == 0 {
+		return true
+	}
+	for _, tokenID := range f.TokenIDs {
+		if tokenID == tokenIDStr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTimeIncluded checks if a lock-time passes the filter.
+func (f *TxHistoryFilter) isTimeIncluded(lockTime int64) bool {
+	if f == nil {
+		return true
+	}
+	t := time.Unix(lockTime, 0)
+	if !f.From.IsZero() && t.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !t.Before(f.To) {
+		return false
+	}
+
+	return true
+}
+
+// NewTxHistoryRecords flattens the histories of several tokens (indexed by tokenIDs) into a list of records sorted
+// by time, computing the running balance of each token along the way. Symbols maps tokenIDs to their symbols and
+// can be nil. Decimals maps tokenIDs to their numbers of decimals and can be nil; missing tokens default to
+// DefaultTokenDecimals.
+//
+// The running balances are computed over the whole history before the date-range filter is applied, so that the
+// first exported record of a range still carries the right balance.
+func NewTxHistoryRecords(
+	histories map[string]*TxHistory, symbols map[string]string, decimals map[string]uint8, filter *TxHistoryFilter,
+) []TxHistoryRecord {
+	all := make([]TxHistoryRecord, 0)
+	for tokenIDStr, txHistory := range histories {
+		if txHistory == nil || !filter.isTokenIncluded(tokenIDStr) {
+			continue
+		}
+		tokenDecimals, ok := decimals[tokenIDStr]
+		if !ok {
+			tokenDecimals = DefaultTokenDecimals
+		}
+		for _, txIn := range txHistory.TxInList {
+			all = append(all, TxHistoryRecord{
+				Timestamp:    txIn.LockTime,
+				TxHash:       txIn.TxHash,
+				Direction:    TxDirectionIn,
+				TokenID:      tokenIDStr,
+				TokenSymbol:  symbols[tokenIDStr],
+				Amount:       txIn.Amount,
+				Counterparty: getHistoryCounterparty(txIn.Metadata),
+				Note:         txIn.Note,
+				Decimals:     tokenDecimals,
+			})
+		}
+		for _, txOut := range txHistory.TxOutList {
+			record := TxHistoryRecord{
+				Timestamp:    txOut.LockTime,
+				TxHash:       txOut.TxHash,
+				Direction:    TxDirectionOut,
+				TokenID:      tokenIDStr,
+				TokenSymbol:  symbols[tokenIDStr],
+				Amount:       txOut.Amount,
+				Counterparty: getHistoryCounterparty(txOut.Metadata),
+				Note:         txOut.Note,
+				Decimals:     tokenDecimals,
+			}
+			// the PRV fee of a token transaction also appears in the PRV history, so it is only reported there.
+			if txOut.TokenFee > 0 {
+				record.Fee = txOut.TokenFee
+				record.FeeTokenID = tokenIDStr
+			} else if tokenIDStr == common.PRVIDStr {
+				record.Fee = txOut.PRVFee
+				record.FeeTokenID = common.PRVIDStr
+			}
+			all = append(all, record)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Timestamp != all[j].Timestamp {
+			return all[i].Timestamp < all[j].Timestamp
+		}
+		if all[i].TokenID != all[j].TokenID {
+			return all[i].TokenID < all[j].TokenID
+		}
+		if all[i].TxHash != all[j].TxHash {
+			return all[i].TxHash < all[j].TxHash
+		}
+		return all[i].Direction < all[j].Direction
+	})
+
+	balances := make(map[string]int64)
+	res := make([]TxHistoryRecord, 0)
+	for _, record := range all {
+		balance := balances[record.TokenID]
+		if record.Direction == TxDirectionIn {
+			balance += int64(record.Amount)
+		} else {
+			balance -= int64(record.Amount)
+			if record.FeeTokenID == record.TokenID {
+				balance -= int64(record.Fee)
+			}
+		}
+		balances[record.TokenID] = balance
+		record.Balance = balance
+
+		if filter.isTimeIncluded(record.Timestamp) {
+			res = append(res, record)
+		}
+	}
+
+	return res
+}
+
+// getHistoryCounterparty returns the counterparty of a history action if it can be read from its metadata.
+func getHistoryCounterparty(md metadata.Metadata) string {
+	switch m := md.(type) {
+	case *metadata.BurningRequest:
+		return m.RemoteAddress
+	case *metadata.PortalUnshieldRequest:
+		return m.RemoteAddress
+	case *metadata.StakingMetadata:
+		return m.RewardReceiverPaymentAddress
+	default:
+		return ""
+	}
+}
+
+// WriteTxHistory writes a list of records to w in the given format (TxHistoryFormatCSV, TxHistoryFormatJSONLines,
+// or TxHistoryFormatOFX).
+func WriteTxHistory(w io.Writer, records []TxHistoryRecord, format string) error {
+	switch format {
+	case TxHistoryFormatCSV:
+		return exportTxHistoryCSV(w, records)
+	case TxHistoryFormatJSONLines:
+		return exportTxHistoryJSONLines(w, records)
+	case TxHistoryFormatOFX:
+		return exportTxHistoryOFX(w, records)
+	default:
+		return fmt.Errorf("history format %v not supported", format)
+	}
+}
+
+// SaveTxHistoryRecords writes a list of records to a file, overwriting it if it exists. If format is empty, it is
+// derived from the file extension.
+func SaveTxHistoryRecords(records []TxHistoryRecord, filePath, format string) error {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+		if format == "json" {
+			format = TxHistoryFormatJSONLines
+		}
+	}
+	switch format {
+	case TxHistoryFormatCSV, TxHistoryFormatJSONLines, TxHistoryFormatOFX:
+	default:
+		return fmt.Errorf("history format %v not supported", format)
+	}
+
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	err = WriteTxHistory(f, records, format)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	Logger.Printf("Finished storing %v history records to file %v\n", len(records), filePath)
+	return f.Close()
+}
+
+// ExportTxHistory retrieves the history (V2) of a private key and writes it to a file in the given format.
+// If the filter does not specify any tokenID, the history of all tokens the private key has interacted with is exported.
+// Decimals maps tokenIDs to their numbers of decimals (see NewTxHistoryRecords) and can be nil.
+func (client *IncClient) ExportTxHistory(
+	privateKey, filePath, format string, filter *TxHistoryFilter, decimals map[string]uint8,
+) error {
+	histories := make(map[string]*TxHistory)
+	if filter != nil && len(filter.TokenIDs) > 0 {
+		for _, tokenIDStr := range filter.TokenIDs {
+			txHistory, err := client.GetTxHistoryV2(privateKey, tokenIDStr)
+			if err != nil {
+				return fmt.Errorf("cannot get the history of token %v: %v", tokenIDStr, err)
+			}
+			histories[tokenIDStr] = txHistory
+		}
+	} else {
+		var err error
+		histories, err = NewTxHistoryProcessor(client, DefaultTxHistoryWorkers).GetAllHistory(privateKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	symbols, err := client.GetTokenSymbols()
+	if err != nil {
+		Logger.Printf("cannot get the token symbols: %v\n", err)
+		symbols = map[string]string{common.PRVIDStr: "PRV"}
+	}
+
+	return SaveTxHistoryRecords(NewTxHistoryRecords(histories, symbols, decimals, filter), filePath, format)
+}
+
+func exportTxHistoryCSV(w io.Writer, records []TxHistoryRecord) error {
+	csvWriter := csv.NewWriter(w)
+	err := csvWriter.Write(txHistoryCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		err = csvWriter.Write([]string{
+			time.Unix(record.Timestamp, 0).UTC().Format(time.RFC3339),
+			record.TxHash,
+			record.Direction,
+			record.TokenID,
+			record.TokenSymbol,
+			fmt.Sprintf("%v", record.Amount),
+			fmt.Sprintf("%v", record.Fee),
+			record.FeeTokenID,
+			record.Counterparty,
+			record.Note,
+			fmt.Sprintf("%v", record.Balance),
+		})
+		if err != nil {
+			return fmt.Errorf("write txHash %v error: %v", record.TxHash, err)
+		}
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+func exportTxHistoryJSONLines(w io.Writer, records []TxHistoryRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		err := encoder.Encode(record)
+		if err != nil {
+			return fmt.Errorf("write txHash %v error: %v", record.TxHash, err)
+		}
+	}
+
+	return nil
+}
+
+// exportTxHistoryOFX writes the records as an OFX 2 document, with one bank statement per token.
+// Amounts are in whole tokens (scaled by the Decimals of the records), and the token is used as the account ID.
+func exportTxHistoryOFX(w io.Writer, records []TxHistoryRecord) error {
+	tokenIDs := make([]string, 0)
+	recordsByToken := make(map[string][]TxHistoryRecord)
+	for _, record := range records {
+		if _, ok := recordsByToken[record.TokenID]; !ok {
+			tokenIDs = append(tokenIDs, record.TokenID)
+		}
+		recordsByToken[record.TokenID] = append(recordsByToken[record.TokenID], record)
+	}
+	sort.Strings(tokenIDs)
+
+	ofxTime := func(lockTime int64) string {
+		return time.Unix(lockTime, 0).UTC().Format("20060102150405")
+	}
+	escape := func(s string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	sb.WriteString("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	sb.WriteString("<OFX>\n<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>")
+	sb.WriteString(fmt.Sprintf("<DTSERVER>%v</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", ofxTime(time.Now().Unix())))
+	sb.WriteString("<BANKMSGSRSV1>\n")
+	for _, tokenIDStr := range tokenIDs {
+		tokenRecords := recordsByToken[tokenIDStr]
+		first, last := tokenRecords[0], tokenRecords[len(tokenRecords)-1]
+
+		sb.WriteString("<STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><STMTRS>\n")
+		sb.WriteString(fmt.Sprintf("<CURDEF>XXX</CURDEF><BANKACCTFROM><BANKID>INCOGNITO</BANKID><ACCTID>%v</ACCTID>"+
+			"<ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n", escape(tokenIDStr)))
+		sb.WriteString(fmt.Sprintf("<BANKTRANLIST><DTSTART>%v</DTSTART><DTEND>%v</DTEND>\n",
+			ofxTime(first.Timestamp), ofxTime(last.Timestamp)))
+		for _, record := range tokenRecords {
+			trnType := "CREDIT"
+			amount := int64(record.Amount)
+			if record.Direction == TxDirectionOut {
+				trnType = "DEBIT"
+				amount = -amount
+				if record.FeeTokenID == record.TokenID {
+					amount -= int64(record.Fee)
+				}
+			}
+			name := []rune(record.Counterparty)
+			if len(name) > maxOFXNameLength {
+				name = name[:maxOFXNameLength]
+			}
+			memo := record.Note
+			if record.TokenSymbol != "" {
+				memo = strings.TrimSpace(fmt.Sprintf("%v %v", record.TokenSymbol, memo))
+			}
+			sb.WriteString(fmt.Sprintf("<STMTTRN><TRNTYPE>%v</TRNTYPE><DTPOSTED>%v</DTPOSTED><TRNAMT>%v</TRNAMT>"+
+				"<FITID>%v-%v</FITID><NAME>%v</NAME><MEMO>%v</MEMO></STMTTRN>\n",
+				trnType, ofxTime(record.Timestamp), formatOFXAmount(amount, record.Decimals), record.TxHash, record.Direction,
+				escape(string(name)), escape(memo)))
+		}
+		sb.WriteString("</BANKTRANLIST>\n")
+		sb.WriteString(fmt.Sprintf("<LEDGERBAL><BALAMT>%v</BALAMT><DTASOF>%v</DTASOF></LEDGERBAL>\n",
+			formatOFXAmount(last.Balance, last.Decimals), ofxTime(last.Timestamp)))
+		sb.WriteString("</STMTRS></STMTTRNRS>\n")
+	}
+	sb.WriteString("</BANKMSGSRSV1>\n</OFX>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatOFXAmount formats an amount given in the smallest unit of a token as a decimal number of whole tokens.
+func formatOFXAmount(amount int64, decimals uint8) string {
+	sign := ""
+	absAmount := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		absAmount = uint64(-(amount + 1)) + 1
+	}
+
+	res := strconv.FormatUint(absAmount, 10)
+	if decimals == 0 {
+		return sign + res
+	}
+	if len(res) <= int(decimals) {
+		res = strings.Repeat("0", int(decimals)-len(res)+1) + res
+	}
+
+	return sign + res[:len(res)-int(decimals)] + "." + res[len(res)-int(decimals):]
+}
@@ -0,0 +1,204 @@
+package incclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+const testHistoryTokenID = "0000000000000000000000000000000000000000000000000000000000000115"
+
+func newTestTxHistories() map[string]*TxHistory {
+	return map[string]*TxHistory{
+		common.PRVIDStr: {
+			TxInList: []TxIn{
+				{LockTime: 100, TxHash: "tx1", Amount: 1000, TokenID: common.PRVIDStr},
+				{LockTime: 300, TxHash: "tx3", Amount: 500, TokenID: common.PRVIDStr, Note: "[Committee] Withdraw Reward Response"},
+			},
+			TxOutList: []TxOut{
+				{LockTime: 200, TxHash: "tx2", Amount: 300, TokenID: common.PRVIDStr, PRVFee: 100},
+				{LockTime: 400, TxHash: "tx4", Amount: 0, TokenID: common.PRVIDStr, PRVFee: 100, Note: "(Tx Fee)"},
+			},
+		},
+		testHistoryTokenID: {
+			TxInList: []TxIn{
+				{LockTime: 150, TxHash: "tx5", Amount: 70, TokenID: testHistoryTokenID},
+			},
+			TxOutList: []TxOut{
+				{LockTime: 400, TxHash: "tx4", Amount: 20, TokenID: testHistoryTokenID, PRVFee: 100},
+			},
+		},
+	}
+}
+
+func TestNewTxHistoryRecords(t *testing.T) {
+	symbols := map[string]string{common.PRVIDStr: "PRV", testHistoryTokenID: "TST"}
+	decimals := map[string]uint8{testHistoryTokenID: 6}
+	records := NewTxHistoryRecords(newTestTxHistories(), symbols, decimals, nil)
+	if len(records) != 6 {
+		panic(fmt.Sprintf("expected 6 records, got %v", len(records)))
+	}
+
+	expectedHashes := []string{"tx1", "tx5", "tx2", "tx3", "tx4", "tx4"}
+	expectedBalances := []int64{1000, 70, 600, 1100, 1000, 50}
+	for i, record := range records {
+		if record.TxHash != expectedHashes[i] || record.Balance != expectedBalances[i] {
+			panic(fmt.Sprintf("record %v: expected (%v, %v), got (%v, %v)", i,
+				expectedHashes[i], expectedBalances[i], record.TxHash, record.Balance))
+		}
+	}
+	// the PRV fee of a token transaction is only reported on its PRV record
+	prvOut, tokenOut := records[4], records[5]
+	if prvOut.TokenID != common.PRVIDStr || prvOut.FeeTokenID != common.PRVIDStr || prvOut.Fee != 100 {
+		panic(fmt.Sprintf("unexpected PRV record %+v", prvOut))
+	}
+	if prvOut.Decimals != DefaultTokenDecimals {
+		panic(fmt.Sprintf("unexpected PRV decimals %v", prvOut.Decimals))
+	}
+	if tokenOut.TokenSymbol != "TST" || tokenOut.FeeTokenID != "" || tokenOut.Fee != 0 || tokenOut.Decimals != 6 {
+		panic(fmt.Sprintf("unexpected token record %+v", tokenOut))
+	}
+
+	// the balance must account for records before the date range
+	filter := &TxHistoryFilter{TokenIDs: []string{common.PRVIDStr}, From: time.Unix(250, 0), To: time.Unix(400, 0)}
+	records = NewTxHistoryRecords(newTestTxHistories(), symbols, decimals, filter)
+	if len(records) != 1 || records[0].TxHash != "tx3" || records[0].Balance != 1100 {
+		panic(fmt.Sprintf("unexpected filtered records %+v", records))
+	}
+}
+
+func TestWriteTxHistory(t *testing.T) {
+	records := NewTxHistoryRecords(newTestTxHistories(), nil, map[string]uint8{testHistoryTokenID: 2}, nil)
+
+	buf := new(bytes.Buffer)
+	err := WriteTxHistory(buf, records, TxHistoryFormatCSV)
+	if err != nil {
+		panic(err)
+	}
+	lines, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+	if len(lines) != len(records)+1 || len(lines[0]) != len(txHistoryCSVHeader) {
+		panic("invalid CSV output")
+	}
+	if lines[3][1] != "tx2" || lines[3][2] != TxDirectionOut || lines[3][10] != "600" {
+		panic(fmt.Sprintf("unexpected CSV line %v", lines[3]))
+	}
+
+	buf.Reset()
+	err = WriteTxHistory(buf, records, TxHistoryFormatJSONLines)
+	if err != nil {
+		panic(err)
+	}
+	scanner := bufio.NewScanner(buf)
+	count := 0
+	for scanner.Scan() {
+		var record TxHistoryRecord
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			panic(err)
+		}
+		if record != records[count] {
+			panic(fmt.Sprintf("expected %+v, got %+v", records[count], record))
+		}
+		count++
+	}
+	if count != len(records) {
+		panic("invalid JSON-lines output")
+	}
+
+	buf.Reset()
+	longName := strings.Repeat("a", maxOFXNameLength) + "bcd"
+	records[0].Counterparty = longName
+	err = WriteTxHistory(buf, records, TxHistoryFormatOFX)
+	if err != nil {
+		panic(err)
+	}
+	ofx := buf.String()
+	if strings.Count(ofx, "<STMTRS>") != 2 || strings.Count(ofx, "<STMTTRN>") != len(records) {
+		panic("invalid OFX output")
+	}
+	// amounts are in whole tokens
+	for _, expected := range []string{"<TRNAMT>-0.000000400</TRNAMT>", "<BALAMT>0.000001000</BALAMT>",
+		"<TRNAMT>-0.20</TRNAMT>", "<BALAMT>0.50</BALAMT>"} {
+		if !strings.Contains(ofx, expected) {
+			panic(fmt.Sprintf("invalid OFX amounts: %v not found", expected))
+		}
+	}
+	if strings.Contains(ofx, longName) || !strings.Contains(ofx, "<NAME>"+longName[:maxOFXNameLength]+"</NAME>") {
+		panic("the OFX NAME must be truncated")
+	}
+
+	err = WriteTxHistory(buf, records, "xls")
+	if err == nil {
+		panic("expected an error")
+	}
+}
+
+func TestSaveTxHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tx-history")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// saving twice must overwrite the file rather than appending to it
+	filePath := filepath.Join(dir, "history.csv")
+	for i := 0; i < 2; i++ {
+		err = SaveTxHistory(newTestTxHistories()[common.PRVIDStr], filePath)
+		if err != nil {
+			panic(err)
+		}
+	}
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		panic(err)
+	}
+	if strings.Count(string(raw), "totalIn") != 1 {
+		panic("history file was not overwritten")
+	}
+
+	filePath = filepath.Join(dir, "history.jsonl")
+	err = SaveTxHistoryRecords(NewTxHistoryRecords(newTestTxHistories(), nil, nil, nil), filePath, "")
+	if err != nil {
+		panic(err)
+	}
+	raw, err = ioutil.ReadFile(filePath)
+	if err != nil {
+		panic(err)
+	}
+	if strings.Count(string(raw), "\n") != 6 {
+		panic("invalid JSON-lines file")
+	}
+}
+
+func TestFormatOFXAmount(t *testing.T) {
+	for _, tc := range []struct {
+		amount   int64
+		decimals uint8
+		expected string
+	}{
+		{0, 9, "0.000000000"},
+		{1500000000, 9, "1.500000000"},
+		{-25, 1, "-2.5"},
+		{-25, 0, "-25"},
+		{math.MinInt64, 0, "-9223372036854775808"},
+		{math.MaxInt64, 18, "9.223372036854775807"},
+	} {
+		if res := formatOFXAmount(tc.amount, tc.decimals); res != tc.expected {
+			panic(fmt.Sprintf("formatOFXAmount(%v, %v): expected %v, got %v", tc.amount, tc.decimals, tc.expected, res))
+		}
+	}
+}
@@ -247,6 +247,16 @@ func getTxFeeBy(tx metadata.Transaction) (uint64, bool) {
 
 // getTxInputAmount returns the total input amount and the amount of each input coins of a transaction.
 func getTxInputAmount(tx metadata.Transaction, tokenIDStr string, listTXOs map[string]coin.PlainCoin) (uint64, map[string]uint64, error) {
+	values := make(map[string]uint64)
+	for keyImageStr, outCoin := range listTXOs {
+		values[keyImageStr] = outCoin.GetValue()
+	}
+
+	return getTxInputAmountByValues(tx, tokenIDStr, values)
+}
+
+// getTxInputAmountByValues is the same as getTxInputAmount, given the values of the owned coins keyed by their key images.
+func getTxInputAmountByValues(tx metadata.Transaction, tokenIDStr string, values map[string]uint64) (uint64, map[string]uint64, error) {
 	listKeyImages, err := getListKeyImagesFromTx(tx, tokenIDStr)
 	if err != nil {
 		return 0, nil, err
@@ -255,9 +265,9 @@ func getTxInputAmount(tx metadata.Transaction, tokenIDStr string, listTXOs map[s
 	amount := uint64(0)
 	txInputs := make(map[string]uint64)
 	for keyImageStr := range listKeyImages {
-		if outCoin, ok := listTXOs[keyImageStr]; ok {
-			amount += outCoin.GetValue()
-			txInputs[keyImageStr] = outCoin.GetValue()
+		if value, ok := values[keyImageStr]; ok {
+			amount += value
+			txInputs[keyImageStr] = value
 		}
 	}
 
@@ -324,13 +334,31 @@ func isTxOut(tx metadata.Transaction, tokenIDStr string, listTXOs map[string]coi
 	return false, nil
 }
 
-// SaveTxHistory saves a TxHistory in a csv file.
+// isTxSpendingKeyImages checks if a transaction spends any of the given key images or not.
+func isTxSpendingKeyImages(tx metadata.Transaction, tokenIDStr string, keyImages map[string]uint8) (bool, error) {
+	listKeyImages, err := getListKeyImagesFromTx(tx, tokenIDStr)
+	if err != nil {
+		return false, err
+	}
+
+	for keyImageStr := range listKeyImages {
+		if _, ok := keyImages[keyImageStr]; ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// SaveTxHistory saves a TxHistory in a csv file, overwriting it if it exists.
+//
+// For an accounting-friendly output with one column per field, see IncClient.ExportTxHistory.
 func SaveTxHistory(txHistory *TxHistory, filePath string) error {
 	if len(filePath) == 0 {
 		filePath = DefaultTxHistory
 	}
 
-	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
@@ -339,8 +367,6 @@ func SaveTxHistory(txHistory *TxHistory, filePath string) error {
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
-	_ = f.Truncate(0)
-
 	totalIn := uint64(0)
 	for _, txIn := range txHistory.TxInList {
 		totalIn += txIn.Amount
@@ -350,6 +376,9 @@ func SaveTxHistory(txHistory *TxHistory, filePath string) error {
 		}
 	}
 	err = w.Write([]string{"totalIn", fmt.Sprintf("%v", totalIn)})
+	if err != nil {
+		return fmt.Errorf("cannot write csv file")
+	}
 
 	err = w.Write([]string{"-----", "-----"})
 	if err != nil {
@@ -365,6 +394,9 @@ func SaveTxHistory(txHistory *TxHistory, filePath string) error {
 		}
 	}
 	err = w.Write([]string{"totalOut", fmt.Sprintf("%v", totalOut)})
+	if err != nil {
+		return fmt.Errorf("cannot write csv file")
+	}
 
 	Logger.Printf("Finished storing history to file %v\n", filePath)
 	return nil
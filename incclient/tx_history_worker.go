@@ -1,8 +1,11 @@
 package incclient
 
 import (
+	"bytes"
 	"fmt"
 	"log"
+	"math/big"
+	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -13,6 +16,7 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
 	"github.com/incognitochain/go-incognito-sdk-v2/key"
 	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
 	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
 )
 
@@ -27,6 +31,9 @@ type TxHistoryProcessor struct {
 	txChan    chan TxHistory
 	workers   []*TxHistoryWorker
 	cachedTxs map[string]metadata.Transaction
+
+	// the directory where checkpoints are stored; empty if checkpoints are disabled.
+	checkpointDir string
 }
 
 // NewTxHistoryProcessor creates a TxHistoryProcess with a number of TxHistoryWorker's.
@@ -52,6 +59,32 @@ func NewTxHistoryProcessor(client *IncClient, numWorkers int) *TxHistoryProcesso
 	}
 }
 
+// SetCheckpointDirectory enables the persisted checkpoints of the processor. Checkpoints are stored in the given
+// directory, one file per network, account and tokenID, and allow subsequent calls to only decrypt the newer coins
+// and process the newer spends.
+func (p *TxHistoryProcessor) SetCheckpointDirectory(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		err = os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+	p.checkpointDir = dir
+
+	return nil
+}
+
+// getTxInList returns the current list of in-coming transactions of a tokenID.
+func (p *TxHistoryProcessor) getTxInList(tokenIDStr string) []TxIn {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	if h, ok := p.history[tokenIDStr]; ok {
+		return h.TxInList
+	}
+	return nil
+}
+
 func (p *TxHistoryProcessor) addHistory(history TxHistory, tokenIDStr string) {
 	p.mtx.Lock()
 
@@ -93,18 +126,229 @@ func (p *TxHistoryProcessor) addHistory(history TxHistory, tokenIDStr string) {
 	p.mtx.Unlock()
 }
 
+// txHistoryCoins holds the output coins of an account w.r.t a tokenID used to retrieve its history.
+type txHistoryCoins struct {
+	// the newly decrypted output coins, keyed by their key images.
+	decrypted map[string]coin.PlainCoin
+
+	// the output coins recorded in the checkpoint (hence neither downloaded nor decrypted again), keyed by their key images.
+	processed map[string]TxHistoryCheckpointCoin
+
+	// the key images of all output coins (decrypted or recorded), mapped to the versions of the coins.
+	keyImages map[string]uint8
+
+	// the highest index of the downloaded v2 output coins, -1 if none.
+	latestIndex int64
+}
+
+// getHistoryCoins retrieves the output coins of a private key w.r.t a tokenID.
+//
+// If a checkpoint is given, the coins it has already processed are neither downloaded nor decrypted again: only the v2
+// coins after the LatestIndex of the checkpoint are downloaded (see getOutputCoinsV2FromIndex), and the v1 coins are
+// looked up in its CoinKeyImages. Without a checkpoint, coins are retrieved via GetOutputCoins.
+func (p *TxHistoryProcessor) getHistoryCoins(privateKey string, tokenIDStr string, cp *TxHistoryCheckpoint) (*txHistoryCoins, error) {
+	outCoinKey, err := p.client.NewOutCoinKeyFromPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	outCoinKey.SetReadonlyKey("") // call this if you do not want the remote full-node to decrypt your coin
+
+	res := &txHistoryCoins{
+		decrypted:   make(map[string]coin.PlainCoin),
+		processed:   make(map[string]TxHistoryCheckpointCoin),
+		keyImages:   make(map[string]uint8),
+		latestIndex: -1,
+	}
+
+	var listOutputCoins []jsonresult.ICoinInfo
+	var listIndices []*big.Int
+	// checkpoints created before the values of the coins were recorded must download all coins again.
+	if cp != nil && cp.LatestIndex >= 0 && len(cp.Coins) > 0 && p.client.version == 2 {
+		for keyImage, processedCoin := range cp.Coins {
+			res.processed[keyImage] = processedCoin
+			res.keyImages[keyImage] = processedCoin.Version
+		}
+
+		kWallet, err := wallet.Base58CheckDeserialize(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
+		}
+		listOutputCoins, res.latestIndex, err = p.getOutputCoinsV2FromIndex(&kWallet.KeySet, tokenIDStr, uint64(cp.LatestIndex+1))
+		if err != nil {
+			return nil, err
+		}
+
+		v1OutCoinKey := *outCoinKey
+		v1OutCoinKey.SetOTAKey("") // set this to empty so that the full-node only query v1 output coins.
+		listV1OutputCoins, _, err := p.client.GetOutputCoinsV1(&v1OutCoinKey, tokenIDStr, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, outCoin := range listV1OutputCoins {
+			if outCoin.GetVersion() == 1 {
+				listOutputCoins = append(listOutputCoins, outCoin)
+			}
+		}
+	} else {
+		listOutputCoins, listIndices, err = p.client.GetOutputCoins(outCoinKey, tokenIDStr, 0)
+		if err != nil {
+			return nil, err
+		}
+		for i, outCoin := range listOutputCoins {
+			if outCoin.GetVersion() == 2 && i < len(listIndices) && listIndices[i] != nil &&
+				listIndices[i].Int64() > res.latestIndex {
+				res.latestIndex = listIndices[i].Int64()
+			}
+		}
+	}
+
+	coinsToDecrypt := make([]jsonresult.ICoinInfo, 0)
+	for _, outCoin := range listOutputCoins {
+		if cp != nil {
+			if keyImage, ok := cp.CoinKeyImages[getHistoryCoinID(outCoin)]; ok {
+				if _, ok = res.processed[keyImage]; ok {
+					continue
+				}
+			}
+		}
+		coinsToDecrypt = append(coinsToDecrypt, outCoin)
+	}
+	Logger.Printf("#OutputCoins = %v, #CoinsToDecrypt = %v\n", len(listOutputCoins), len(coinsToDecrypt))
+	if len(coinsToDecrypt) == 0 {
+		return res, nil
+	}
+
+	listDecryptedCoins, listKeyImages, err := GetListDecryptedCoins(privateKey, coinsToDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	for i, decryptedCoin := range listDecryptedCoins {
+		res.decrypted[listKeyImages[i]] = decryptedCoin
+		res.keyImages[listKeyImages[i]] = decryptedCoin.GetVersion()
+	}
+
+	return res, nil
+}
+
+// getOutputCoinsV2FromIndex downloads the v2 output coins of the shard of a key set w.r.t a tokenID from the given
+// index on, and returns the ones belonging to the key set, along with the index of the last downloaded coin.
+func (p *TxHistoryProcessor) getOutputCoinsV2FromIndex(keySet *key.KeySet, tokenIDStr string, fromIndex uint64) ([]jsonresult.ICoinInfo, int64, error) {
+	pk := keySet.PaymentAddress.Pk
+	shardID := p.client.networkParams.GetShardIDFromLastByte(pk[len(pk)-1])
+	scanningTokenID := getScanningTokenID(tokenIDStr)
+
+	coinLength, err := p.client.GetOTACoinLengthByShard(shardID, scanningTokenID)
+	if err != nil {
+		return nil, 0, err
+	}
+	lastIndex := int64(fromIndex) - 1
+	if fromIndex >= coinLength {
+		return nil, lastIndex, nil
+	}
+
+	// token coins of all tokenIDs are indexed together, and are told apart by their asset tags.
+	var rawAssetTags map[string]*common.Hash
+	if tokenIDStr != common.PRVIDStr {
+		rawAssetTags, err = p.client.GetAllAssetTags()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	burningPubKey := wallet.GetBurningPublicKey()
+
+	res := make([]jsonresult.ICoinInfo, 0)
+	for current := fromIndex; current < coinLength; current += uint64(batchSize) {
+		next := current + uint64(batchSize)
+		if next > coinLength {
+			next = coinLength
+		}
+		idxList := make([]uint64, 0)
+		for idx := current; idx < next; idx++ {
+			idxList = append(idxList, idx)
+		}
+
+		outCoins, err := p.client.GetOTACoinsByIndices(shardID, scanningTokenID, idxList)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, idx := range idxList {
+			outCoin, ok := outCoins[idx]
+			if !ok || outCoin == nil || bytes.Equal(outCoin.GetPublicKey().ToBytesS(), burningPubKey) {
+				continue
+			}
+			if belongs, _ := outCoin.DoesCoinBelongToKeySet(keySet); !belongs {
+				continue
+			}
+			if tokenIDStr != common.PRVIDStr {
+				coinV2, ok := outCoin.(*coin.CoinV2)
+				if !ok {
+					continue
+				}
+				assetID, err := coinV2.GetTokenId(keySet, rawAssetTags)
+				if err != nil || assetID == nil || assetID.String() != tokenIDStr {
+					continue
+				}
+			}
+			res = append(res, outCoin)
+		}
+		lastIndex = int64(next) - 1
+	}
+	Logger.Printf("Downloaded output coins [%v, %v), #OwnedCoins = %v\n", fromIndex, coinLength, len(res))
+
+	return res, lastIndex, nil
+}
+
+// getSpentHistoryCoins returns the values of the non-zero coins of a list of key images that have been spent, keyed
+// by their key images. The values of the coins recorded in the checkpoint are taken from the checkpoint.
+func (p *TxHistoryProcessor) getSpentHistoryCoins(privateKey string, tokenIDStr string, snList []string, coins *txHistoryCoins) (map[string]uint64, error) {
+	shardID := p.client.GetShardIDFromPrivateKey(privateKey)
+	checkSpentList, err := p.client.CheckCoinsSpent(shardID, tokenIDStr, snList)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]uint64)
+	for i, snStr := range snList {
+		if !checkSpentList[i] {
+			continue
+		}
+		value := uint64(0)
+		if decryptedCoin, ok := coins.decrypted[snStr]; ok {
+			value = decryptedCoin.GetValue()
+		} else if processedCoin, ok := coins.processed[snStr]; ok {
+			value = processedCoin.Value
+		}
+		if value != 0 {
+			res[snStr] = value
+		}
+	}
+
+	return res, nil
+}
+
 // GetTxsIn returns the list of in-coming transactions in a parallel manner.
 func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, version int8) ([]TxIn, error) {
-	kWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	coins, err := p.getHistoryCoins(privateKey, tokenIDStr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
+		return nil, err
 	}
-	addrStr := PrivateKeyToPaymentAddress(privateKey, -1)
 
-	listDecryptedCoins, err := p.client.GetListDecryptedOutCoin(privateKey, tokenIDStr, 0)
+	return p.getTxsIn(privateKey, tokenIDStr, version, nil, coins)
+}
+
+// getTxsIn returns the list of in-coming transactions in a parallel manner, skipping the coins (and v1 transactions)
+// already processed in the given checkpoint. Newly-processed items are recorded to the checkpoint on success.
+func (p *TxHistoryProcessor) getTxsIn(privateKey string, tokenIDStr string, version int8, cp *TxHistoryCheckpoint, coins *txHistoryCoins) ([]TxIn, error) {
+	kWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
 	}
+	addrStr := p.client.PrivateKeyToPaymentAddress(privateKey, -1)
+	listDecryptedCoins := coins.decrypted
+
+	// the items to be processed in this call, and the checkpoint set they will be recorded to
+	var newItems []string
+	var processed map[string]bool
 
 	numWorkers := 0
 	if version == 1 {
@@ -112,10 +356,18 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 		if err != nil {
 			return nil, err
 		}
+		if cp != nil {
+			processed = cp.ProcessedTxs
+			txList = filterUnprocessed(processed, txList)
+			if len(txList) == 0 {
+				return p.getTxInList(tokenIDStr), nil
+			}
+		}
+		newItems = txList
 
 		if len(txList) < len(p.workers) {
 			numWorkers = 1
-			go p.workers[0].getTxsInV1(&kWallet.KeySet, listDecryptedCoins,
+			go p.workers[0].getTxsInV1(&kWallet.KeySet, listDecryptedCoins, coins.keyImages,
 				txList, tokenIDStr, p.txChan, p.errChan)
 		} else {
 			numWorkers = len(p.workers)
@@ -129,7 +381,7 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 				if i == len(p.workers)-1 {
 					end = len(txList)
 				}
-				go p.workers[i].getTxsInV1(&kWallet.KeySet, listDecryptedCoins,
+				go p.workers[i].getTxsInV1(&kWallet.KeySet, listDecryptedCoins, coins.keyImages,
 					txList[start:end], tokenIDStr, p.txChan, p.errChan)
 			}
 		}
@@ -138,10 +390,18 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 		for _, outCoin := range listDecryptedCoins {
 			pubKeys = append(pubKeys, base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0))
 		}
+		if cp != nil {
+			processed = cp.ProcessedOTAs
+			pubKeys = filterUnprocessed(processed, pubKeys)
+			if len(pubKeys) == 0 {
+				return p.getTxInList(tokenIDStr), nil
+			}
+		}
+		newItems = pubKeys
 
 		if len(pubKeys) < len(p.workers) {
 			numWorkers = 1
-			go p.workers[0].getTxsInV2(&kWallet.KeySet, listDecryptedCoins,
+			go p.workers[0].getTxsInV2(&kWallet.KeySet, listDecryptedCoins, coins.keyImages,
 				pubKeys, tokenIDStr, p.txChan, p.errChan)
 		} else {
 			numWorkers = len(p.workers)
@@ -155,7 +415,7 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 				if i == len(p.workers)-1 {
 					end = len(pubKeys)
 				}
-				go p.workers[i].getTxsInV2(&kWallet.KeySet, listDecryptedCoins,
+				go p.workers[i].getTxsInV2(&kWallet.KeySet, listDecryptedCoins, coins.keyImages,
 					pubKeys[start:end], tokenIDStr, p.txChan, p.errChan)
 			}
 		}
@@ -174,6 +434,7 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 			numSuccess++
 			p.addHistory(txHistory, tokenIDStr)
 			if numSuccess == numWorkers {
+				markProcessed(processed, newItems)
 				h := p.history[tokenIDStr]
 				sort.Slice(h.TxInList, func(i, j int) bool {
 					return h.TxInList[i].LockTime > h.TxInList[j].LockTime
@@ -188,27 +449,46 @@ func (p *TxHistoryProcessor) GetTxsIn(privateKey string, tokenIDStr string, vers
 
 // GetTxsOut returns the list of out-going transactions in a parallel manner.
 func (p *TxHistoryProcessor) GetTxsOut(privateKey string, tokenIDStr string, version int8) ([]TxOut, error) {
-	kWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	coins, err := p.getHistoryCoins(privateKey, tokenIDStr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
+		return nil, err
 	}
 
-	listSpentCoins, _, err := p.client.GetSpentOutputCoins(privateKey, tokenIDStr, 0)
+	return p.getTxsOut(privateKey, tokenIDStr, version, nil, coins)
+}
+
+// getTxsOut returns the list of out-going transactions in a parallel manner, skipping the key images already processed
+// in the given checkpoint. Newly-processed key images are recorded to the checkpoint on success.
+func (p *TxHistoryProcessor) getTxsOut(privateKey string, tokenIDStr string, version int8, cp *TxHistoryCheckpoint, coins *txHistoryCoins) ([]TxOut, error) {
+	kWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot deserialize private key %v: %v", privateKey, err)
 	}
 
-	// Create a map from serial numbers to coins
-	mapSpentCoins := make(map[string]coin.PlainCoin)
 	snList := make([]string, 0)
-	for _, spentCoin := range listSpentCoins {
-		if spentCoin.GetVersion() == uint8(version) {
-			snStr := base58.Base58Check{}.Encode(spentCoin.GetKeyImage().ToBytesS(), common.ZeroByte)
-			mapSpentCoins[snStr] = spentCoin
+	for snStr, coinVersion := range coins.keyImages {
+		if coinVersion == uint8(version) {
 			snList = append(snList, snStr)
 		}
 	}
+	var processed map[string]bool
+	if cp != nil {
+		processed = cp.ProcessedKeyImages
+		snList = filterUnprocessed(processed, snList)
+	}
+	if len(snList) == 0 {
+		return nil, nil
+	}
 
+	// Create a map from serial numbers to spent coins
+	spentValues, err := p.getSpentHistoryCoins(privateKey, tokenIDStr, snList, coins)
+	if err != nil {
+		return nil, err
+	}
+	snList = make([]string, 0)
+	for snStr := range spentValues {
+		snList = append(snList, snStr)
+	}
 	if len(snList) == 0 {
 		return nil, nil
 	}
@@ -218,7 +498,7 @@ func (p *TxHistoryProcessor) GetTxsOut(privateKey string, tokenIDStr string, ver
 	numWorkers := 0
 	if len(snList) < len(p.workers) {
 		numWorkers = 1
-		go p.workers[0].getTxsOut(&kWallet.KeySet, mapSpentCoins,
+		go p.workers[0].getTxsOut(&kWallet.KeySet, spentValues,
 			snList, tokenIDStr, p.txChan, p.errChan)
 	} else {
 		numWorkers = len(p.workers)
@@ -232,7 +512,7 @@ func (p *TxHistoryProcessor) GetTxsOut(privateKey string, tokenIDStr string, ver
 			if i == len(p.workers)-1 {
 				end = len(snList)
 			}
-			go p.workers[i].getTxsOut(&kWallet.KeySet, mapSpentCoins,
+			go p.workers[i].getTxsOut(&kWallet.KeySet, spentValues,
 				snList[start:end], tokenIDStr, p.txChan, p.errChan)
 		}
 	}
@@ -250,6 +530,7 @@ func (p *TxHistoryProcessor) GetTxsOut(privateKey string, tokenIDStr string, ver
 			numSuccess++
 			p.addHistory(txHistory, tokenIDStr)
 			if numSuccess == numWorkers {
+				markProcessed(processed, snList)
 				h := p.history[tokenIDStr]
 				sort.Slice(h.TxOutList, func(i, j int) bool {
 					return h.TxOutList[i].LockTime > h.TxOutList[j].LockTime
@@ -263,34 +544,57 @@ func (p *TxHistoryProcessor) GetTxsOut(privateKey string, tokenIDStr string, ver
 }
 
 // GetTokenHistory returns the history of a private key w.r.t a tokenID in a parallel manner.
+//
+// If checkpoints are enabled (see SetCheckpointDirectory), only the coins and spends not recorded in the checkpoint
+// of the account are processed, and the checkpoint is updated with the result.
 func (p *TxHistoryProcessor) GetTokenHistory(privateKey string, tokenIDStr string) (*TxHistory, error) {
+	var cp *TxHistoryCheckpoint
+	var err error
+	if p.checkpointDir != "" {
+		cp, err = loadTxHistoryCheckpoint(p.checkpointDir, p.client.GetNetwork(), privateKey, tokenIDStr)
+		if err != nil {
+			return nil, err
+		}
+		Logger.Printf("Loaded checkpoint for token %v: #TxsIn = %v, #TxsOut = %v\n",
+			tokenIDStr, len(cp.History.TxInList), len(cp.History.TxOutList))
+	}
+
+	coins, err := p.getHistoryCoins(privateKey, tokenIDStr, cp)
+	if err != nil {
+		return nil, err
+	}
+
 	Logger.Printf("GETTING in-coming v1 txs for token %v\n", tokenIDStr)
-	txsInV1, err := p.GetTxsIn(privateKey, tokenIDStr, 1)
+	txsInV1, err := p.getTxsIn(privateKey, tokenIDStr, 1, cp, coins)
 	if err != nil {
 		return nil, err
 	}
 	Logger.Printf("FINISHED in-coming v1 txs for token %v\n\n", tokenIDStr)
 
 	Logger.Printf("GETTING out-going v1 txs for token %v\n", tokenIDStr)
-	txsOutV1, err := p.GetTxsOut(privateKey, tokenIDStr, 1)
+	txsOutV1, err := p.getTxsOut(privateKey, tokenIDStr, 1, cp, coins)
 	if err != nil {
 		return nil, err
 	}
 	Logger.Printf("FINISHED out-going v1 txs for token %v\n\n", tokenIDStr)
 
 	Logger.Printf("GETTING in-coming v2 txs for token %v\n", tokenIDStr)
-	txsInV2, err := p.GetTxsIn(privateKey, tokenIDStr, 2)
+	txsInV2, err := p.getTxsIn(privateKey, tokenIDStr, 2, cp, coins)
 	if err != nil {
 		return nil, err
 	}
 	Logger.Printf("FINISHED in-coming v2 txs for token %v\n\n", tokenIDStr)
 
 	Logger.Printf("GETTING out-going v2 txs for token %v\n", tokenIDStr)
-	txsOutV2, err := p.GetTxsOut(privateKey, tokenIDStr, 2)
+	txsOutV2, err := p.getTxsOut(privateKey, tokenIDStr, 2, cp, coins)
 	if err != nil {
 		return nil, err
 	}
 	Logger.Printf("FINISHED out-going v2 txs for token %v\n\n", tokenIDStr)
+	if cp != nil {
+		txsInV1 = append(cp.History.TxInList, txsInV1...)
+		txsOutV1 = append(cp.History.TxOutList, txsOutV1...)
+	}
 
 	addedTxsIn := make(map[string]interface{})
 	txsInRes := make([]TxIn, 0)
@@ -332,11 +636,27 @@ func (p *TxHistoryProcessor) GetTokenHistory(privateKey string, tokenIDStr strin
 		return txsOutRes[i].LockTime > txsOutRes[j].LockTime
 	})
 
-	return &TxHistory{
+	res := &TxHistory{
 		TxInList:  txsInRes,
 		TxOutList: txsOutRes,
-	}, nil
+	}
+	if cp != nil {
+		cp.History = *res
+		cp.LatestIndex = coins.latestIndex
+		for keyImage, decryptedCoin := range coins.decrypted {
+			cp.CoinKeyImages[getHistoryCoinID(decryptedCoin)] = keyImage
+			cp.Coins[keyImage] = TxHistoryCheckpointCoin{
+				Version: decryptedCoin.GetVersion(),
+				Value:   decryptedCoin.GetValue(),
+			}
+		}
+		err = cp.store(p.checkpointDir, privateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	return res, nil
 }
 
 // GetAllHistory returns all the history of an account in a parallel manner.
@@ -421,7 +741,8 @@ func (worker TxHistoryWorker) getListTxs(txList []string) (map[string]metadata.T
 // getTxsInV1 returns the list of in-coming transactions of version 1.
 //
 // It only returns the list of transactions whose value is greater than 0.
-func (worker TxHistoryWorker) getTxsInV1(keySet *key.KeySet, listDecryptedCoins map[string]coin.PlainCoin, txList []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
+// Transactions spending any of the given key images are out-going, and are skipped.
+func (worker TxHistoryWorker) getTxsInV1(keySet *key.KeySet, listDecryptedCoins map[string]coin.PlainCoin, keyImages map[string]uint8, txList []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
 	Logger.Printf("[WORKER %v] getTxsInV1, #TXS: %v\n", worker.id, len(txList))
 	mapCmt := makeMapCMToPlainCoin(listDecryptedCoins)
 
@@ -434,7 +755,7 @@ func (worker TxHistoryWorker) getTxsInV1(keySet *key.KeySet, listDecryptedCoins
 
 	res := make([]TxIn, 0)
 	for txHash, tx := range txMap {
-		if isOut, err := isTxOut(tx, tokenIDStr, listDecryptedCoins); err != nil {
+		if isOut, err := isTxSpendingKeyImages(tx, tokenIDStr, keyImages); err != nil {
 			errChan <- err
 			return
 		} else if isOut {
@@ -466,6 +787,7 @@ func (worker TxHistoryWorker) getTxsInV1(keySet *key.KeySet, listDecryptedCoins
 				TokenID:  tx.GetTokenID().String(),
 				Metadata: tx.GetMetadata(),
 				Note:     note,
+				Memos:    getTxInMemos(outCoins, mapCmt, keySet),
 			}
 			newTxIn.Amount = amount
 			res = append(res, newTxIn)
@@ -486,7 +808,8 @@ func (worker TxHistoryWorker) getTxsInV1(keySet *key.KeySet, listDecryptedCoins
 // getTxsInV2 returns the list of in-coming transactions of version 2.
 //
 // It only returns the list of transactions whose value is greater than 0.
-func (worker TxHistoryWorker) getTxsInV2(keySet *key.KeySet, listDecryptedCoins map[string]coin.PlainCoin, publicKeys []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
+// Transactions spending any of the given key images are out-going, and are skipped.
+func (worker TxHistoryWorker) getTxsInV2(keySet *key.KeySet, listDecryptedCoins map[string]coin.PlainCoin, keyImages map[string]uint8, publicKeys []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
 	res := make([]TxIn, 0)
 	if worker.client.version != 2 {
 		txChan <- TxHistory{
@@ -511,7 +834,7 @@ func (worker TxHistoryWorker) getTxsInV2(keySet *key.KeySet, listDecryptedCoins
 			if _, ok := mapRes[txHash]; ok {
 				continue
 			}
-			if isOut, err := isTxOut(tx, tokenIDStr, listDecryptedCoins); err != nil {
+			if isOut, err := isTxSpendingKeyImages(tx, tokenIDStr, keyImages); err != nil {
 				errChan <- err
 				return
 			} else if isOut {
@@ -547,6 +870,7 @@ func (worker TxHistoryWorker) getTxsInV2(keySet *key.KeySet, listDecryptedCoins
 					Amount:   amount,
 					Metadata: tx.GetMetadata(),
 					Note:     note,
+					Memos:    getTxInMemos(outCoins, mapCmt, keySet),
 				}
 				mapRes[txHash] = newTxIn
 			}
@@ -568,13 +892,14 @@ func (worker TxHistoryWorker) getTxsInV2(keySet *key.KeySet, listDecryptedCoins
 	Logger.Printf("[WORKER %v] FINISHED getTxsInV2, #TXS: %v!!\n\n", worker.id, len(res))
 }
 
-// getTxsOut returns the list of out-going transactions of version 1.
+// getTxsOut returns the list of out-going transactions spending the given key images, given the values of the spent
+// coins keyed by their key images.
 //
 // It only returns the list of transactions whose value is greater than 0.
-func (worker TxHistoryWorker) getTxsOut(keySet *key.KeySet, mapSpentCoins map[string]coin.PlainCoin, snList []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
+func (worker TxHistoryWorker) getTxsOut(keySet *key.KeySet, spentValues map[string]uint64, snList []string, tokenIDStr string, txChan chan TxHistory, errChan chan error) {
 	Logger.Printf("[WORKER %v] getTxsOut, #No: %v\n", worker.id, len(snList))
 
-	shardID := common.GetShardIDFromLastByte(keySet.PaymentAddress.Pk[len(keySet.PaymentAddress.Pk)-1])
+	shardID := worker.client.networkParams.GetShardIDFromLastByte(keySet.PaymentAddress.Pk[len(keySet.PaymentAddress.Pk)-1])
 
 	// Retrieve the list of transactions which spent these coins
 	mapSpentTxs, err := worker.client.GetTxHashBySerialNumbers(snList, tokenIDStr, shardID)
@@ -619,7 +944,7 @@ func (worker TxHistoryWorker) getTxsOut(keySet *key.KeySet, mapSpentCoins map[st
 		fee, isPRVFee := getTxFeeBy(tx)
 
 		//calculate transaction's amount
-		inputAmount, spentCoins, err := getTxInputAmount(tx, tokenIDStr, mapSpentCoins)
+		inputAmount, spentCoins, err := getTxInputAmountByValues(tx, tokenIDStr, spentValues)
 		if err != nil {
 			errChan <- err
 			return
@@ -120,10 +120,14 @@ func (cp *coinParams) SetBytes(data []byte) error {
 }
 
 // createPaymentInfos creates a list of key.PaymentInfo based on the provided address list and corresponding amount list.
-func createPaymentInfos(addrList []string, amountList []uint64) ([]*key.PaymentInfo, error) {
+// If memoList is not empty, each non-empty memo is encrypted to its receiver and attached to the payment.
+func createPaymentInfos(addrList []string, amountList []uint64, memoList []string) ([]*key.PaymentInfo, error) {
 	if len(addrList) != len(amountList) {
 		return nil, fmt.Errorf("length of payment address (%v) and length amount (%v) mismatch", len(addrList), len(amountList))
 	}
+	if len(memoList) != 0 && len(memoList) != len(addrList) {
+		return nil, fmt.Errorf("length of payment address (%v) and length memo (%v) mismatch", len(addrList), len(memoList))
+	}
 
 	paymentInfos := make([]*key.PaymentInfo, 0)
 	for i, addr := range addrList {
@@ -132,6 +136,12 @@ func createPaymentInfos(addrList []string, amountList []uint64) ([]*key.PaymentI
 			return nil, fmt.Errorf("cannot deserialize key %v: %v", addr, err)
 		}
 		paymentInfo := key.PaymentInfo{PaymentAddress: receiverWallet.KeySet.PaymentAddress, Amount: amountList[i], Message: []byte{}}
+		if len(memoList) != 0 && memoList[i] != "" {
+			paymentInfo.Message, err = EncryptMemo(receiverWallet.KeySet.PaymentAddress, []byte(memoList[i]))
+			if err != nil {
+				return nil, fmt.Errorf("cannot encrypt memo for %v: %v", addr, err)
+			}
+		}
 		paymentInfos = append(paymentInfos, &paymentInfo)
 	}
 
@@ -300,7 +310,7 @@ func (client *IncClient) getRandomCommitmentV1(inputCoins []coin.PlainCoin, toke
 	}
 
 	lastByte := inputCoins[0].GetPublicKey().ToBytesS()[len(inputCoins[0].GetPublicKey().ToBytesS())-1]
-	shardID := common.GetShardIDFromLastByte(lastByte)
+	shardID := client.networkParams.GetShardIDFromLastByte(lastByte)
 
 	responseInBytes, err := client.rpcServer.RandomCommitments(shardID, outCoinList, tokenID)
 	if err != nil {
@@ -419,7 +429,7 @@ func (client *IncClient) initParams(privateKey string, tokenIDStr string, totalA
 	}
 
 	lastByteSender := senderWallet.KeySet.PaymentAddress.Pk[len(senderWallet.KeySet.PaymentAddress.Pk)-1]
-	shardID := common.GetShardIDFromLastByte(lastByteSender)
+	shardID := client.networkParams.GetShardIDFromLastByte(lastByteSender)
 
 	//fmt.Printf("Getting UTXOs for tokenID %v...\n", tokenIDStr)
 	//Get list of UTXOs
@@ -557,7 +567,7 @@ func (client *IncClient) initParamsV2(txParam *TxParam, tokenIDStr string, total
 	}
 
 	lastByteSender := senderWallet.KeySet.PaymentAddress.Pk[len(senderWallet.KeySet.PaymentAddress.Pk)-1]
-	shardID := common.GetShardIDFromLastByte(lastByteSender)
+	shardID := client.networkParams.GetShardIDFromLastByte(lastByteSender)
 
 	var coinsToSpend []coin.PlainCoin
 	var myIndices []uint64
@@ -623,6 +633,13 @@ func (client *IncClient) GetTokenFee(shardID byte, tokenIDStr string) (uint64, e
 	if tokenIDStr == common.PRVIDStr {
 		return DefaultPRVFee, nil
 	}
+
+	return client.GetEstimatedFeePerKb(shardID, tokenIDStr)
+}
+
+// GetEstimatedFeePerKb returns the fee per kb of a tokenID estimated by the remote node for the given shard.
+// Unlike GetTokenFee, the PRV fee is estimated as well.
+func (client *IncClient) GetEstimatedFeePerKb(shardID byte, tokenIDStr string) (uint64, error) {
 	responseInBytes, err := client.rpcServer.EstimateFeeWithEstimator(-1, shardID, 10, tokenIDStr)
 	if err != nil {
 		return 0, err
@@ -635,7 +652,6 @@ func (client *IncClient) GetTokenFee(shardID byte, tokenIDStr string) (uint64, e
 	}
 
 	return feeEstimateResult.EstimateFeeCoinPerKb, nil
-
 }
 
 // GetTxDetail retrieves the transaction detail from its hash.
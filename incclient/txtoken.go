@@ -24,6 +24,9 @@ func (client *IncClient) CreateRawTokenTransaction(txParam *TxParam, version int
 	if txParam.txTokenParam == nil {
 		return nil, "", fmt.Errorf("TxTokenParam must not be nil")
 	}
+	if version == -1 && client.dryRun != nil {
+		version = 2
+	}
 	if version == -1 { //Try either one of the version, if possible
 		encodedTx, txHash, err := client.CreateRawTokenTransactionVer1(txParam)
 		if err != nil {
@@ -50,6 +53,12 @@ func (client *IncClient) CreateRawTokenTransactionVer1(txParam *TxParam) ([]byte
 	if txParam.txTokenParam == nil {
 		return nil, "", fmt.Errorf("TxTokenParam must not be nil")
 	}
+	if len(txParam.memoList) != 0 || len(txParam.txTokenParam.memoList) != 0 {
+		return nil, "", fmt.Errorf("memos are only supported by transactions v2")
+	}
+	if client.dryRun != nil {
+		return client.dryRunTransaction(txParam, 1)
+	}
 
 	privateKey := txParam.senderPrivateKey
 
@@ -65,7 +74,7 @@ func (client *IncClient) CreateRawTokenTransactionVer1(txParam *TxParam) ([]byte
 	}
 
 	lastByteSender := senderWallet.KeySet.PaymentAddress.Pk[len(senderWallet.KeySet.PaymentAddress.Pk)-1]
-	shardID := common.GetShardIDFromLastByte(lastByteSender)
+	shardID := client.networkParams.GetShardIDFromLastByte(lastByteSender)
 
 	hasTokenFee := txParam.txTokenParam.hasTokenFee
 
@@ -81,7 +90,7 @@ func (client *IncClient) CreateRawTokenTransactionVer1(txParam *TxParam) ([]byte
 		uniqueReceiver := key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: totalAmount, Message: []byte{}}
 		tokenReceivers = []*key.PaymentInfo{&uniqueReceiver}
 	} else {
-		tokenReceivers, err = createPaymentInfos(txParam.txTokenParam.receiverList, txParam.txTokenParam.amountList)
+		tokenReceivers, err = createPaymentInfos(txParam.txTokenParam.receiverList, txParam.txTokenParam.amountList, txParam.txTokenParam.memoList)
 		if err != nil {
 			return nil, "", err
 		}
@@ -149,7 +158,7 @@ func (client *IncClient) CreateRawTokenTransactionVer1(txParam *TxParam) ([]byte
 
 	prvReceivers := make([]*key.PaymentInfo, 0)
 	if len(txParam.receiverList) > 0 {
-		prvReceivers, err = createPaymentInfos(txParam.receiverList, txParam.amountList)
+		prvReceivers, err = createPaymentInfos(txParam.receiverList, txParam.amountList, txParam.memoList)
 		if err != nil {
 			return nil, "", err
 		}
@@ -157,6 +166,7 @@ func (client *IncClient) CreateRawTokenTransactionVer1(txParam *TxParam) ([]byte
 
 	txTokenParam := tx_generic.NewTxTokenParams(&senderWallet.KeySet.PrivateKey, prvReceivers, coinsPRVToSpend, prvFee,
 		tokenParam, txParam.md, hasPrivacyPRV, hasPrivacyToken, shardID, nil, kvArgsPRV)
+	txTokenParam.NetworkParams = client.networkParams
 
 	tx := new(tx_ver1.TxToken)
 	err = tx.Init(txTokenParam)
@@ -181,22 +191,42 @@ func (client *IncClient) CreateRawTokenTransactionVer2(txParam *TxParam) ([]byte
 	if txParam.txTokenParam == nil {
 		return nil, "", fmt.Errorf("TxTokenParam must not be nil")
 	}
+	if client.dryRun != nil {
+		return client.dryRunTransaction(txParam, 2)
+	}
+
+	tx, err := client.createTokenTransactionVer2(txParam)
+	if err != nil {
+		return nil, "", err
+	}
+
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot marshal txtokenver2: %v", err)
+	}
 
+	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
+
+	return []byte(base58CheckData), tx.Hash().String(), nil
+}
+
+// createTokenTransactionVer2 creates and signs a token transaction version 2.
+func (client *IncClient) createTokenTransactionVer2(txParam *TxParam) (*tx_ver2.TxToken, error) {
 	privateKey := txParam.senderPrivateKey
 
 	tokenIDStr := txParam.txTokenParam.tokenID
 	_, err := new(common.Hash).NewHashFromStr(tokenIDStr)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 	//Create sender private key from string
 	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
 	if err != nil {
-		return nil, "", fmt.Errorf("cannot init private key %v: %v", privateKey, err)
+		return nil, fmt.Errorf("cannot init private key %v: %v", privateKey, err)
 	}
 
 	lastByteSender := senderWallet.KeySet.PaymentAddress.Pk[len(senderWallet.KeySet.PaymentAddress.Pk)-1]
-	shardID := common.GetShardIDFromLastByte(lastByteSender)
+	shardID := client.networkParams.GetShardIDFromLastByte(lastByteSender)
 
 	//Calculate the total transacted amount
 	totalAmount := uint64(0)
@@ -205,9 +235,9 @@ func (client *IncClient) CreateRawTokenTransactionVer2(txParam *TxParam) ([]byte
 	}
 
 	//Create list of payment infos
-	tokenReceivers, err := createPaymentInfos(txParam.txTokenParam.receiverList, txParam.txTokenParam.amountList)
+	tokenReceivers, err := createPaymentInfos(txParam.txTokenParam.receiverList, txParam.txTokenParam.amountList, txParam.txTokenParam.memoList)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	prvFee := txParam.fee
@@ -224,7 +254,7 @@ func (client *IncClient) CreateRawTokenTransactionVer2(txParam *TxParam) ([]byte
 	coinsToSpendPRV, kvArgsPRV, err := client.initParamsV2(txParam, common.PRVIDStr, totalPRVAmount)
 	if err != nil {
 		Logger.Printf("init PRVParamsV2 error: %v\n", err)
-		return nil, "", err
+		return nil, err
 	}
 	//End init PRV fee param
 
@@ -232,7 +262,7 @@ func (client *IncClient) CreateRawTokenTransactionVer2(txParam *TxParam) ([]byte
 	coinsTokenToSpend, kvArgsToken, err := client.initParamsV2(txParam, tokenIDStr, totalAmount)
 	if err != nil {
 		Logger.Printf("init TokenParamsV2 error: %v\n", err)
-		return nil, "", err
+		return nil, err
 	}
 	//End init token param
 
@@ -242,28 +272,22 @@ func (client *IncClient) CreateRawTokenTransactionVer2(txParam *TxParam) ([]byte
 
 	prvReceivers := make([]*key.PaymentInfo, 0)
 	if len(txParam.receiverList) > 0 {
-		prvReceivers, err = createPaymentInfos(txParam.receiverList, txParam.amountList)
+		prvReceivers, err = createPaymentInfos(txParam.receiverList, txParam.amountList, txParam.memoList)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 	}
 	txTokenParam := tx_generic.NewTxTokenParams(&senderWallet.KeySet.PrivateKey, prvReceivers, coinsToSpendPRV, prvFee,
 		tokenParam, txParam.md, true, true, shardID, nil, kvArgsPRV)
+	txTokenParam.NetworkParams = client.networkParams
 
 	tx := new(tx_ver2.TxToken)
 	err = tx.Init(txTokenParam)
 	if err != nil {
-		return nil, "", fmt.Errorf("init txtokenver2 error: %v", err)
-	}
-
-	txBytes, err := json.Marshal(tx)
-	if err != nil {
-		return nil, "", fmt.Errorf("cannot marshal txtokenver2: %v", err)
+		return nil, fmt.Errorf("init txtokenver2 error: %v", err)
 	}
 
-	base58CheckData := base58.Base58Check{}.Encode(txBytes, common.ZeroByte)
-
-	return []byte(base58CheckData), tx.Hash().String(), nil
+	return tx, nil
 }
 
 // CreateAndSendRawTokenTransaction creates a token transaction with the provided version, and submits it to the Incognito network.
@@ -286,6 +310,26 @@ func (client *IncClient) CreateAndSendRawTokenTransaction(privateKey string, add
 	return txHash, nil
 }
 
+// CreateAndSendRawTokenTransactionWithMemos is the same as CreateAndSendRawTokenTransaction, except that it attaches
+// a memo to each receiver. Memos are encrypted to their receivers, and only supported by transactions v2.
+//
+// It returns the transaction's hash, and an error (if any).
+func (client *IncClient) CreateAndSendRawTokenTransactionWithMemos(privateKey string, addrList []string, amountList []uint64, memoList []string, tokenID string, version int8, md metadata.Metadata) (string, error) {
+	tokenParams := NewTxTokenParam(tokenID, 1, addrList, amountList, false, 0, nil).SetMemos(memoList)
+	txParam := NewTxParam(privateKey, []string{}, []uint64{}, DefaultPRVFee, tokenParams, md, nil)
+	encodedTx, txHash, err := client.CreateRawTokenTransaction(txParam, version)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.SendRawTokenTx(encodedTx)
+	if err != nil {
+		return "", err
+	}
+
+	return txHash, nil
+}
+
 // CreateTokenInitTransaction creates a token init transaction with the provided version.
 // Version = -1 indicates that whichever version is accepted.
 //
@@ -336,8 +380,8 @@ func (client *IncClient) CreateTokenInitTransactionV2(privateKey, tokenName, tok
 		return nil, "", err
 	}
 
-	addr := senderWallet.Base58CheckSerialize(wallet.PaymentAddressType)
-	pubKeyStr, txRandomStr, err := GenerateOTAFromPaymentAddress(addr, coin.PrivacyTypeMint)
+	addr := senderWallet.Base58CheckSerializeWithParams(wallet.PaymentAddressType, client.networkParams)
+	pubKeyStr, txRandomStr, err := GenerateOTAFromPaymentAddressWithParams(client.networkParams, addr, coin.PrivacyTypeMint)
 	if err != nil {
 		return nil, "", err
 	}
@@ -440,6 +484,9 @@ func (client *IncClient) CreateRawTokenTransactionWithInputCoins(txParam *TxPara
 
 // SendRawTokenTx sends submits a raw token transaction to the Incognito blockchain.
 func (client *IncClient) SendRawTokenTx(encodedTx []byte) error {
+	if client.dryRun != nil {
+		return nil
+	}
 	responseInBytes, err := client.rpcServer.SendRawTokenTx(string(encodedTx))
 	if err != nil {
 		return nil
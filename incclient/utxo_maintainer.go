@@ -0,0 +1,592 @@
+package incclient
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+const (
+	// UTXOEventWaiting indicates that the maintainer is waiting for previous transactions to be confirmed.
+	UTXOEventWaiting = "waiting"
+
+	// UTXOEventConfirmed indicates that a previous transaction has been confirmed.
+	UTXOEventConfirmed = "confirmed"
+
+	// UTXOEventDropped indicates that a previous transaction has not been confirmed in time.
+	UTXOEventDropped = "dropped"
+
+	// UTXOEventHighFee indicates that the round has been skipped because the network fee is above the limit.
+	UTXOEventHighFee = "highfee"
+
+	// UTXOEventConvert indicates that UTXOs v1 are being converted.
+	UTXOEventConvert = "convert"
+
+	// UTXOEventConsolidate indicates that UTXOs are being consolidated.
+	UTXOEventConsolidate = "consolidate"
+
+	// UTXOEventSplit indicates that a UTXO is being split to reach the minimum number of UTXOs.
+	UTXOEventSplit = "split"
+
+	// UTXOEventSplitFee indicates that a PRV UTXO is being split into fee-sized UTXOs.
+	UTXOEventSplitFee = "splitfee"
+
+	// UTXOEventIdle indicates that the UTXOs of a token already satisfy the policy.
+	UTXOEventIdle = "idle"
+
+	// UTXOEventError indicates that an error occurred.
+	UTXOEventError = "error"
+)
+
+// utxoPendingTimeOut is the duration after which a transaction not yet confirmed is no longer waited for.
+const utxoPendingTimeOut = 30 * time.Minute
+
+// UTXOPolicy specifies how a UTXOMaintainer manages the UTXOs of an account. A zero value disables the
+// corresponding rule. Only UTXOs v2 are consolidated or split.
+type UTXOPolicy struct {
+	// TokenIDs is the list of tokens to maintain besides PRV.
+	TokenIDs []string
+
+	// MinUTXOs is the minimum number of UTXOs of each token. Below it, the largest UTXO is split.
+	MinUTXOs int
+
+	// MaxUTXOs is the maximum number of UTXOs of each token. Above it, the smallest UTXOs are consolidated.
+	MaxUTXOs int
+
+	// NumFeeCoins is the number of PRV UTXOs of at least DefaultPRVFee to keep, so that as many transactions can be
+	// sent in parallel.
+	NumFeeCoins int
+
+	// ConvertV1 indicates whether UTXOs v1 are converted to UTXOs v2 as soon as they appear.
+	ConvertV1 bool
+
+	// MaxFeePerKb is the maximum PRV fee per kb (as estimated by the network) at which the maintainer acts.
+	MaxFeePerKb uint64
+
+	// MaxTxsPerRound is the maximum number of consolidating transactions sent for a token in a round (default 1).
+	MaxTxsPerRound int
+}
+
+// UTXOEvent reports the progress of a UTXOMaintainer.
+type UTXOEvent struct {
+	Time     int64
+	Type     string
+	TokenID  string
+	NumUTXOs int
+	TxHashes []string
+	Err      string `json:"Err,omitempty"`
+}
+
+// String returns the string-representation of a UTXOEvent.
+func (e UTXOEvent) String() string {
+	res := fmt.Sprintf("%v %v", e.Type, e.TokenID)
+	if e.NumUTXOs > 0 {
+		res += fmt.Sprintf(", numUTXOs %v", e.NumUTXOs)
+	}
+	if len(e.TxHashes) > 0 {
+		res += fmt.Sprintf(", txs %v", e.TxHashes)
+	}
+	if e.Err != "" {
+		res += fmt.Sprintf(", error: %v", e.Err)
+	}
+	return res
+}
+
+// UTXOMaintainer keeps the UTXOs of an account in shape according to a UTXOPolicy.
+//
+// Unlike Consolidate or ConvertAllUTXOs, a round (MaintainOnce) never waits for its transactions to be confirmed:
+// it takes at most one step per token, and the next rounds are skipped until the transactions are confirmed. This
+// way, the same UTXO is never spent twice and the maintainer can run in the background.
+type UTXOMaintainer struct {
+	client     *IncClient
+	privateKey string
+	addr       string
+	shardID    byte
+	policy     UTXOPolicy
+	pending    map[string]time.Time
+}
+
+// NewUTXOMaintainer creates a new UTXOMaintainer for a private key. The shard and the payment address of the account
+// are derived with the network parameters of the client.
+func NewUTXOMaintainer(client *IncClient, privateKey string, policy UTXOPolicy) (*UTXOMaintainer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is nil")
+	}
+	if _, err := wallet.Base58CheckDeserialize(privateKey); err != nil {
+		return nil, fmt.Errorf("cannot init private key %v: %v", privateKey, err)
+	}
+	if policy.MinUTXOs < 0 || policy.MaxUTXOs < 0 || policy.NumFeeCoins < 0 || policy.MaxTxsPerRound < 0 {
+		return nil, fmt.Errorf("invalid policy %+v", policy)
+	}
+	if policy.MaxUTXOs > 0 && policy.MaxUTXOs < 2 {
+		return nil, fmt.Errorf("MaxUTXOs must be at least 2, got %v", policy.MaxUTXOs)
+	}
+	if policy.MaxUTXOs > 0 && policy.MinUTXOs > policy.MaxUTXOs {
+		return nil, fmt.Errorf("MinUTXOs (%v) is greater than MaxUTXOs (%v)", policy.MinUTXOs, policy.MaxUTXOs)
+	}
+	if policy.MaxUTXOs > 0 && policy.NumFeeCoins >= policy.MaxUTXOs {
+		return nil, fmt.Errorf("NumFeeCoins (%v) must be less than MaxUTXOs (%v)", policy.NumFeeCoins, policy.MaxUTXOs)
+	}
+	if policy.MaxTxsPerRound == 0 {
+		policy.MaxTxsPerRound = 1
+	}
+
+	return &UTXOMaintainer{
+		client:     client,
+		privateKey: privateKey,
+		addr:       client.PrivateKeyToPaymentAddress(privateKey, -1),
+		shardID:    client.GetShardIDFromPrivateKey(privateKey),
+		policy:     policy,
+		pending:    make(map[string]time.Time),
+	}, nil
+}
+
+// GetPendingTxs returns the hashes of the transactions sent by the maintainer and not yet confirmed.
+func (m *UTXOMaintainer) GetPendingTxs() []string {
+	res := make([]string, 0)
+	for txHash := range m.pending {
+		res = append(res, txHash)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// MaintainOnce runs a single round of maintenance, and returns the events of the round.
+func (m *UTXOMaintainer) MaintainOnce() []*UTXOEvent {
+	events := m.checkPending()
+	if len(m.pending) > 0 {
+		return append(events, m.newEvent(UTXOEventWaiting, "", 0, m.GetPendingTxs(), nil))
+	}
+
+	if m.policy.MaxFeePerKb > 0 {
+		feePerKb, err := m.client.GetEstimatedFeePerKb(m.shardID, common.PRVIDStr)
+		if err != nil {
+			return append(events, m.newEvent(UTXOEventError, common.PRVIDStr, 0, nil, fmt.Errorf("cannot estimate the fee: %v", err)))
+		}
+		if feePerKb > m.policy.MaxFeePerKb {
+			return append(events, m.newEvent(UTXOEventHighFee, common.PRVIDStr, 0, nil,
+				fmt.Errorf("fee per kb %v is above %v", feePerKb, m.policy.MaxFeePerKb)))
+		}
+	}
+
+	// PRV UTXOs spent during this round, which must not be used to pay the fees of token transactions.
+	spentPRVs := make(map[string]bool)
+	events = append(events, m.maintainToken(common.PRVIDStr, spentPRVs))
+	done := map[string]bool{common.PRVIDStr: true}
+	for _, tokenIDStr := range m.policy.TokenIDs {
+		if done[tokenIDStr] {
+			continue
+		}
+		done[tokenIDStr] = true
+		events = append(events, m.maintainToken(tokenIDStr, spentPRVs))
+	}
+
+	return events
+}
+
+// Run calls MaintainOnce every interval until the stop channel is closed. The events of each round are passed to
+// the given callback (if not nil).
+func (m *UTXOMaintainer) Run(interval time.Duration, stop <-chan struct{}, callback func([]*UTXOEvent)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events := m.MaintainOnce()
+		if callback != nil {
+			callback(events)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPending removes the confirmed (or timed-out) transactions from the pending list. A transaction whose status
+// cannot be retrieved remains pending until it times out.
+func (m *UTXOMaintainer) checkPending() []*UTXOEvent {
+	events := make([]*UTXOEvent, 0)
+	for _, txHash := range m.GetPendingTxs() {
+		isInBlock, err := m.client.CheckTxInBlock(txHash)
+		switch {
+		case err == nil && isInBlock:
+			delete(m.pending, txHash)
+			events = append(events, m.newEvent(UTXOEventConfirmed, "", 0, []string{txHash}, nil))
+		case time.Since(m.pending[txHash]) > utxoPendingTimeOut:
+			delete(m.pending, txHash)
+			events = append(events, m.newEvent(UTXOEventDropped, "", 0, []string{txHash}, fmt.Errorf("time-out")))
+		case err != nil:
+			events = append(events, m.newEvent(UTXOEventError, "", 0, []string{txHash}, err))
+		}
+	}
+
+	return events
+}
+
+// maintainToken takes (at most) one step to make the UTXOs of a token satisfy the policy.
+func (m *UTXOMaintainer) maintainToken(tokenIDStr string, spentPRVs map[string]bool) *UTXOEvent {
+	v1Coins, v2Coins, v2Indices, err := m.getUTXOs(tokenIDStr)
+	if err != nil {
+		return m.newEvent(UTXOEventError, tokenIDStr, 0, nil, err)
+	}
+	isPRV := tokenIDStr == common.PRVIDStr
+
+	var eventType string
+	var txHashes []string
+	switch {
+	case m.policy.ConvertV1 && len(v1Coins) > 0:
+		eventType = UTXOEventConvert
+		txHashes, err = m.convert(tokenIDStr, v1Coins, spentPRVs)
+	case m.policy.MaxUTXOs > 0 && len(v2Coins) > m.policy.MaxUTXOs:
+		eventType = UTXOEventConsolidate
+		txHashes, err = m.consolidate(tokenIDStr, v2Coins, v2Indices, m.policy.MaxUTXOs, spentPRVs)
+	case m.policy.MinUTXOs > 0 && len(v2Coins) > 0 && len(v2Coins) < m.policy.MinUTXOs:
+		eventType = UTXOEventSplit
+		txHashes, err = m.split(tokenIDStr, v2Coins, v2Indices, m.policy.MinUTXOs-len(v2Coins), 0, spentPRVs)
+	case isPRV && m.policy.NumFeeCoins > 0 && countFeeCoins(v2Coins) < m.policy.NumFeeCoins:
+		eventType = UTXOEventSplitFee
+		numOutputs := m.policy.NumFeeCoins - countFeeCoins(v2Coins)
+		if m.policy.MaxUTXOs > 0 && len(v2Coins)+numOutputs > m.policy.MaxUTXOs {
+			numOutputs = m.policy.MaxUTXOs - len(v2Coins)
+		}
+		if numOutputs > 0 {
+			txHashes, err = m.split(tokenIDStr, v2Coins, v2Indices, numOutputs, DefaultPRVFee, spentPRVs)
+		} else {
+			// make room for the fee-sized UTXOs first
+			eventType = UTXOEventConsolidate
+			target := m.policy.MaxUTXOs - (m.policy.NumFeeCoins - countFeeCoins(v2Coins))
+			if target < 1 {
+				target = 1
+			}
+			txHashes, err = m.consolidate(tokenIDStr, v2Coins, v2Indices, target, spentPRVs)
+		}
+	default:
+		return m.newEvent(UTXOEventIdle, tokenIDStr, len(v2Coins), nil, nil)
+	}
+
+	for _, txHash := range txHashes {
+		m.pending[txHash] = time.Now()
+	}
+	if err != nil && len(txHashes) == 0 {
+		eventType = UTXOEventError
+	}
+
+	return m.newEvent(eventType, tokenIDStr, len(v1Coins)+len(v2Coins), txHashes, err)
+}
+
+// getUTXOs returns the UTXOs v1, and the UTXOs v2 (with their indices) of a token.
+func (m *UTXOMaintainer) getUTXOs(tokenIDStr string) ([]coin.PlainCoin, []coin.PlainCoin, []uint64, error) {
+	utxoList, idxList, err := m.client.GetUnspentOutputCoins(m.privateKey, tokenIDStr, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	v1Coins := make([]coin.PlainCoin, 0)
+	v2Coins := make([]coin.PlainCoin, 0)
+	v2Indices := make([]uint64, 0)
+	for i, utxo := range utxoList {
+		if utxo.GetVersion() == 1 {
+			v1Coins = append(v1Coins, utxo)
+			continue
+		}
+		v2Coins = append(v2Coins, utxo)
+		idx := uint64(0)
+		if i < len(idxList) && idxList[i] != nil {
+			idx = idxList[i].Uint64()
+		}
+		v2Indices = append(v2Indices, idx)
+	}
+
+	return v1Coins, v2Coins, v2Indices, nil
+}
+
+// getFeeCoin returns a PRV UTXO v2 able to pay the DefaultPRVFee which has not been spent during the current round,
+// and marks it as spent.
+func (m *UTXOMaintainer) getFeeCoin(spentPRVs map[string]bool) ([]coin.PlainCoin, []uint64, error) {
+	_, prvCoins, prvIndices, err := m.getUTXOs(common.PRVIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, c := range prvCoins {
+		coinID := getCoinID(c)
+		if c.GetValue() >= DefaultPRVFee && !spentPRVs[coinID] {
+			spentPRVs[coinID] = true
+			return []coin.PlainCoin{c}, []uint64{prvIndices[i]}, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no PRV UTXO v2 available to pay the fee")
+}
+
+// convert sends a transaction converting (at most MaxInputSize) UTXOs v1 of a token.
+func (m *UTXOMaintainer) convert(tokenIDStr string, v1Coins []coin.PlainCoin, spentPRVs map[string]bool) ([]string, error) {
+	if len(v1Coins) > MaxInputSize {
+		v1Coins = v1Coins[:MaxInputSize]
+	}
+
+	var encodedTx []byte
+	var txHash string
+	var err error
+	if tokenIDStr == common.PRVIDStr {
+		if sumCoinValues(v1Coins) <= DefaultPRVFee {
+			return nil, fmt.Errorf("not enough PRV v1 to convert, got %v, want more than %v", sumCoinValues(v1Coins), DefaultPRVFee)
+		}
+		encodedTx, txHash, err = m.client.CreateConversionTransactionWithInputCoins(m.privateKey, v1Coins)
+		if err != nil {
+			return nil, err
+		}
+		err = m.client.SendRawTx(encodedTx)
+	} else {
+		var prvCoins []coin.PlainCoin
+		var prvIndices []uint64
+		prvCoins, prvIndices, err = m.getFeeCoin(spentPRVs)
+		if err != nil {
+			return nil, err
+		}
+		encodedTx, txHash, err = m.client.CreateTokenConversionTransactionWithInputCoins(m.privateKey, tokenIDStr,
+			v1Coins, prvCoins, prvIndices)
+		if err != nil {
+			return nil, err
+		}
+		err = m.client.SendRawTokenTx(encodedTx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{txHash}, nil
+}
+
+// consolidate sends transactions merging the smallest UTXOs of a token, so that at most maxUTXOs remain.
+func (m *UTXOMaintainer) consolidate(tokenIDStr string, coins []coin.PlainCoin, indices []uint64, maxUTXOs int,
+	spentPRVs map[string]bool) ([]string, error) {
+	groups := planConsolidation(coins, maxUTXOs, m.policy.MaxTxsPerRound)
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("cannot consolidate %v UTXOs into %v", len(coins), maxUTXOs)
+	}
+
+	txHashes := make([]string, 0)
+	for _, group := range groups {
+		inCoins := make([]coin.PlainCoin, 0)
+		inIndices := make([]uint64, 0)
+		for _, i := range group {
+			inCoins = append(inCoins, coins[i])
+			inIndices = append(inIndices, indices[i])
+		}
+		totalAmount := sumCoinValues(inCoins)
+
+		var txHash string
+		var err error
+		if tokenIDStr == common.PRVIDStr {
+			if totalAmount <= DefaultPRVFee {
+				return txHashes, fmt.Errorf("not enough PRV to consolidate, got %v, want more than %v", totalAmount, DefaultPRVFee)
+			}
+			for _, c := range inCoins {
+				spentPRVs[getCoinID(c)] = true
+			}
+			txParam := NewTxParam(m.privateKey, []string{m.addr}, []uint64{totalAmount - DefaultPRVFee}, DefaultPRVFee, nil, nil, nil)
+			txHash, err = m.sendTxWithInputCoins(txParam, inCoins, inIndices, nil, nil)
+		} else {
+			var prvCoins []coin.PlainCoin
+			var prvIndices []uint64
+			prvCoins, prvIndices, err = m.getFeeCoin(spentPRVs)
+			if err != nil {
+				return txHashes, err
+			}
+			txTokenParam := NewTxTokenParam(tokenIDStr, 1, []string{m.addr}, []uint64{totalAmount}, false, 0, nil)
+			txParam := NewTxParam(m.privateKey, []string{}, []uint64{}, DefaultPRVFee, txTokenParam, nil, nil)
+			txHash, err = m.sendTxWithInputCoins(txParam, inCoins, inIndices, prvCoins, prvIndices)
+		}
+		if err != nil {
+			return txHashes, err
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	return txHashes, nil
+}
+
+// split sends a transaction splitting the largest UTXO of a token into numOutputs + 1 UTXOs. If outputAmount is 0,
+// the UTXO is split evenly; otherwise, numOutputs UTXOs of outputAmount are created, and the rest is sent back as
+// change.
+func (m *UTXOMaintainer) split(tokenIDStr string, coins []coin.PlainCoin, indices []uint64,
+	numOutputs int, outputAmount uint64, spentPRVs map[string]bool) ([]string, error) {
+	isPRV := tokenIDStr == common.PRVIDStr
+	if numOutputs > MaxOutputSize-1 {
+		numOutputs = MaxOutputSize - 1
+	}
+
+	largest := 0
+	for i, c := range coins {
+		if c.GetValue() > coins[largest].GetValue() {
+			largest = i
+		}
+	}
+	available := coins[largest].GetValue()
+	if isPRV {
+		if available <= DefaultPRVFee {
+			return nil, fmt.Errorf("not enough PRV to split, got %v, want more than %v", available, DefaultPRVFee)
+		}
+		available -= DefaultPRVFee
+	}
+	amounts, err := getSplitAmounts(available, numOutputs, outputAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	addrList := make([]string, 0)
+	for range amounts {
+		addrList = append(addrList, m.addr)
+	}
+	inCoins := []coin.PlainCoin{coins[largest]}
+	inIndices := []uint64{indices[largest]}
+
+	var txHash string
+	if isPRV {
+		spentPRVs[getCoinID(coins[largest])] = true
+		txParam := NewTxParam(m.privateKey, addrList, amounts, DefaultPRVFee, nil, nil, nil)
+		txHash, err = m.sendTxWithInputCoins(txParam, inCoins, inIndices, nil, nil)
+	} else {
+		var prvCoins []coin.PlainCoin
+		var prvIndices []uint64
+		prvCoins, prvIndices, err = m.getFeeCoin(spentPRVs)
+		if err != nil {
+			return nil, err
+		}
+		txTokenParam := NewTxTokenParam(tokenIDStr, 1, addrList, amounts, false, 0, nil)
+		txParam := NewTxParam(m.privateKey, []string{}, []uint64{}, DefaultPRVFee, txTokenParam, nil, nil)
+		txHash, err = m.sendTxWithInputCoins(txParam, inCoins, inIndices, prvCoins, prvIndices)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{txHash}, nil
+}
+
+// sendTxWithInputCoins creates and sends a PRV (if prvCoins is nil) or token transaction with the given input coins.
+func (m *UTXOMaintainer) sendTxWithInputCoins(txParam *TxParam, inCoins []coin.PlainCoin, inIndices []uint64,
+	prvCoins []coin.PlainCoin, prvIndices []uint64) (string, error) {
+	if prvCoins == nil {
+		encodedTx, txHash, err := m.client.CreateRawTransactionWithInputCoins(txParam, inCoins, inIndices)
+		if err != nil {
+			return "", err
+		}
+		return txHash, m.client.SendRawTx(encodedTx)
+	}
+
+	encodedTx, txHash, err := m.client.CreateRawTokenTransactionWithInputCoins(txParam, inCoins, inIndices, prvCoins, prvIndices)
+	if err != nil {
+		return "", err
+	}
+	return txHash, m.client.SendRawTokenTx(encodedTx)
+}
+
+func (m *UTXOMaintainer) newEvent(eventType, tokenIDStr string, numUTXOs int, txHashes []string, err error) *UTXOEvent {
+	if err != nil {
+		Logger.Printf("UTXOMaintainer %v %v: %v\n", eventType, tokenIDStr, err)
+	}
+	res := &UTXOEvent{
+		Time:     time.Now().Unix(),
+		Type:     eventType,
+		TokenID:  tokenIDStr,
+		NumUTXOs: numUTXOs,
+		TxHashes: txHashes,
+	}
+	if err != nil {
+		res.Err = err.Error()
+	}
+	return res
+}
+
+// planConsolidation groups the smallest UTXOs into (at most maxTxs) groups of at most MaxInputSize UTXOs, so that
+// merging each group into a single UTXO leaves (as close as possible to) maxUTXOs UTXOs. It returns the indices of
+// the UTXOs of each group.
+func planConsolidation(coins []coin.PlainCoin, maxUTXOs, maxTxs int) [][]int {
+	order := make([]int, len(coins))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return coins[order[i]].GetValue() < coins[order[j]].GetValue()
+	})
+
+	res := make([][]int, 0)
+	toRemove := len(coins) - maxUTXOs
+	current := 0
+	for toRemove > 0 && len(res) < maxTxs {
+		size := toRemove + 1
+		if size > MaxInputSize {
+			size = MaxInputSize
+		}
+		if current+size > len(order) {
+			break
+		}
+		res = append(res, order[current:current+size])
+		current += size
+		toRemove -= size - 1
+	}
+
+	return res
+}
+
+// getSplitAmounts returns the amounts of the outputs of a split transaction spending the given available amount.
+func getSplitAmounts(available uint64, numOutputs int, outputAmount uint64) ([]uint64, error) {
+	if numOutputs <= 0 {
+		return nil, fmt.Errorf("nothing to split")
+	}
+
+	amounts := make([]uint64, 0)
+	if outputAmount == 0 {
+		outputAmount = available / uint64(numOutputs+1)
+		if outputAmount == 0 {
+			return nil, fmt.Errorf("amount %v is too small to be split into %v UTXOs", available, numOutputs+1)
+		}
+		for i := 0; i < numOutputs; i++ {
+			amounts = append(amounts, outputAmount)
+		}
+		return append(amounts, available-outputAmount*uint64(numOutputs)), nil
+	}
+
+	if available < outputAmount*uint64(numOutputs) {
+		return nil, fmt.Errorf("amount %v is too small to create %v UTXOs of %v", available, numOutputs, outputAmount)
+	}
+	for i := 0; i < numOutputs; i++ {
+		amounts = append(amounts, outputAmount)
+	}
+	if change := available - outputAmount*uint64(numOutputs); change > 0 {
+		amounts = append(amounts, change)
+	}
+
+	return amounts, nil
+}
+
+// countFeeCoins returns the number of UTXOs able to pay the DefaultPRVFee.
+func countFeeCoins(coins []coin.PlainCoin) int {
+	res := 0
+	for _, c := range coins {
+		if c.GetValue() >= DefaultPRVFee {
+			res++
+		}
+	}
+	return res
+}
+
+func sumCoinValues(coins []coin.PlainCoin) uint64 {
+	res := uint64(0)
+	for _, c := range coins {
+		res += c.GetValue()
+	}
+	return res
+}
+
+// getCoinID returns the base58-encoded public key of a coin.
+func getCoinID(c coin.PlainCoin) string {
+	return base58.Base58Check{}.Encode(c.GetPublicKey().ToBytesS(), 0)
+}
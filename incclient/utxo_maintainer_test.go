@@ -0,0 +1,221 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func newTestPlainCoins(values ...uint64) []coin.PlainCoin {
+	res := make([]coin.PlainCoin, 0)
+	for _, v := range values {
+		c := new(coin.PlainCoinV1)
+		c.SetValue(v)
+		res = append(res, c)
+	}
+	return res
+}
+
+func TestPlanConsolidation(t *testing.T) {
+	// 5 UTXOs down to 3: merge the 3 smallest ones
+	groups := planConsolidation(newTestPlainCoins(50, 10, 40, 20, 30), 3, 1)
+	if len(groups) != 1 || fmt.Sprint(groups[0]) != "[1 3 4]" {
+		panic(fmt.Sprintf("unexpected groups %v", groups))
+	}
+
+	values := make([]uint64, 0)
+	for i := 0; i < 2*MaxInputSize+5; i++ {
+		values = append(values, uint64(i+1))
+	}
+	coins := newTestPlainCoins(values...)
+
+	// a single transaction per round
+	groups = planConsolidation(coins, 10, 1)
+	if len(groups) != 1 || len(groups[0]) != MaxInputSize {
+		panic(fmt.Sprintf("unexpected groups %v", groups))
+	}
+
+	// enough transactions to reach the target
+	groups = planConsolidation(coins, 10, 5)
+	numRemaining := len(coins)
+	for _, group := range groups {
+		if len(group) < 2 || len(group) > MaxInputSize {
+			panic(fmt.Sprintf("invalid group size %v", len(group)))
+		}
+		numRemaining -= len(group) - 1
+	}
+	if numRemaining != 10 {
+		panic(fmt.Sprintf("expected 10 remaining UTXOs, got %v", numRemaining))
+	}
+
+	if len(planConsolidation(coins, len(coins), 5)) != 0 {
+		panic("nothing to consolidate")
+	}
+}
+
+func TestGetSplitAmounts(t *testing.T) {
+	amounts, err := getSplitAmounts(100, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+	if fmt.Sprint(amounts) != "[33 33 34]" {
+		panic(fmt.Sprintf("unexpected amounts %v", amounts))
+	}
+
+	amounts, err = getSplitAmounts(350, 3, 100)
+	if err != nil {
+		panic(err)
+	}
+	if fmt.Sprint(amounts) != "[100 100 100 50]" {
+		panic(fmt.Sprintf("unexpected amounts %v", amounts))
+	}
+
+	amounts, err = getSplitAmounts(300, 3, 100)
+	if err != nil || len(amounts) != 3 {
+		panic(fmt.Sprintf("expected no change output, got %v, %v", amounts, err))
+	}
+
+	if _, err = getSplitAmounts(250, 3, 100); err == nil {
+		panic("expected an error for an insufficient amount")
+	}
+	if _, err = getSplitAmounts(2, 2, 0); err == nil {
+		panic("expected an error for a too small amount")
+	}
+}
+
+func TestNewUTXOMaintainer(t *testing.T) {
+	privateKey := newRandomWalletInShard(0).Base58CheckSerialize(wallet.PrivateKeyType)
+	client := &IncClient{version: 2}
+
+	invalidPolicies := []UTXOPolicy{
+		{MaxUTXOs: 1},
+		{MinUTXOs: 10, MaxUTXOs: 5},
+		{MaxUTXOs: 5, NumFeeCoins: 5},
+		{MaxTxsPerRound: -1},
+	}
+	for _, policy := range invalidPolicies {
+		if _, err := NewUTXOMaintainer(client, privateKey, policy); err == nil {
+			panic(fmt.Sprintf("expected an error for policy %+v", policy))
+		}
+	}
+
+	m, err := NewUTXOMaintainer(client, privateKey, UTXOPolicy{MinUTXOs: 2, MaxUTXOs: 10, NumFeeCoins: 5, ConvertV1: true})
+	if err != nil {
+		panic(err)
+	}
+	if m.policy.MaxTxsPerRound != 1 || len(m.GetPendingTxs()) != 0 {
+		panic(fmt.Sprintf("unexpected maintainer %+v", m))
+	}
+	if m.shardID != GetShardIDFromPrivateKey(privateKey) || m.addr != PrivateKeyToPaymentAddress(privateKey, -1) {
+		panic(fmt.Sprintf("unexpected shard %v or address %v", m.shardID, m.addr))
+	}
+
+	// the shard and the address follow the network parameters of the client
+	params, err := newNetworkParams(2, 2)
+	if err != nil {
+		panic(err)
+	}
+	client = &IncClient{version: 2, networkParams: params}
+	m, err = NewUTXOMaintainer(client, privateKey, UTXOPolicy{})
+	if err != nil {
+		panic(err)
+	}
+	if m.shardID != client.GetShardIDFromPrivateKey(privateKey) || m.addr != client.PrivateKeyToPaymentAddress(privateKey, -1) {
+		panic(fmt.Sprintf("unexpected shard %v or address %v", m.shardID, m.addr))
+	}
+
+	event := m.newEvent(UTXOEventError, "", 0, nil, fmt.Errorf("some error"))
+	jsb, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+	if !strings.Contains(string(jsb), `"Err":"some error"`) {
+		panic(fmt.Sprintf("error not marshalled: %v", string(jsb)))
+	}
+
+	if _, err = NewUTXOMaintainer(client, "invalid", UTXOPolicy{}); err == nil {
+		panic("expected an error for an invalid private key")
+	}
+	if _, err = NewUTXOMaintainer(nil, privateKey, UTXOPolicy{}); err == nil {
+		panic("expected an error for a nil client")
+	}
+}
+
+// mockFeeNode estimates the fee per kb, and fails to return any transaction.
+type mockFeeNode struct {
+	feePerKb   uint64
+	numQueries map[string]int
+}
+
+func (n *mockFeeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req struct {
+		Method string
+	}
+	_ = json.Unmarshal(body, &req)
+	n.numQueries[req.Method]++
+
+	resp := map[string]interface{}{"Result": nil, "Error": map[string]interface{}{"Code": -1, "Message": "not found"}}
+	if req.Method == "estimatefeewithestimator" {
+		resp = map[string]interface{}{"Result": rpc.EstimateFeeResult{EstimateFeeCoinPerKb: n.feePerKb}, "Error": nil}
+	}
+	jsb, _ := json.Marshal(resp)
+	_, _ = w.Write(jsb)
+}
+
+func hasUTXOEvent(events []*UTXOEvent, eventType string) bool {
+	for _, event := range events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUTXOMaintainer_MaintainOnce(t *testing.T) {
+	privateKey := newRandomWalletInShard(0).Base58CheckSerialize(wallet.PrivateKeyType)
+	node := &mockFeeNode{feePerKb: 100, numQueries: make(map[string]int)}
+	server := httptest.NewServer(node)
+	defer server.Close()
+	client := &IncClient{rpcServer: rpc.NewRPCServer(server.URL), version: 2}
+
+	m, err := NewUTXOMaintainer(client, privateKey, UTXOPolicy{MaxUTXOs: 10, MaxFeePerKb: 50})
+	if err != nil {
+		panic(err)
+	}
+
+	// a transaction whose status cannot be retrieved remains pending until it times out
+	m.pending["txHash"] = time.Now()
+	events := m.MaintainOnce()
+	if len(m.GetPendingTxs()) != 1 || !hasUTXOEvent(events, UTXOEventWaiting) || hasUTXOEvent(events, UTXOEventDropped) {
+		panic(fmt.Sprintf("expected the tx to remain pending, got %v", events))
+	}
+	m.pending["txHash"] = time.Now().Add(-utxoPendingTimeOut - time.Minute)
+	events = m.MaintainOnce()
+	if len(m.GetPendingTxs()) != 0 || !hasUTXOEvent(events, UTXOEventDropped) {
+		panic(fmt.Sprintf("expected the tx to be dropped, got %v", events))
+	}
+
+	// the round is skipped while the estimated fee is above MaxFeePerKb
+	if !hasUTXOEvent(events, UTXOEventHighFee) || node.numQueries["estimatefeewithestimator"] != 1 {
+		panic(fmt.Sprintf("expected a high-fee event, got %v", events))
+	}
+	if node.numQueries["listoutputcoinsfromcache"] != 0 {
+		panic("expected no UTXO to be retrieved")
+	}
+
+	node.feePerKb = 50
+	events = m.MaintainOnce()
+	if hasUTXOEvent(events, UTXOEventHighFee) || node.numQueries["listoutputcoinsfromcache"] == 0 {
+		panic(fmt.Sprintf("expected the round to proceed, got %v", events))
+	}
+}
@@ -0,0 +1,496 @@
+package incclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// ValidatorRole represents the role of a validator in the current beacon best state.
+type ValidatorRole int
+
+const (
+	ValidatorNotStaked ValidatorRole = iota
+	ValidatorShardCandidate
+	ValidatorShardPending
+	ValidatorShardCommittee
+	ValidatorBeaconCandidate
+	ValidatorBeaconPending
+	ValidatorBeaconCommittee
+)
+
+// String returns the human-readable form of a ValidatorRole.
+func (r ValidatorRole) String() string {
+	switch r {
+	case ValidatorNotStaked:
+		return "NotStaked"
+	case ValidatorShardCandidate:
+		return "ShardCandidate"
+	case ValidatorShardPending:
+		return "ShardPending"
+	case ValidatorShardCommittee:
+		return "ShardCommittee"
+	case ValidatorBeaconCandidate:
+		return "BeaconCandidate"
+	case ValidatorBeaconPending:
+		return "BeaconPending"
+	case ValidatorBeaconCommittee:
+		return "BeaconCommittee"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(r))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r ValidatorRole) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// ValidatorKey consists of the keys of a validator managed by a ValidatorManager.
+type ValidatorKey struct {
+	// Label is a user-defined name of the validator. If empty, the committee public key is used.
+	Label string
+
+	// PrivateKey is the private key of the funder, used to stake and to withdraw rewards.
+	PrivateKey string
+
+	// PrivateSeed is the mining key of the validator. If empty, it is derived from the PrivateKey.
+	PrivateSeed string
+
+	// CandidateAddress is the payment address of the candidate. If empty, the payment address of the PrivateKey is used.
+	CandidateAddress string
+
+	// RewardReceiver is the payment address receiving the staking rewards. If empty, the payment address of the
+	// PrivateKey is used.
+	RewardReceiver string
+
+	// AutoStake indicates whether the auto re-stake flag is set when the validator is (re-)staked.
+	AutoStake bool
+}
+
+// ValidatorStatus describes the status of a validator in the current beacon best state.
+type ValidatorStatus struct {
+	Label              string
+	CommitteePublicKey string
+	Role               ValidatorRole
+
+	// ShardID is the shard in which the validator is pending or committing. It is -1 if the validator is not in any shard.
+	ShardID int
+
+	// AutoStaking is the auto re-stake flag recorded by the beacon chain.
+	AutoStaking bool
+
+	// MissingSignature is the signature statistics of the validator in the current epoch (if any).
+	MissingSignature *jsonresult.MissingSignature
+
+	// Penalty is the missing-signature penalty applied to the validator (if any).
+	Penalty *jsonresult.Penalty
+
+	// RewardReceiver is the reward receiver recorded by the beacon chain. It falls back to ValidatorKey.RewardReceiver
+	// if the validator is not staked.
+	RewardReceiver string
+
+	// Rewards is the current staking reward (tokenID => amount) of the RewardReceiver.
+	Rewards map[string]uint64
+}
+
+// IsStaked checks if the validator is either a candidate, a pending validator, or a committee member.
+func (s ValidatorStatus) IsStaked() bool {
+	return s.Role != ValidatorNotStaked
+}
+
+// ValidatorAction is the result of an action (staking or withdrawing rewards) performed by a ValidatorManager.
+type ValidatorAction struct {
+	Label  string
+	Action string
+	Amount uint64
+	TxHash string
+
+	// Err is the error message of the action (if any).
+	Err string `json:"Err,omitempty"`
+}
+
+const (
+	validatorActionStake    = "stake"
+	validatorActionWithdraw = "withdraw"
+)
+
+// DefaultPendingStakeTimeout is the default duration a ValidatorManager waits for a staking transaction to be processed
+// by the beacon chain before re-staking the validator.
+const DefaultPendingStakeTimeout = 2 * time.Hour
+
+// pendingStake is a staking transaction of a ValidatorManager that has not been processed by the beacon chain.
+type pendingStake struct {
+	txHash    string
+	createdAt time.Time
+}
+
+// ValidatorManager monitors and maintains a set of validators: it reports their roles, missing signatures and penalties,
+// re-stakes those that have been un-staked (or swapped out), and sweeps their rewards once they reach a threshold.
+type ValidatorManager struct {
+	client *IncClient
+	keys   []ValidatorKey
+
+	// committeeKeys[i] is the base58-encoded committee public key of keys[i].
+	committeeKeys []string
+
+	stakingAmount       uint64
+	pendingStakeTimeout time.Duration
+
+	mtx           sync.Mutex
+	pendingStakes map[string]*pendingStake // committee public key => staking tx
+}
+
+// NewValidatorManager creates a new ValidatorManager for the given validator keys.
+func NewValidatorManager(client *IncClient, keys []ValidatorKey) (*ValidatorManager, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not found")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no validator key provided")
+	}
+
+	res := &ValidatorManager{
+		client:              client,
+		keys:                make([]ValidatorKey, 0),
+		committeeKeys:       make([]string, 0),
+		stakingAmount:       DefaultShardStakingAmount,
+		pendingStakeTimeout: DefaultPendingStakeTimeout,
+		pendingStakes:       make(map[string]*pendingStake),
+	}
+	seenKeys := make(map[string]bool)
+	for i, k := range keys {
+		funderWallet, err := wallet.Base58CheckDeserialize(k.PrivateKey)
+		if err != nil || len(funderWallet.KeySet.PrivateKey) == 0 {
+			return nil, fmt.Errorf("invalid private key for validator #%v", i)
+		}
+		funderAddr := client.PrivateKeyToPaymentAddress(k.PrivateKey, -1)
+		if k.PrivateSeed == "" {
+			k.PrivateSeed = PrivateKeyToMiningKey(k.PrivateKey)
+		}
+		if k.CandidateAddress == "" {
+			k.CandidateAddress = funderAddr
+		}
+		if k.RewardReceiver == "" {
+			k.RewardReceiver = funderAddr
+		}
+
+		candidateWallet, err := wallet.Base58CheckDeserialize(k.CandidateAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candidate address for validator #%v: %v", i, err)
+		}
+		pk := candidateWallet.KeySet.PaymentAddress.Pk
+		if len(pk) == 0 {
+			return nil, fmt.Errorf("candidate payment address invalid: %v", k.CandidateAddress)
+		}
+		committeeKey, err := getCommitteeKeyStr(k.PrivateSeed, pk)
+		if err != nil {
+			return nil, err
+		}
+		if seenKeys[committeeKey] {
+			return nil, fmt.Errorf("duplicate validator %v", committeeKey)
+		}
+		seenKeys[committeeKey] = true
+		if k.Label == "" {
+			k.Label = committeeKey
+		}
+
+		res.keys = append(res.keys, k)
+		res.committeeKeys = append(res.committeeKeys, committeeKey)
+	}
+
+	return res, nil
+}
+
+// SetStakingAmount sets the amount used when re-staking validators. By default, it is DefaultShardStakingAmount.
+func (m *ValidatorManager) SetStakingAmount(amount uint64) {
+	m.stakingAmount = amount
+}
+
+// SetPendingStakeTimeout sets the duration to wait for a staking transaction to be processed by the beacon chain before
+// re-staking the validator. By default, it is DefaultPendingStakeTimeout.
+func (m *ValidatorManager) SetPendingStakeTimeout(timeout time.Duration) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.pendingStakeTimeout = timeout
+}
+
+// GetCommitteePublicKeys returns the committee public keys of the managed validators, in the order of the given keys.
+func (m *ValidatorManager) GetCommitteePublicKeys() []string {
+	return append([]string{}, m.committeeKeys...)
+}
+
+// GetStatuses returns the current statuses of the managed validators, in the order of the given keys.
+// If withRewards is set, the rewards of each reward receiver are also retrieved.
+func (m *ValidatorManager) GetStatuses(withRewards bool) ([]*ValidatorStatus, error) {
+	bestState, err := m.client.GetBeaconBestState(0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := m.getStatusesFromBestState(bestState)
+	if withRewards {
+		rewardCache := make(map[string]map[string]uint64)
+		for _, status := range res {
+			rewards, ok := rewardCache[status.RewardReceiver]
+			if !ok {
+				rewards, err = m.client.GetRewardAmount(status.RewardReceiver)
+				if err != nil {
+					return nil, fmt.Errorf("cannot get rewards of %v: %v", status.RewardReceiver, err)
+				}
+				rewardCache[status.RewardReceiver] = rewards
+			}
+			status.Rewards = rewards
+		}
+	}
+
+	return res, nil
+}
+
+// getStatusesFromBestState extracts the statuses of the managed validators from a BeaconBestState.
+func (m *ValidatorManager) getStatusesFromBestState(bestState *jsonresult.BeaconBestState) []*ValidatorStatus {
+	res := make([]*ValidatorStatus, 0)
+	for i, k := range m.keys {
+		committeeKey := m.committeeKeys[i]
+		role, shardID := getValidatorRole(bestState, committeeKey)
+		status := &ValidatorStatus{
+			Label:              k.Label,
+			CommitteePublicKey: committeeKey,
+			Role:               role,
+			ShardID:            shardID,
+			AutoStaking:        bestState.AutoStaking[committeeKey],
+			RewardReceiver:     k.RewardReceiver,
+		}
+		if receiver, ok := bestState.RewardReceiver[committeeKey]; ok && receiver != "" {
+			status.RewardReceiver = receiver
+		}
+		if missingSig, ok := bestState.NumberOfMissingSignature[committeeKey]; ok {
+			status.MissingSignature = &missingSig
+		}
+		if penalty, ok := bestState.MissingSignaturePenalty[committeeKey]; ok {
+			status.Penalty = &penalty
+		}
+
+		res = append(res, status)
+	}
+
+	return res
+}
+
+// getValidatorRole returns the role of a committee public key, and the shard it belongs to (-1 if not applicable).
+func getValidatorRole(bestState *jsonresult.BeaconBestState, committeeKey string) (ValidatorRole, int) {
+	if bestState == nil {
+		return ValidatorNotStaked, -1
+	}
+	for shardID, committee := range bestState.ShardCommittee {
+		if containsString(committee, committeeKey) {
+			return ValidatorShardCommittee, int(shardID)
+		}
+	}
+	for shardID, pending := range bestState.ShardPendingValidator {
+		if containsString(pending, committeeKey) {
+			return ValidatorShardPending, int(shardID)
+		}
+	}
+	if containsString(bestState.CandidateShardWaitingForCurrentRandom, committeeKey) ||
+		containsString(bestState.CandidateShardWaitingForNextRandom, committeeKey) {
+		return ValidatorShardCandidate, -1
+	}
+	if containsString(bestState.BeaconCommittee, committeeKey) {
+		return ValidatorBeaconCommittee, -1
+	}
+	if containsString(bestState.BeaconPendingValidator, committeeKey) {
+		return ValidatorBeaconPending, -1
+	}
+	if containsString(bestState.CandidateBeaconWaitingForCurrentRandom, committeeKey) ||
+		containsString(bestState.CandidateBeaconWaitingForNextRandom, committeeKey) {
+		return ValidatorBeaconCandidate, -1
+	}
+
+	return ValidatorNotStaked, -1
+}
+
+func containsString(list []string, s string) bool {
+	for _, tmp := range list {
+		if tmp == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RestakeUnstaked stakes every managed validator listed in restakeLabels that is not currently staked (e.g, it has been
+// un-staked, or swapped out with the auto re-stake flag off). Validators not listed in restakeLabels are never re-staked.
+// A validator whose previous staking transaction is still in the mempool is skipped; one whose staking transaction has
+// been confirmed but not yet processed by the beacon chain is skipped until the pending-stake timeout has elapsed
+// (see SetPendingStakeTimeout).
+//
+// It returns the list of performed actions; errors of individual validators are reported in ValidatorAction.Err.
+func (m *ValidatorManager) RestakeUnstaked(statuses []*ValidatorStatus, restakeLabels []string) []*ValidatorAction {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	res := make([]*ValidatorAction, 0)
+	for i, status := range statuses {
+		if i >= len(m.keys) {
+			break
+		}
+		committeeKey := m.committeeKeys[i]
+		if status.IsStaked() {
+			delete(m.pendingStakes, committeeKey)
+			continue
+		}
+		k := m.keys[i]
+		if !containsString(restakeLabels, k.Label) {
+			continue
+		}
+		if p, ok := m.pendingStakes[committeeKey]; ok {
+			txDetail, err := m.client.GetTxDetail(p.txHash)
+			if err == nil && txDetail.IsInMempool {
+				Logger.Printf("Validator %v: staking tx %v is still pending\n", status.Label, p.txHash)
+				continue
+			}
+			if time.Since(p.createdAt) >= m.pendingStakeTimeout {
+				// the staking transaction has not been reflected in the beacon state in time (e.g, it was rejected)
+				Logger.Printf("Validator %v: staking tx %v timed out\n", status.Label, p.txHash)
+			} else if err == nil {
+				Logger.Printf("Validator %v: staking tx %v is being processed\n", status.Label, p.txHash)
+				continue
+			}
+			// the previous staking transaction has been dropped, or has timed out
+			delete(m.pendingStakes, committeeKey)
+		}
+
+		action := &ValidatorAction{Label: k.Label, Action: validatorActionStake, Amount: m.stakingAmount}
+		encodedTx, txHash, err := m.client.CreateShardStakingTransactionWithAmount(
+			k.PrivateKey, k.PrivateSeed, k.CandidateAddress, k.RewardReceiver, k.AutoStake, m.stakingAmount)
+		if err == nil {
+			err = m.client.SendRawTx(encodedTx)
+		}
+		if err != nil {
+			action.Err = err.Error()
+		} else {
+			action.TxHash = txHash
+			m.pendingStakes[committeeKey] = &pendingStake{txHash: txHash, createdAt: time.Now()}
+		}
+		res = append(res, action)
+	}
+
+	return res
+}
+
+// SweepRewards withdraws the PRV rewards of every reward receiver whose balance is at least threshold. Rewards can only be
+// withdrawn by the owner of the reward receiver; hence, receivers not belonging to the PrivateKey of any managed validator
+// are reported with an error. Receivers are compared by their public keys, so different encodings of the same payment
+// address are withdrawn at most once.
+//
+// Only PRV rewards are swept; rewards in other tokens (see ValidatorStatus.Rewards) must be withdrawn with
+// CreateAndSendWithDrawRewardTransaction.
+//
+// It returns the list of performed actions; errors of individual validators are reported in ValidatorAction.Err.
+func (m *ValidatorManager) SweepRewards(statuses []*ValidatorStatus, threshold uint64) []*ValidatorAction {
+	ownedReceivers := make(map[string]string) // public key => private key
+	for _, k := range m.keys {
+		w, err := wallet.Base58CheckDeserialize(k.PrivateKey)
+		if err != nil {
+			continue
+		}
+		ownedReceivers[string(w.KeySet.PaymentAddress.Pk)] = k.PrivateKey
+	}
+
+	res := make([]*ValidatorAction, 0)
+	swept := make(map[string]bool)
+	for _, status := range statuses {
+		receiver := status.RewardReceiver
+		action := &ValidatorAction{Label: status.Label, Action: validatorActionWithdraw}
+		receiverWallet, err := wallet.Base58CheckDeserialize(receiver)
+		if err != nil || len(receiverWallet.KeySet.PaymentAddress.Pk) == 0 {
+			action.Err = fmt.Sprintf("invalid reward receiver %v", receiver)
+			res = append(res, action)
+			continue
+		}
+		receiverPk := string(receiverWallet.KeySet.PaymentAddress.Pk)
+		if swept[receiverPk] {
+			continue
+		}
+		swept[receiverPk] = true
+
+		rewards := status.Rewards
+		if rewards == nil {
+			rewards, err = m.client.GetRewardAmount(receiver)
+			if err != nil {
+				action.Err = fmt.Sprintf("cannot get rewards of %v: %v", receiver, err)
+				res = append(res, action)
+				continue
+			}
+		}
+		action.Amount = rewards[common.PRVIDStr]
+		if action.Amount == 0 || action.Amount < threshold {
+			continue
+		}
+
+		privateKey, ok := ownedReceivers[receiverPk]
+		if !ok {
+			action.Err = fmt.Sprintf("reward receiver %v does not belong to any managed private key", receiver)
+			res = append(res, action)
+			continue
+		}
+		txHash, err := m.client.CreateAndSendWithDrawRewardTransaction(privateKey, receiver, common.PRVIDStr, 2)
+		if err != nil {
+			action.Err = err.Error()
+		} else {
+			action.TxHash = txHash
+		}
+		res = append(res, action)
+	}
+
+	return res
+}
+
+// MaintainOnce retrieves the statuses of the managed validators, re-stakes the un-staked validators listed in
+// restakeLabels (see RestakeUnstaked), and sweeps rewards above rewardThreshold (if rewardThreshold > 0).
+func (m *ValidatorManager) MaintainOnce(restakeLabels []string, rewardThreshold uint64) ([]*ValidatorStatus, []*ValidatorAction, error) {
+	statuses, err := m.GetStatuses(rewardThreshold > 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actions := make([]*ValidatorAction, 0)
+	if len(restakeLabels) > 0 {
+		actions = append(actions, m.RestakeUnstaked(statuses, restakeLabels)...)
+	}
+	if rewardThreshold > 0 {
+		actions = append(actions, m.SweepRewards(statuses, rewardThreshold)...)
+	}
+
+	return statuses, actions, nil
+}
+
+// Run calls MaintainOnce every interval until the stop channel is closed. The results of each round are passed to
+// the given callback (if not nil).
+func (m *ValidatorManager) Run(
+	interval time.Duration, restakeLabels []string, rewardThreshold uint64, stop <-chan struct{},
+	callback func([]*ValidatorStatus, []*ValidatorAction, error),
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		statuses, actions, err := m.MaintainOnce(restakeLabels, rewardThreshold)
+		if err != nil {
+			Logger.Printf("MaintainOnce error: %v\n", err)
+		}
+		if callback != nil {
+			callback(statuses, actions, err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package incclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestValidatorManager_getStatusesFromBestState(t *testing.T) {
+	keys := make([]ValidatorKey, 0)
+	for i := 0; i < 5; i++ {
+		w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+		if err != nil {
+			panic(err)
+		}
+		privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+		keys = append(keys, ValidatorKey{Label: fmt.Sprintf("node-%v", i), PrivateKey: privateKey})
+	}
+
+	m, err := NewValidatorManager(&IncClient{}, keys)
+	if err != nil {
+		panic(err)
+	}
+	committeeKeys := m.GetCommitteePublicKeys()
+
+	bestState := &jsonresult.BeaconBestState{
+		BeaconCommittee:                    []string{committeeKeys[4]},
+		CandidateShardWaitingForNextRandom: []string{committeeKeys[0]},
+		ShardPendingValidator:              map[byte][]string{3: {committeeKeys[1]}},
+		ShardCommittee:                     map[byte][]string{0: {"foo"}, 5: {committeeKeys[2]}},
+		AutoStaking:                        map[string]bool{committeeKeys[1]: true, committeeKeys[2]: true},
+		RewardReceiver:                     map[string]string{committeeKeys[2]: "someReceiver"},
+		NumberOfMissingSignature:           map[string]jsonresult.MissingSignature{committeeKeys[2]: {Total: 100, Missing: 30}},
+		MissingSignaturePenalty:            map[string]jsonresult.Penalty{committeeKeys[2]: {MinPercent: 50, ForceUnStake: true}},
+	}
+
+	expectedRoles := []ValidatorRole{ValidatorShardCandidate, ValidatorShardPending, ValidatorShardCommittee, ValidatorNotStaked, ValidatorBeaconCommittee}
+	expectedShards := []int{-1, 3, 5, -1, -1}
+	statuses := m.getStatusesFromBestState(bestState)
+	for i, status := range statuses {
+		if status.Role != expectedRoles[i] {
+			panic(fmt.Sprintf("validator #%v: expect role %v, got %v", i, expectedRoles[i], status.Role))
+		}
+		if status.ShardID != expectedShards[i] {
+			panic(fmt.Sprintf("validator #%v: expect shard %v, got %v", i, expectedShards[i], status.ShardID))
+		}
+		if status.AutoStaking != (i == 1 || i == 2) {
+			panic(fmt.Sprintf("validator #%v: invalid auto-staking flag", i))
+		}
+		if status.IsStaked() != (i != 3) {
+			panic(fmt.Sprintf("validator #%v: invalid IsStaked", i))
+		}
+	}
+	if statuses[2].RewardReceiver != "someReceiver" {
+		panic(fmt.Sprintf("expect reward receiver someReceiver, got %v", statuses[2].RewardReceiver))
+	}
+	if statuses[0].RewardReceiver != PrivateKeyToPaymentAddress(keys[0].PrivateKey, -1) {
+		panic("expect the default reward receiver")
+	}
+	if statuses[2].MissingSignature == nil || statuses[2].MissingSignature.Missing != 30 {
+		panic("missing signature not found")
+	}
+	if statuses[2].Penalty == nil || !statuses[2].Penalty.ForceUnStake {
+		panic("penalty not found")
+	}
+	if statuses[0].MissingSignature != nil || statuses[0].Penalty != nil {
+		panic("expect no missing signature and penalty")
+	}
+
+	jsb, err := json.MarshalIndent(statuses, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(jsb))
+}
+
+func TestNewValidatorManager(t *testing.T) {
+	w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+	privateKey := w.Base58CheckSerialize(wallet.PrivateKeyType)
+
+	_, err = NewValidatorManager(&IncClient{}, []ValidatorKey{{PrivateKey: "abc"}})
+	if err == nil {
+		panic("expect an error for invalid private key")
+	}
+	_, err = NewValidatorManager(&IncClient{}, []ValidatorKey{{PrivateKey: privateKey}, {PrivateKey: privateKey}})
+	if err == nil {
+		panic("expect an error for duplicate validators")
+	}
+
+	m, err := NewValidatorManager(&IncClient{}, []ValidatorKey{{PrivateKey: privateKey}})
+	if err != nil {
+		panic(err)
+	}
+	expectedKey, err := getCommitteeKeyStr(PrivateKeyToMiningKey(privateKey), PrivateKeyToPublicKey(privateKey))
+	if err != nil {
+		panic(err)
+	}
+	if m.GetCommitteePublicKeys()[0] != expectedKey || m.keys[0].Label != expectedKey {
+		panic(fmt.Sprintf("expect committee key %v, got %v", expectedKey, m.GetCommitteePublicKeys()[0]))
+	}
+}
+
+func TestValidatorManager_MaintainOnce(t *testing.T) {
+	var err error
+	ic, err = NewTestNet1Client()
+	if err != nil {
+		panic(err)
+	}
+
+	privateKey := "" // input the private key of the funder
+	m, err := NewValidatorManager(ic, []ValidatorKey{{PrivateKey: privateKey, AutoStake: true}})
+	if err != nil {
+		panic(err)
+	}
+
+	statuses, actions, err := m.MaintainOnce(nil, 100*DefaultPRVFee)
+	if err != nil {
+		panic(err)
+	}
+	jsb, _ := json.MarshalIndent(statuses, "", "\t")
+	fmt.Println(string(jsb))
+	for _, action := range actions {
+		fmt.Printf("%v %v %v: %v %v\n", action.Label, action.Action, action.Amount, action.TxHash, action.Err)
+	}
+}
+
+func TestValidatorManager_RestakeUnstaked(t *testing.T) {
+	pendingTxHash := common.HashH(common.RandBytes(32)).String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string
+			Params []interface{}
+		}
+		_ = json.Unmarshal(body, &req)
+
+		var result interface{}
+		if req.Method == "gettransactionbyhash" && req.Params[0] == pendingTxHash {
+			result = jsonresult.TransactionDetail{Hash: pendingTxHash, IsInMempool: true}
+		}
+		jsb, _ := json.Marshal(result)
+		resp, _ := json.Marshal(map[string]interface{}{"Result": json.RawMessage(jsb), "Error": nil})
+		_, _ = w.Write(resp)
+	}))
+	defer server.Close()
+
+	keys := make([]ValidatorKey, 0)
+	for i := 0; i < 2; i++ {
+		w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, ValidatorKey{Label: fmt.Sprintf("node-%v", i), PrivateKey: w.Base58CheckSerialize(wallet.PrivateKeyType)})
+	}
+	m, err := NewValidatorManager(&IncClient{rpcServer: rpc.NewRPCServer(server.URL)}, keys)
+	if err != nil {
+		panic(err)
+	}
+	m.SetPendingStakeTimeout(0)
+	m.pendingStakes[m.committeeKeys[0]] = &pendingStake{txHash: pendingTxHash, createdAt: time.Now().Add(-time.Hour)}
+
+	statuses := m.getStatusesFromBestState(&jsonresult.BeaconBestState{})
+
+	// node-0 has a staking tx in the mempool (even though it has timed out), and node-1 has not opted in.
+	actions := m.RestakeUnstaked(statuses, []string{"node-0"})
+	if len(actions) != 0 {
+		panic(fmt.Sprintf("expect no actions, got %v", len(actions)))
+	}
+	if _, ok := m.pendingStakes[m.committeeKeys[0]]; !ok {
+		panic("the pending stake of node-0 must be kept")
+	}
+}
+
+func TestValidatorManager_SweepRewards(t *testing.T) {
+	w, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+	m, err := NewValidatorManager(&IncClient{}, []ValidatorKey{{Label: "node", PrivateKey: w.Base58CheckSerialize(wallet.PrivateKeyType)}})
+	if err != nil {
+		panic(err)
+	}
+
+	// the same receiver (not owned by the manager) in two different encodings.
+	other, err := wallet.NewMasterKeyFromSeed(common.RandBytes(32))
+	if err != nil {
+		panic(err)
+	}
+	receiver := other.Base58CheckSerialize(wallet.PaymentAddressType)
+	receiverV1, err := wallet.GetPaymentAddressV1(receiver, false)
+	if err != nil {
+		panic(err)
+	}
+	rewards := map[string]uint64{common.PRVIDStr: 1000}
+	statuses := []*ValidatorStatus{
+		{Label: "a", RewardReceiver: receiver, Rewards: rewards},
+		{Label: "b", RewardReceiver: receiverV1, Rewards: rewards},
+		{Label: "c", RewardReceiver: "invalid", Rewards: rewards},
+	}
+
+	actions := m.SweepRewards(statuses, 100)
+	if len(actions) != 2 {
+		panic(fmt.Sprintf("expect 2 actions, got %v", len(actions)))
+	}
+	if actions[0].Label != "a" || actions[0].Err == "" {
+		panic("expect an error for a receiver not owned by the manager")
+	}
+	if actions[1].Label != "c" || actions[1].Err == "" {
+		panic("expect an error for an invalid receiver")
+	}
+}
@@ -340,6 +340,49 @@ var portalV4MetaTypes = []int{
 	PortalV4ConvertVaultRequestMeta,
 }
 
+var pdexMetaTypes = []int{
+	PDEContributionMeta,
+	PDETradeRequestMeta,
+	PDETradeResponseMeta,
+	PDEWithdrawalRequestMeta,
+	PDEWithdrawalResponseMeta,
+	PDEContributionResponseMeta,
+	PDEPRVRequiredContributionRequestMeta,
+	PDECrossPoolTradeRequestMeta,
+	PDECrossPoolTradeResponseMeta,
+	PDEFeeWithdrawalRequestMeta,
+	PDEFeeWithdrawalResponseMeta,
+	PDETradingFeesDistributionMeta,
+	Pdexv3ModifyParamsMeta,
+	Pdexv3AddLiquidityRequestMeta,
+	Pdexv3AddLiquidityResponseMeta,
+	Pdexv3WithdrawLiquidityRequestMeta,
+	Pdexv3WithdrawLiquidityResponseMeta,
+	Pdexv3TradeRequestMeta,
+	Pdexv3TradeResponseMeta,
+	Pdexv3AddOrderRequestMeta,
+	Pdexv3AddOrderResponseMeta,
+	Pdexv3WithdrawOrderRequestMeta,
+	Pdexv3WithdrawOrderResponseMeta,
+	Pdexv3UserMintNftRequestMeta,
+	Pdexv3UserMintNftResponseMeta,
+	Pdexv3MintNftRequestMeta,
+	Pdexv3MintNftResponseMeta,
+	Pdexv3StakingRequestMeta,
+	Pdexv3StakingResponseMeta,
+	Pdexv3UnstakingRequestMeta,
+	Pdexv3UnstakingResponseMeta,
+	Pdexv3WithdrawLPFeeRequestMeta,
+	Pdexv3WithdrawLPFeeResponseMeta,
+	Pdexv3WithdrawProtocolFeeRequestMeta,
+	Pdexv3WithdrawProtocolFeeResponseMeta,
+	Pdexv3MintPDEXGenesisMeta,
+	Pdexv3MintBlockRewardMeta,
+	Pdexv3DistributeStakingRewardMeta,
+	Pdexv3WithdrawStakingRewardRequestMeta,
+	Pdexv3WithdrawStakingRewardResponseMeta,
+}
+
 // NOTE: add new records when add new feature flags
 // var FeatureFlagWithMetaTypes = map[string][]int{
 // 	common.PortalRelayingFlag: portalRelayingMetaTypes,
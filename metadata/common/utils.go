@@ -489,6 +489,12 @@ func IsPortalMetaTypeV4(metaType int) bool {
 	return res
 }
 
+// IsPDEXMetaType checks if a metadata type belongs to the pDEX (v2 or v3).
+func IsPDEXMetaType(metaType int) bool {
+	res, _ := common.SliceExists(pdexMetaTypes, metaType)
+	return res
+}
+
 //genTokenID generates a (deterministically) random tokenID for the request transaction.
 //From now on, users cannot generate their own tokenID.
 //The generated tokenID is calculated as the hash of the following components:
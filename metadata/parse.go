@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
 	metadataInsc "github.com/incognitochain/go-incognito-sdk-v2/metadata/inscription"
@@ -11,6 +12,57 @@ import (
 	"github.com/pkg/errors"
 )
 
+// evmMetadataRegistry keeps track of the metadata types of EVM networks registered at runtime.
+type evmMetadataRegistry struct {
+	mtx                  sync.RWMutex
+	issuingRequestTypes  map[int]bool
+	issuingResponseTypes map[int]bool
+	burningRequestTypes  map[int]bool
+}
+
+var registeredEVMMetadata = evmMetadataRegistry{
+	issuingRequestTypes:  make(map[int]bool),
+	issuingResponseTypes: make(map[int]bool),
+	burningRequestTypes:  make(map[int]bool),
+}
+
+// RegisterEVMMetadataTypes registers the metadata types of an additional EVM-compatible network so that they can be
+// decoded by ParseMetadata. A zero-valued type is ignored.
+func RegisterEVMMetadataTypes(issuingRequestType, issuingResponseType int, burningRequestTypes ...int) {
+	registeredEVMMetadata.mtx.Lock()
+	defer registeredEVMMetadata.mtx.Unlock()
+
+	if issuingRequestType != 0 {
+		registeredEVMMetadata.issuingRequestTypes[issuingRequestType] = true
+	}
+	if issuingResponseType != 0 {
+		registeredEVMMetadata.issuingResponseTypes[issuingResponseType] = true
+	}
+	for _, mdType := range burningRequestTypes {
+		if mdType != 0 {
+			registeredEVMMetadata.burningRequestTypes[mdType] = true
+		}
+	}
+}
+
+// newRegisteredEVMMetadata returns an empty Metadata for a type registered via RegisterEVMMetadataTypes.
+func newRegisteredEVMMetadata(mdType int) (Metadata, bool) {
+	registeredEVMMetadata.mtx.RLock()
+	defer registeredEVMMetadata.mtx.RUnlock()
+
+	switch {
+	case registeredEVMMetadata.issuingRequestTypes[mdType]:
+		return &IssuingEVMRequest{}, true
+	case registeredEVMMetadata.issuingResponseTypes[mdType]:
+		return &IssuingEVMResponse{}, true
+	case registeredEVMMetadata.burningRequestTypes[mdType]:
+		return &BurningRequest{}, true
+	default:
+		return nil, false
+	}
+}
+
+// ParseMetadata parses a metadata from its JSON representation.
 func ParseMetadata(metaInBytes []byte) (Metadata, error) {
 	if len(metaInBytes) == 0 {
 		return nil, nil
@@ -186,7 +238,9 @@ func ParseMetadata(metaInBytes []byte) (Metadata, error) {
 	case metadataCommon.InscribeResponseMeta:
 		md = &metadataInsc.InscribeResponse{}
 	default:
-		return nil, errors.Errorf("Could not parse metadata with type: %d", theType)
+		if md, ok = newRegisteredEVMMetadata(theType); !ok {
+			return nil, errors.Errorf("Could not parse metadata with type: %d", theType)
+		}
 	}
 
 	err = json.Unmarshal(metaInBytes, &md)
@@ -12,7 +12,8 @@ type ReceiverInfo struct {
 	Amount  uint64           `json:"Amount"`
 }
 
-// Check if the given OTA address is a valid address and has the expected shard ID
+// Check if the given OTA address is a valid address and has the expected shard ID.
+// The shard ID is derived w.r.t the default number of shards; hence, it only supports networks with the default parameters.
 func isValidOTAReceiver(receiverAddress coin.OTAReceiver, expectedShardID byte) (coin.OTAReceiver, error) {
 	if !receiverAddress.IsValid() {
 		return receiverAddress, errors.New("ReceiverAddress is invalid")
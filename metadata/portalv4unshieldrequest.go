@@ -29,6 +29,30 @@ type PortalUnshieldRequestStatus struct {
 	Status         int
 }
 
+// The statuses of a PortalUnshieldRequestStatus.
+const (
+	PortalUnshieldReqWaitingStatus   = 0
+	PortalUnshieldReqProcessedStatus = 1
+	PortalUnshieldReqCompletedStatus = 2
+	PortalUnshieldReqRefundedStatus  = 3
+)
+
+// PortalUnshieldRequestBatchStatus represents the status of a batch of un-shield requests on the Portal.
+type PortalUnshieldRequestBatchStatus struct {
+	TokenID       string
+	BatchID       string
+	UnshieldIDs   []string
+	RawExternalTx string
+	BeaconHeight  uint64
+	Status        byte
+}
+
+// The statuses of a PortalUnshieldRequestBatchStatus.
+const (
+	PortalBatchUnshieldReqProcessedStatus = 0
+	PortalBatchUnshieldReqCompletedStatus = 1
+)
+
 // NewPortalUnshieldRequest creates a new PortalUnshieldRequest.
 func NewPortalUnshieldRequest(metaType int, otaPubKeyStr, txRandomStr string, tokenID, remoteAddress string, burnAmount uint64) (*PortalUnshieldRequest, error) {
 	portalUnshieldReq := &PortalUnshieldRequest{
@@ -0,0 +1,287 @@
+// Package paymenturi implements the `incognito:` URI scheme used to request a payment on the Incognito network.
+//
+// A payment URI has the following form:
+//
+//	incognito:<paymentAddress>?amount=<amount>&decimals=<decimals>&token=<tokenID>&memo=<memo>&label=<label>
+//
+// All query parameters are optional:
+//   - amount: the requested amount, in human-readable units (e.g, 1.5).
+//   - decimals: the number of decimals of the token, used to convert the amount into its smallest unit. It defaults
+//     to 9 for PRV, and is required for other tokens whenever an amount is given.
+//   - token: the requested tokenID. It defaults to PRV.
+//   - memo: a payment reference (e.g, an invoice ID) to be attached, encrypted, to the payment.
+//   - label: a description of the receiver or of the payment, for display purposes only.
+//
+// Unknown parameters are ignored, except those prefixed with `req-`, which a parser must understand.
+package paymenturi
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+const (
+	// Scheme is the scheme of a payment URI.
+	Scheme = "incognito"
+
+	// PRVDecimals is the number of decimals of PRV.
+	PRVDecimals = 9
+
+	// MaxDecimals is the maximum number of decimals supported by a payment URI.
+	MaxDecimals = 18
+
+	amountParam   = "amount"
+	decimalsParam = "decimals"
+	tokenParam    = "token"
+	memoParam     = "memo"
+	labelParam    = "label"
+	requiredParam = "req-"
+)
+
+// PaymentRequest is a request to pay an amount of a token to a payment address.
+type PaymentRequest struct {
+	// Address is the base58-encoded payment address of the receiver.
+	Address string
+
+	// TokenID is the requested tokenID. An empty TokenID means PRV.
+	TokenID string
+
+	// Amount is the requested amount in the smallest unit of the token. A zero Amount lets the payer decide.
+	Amount uint64
+
+	// Decimals is the number of decimals of the token.
+	Decimals uint8
+
+	// Memo is a payment reference attached to the payment.
+	Memo string
+
+	// Label is a description of the receiver or of the payment.
+	Label string
+}
+
+// NewPaymentRequest creates a new PaymentRequest of a PRV or token amount (in the smallest unit), and validates it.
+// For PRV, decimals can be left 0.
+func NewPaymentRequest(address, tokenIDStr string, amount uint64, decimals uint8, memo string) (*PaymentRequest, error) {
+	if tokenIDStr == "" || tokenIDStr == common.PRVIDStr {
+		tokenIDStr = ""
+		decimals = PRVDecimals
+	}
+	req := &PaymentRequest{
+		Address:  address,
+		TokenID:  tokenIDStr,
+		Amount:   amount,
+		Decimals: decimals,
+		Memo:     memo,
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// GetTokenID returns the requested tokenID, PRV if not specified.
+func (req PaymentRequest) GetTokenID() string {
+	if req.TokenID == "" {
+		return common.PRVIDStr
+	}
+	return req.TokenID
+}
+
+// Validate checks if a PaymentRequest is valid.
+func (req PaymentRequest) Validate() error {
+	w, err := wallet.Base58CheckDeserialize(req.Address)
+	if err != nil {
+		return fmt.Errorf("invalid payment address %v: %v", req.Address, err)
+	}
+	if len(w.KeySet.PrivateKey) != 0 || len(w.KeySet.PaymentAddress.Pk) == 0 {
+		return fmt.Errorf("%v is not a payment address", req.Address)
+	}
+
+	if len(req.GetTokenID()) != 2*common.HashSize {
+		return fmt.Errorf("invalid tokenID %v: expected %v hex characters", req.TokenID, 2*common.HashSize)
+	}
+	if _, err = new(common.Hash).NewHashFromStr(req.GetTokenID()); err != nil {
+		return fmt.Errorf("invalid tokenID %v: %v", req.TokenID, err)
+	}
+	if req.Decimals > MaxDecimals {
+		return fmt.Errorf("decimals (%v) exceeds %v", req.Decimals, MaxDecimals)
+	}
+	if req.GetTokenID() == common.PRVIDStr && req.Decimals != PRVDecimals {
+		return fmt.Errorf("PRV has %v decimals, got %v", PRVDecimals, req.Decimals)
+	}
+	if len(req.Memo) > incclient.MaxMemoSize {
+		return fmt.Errorf("memo size (%v) exceeds the maximum size (%v)", len(req.Memo), incclient.MaxMemoSize)
+	}
+
+	return nil
+}
+
+// String returns the URI of a PaymentRequest. Parameters are sorted by key, so that the same request always renders
+// into the same (QR-friendly) string.
+func (req PaymentRequest) String() string {
+	values := url.Values{}
+	if req.Amount > 0 {
+		values.Set(amountParam, FormatAmount(req.Amount, req.Decimals))
+		if req.GetTokenID() != common.PRVIDStr {
+			values.Set(decimalsParam, strconv.Itoa(int(req.Decimals)))
+		}
+	}
+	if req.GetTokenID() != common.PRVIDStr {
+		values.Set(tokenParam, req.TokenID)
+	}
+	if req.Memo != "" {
+		values.Set(memoParam, req.Memo)
+	}
+	if req.Label != "" {
+		values.Set(labelParam, req.Label)
+	}
+
+	res := Scheme + ":" + req.Address
+	if len(values) > 0 {
+		// spaces are rendered as %20 rather than +, which is not decoded by every URI parser
+		res += "?" + strings.Replace(values.Encode(), "+", "%20", -1)
+	}
+	return res
+}
+
+// Parse parses and validates a payment URI.
+func Parse(uri string) (*PaymentRequest, error) {
+	uri = strings.TrimSpace(uri)
+	prefix := Scheme + ":"
+	if len(uri) < len(prefix) || !strings.EqualFold(uri[:len(prefix)], prefix) {
+		return nil, fmt.Errorf("not an %v URI: %v", Scheme, uri)
+	}
+	rest := strings.TrimPrefix(uri[len(prefix):], "//")
+
+	address, rawQuery := rest, ""
+	if i := strings.Index(rest, "?"); i >= 0 {
+		address, rawQuery = rest[:i], rest[i+1:]
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %v: %v", rawQuery, err)
+	}
+
+	req := &PaymentRequest{Address: address, Decimals: PRVDecimals}
+	for k, v := range values {
+		if len(v) != 1 {
+			return nil, fmt.Errorf("parameter %v is given %v times", k, len(v))
+		}
+		switch k {
+		case amountParam, decimalsParam:
+		case tokenParam:
+			req.TokenID = v[0]
+			if req.TokenID == common.PRVIDStr {
+				req.TokenID = ""
+			}
+		case memoParam:
+			req.Memo = v[0]
+		case labelParam:
+			req.Label = v[0]
+		default:
+			if strings.HasPrefix(k, requiredParam) {
+				return nil, fmt.Errorf("unsupported required parameter %v", k)
+			}
+		}
+	}
+
+	if decimalsStr := values.Get(decimalsParam); decimalsStr != "" {
+		decimals, err := strconv.ParseUint(decimalsStr, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimals %v: %v", decimalsStr, err)
+		}
+		req.Decimals = uint8(decimals)
+	} else if req.GetTokenID() != common.PRVIDStr && values.Get(amountParam) != "" {
+		return nil, fmt.Errorf("decimals is required for the amount of token %v", req.TokenID)
+	}
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if amountStr := values.Get(amountParam); amountStr != "" {
+		req.Amount, err = ParseAmount(amountStr, req.Decimals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// NewTxParam creates a TxParam paying a PaymentRequest from the given private key. For a token request, the returned
+// TxParam holds the corresponding TxTokenParam. The memo of the request, if any, is attached to the payment.
+func (req PaymentRequest) NewTxParam(privateKey string) (*incclient.TxParam, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Amount == 0 {
+		return nil, fmt.Errorf("the payment request does not specify an amount")
+	}
+
+	var memoList []string
+	if req.Memo != "" {
+		memoList = []string{req.Memo}
+	}
+
+	if req.GetTokenID() == common.PRVIDStr {
+		return incclient.NewTxParam(privateKey, []string{req.Address}, []uint64{req.Amount}, 0, nil, nil, nil).
+			SetMemos(memoList), nil
+	}
+	tokenParam := incclient.NewTxTokenParam(req.TokenID, 1, []string{req.Address}, []uint64{req.Amount}, false, 0, nil).
+		SetMemos(memoList)
+	return incclient.NewTxParam(privateKey, []string{}, []uint64{}, 0, tokenParam, nil, nil), nil
+}
+
+// FormatAmount formats an amount in the smallest unit into a human-readable decimal string, without trailing zeros.
+func FormatAmount(amount uint64, decimals uint8) string {
+	res := new(big.Int).SetUint64(amount).String()
+	if decimals == 0 {
+		return res
+	}
+	if len(res) <= int(decimals) {
+		res = strings.Repeat("0", int(decimals)-len(res)+1) + res
+	}
+
+	integer, fraction := res[:len(res)-int(decimals)], strings.TrimRight(res[len(res)-int(decimals):], "0")
+	if fraction == "" {
+		return integer
+	}
+	return integer + "." + fraction
+}
+
+// ParseAmount parses a human-readable decimal amount into its smallest unit given the number of decimals.
+func ParseAmount(amountStr string, decimals uint8) (uint64, error) {
+	integer, fraction := amountStr, ""
+	if i := strings.Index(amountStr, "."); i >= 0 {
+		integer, fraction = amountStr[:i], amountStr[i+1:]
+	}
+	if integer == "" && fraction == "" {
+		return 0, fmt.Errorf("invalid amount %v", amountStr)
+	}
+	if len(fraction) > int(decimals) {
+		return 0, fmt.Errorf("amount %v has more than %v decimals", amountStr, decimals)
+	}
+	digits := integer + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid amount %v", amountStr)
+		}
+	}
+
+	amount, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %v: %v", amountStr, err)
+	}
+	if amount == 0 {
+		return 0, fmt.Errorf("amount must be greater than 0")
+	}
+	return amount, nil
+}
@@ -0,0 +1,187 @@
+package paymenturi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+var testTokenID = common.HashH([]byte("token")).String()
+
+func newTestAddress() string {
+	w, err := wallet.GenRandomWalletForShardID(0)
+	if err != nil {
+		panic(err)
+	}
+	return w.Base58CheckSerialize(wallet.PaymentAddressType)
+}
+
+func TestAmount(t *testing.T) {
+	for _, tc := range []struct {
+		amount   uint64
+		decimals uint8
+		str      string
+	}{
+		{1500000000, 9, "1.5"},
+		{1, 9, "0.000000001"},
+		{42, 0, "42"},
+		{1000000, 6, "1"},
+		{123456789, 4, "12345.6789"},
+	} {
+		if s := FormatAmount(tc.amount, tc.decimals); s != tc.str {
+			panic(fmt.Sprintf("expected %v, got %v", tc.str, s))
+		}
+		amount, err := ParseAmount(tc.str, tc.decimals)
+		if err != nil {
+			panic(err)
+		}
+		if amount != tc.amount {
+			panic(fmt.Sprintf("expected %v, got %v", tc.amount, amount))
+		}
+	}
+
+	if amount, err := ParseAmount(".5", 1); err != nil || amount != 5 {
+		panic(fmt.Sprintf("expected 5, got %v, %v", amount, err))
+	}
+	for _, invalid := range []string{"", ".", "1.234", "-1", "1e3", "0", "0.00", "18446744073709551616"} {
+		if _, err := ParseAmount(invalid, 2); err == nil {
+			panic(fmt.Sprintf("expected an error for %v", invalid))
+		}
+	}
+}
+
+func TestPaymentRequest(t *testing.T) {
+	addr := newTestAddress()
+
+	req, err := NewPaymentRequest(addr, "", 1500000000, 0, "INV 42/A")
+	if err != nil {
+		panic(err)
+	}
+	uri := req.String()
+	if uri != fmt.Sprintf("incognito:%v?amount=1.5&memo=INV%%2042%%2FA", addr) {
+		panic(fmt.Sprintf("unexpected uri %v", uri))
+	}
+	parsed, err := Parse(uri)
+	if err != nil {
+		panic(err)
+	}
+	if *parsed != *req {
+		panic(fmt.Sprintf("expected %+v, got %+v", req, parsed))
+	}
+
+	req, err = NewPaymentRequest(addr, testTokenID, 2500, 3, "")
+	if err != nil {
+		panic(err)
+	}
+	req.Label = "Coffee shop"
+	parsed, err = Parse(strings.ToUpper(Scheme) + "://" + strings.TrimPrefix(req.String(), Scheme+":"))
+	if err != nil {
+		panic(err)
+	}
+	if *parsed != *req || parsed.GetTokenID() != testTokenID {
+		panic(fmt.Sprintf("expected %+v, got %+v", req, parsed))
+	}
+
+	// an amount-less request, with an ignored unknown parameter
+	parsed, err = Parse(fmt.Sprintf("incognito:%v?token=%v&foo=bar", addr, testTokenID))
+	if err != nil {
+		panic(err)
+	}
+	if parsed.Amount != 0 {
+		panic(fmt.Sprintf("unexpected amount %v", parsed.Amount))
+	}
+	if _, err = parsed.NewTxParam(""); err == nil {
+		panic("expected an error for a request without amount")
+	}
+
+	sender, err := wallet.GenRandomWalletForShardID(0)
+	if err != nil {
+		panic(err)
+	}
+	invalidURIs := []string{
+		"bitcoin:" + addr,
+		"incognito:",
+		"incognito:" + sender.Base58CheckSerialize(wallet.PrivateKeyType),
+		fmt.Sprintf("incognito:%v?amount=1.0000000001", addr),
+		fmt.Sprintf("incognito:%v?amount=1&amount=2", addr),
+		fmt.Sprintf("incognito:%v?amount=1&decimals=6", addr),
+		fmt.Sprintf("incognito:%v?amount=1&token=%v", addr, testTokenID),
+		fmt.Sprintf("incognito:%v?amount=1&decimals=19&token=%v", addr, testTokenID),
+		fmt.Sprintf("incognito:%v?token=abc", addr),
+		fmt.Sprintf("incognito:%v?req-expiry=1", addr),
+		fmt.Sprintf("incognito:%v?memo=%v", addr, strings.Repeat("x", incclient.MaxMemoSize+1)),
+	}
+	for _, uri := range invalidURIs {
+		if _, err = Parse(uri); err == nil {
+			panic(fmt.Sprintf("expected an error for %v", uri))
+		}
+	}
+}
+
+func TestPaymentRequest_NewTxParam(t *testing.T) {
+	sender, err := wallet.GenRandomWalletForShardID(0)
+	if err != nil {
+		panic(err)
+	}
+	privateKey := sender.Base58CheckSerialize(wallet.PrivateKeyType)
+	addr := newTestAddress()
+
+	newCoins := func(values ...uint64) ([]coin.PlainCoin, []uint64) {
+		coins := make([]coin.PlainCoin, 0)
+		indices := make([]uint64, 0)
+		for i, v := range values {
+			c, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(key.InitPaymentInfo(sender.KeySet.PaymentAddress, v, []byte{})))
+			if err != nil {
+				panic(err)
+			}
+			coins = append(coins, c)
+			indices = append(indices, uint64(i+1))
+		}
+		return coins, indices
+	}
+	prvCoins, prvIndices := newCoins(5 * incclient.DefaultPRVFee)
+	tokenCoins, tokenIndices := newCoins(5000)
+
+	client := (&incclient.IncClient{}).NewDryRunClient()
+	req, err := NewPaymentRequest(addr, "", incclient.DefaultPRVFee, 0, "INV-1")
+	if err != nil {
+		panic(err)
+	}
+	txParam, err := req.NewTxParam(privateKey)
+	if err != nil {
+		panic(err)
+	}
+	if _, _, err = client.CreateRawTransactionWithInputCoins(txParam, prvCoins, prvIndices); err != nil {
+		panic(err)
+	}
+
+	req, err = NewPaymentRequest(addr, testTokenID, 2500, 2, "")
+	if err != nil {
+		panic(err)
+	}
+	txParam, err = req.NewTxParam(privateKey)
+	if err != nil {
+		panic(err)
+	}
+	if _, _, err = client.CreateRawTokenTransactionWithInputCoins(txParam, tokenCoins, tokenIndices, prvCoins, prvIndices); err != nil {
+		panic(err)
+	}
+
+	reports := client.GetDryRunReports()
+	if len(reports) != 2 {
+		panic(fmt.Sprintf("expected 2 reports, got %v", len(reports)))
+	}
+	prvOutput, tokenOutput := reports[0].Outputs[0], reports[1].Outputs[0]
+	if prvOutput.Address != addr || prvOutput.Amount != incclient.DefaultPRVFee || prvOutput.Memo != "INV-1" {
+		panic(fmt.Sprintf("unexpected PRV output %+v", prvOutput))
+	}
+	if tokenOutput.TokenID != testTokenID || tokenOutput.Address != addr || tokenOutput.Amount != 2500 || tokenOutput.Memo != "" {
+		panic(fmt.Sprintf("unexpected token output %+v", tokenOutput))
+	}
+}
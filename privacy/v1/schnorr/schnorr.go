@@ -140,3 +140,20 @@ func (privateKey SchnorrPrivateKey) Sign(data []byte) (*SchnSignature, error) {
 
 	return signature, nil
 }
+
+// Verify checks if a signature is valid for the given data under a SchnorrPublicKey.
+func (publicKey SchnorrPublicKey) Verify(signature *SchnSignature, data []byte) bool {
+	if signature == nil || signature.e == nil || signature.z1 == nil {
+		return false
+	}
+
+	// t = z1*G + z2*H + e*PK
+	t := new(crypto.Point).ScalarMult(publicKey.publicKey, signature.e)
+	t.Add(t, new(crypto.Point).ScalarMult(publicKey.g, signature.z1))
+	if signature.z2 != nil {
+		t.Add(t, new(crypto.Point).ScalarMult(publicKey.h, signature.z2))
+	}
+
+	msg := append(t.ToBytesS(), data...)
+	return crypto.IsScalarEqual(crypto.HashToScalar(msg), signature.e)
+}
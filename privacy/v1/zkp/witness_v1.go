@@ -55,6 +55,9 @@ type PaymentWitnessParam struct {
 	CommitmentIndices       []uint64
 	MyCommitmentIndices     []uint64
 	Fee                     uint64
+
+	// NetworkParams is used to derive the shards of the output coins. If nil, the default parameters are used.
+	NetworkParams *common.NetworkParams
 }
 
 // Init creates a PaymentWitness from the given PaymentWitnessParam.
@@ -77,7 +80,7 @@ func (w *PaymentWitness) Init(PaymentWitnessParam PaymentWitnessParam) error {
 	if !PaymentWitnessParam.HasPrivacy {
 		for _, outCoin := range w.outputCoins {
 			outCoin.CoinDetails.SetRandomness(crypto.RandomScalar())
-			err := outCoin.CoinDetails.CommitAll()
+			err := outCoin.CoinDetails.CommitAllWithParams(PaymentWitnessParam.NetworkParams)
 			if err != nil {
 				return err
 			}
@@ -104,7 +107,7 @@ func (w *PaymentWitness) Init(PaymentWitnessParam PaymentWitnessParam) error {
 	w.comInputSecretKey = new(crypto.Point).Set(cmInputSK)
 
 	randInputShardID := FixedRandomnessShardID
-	senderShardID := common.GetShardIDFromLastByte(PaymentWitnessParam.PublicKeyLastByteSender)
+	senderShardID := PaymentWitnessParam.NetworkParams.GetShardIDFromLastByte(PaymentWitnessParam.PublicKeyLastByteSender)
 	w.comInputShardID = crypto.PedCom.CommitAtIndex(new(crypto.Scalar).FromUint64(uint64(senderShardID)), randInputShardID, crypto.PedersenShardIDIndex)
 
 	w.comInputValue = make([]*crypto.Point, numInputCoin)
@@ -217,7 +220,7 @@ func (w *PaymentWitness) Init(PaymentWitnessParam PaymentWitnessParam) error {
 		cmOutputValue[i] = crypto.PedCom.CommitAtIndex(new(crypto.Scalar).FromUint64(outputCoin.CoinDetails.GetValue()), randOutputValue[i], crypto.PedersenValueIndex)
 		cmOutputSND[i] = crypto.PedCom.CommitAtIndex(outputCoin.CoinDetails.GetSNDerivator(), randOutputSND[i], crypto.PedersenSndIndex)
 
-		receiverShardID, err := outputCoins[i].GetShardID()
+		receiverShardID, err := outputCoins[i].GetShardIDWithParams(PaymentWitnessParam.NetworkParams)
 		if err != nil {
 			return fmt.Errorf("cannot parse shardID of outputCoins")
 		}
@@ -19,6 +19,10 @@ type ProofV2 struct {
 	rangeProof  *bulletproofs.RangeProof
 	inputCoins  []coin.PlainCoin
 	outputCoins []*coin.CoinV2
+
+	// the shared randoms of the output coins, only known to the creator of the proof (they are never serialized)
+	outputSharedRandoms        []*crypto.Scalar
+	outputSharedConcealRandoms []*crypto.Scalar
 }
 
 // GetVersion returns the version of a ProofV2.
@@ -37,6 +41,13 @@ func (proof ProofV2) GetOutputCoins() []coin.Coin {
 	return res
 }
 
+// GetOutputSharedRandoms returns the shared OTA randoms and the shared conceal randoms used to create the output coins
+// of a ProofV2. They are only available to the creator of the proof (i.e, the sender of the transaction), and are nil
+// for a burnt output coin.
+func (proof ProofV2) GetOutputSharedRandoms() ([]*crypto.Scalar, []*crypto.Scalar) {
+	return proof.outputSharedRandoms, proof.outputSharedConcealRandoms
+}
+
 // GetRangeProof returns the range proof of a ProofV2.
 func (proof ProofV2) GetRangeProof() range_proof.RangeProof {
 	return proof.rangeProof
@@ -399,8 +410,13 @@ func Prove(inputCoins []coin.PlainCoin, outputCoins []*coin.CoinV2, sharedSecret
 	}
 
 	// After Prove, we should hide all information in coin details.
+	proof.outputSharedRandoms = make([]*crypto.Scalar, len(proof.outputCoins))
+	proof.outputSharedConcealRandoms = make([]*crypto.Scalar, len(proof.outputCoins))
 	for i, outputCoin := range proof.outputCoins {
 		if !wallet.IsPublicKeyBurningAddress(outputCoin.GetPublicKey().ToBytesS()) {
+			proof.outputSharedRandoms[i] = outputCoin.GetSharedRandom()
+			proof.outputSharedConcealRandoms[i] = outputCoin.GetSharedConcealRandom()
+
 			if err = outputCoin.ConcealOutputCoin(paymentInfo[i].PaymentAddress.GetPublicView()); err != nil {
 				return nil, err
 			}
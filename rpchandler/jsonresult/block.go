@@ -0,0 +1,72 @@
+package jsonresult
+
+// ShardBlock describes the detail of a shard block.
+type ShardBlock struct {
+	Hash               string     `json:"Hash"`
+	ShardID            byte       `json:"ShardID"`
+	Height             uint64     `json:"Height"`
+	Confirmations      int64      `json:"Confirmations"`
+	Version            int        `json:"Version"`
+	TxRoot             string     `json:"TxRoot"`
+	Time               int64      `json:"Time"`
+	PreviousBlockHash  string     `json:"PreviousBlockHash"`
+	NextBlockHash      string     `json:"NextBlockHash"`
+	TxHashes           []string   `json:"TxHashes"`
+	Txs                []BlockTx  `json:"Txs"`
+	BlockProducer      string     `json:"BlockProducer"`
+	ValidationData     string     `json:"ValidationData"`
+	ConsensusType      string     `json:"ConsensusType"`
+	Data               string     `json:"Data"`
+	BeaconHeight       uint64     `json:"BeaconHeight"`
+	BeaconBlockHash    string     `json:"BeaconBlockHash"`
+	Round              int        `json:"Round"`
+	Epoch              uint64     `json:"Epoch"`
+	Reward             uint64     `json:"Reward"`
+	RewardBeacon       uint64     `json:"RewardBeacon"`
+	Fee                uint64     `json:"Fee"`
+	Size               uint64     `json:"Size"`
+	CommitteeFromBlock string     `json:"CommitteeFromBlock"`
+	Instruction        [][]string `json:"Instruction"`
+	CrossShardBitMap   []int      `json:"CrossShardBitMap"`
+	ProposeTime        int64      `json:"ProposeTime"`
+	ProposerTime       int64      `json:"ProposerTime"`
+}
+
+// BlockTx describes a transaction included in a shard block.
+// HexData is the hex-encoding of the JSON representation of the transaction.
+type BlockTx struct {
+	Hash     string `json:"Hash"`
+	Locktime int64  `json:"Locktime"`
+	HexData  string `json:"HexData"`
+}
+
+// BeaconBlock describes the detail of a beacon block.
+type BeaconBlock struct {
+	Hash              string                      `json:"Hash"`
+	Height            uint64                      `json:"Height"`
+	BlockProducer     string                      `json:"BlockProducer"`
+	ValidationData    string                      `json:"ValidationData"`
+	ConsensusType     string                      `json:"ConsensusType"`
+	Version           int                         `json:"Version"`
+	Epoch             uint64                      `json:"Epoch"`
+	Round             int                         `json:"Round"`
+	Time              int64                       `json:"Time"`
+	PreviousBlockHash string                      `json:"PreviousBlockHash"`
+	NextBlockHash     string                      `json:"NextBlockHash"`
+	Instructions      [][]string                  `json:"Instructions"`
+	Size              uint64                      `json:"Size"`
+	ShardStates       map[byte][]BeaconShardState `json:"ShardStates"`
+	ProposeTime       int64                       `json:"ProposeTime"`
+	ProposerTime      int64                       `json:"ProposerTime"`
+}
+
+// BeaconShardState describes a shard block confirmed by a beacon block.
+type BeaconShardState struct {
+	ValidationData     string `json:"ValidationData"`
+	CommitteeFromBlock string `json:"CommitteeFromBlock"`
+	Height             uint64 `json:"Height"`
+	Hash               string `json:"Hash"`
+	CrossShard         []byte `json:"CrossShard"`
+	ProposerTime       int64  `json:"ProposerTime"`
+	Version            int    `json:"Version"`
+}
@@ -0,0 +1,36 @@
+package jsonresult
+
+// PortalExternalFeeInfo describes the network fee of a batch of un-shielding requests at a beacon height.
+type PortalExternalFeeInfo struct {
+	NetworkFee    uint
+	RBFReqIncTxID string
+}
+
+// PortalUTXO describes an external UTXO managed by the v4 Portal.
+type PortalUTXO struct {
+	WalletAddress string
+	TxHash        string
+	OutputIdx     uint32
+	OutputAmount  uint64
+	ChainCodeStr  string
+}
+
+// PortalProcessedUnshieldBatch describes a batch of un-shielding requests which have been processed but not completed.
+type PortalProcessedUnshieldBatch struct {
+	UnshieldRequests []string
+	UTXOs            []*PortalUTXO
+	ExternalFees     map[uint64]PortalExternalFeeInfo
+}
+
+// PortalV4State is a simplified version of the state of the v4 Portal.
+type PortalV4State struct {
+	// ProcessedUnshieldRequests maps a tokenID to its processed batches (indexed by their batchIDs).
+	ProcessedUnshieldRequests map[string]map[string]*PortalProcessedUnshieldBatch
+}
+
+// PortalSignedRawTransaction describes a signed external transaction generated by the v4 Portal.
+type PortalSignedRawTransaction struct {
+	SignedTx     string
+	BeaconHeight uint64
+	TxID         string
+}
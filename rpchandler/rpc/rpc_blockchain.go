@@ -24,6 +24,32 @@ func (server *RPCServer) RetrieveBlock(blockHash string, verbosity string) ([]by
 	return server.SendQuery(retrieveBlock, params)
 }
 
+// RetrieveBlockByHeight returns the details of the shard blocks at the given height.
+func (server *RPCServer) RetrieveBlockByHeight(blockHeight uint64, shardID byte, verbosity string) ([]byte, error) {
+	params := make([]interface{}, 0)
+	params = append(params, blockHeight)
+	params = append(params, shardID)
+	params = append(params, verbosity)
+
+	return server.SendQuery(retrieveBlockByHeight, params)
+}
+
+// RetrieveBeaconBlock returns the detail of a beacon block given its hash.
+func (server *RPCServer) RetrieveBeaconBlock(blockHash string) ([]byte, error) {
+	params := make([]interface{}, 0)
+	params = append(params, blockHash)
+
+	return server.SendQuery(retrieveBeaconBlock, params)
+}
+
+// RetrieveBeaconBlockByHeight returns the details of the beacon blocks at the given height.
+func (server *RPCServer) RetrieveBeaconBlockByHeight(blockHeight uint64) ([]byte, error) {
+	params := make([]interface{}, 0)
+	params = append(params, blockHeight)
+
+	return server.SendQuery(retrieveBeaconBlockByHeight, params)
+}
+
 // GetShardBestState returns the best state of a shard chain.
 func (server *RPCServer) GetShardBestState(shardID byte) ([]byte, error) {
 	params := make([]interface{}, 0)
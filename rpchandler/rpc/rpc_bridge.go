@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
 )
@@ -13,7 +14,28 @@ const (
 	FTMNetworkID
 )
 
-// EVMIssuingMetadata keeps track of EVM issuing metadata types based on the EVM networkIDs.
+// evmIssuingMetadata keeps track of EVM issuing metadata types based on the EVM networkIDs.
+// It must be accessed via GetEVMIssuingMetadataType and RegisterEVMNetwork.
+var evmIssuingMetadata = map[int]int{
+	ETHNetworkID: metadata.IssuingETHRequestMeta,
+	BSCNetworkID: metadata.IssuingBSCRequestMeta,
+	PLGNetworkID: metadata.IssuingPLGRequestMeta,
+	FTMNetworkID: metadata.IssuingFantomRequestMeta,
+}
+
+// evmBurningMetadata keeps track of EVM burning metadata types based on the EVM networkIDs.
+// It must be accessed via GetEVMBurningMetadataType and RegisterEVMNetwork.
+var evmBurningMetadata = map[int]int{
+	ETHNetworkID: metadata.BurningRequestMetaV2,
+	BSCNetworkID: metadata.BurningPBSCRequestMeta,
+	PLGNetworkID: metadata.BurningPLGRequestMeta,
+	FTMNetworkID: metadata.BurningFantomRequestMeta,
+}
+
+// EVMIssuingMetadata keeps track of EVM issuing metadata types of the built-in EVM networkIDs.
+//
+// Deprecated: networks added by RegisterEVMNetwork are not reflected in this map, and changes to it have no effect.
+// Use GetEVMIssuingMetadataType instead.
 var EVMIssuingMetadata = map[int]int{
 	ETHNetworkID: metadata.IssuingETHRequestMeta,
 	BSCNetworkID: metadata.IssuingBSCRequestMeta,
@@ -21,7 +43,10 @@ var EVMIssuingMetadata = map[int]int{
 	FTMNetworkID: metadata.IssuingFantomRequestMeta,
 }
 
-// EVMBurningMetadata keeps track of EVM burning metadata types based on the EVM networkIDs.
+// EVMBurningMetadata keeps track of EVM burning metadata types of the built-in EVM networkIDs.
+//
+// Deprecated: networks added by RegisterEVMNetwork are not reflected in this map, and changes to it have no effect.
+// Use GetEVMBurningMetadataType instead.
 var EVMBurningMetadata = map[int]int{
 	ETHNetworkID: metadata.BurningRequestMetaV2,
 	BSCNetworkID: metadata.BurningPBSCRequestMeta,
@@ -36,6 +61,89 @@ var burnProofRPCMethod = map[int]string{
 	FTMNetworkID: getFTMBurnProof,
 }
 
+// EVMNetworkInfo describes the Incognito-side parameters of an EVM-compatible network.
+type EVMNetworkInfo struct {
+	// Name is a human-readable name of the network (e.g. Avalanche).
+	Name string
+
+	// IssuingMetadataType is the metadata type of shielding requests from the network.
+	IssuingMetadataType int
+
+	// IssuingResponseMetadataType is the metadata type of responses to shielding requests (optional).
+	IssuingResponseMetadataType int
+
+	// BurningMetadataType is the metadata type of un-shielding (burning) requests to the network.
+	BurningMetadataType int
+
+	// BurningForDepositToSCMetadataType is the metadata type of burning requests for depositing to smart contracts (optional).
+	BurningForDepositToSCMetadataType int
+
+	// BurnProofRPCMethod is the RPC method of the full-node for retrieving burning proofs.
+	BurnProofRPCMethod string
+}
+
+// evmNetworkMtx guards evmIssuingMetadata, evmBurningMetadata and burnProofRPCMethod against runtime registrations.
+var evmNetworkMtx sync.RWMutex
+
+// RegisterEVMNetwork registers a new EVM-compatible network with the given evmNetworkID. After that, the network can be
+// used as the evmNetworkID of bridge-related functions. The metadata types of the network are also registered so that
+// they can be decoded by metadata.ParseMetadata.
+func RegisterEVMNetwork(evmNetworkID int, info EVMNetworkInfo) error {
+	if info.IssuingMetadataType == 0 || info.BurningMetadataType == 0 {
+		return fmt.Errorf("issuing and burning metadata types must be specified")
+	}
+	if info.BurnProofRPCMethod == "" {
+		return fmt.Errorf("burn-proof RPC method must be specified")
+	}
+
+	evmNetworkMtx.Lock()
+	defer evmNetworkMtx.Unlock()
+	if _, ok := burnProofRPCMethod[evmNetworkID]; ok {
+		return fmt.Errorf("EVMNetworkID %v already registered", evmNetworkID)
+	}
+
+	evmIssuingMetadata[evmNetworkID] = info.IssuingMetadataType
+	evmBurningMetadata[evmNetworkID] = info.BurningMetadataType
+	burnProofRPCMethod[evmNetworkID] = info.BurnProofRPCMethod
+	metadata.RegisterEVMMetadataTypes(info.IssuingMetadataType, info.IssuingResponseMetadataType,
+		info.BurningMetadataType, info.BurningForDepositToSCMetadataType)
+
+	return nil
+}
+
+// IsEVMNetworkSupported checks if the given evmNetworkID is supported (either built-in or registered).
+func IsEVMNetworkSupported(evmNetworkID int) bool {
+	evmNetworkMtx.RLock()
+	defer evmNetworkMtx.RUnlock()
+
+	_, ok := burnProofRPCMethod[evmNetworkID]
+	return ok
+}
+
+// GetEVMIssuingMetadataType returns the shielding metadata type of the given evmNetworkID.
+func GetEVMIssuingMetadataType(evmNetworkID int) (int, error) {
+	evmNetworkMtx.RLock()
+	defer evmNetworkMtx.RUnlock()
+
+	mdType, ok := evmIssuingMetadata[evmNetworkID]
+	if !ok {
+		return 0, EVMNetworkNotFoundError(evmNetworkID)
+	}
+	return mdType, nil
+}
+
+// GetEVMBurningMetadataType returns the burning metadata type of the given evmNetworkID.
+func GetEVMBurningMetadataType(evmNetworkID int) (int, error) {
+	evmNetworkMtx.RLock()
+	defer evmNetworkMtx.RUnlock()
+
+	mdType, ok := evmBurningMetadata[evmNetworkID]
+	if !ok {
+		return 0, EVMNetworkNotFoundError(evmNetworkID)
+	}
+	return mdType, nil
+}
+
 // EVMNetworkNotFoundError returns an error indicating that the given EVM networkID is not supported.
 func EVMNetworkNotFoundError(evmNetworkID int) error {
 	return fmt.Errorf("EVMNetworkID %v not supported", evmNetworkID)
@@ -47,6 +155,7 @@ func EVMNetworkNotFoundError(evmNetworkID int) error {
 //   - BSCNetworkID: the Binance Smart Chain network
 //   - PLGNetworkID: the Polygon network
 //   - FTMNetworkID: the Fantom network
+//   - any network registered via RegisterEVMNetwork
 //
 // If set empty, evmNetworkID defaults to ETHNetworkID. NOTE that only the first value of evmNetworkID is used.
 func (server *RPCServer) GetBurnProof(txHash string, evmNetworkID ...int) ([]byte, error) {
@@ -55,10 +164,12 @@ func (server *RPCServer) GetBurnProof(txHash string, evmNetworkID ...int) ([]byt
 		networkID = evmNetworkID[0]
 	}
 
-	if _, ok := burnProofRPCMethod[networkID]; !ok {
+	evmNetworkMtx.RLock()
+	method, ok := burnProofRPCMethod[networkID]
+	evmNetworkMtx.RUnlock()
+	if !ok {
 		return nil, EVMNetworkNotFoundError(networkID)
 	}
-	method := burnProofRPCMethod[networkID]
 	params := make([]interface{}, 0)
 	params = append(params, txHash)
 	return server.SendQuery(method, params)
@@ -30,3 +30,53 @@ func (server *RPCServer) GetPortalUnShieldingRequestStatus(unShieldID string) ([
 	params = append(params, mapParams)
 	return server.SendQuery(method, params)
 }
+
+// GetPortalV4State retrieves the state of the v4 Portal at the given beacon height.
+func (server *RPCServer) GetPortalV4State(beaconHeight uint64) ([]byte, error) {
+	method := getPortalV4State
+	params := make([]interface{}, 0)
+	mapParams := make(map[string]interface{})
+	mapParams["BeaconHeight"] = beaconHeight
+	params = append(params, mapParams)
+	return server.SendQuery(method, params)
+}
+
+// GetPortalBatchUnShieldingRequestStatus retrieves the status of a batch of portal un-shielding requests.
+func (server *RPCServer) GetPortalBatchUnShieldingRequestStatus(batchID string) ([]byte, error) {
+	method := getPortalBatchUnShieldingRequestStatus
+	params := make([]interface{}, 0)
+	mapParams := make(map[string]interface{})
+	mapParams["BatchID"] = batchID
+	params = append(params, mapParams)
+	return server.SendQuery(method, params)
+}
+
+// GetPortalSignedRawTransaction retrieves the signed raw external transaction of a batch of portal un-shielding requests.
+func (server *RPCServer) GetPortalSignedRawTransaction(batchID string) ([]byte, error) {
+	method := getSignedRawTransactionByBatchID
+	params := make([]interface{}, 0)
+	mapParams := make(map[string]interface{})
+	mapParams["BatchID"] = batchID
+	params = append(params, mapParams)
+	return server.SendQuery(method, params)
+}
+
+// GetPortalReplacementFeeStatus retrieves the status of a portal replace-by-fee request.
+func (server *RPCServer) GetPortalReplacementFeeStatus(txID string) ([]byte, error) {
+	method := getPortalReplacementFeeStatus
+	params := make([]interface{}, 0)
+	mapParams := make(map[string]interface{})
+	mapParams["ReqTxID"] = txID
+	params = append(params, mapParams)
+	return server.SendQuery(method, params)
+}
+
+// GetPortalSignedRawReplaceFeeTransaction retrieves the signed raw external transaction of a portal replace-by-fee request.
+func (server *RPCServer) GetPortalSignedRawReplaceFeeTransaction(txID string) ([]byte, error) {
+	method := getSignedRawReplaceFeeTransaction
+	params := make([]interface{}, 0)
+	mapParams := make(map[string]interface{})
+	mapParams["TxID"] = txID
+	params = append(params, mapParams)
+	return server.SendQuery(method, params)
+}
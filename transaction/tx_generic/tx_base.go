@@ -49,6 +49,10 @@ type TxPrivacyInitParams struct {
 	MetaData    metadata.Metadata
 	Info        []byte // 512 bytes
 	KvArgs      map[string]interface{}
+
+	// NetworkParams is used to derive the shards of the sender and the output coins. If nil, the default parameters
+	// are used.
+	NetworkParams *common.NetworkParams
 }
 
 // NewTxPrivacyInitParams creates a new TxPrivacyInitParams based on the given inputs.
@@ -84,7 +88,7 @@ func (param *TxPrivacyInitParams) GetSenderShard() byte {
 	pubKey := new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromBytesS(*param.SenderSK))
 	pubKeyBytes := pubKey.ToBytesS()
 
-	return common.GetShardIDFromLastByte(pubKeyBytes[len(pubKeyBytes)-1])
+	return param.NetworkParams.GetShardIDFromLastByte(pubKeyBytes[len(pubKeyBytes)-1])
 }
 
 // GetTxInfo checks and returns valid info.
@@ -170,7 +174,7 @@ func (tx *TxBase) InitializeTxAndParams(params *TxPrivacyInitParams) error {
 	tx.Fee = params.Fee
 	tx.Type = common.TxNormalType
 	tx.Metadata = params.MetaData
-	tx.PubKeyLastByteSender = common.GetShardIDFromLastByte(senderKeySet.PaymentAddress.Pk[len(senderKeySet.PaymentAddress.Pk)-1])
+	tx.PubKeyLastByteSender = params.NetworkParams.GetShardIDFromLastByte(senderKeySet.PaymentAddress.Pk[len(senderKeySet.PaymentAddress.Pk)-1])
 
 	if tx.Version, err = GetTxVersionFromCoins(params.InputCoins); err != nil {
 		return err
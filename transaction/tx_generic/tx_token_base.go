@@ -35,6 +35,10 @@ type TxTokenParams struct {
 	ShardID         byte
 	Info            []byte
 	KvArgs          map[string]interface{}
+
+	// NetworkParams is used to derive the shards of the sender and the output coins. If nil, the default parameters
+	// are used.
+	NetworkParams *common.NetworkParams
 }
 
 // TokenParam represents the parameters of a token transaction.
@@ -38,6 +38,7 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 		params.MetaData,
 		params.Info,
 		params.KvArgs)
+	txPrivacyParams.NetworkParams = params.NetworkParams
 	txToken.Tx = new(Tx)
 	if err := txToken.Tx.Init(txPrivacyParams); err != nil {
 		return err
@@ -82,7 +83,7 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 				tempOutputCoin[0].CoinDetails.SetInfo(params.TokenParams.Receiver[0].Message)
 			}
 			tempOutputCoin[0].CoinDetails.SetSNDerivator(crypto.RandomScalar())
-			err = tempOutputCoin[0].CoinDetails.CommitAll()
+			err = tempOutputCoin[0].CoinDetails.CommitAllWithParams(params.NetworkParams)
 			if err != nil {
 				return err
 			}
@@ -97,7 +98,7 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 
 			// get last byte
 			lastBytes := params.TokenParams.Receiver[0].PaymentAddress.Pk[len(params.TokenParams.Receiver[0].PaymentAddress.Pk)-1]
-			temp.PubKeyLastByteSender = common.GetShardIDFromLastByte(lastBytes)
+			temp.PubKeyLastByteSender = params.NetworkParams.GetShardIDFromLastByte(lastBytes)
 
 			// signOnMessage Tx
 			temp.SigPubKey = params.TokenParams.Receiver[0].PaymentAddress.Pk
@@ -136,7 +137,7 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 			txToken.TxTokenData.SetMintable(params.TokenParams.Mintable)
 
 			txToken.TxTokenData.TxNormal = new(Tx)
-			err := txToken.TxTokenData.TxNormal.Init(tx_generic.NewTxPrivacyInitParams(params.SenderKey,
+			txNormalParams := tx_generic.NewTxPrivacyInitParams(params.SenderKey,
 				params.TokenParams.Receiver,
 				params.TokenParams.TokenInput,
 				params.TokenParams.Fee,
@@ -144,7 +145,9 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 				propertyID,
 				nil,
 				nil,
-				params.TokenParams.KvArgs))
+				params.TokenParams.KvArgs)
+			txNormalParams.NetworkParams = params.NetworkParams
+			err := txToken.TxTokenData.TxNormal.Init(txNormalParams)
 			if err != nil {
 				fmt.Printf("Init PRV fee transaction returns an error: %v\n", err)
 				return err
@@ -205,11 +205,12 @@ func (tx *Tx) initPaymentWitnessParam(params *tx_generic.TxPrivacyInitParams) (*
 		PrivateKey:              new(crypto.Scalar).FromBytesS(*params.SenderSK),
 		InputCoins:              params.InputCoins,
 		OutputCoins:             outputCoins,
-		PublicKeyLastByteSender: common.GetShardIDFromLastByte(tx.PubKeyLastByteSender),
+		PublicKeyLastByteSender: params.NetworkParams.GetShardIDFromLastByte(tx.PubKeyLastByteSender),
 		Commitments:             commitments,
 		CommitmentIndices:       commitmentIndices,
 		MyCommitmentIndices:     inputCoinCommitmentIndices,
 		Fee:                     params.Fee,
+		NetworkParams:           params.NetworkParams,
 	}
 	return &paymentWitnessParam, nil
 }
@@ -27,6 +27,8 @@ type TxConvertVer1ToVer2InitParams struct {
 	metaData    metadata.Metadata
 	info        []byte // 512 bytes
 	kvArgs      map[string]interface{}
+
+	networkParams *common.NetworkParams
 }
 
 // NewTxConvertVer1ToVer2InitParams creates a new TxConvertVer1ToVer2InitParams from the given parameters.
@@ -56,6 +58,12 @@ func NewTxConvertVer1ToVer2InitParams(senderSK *key.PrivateKey,
 	}
 }
 
+// SetNetworkParams sets the NetworkParams used to derive the shards of the sender and the output coins. By default,
+// the default parameters are used.
+func (params *TxConvertVer1ToVer2InitParams) SetNetworkParams(networkParams *common.NetworkParams) {
+	params.networkParams = networkParams
+}
+
 // InitConversion creates a conversion transaction that converts PRV UTXOs v1 to v2. A conversion transaction is
 // a special PRV transaction of version 2. It is non-private, meaning that all details of the transaction are publicly visible.
 // 	- InputCoins: PlainCoin V1
@@ -131,7 +139,7 @@ func initializeTxConversion(tx *Tx, params *TxConvertVer1ToVer2InitParams) error
 	tx.Version = utils.TxConversionVersion12Number
 	tx.Type = common.TxConversionType
 	tx.Metadata = params.metaData
-	tx.PubKeyLastByteSender = common.GetShardIDFromLastByte(senderKeySet.PaymentAddress.Pk[len(senderKeySet.PaymentAddress.Pk)-1])
+	tx.PubKeyLastByteSender = params.networkParams.GetShardIDFromLastByte(senderKeySet.PaymentAddress.Pk[len(senderKeySet.PaymentAddress.Pk)-1])
 
 	if tx.LockTime == 0 {
 		tx.LockTime = time.Now().Unix()
@@ -142,20 +150,20 @@ func initializeTxConversion(tx *Tx, params *TxConvertVer1ToVer2InitParams) error
 	return nil
 }
 
-func createOutputCoins(paymentInfos []*key.PaymentInfo, tokenID *common.Hash) ([]*coin.CoinV2, error) {
+func createOutputCoins(paymentInfos []*key.PaymentInfo, tokenID *common.Hash, networkParams *common.NetworkParams) ([]*coin.CoinV2, error) {
 	var err error
 	isPRV := (tokenID == nil) || (*tokenID == common.PRVCoinID)
 	c := make([]*coin.CoinV2, len(paymentInfos))
 
 	for i := 0; i < len(paymentInfos); i += 1 {
 		if isPRV {
-			c[i], err = coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(paymentInfos[i]))
+			c[i], err = coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParamsWithNetwork(networkParams, paymentInfos[i]))
 			if err != nil {
 				log.Printf("TxConversion cannot create new coin unique OTA, got error %v\n", err)
 				return nil, err
 			}
 		} else {
-			createdCACoin, _, err := createUniqueOTACoinCA(coin.NewTransferCoinParams(paymentInfos[i]), tokenID)
+			createdCACoin, _, err := createUniqueOTACoinCA(coin.NewTransferCoinParamsWithNetwork(networkParams, paymentInfos[i]), tokenID)
 			if err != nil {
 				log.Printf("TxConversion cannot create new CA coin - %v\n", err)
 				return nil, err
@@ -172,7 +180,7 @@ func createOutputCoins(paymentInfos []*key.PaymentInfo, tokenID *common.Hash) ([
 
 func proveConversion(tx *Tx, params *TxConvertVer1ToVer2InitParams) error {
 	inputCoins := params.inputCoins
-	outputCoins, err := createOutputCoins(params.paymentInfo, params.tokenID)
+	outputCoins, err := createOutputCoins(params.paymentInfo, params.tokenID, params.networkParams)
 	if err != nil {
 		log.Printf("TxConversion cannot get output coins from payment info got error %v\n", err)
 		return err
@@ -216,6 +224,8 @@ type TxTokenConvertVer1ToVer2InitParams struct {
 	metaData    metadata.Metadata
 	info        []byte // 512 bytes
 	kvArgs      map[string]interface{}
+
+	networkParams *common.NetworkParams
 }
 
 // NewTxTokenConvertVer1ToVer2InitParams creates a new TxTokenConvertVer1ToVer2InitParams from the given parameters.
@@ -251,6 +261,12 @@ func NewTxTokenConvertVer1ToVer2InitParams(senderSK *key.PrivateKey,
 	}
 }
 
+// SetNetworkParams sets the NetworkParams used to derive the shards of the sender and the output coins. By default,
+// the default parameters are used.
+func (params *TxTokenConvertVer1ToVer2InitParams) SetNetworkParams(networkParams *common.NetworkParams) {
+	params.networkParams = networkParams
+}
+
 // InitTokenConversion creates a token conversion transaction that converts token UTXOs v1 to v2. A token conversion
 // transaction is a special token transaction of version 2. It pays the transaction fee in PRV and it is required that
 // the account has enough PRV v2 to pay the fee. This transaction is non-private, meaning that all details of the
@@ -268,6 +284,7 @@ func InitTokenConversion(txToken *TxToken, params *TxTokenConvertVer1ToVer2InitP
 	txPrivacyParams := tx_generic.NewTxPrivacyInitParams(
 		params.senderSK, params.feePayments, params.feeInputs, params.fee,
 		false, nil, params.metaData, params.info, params.kvArgs)
+	txPrivacyParams.NetworkParams = params.networkParams
 	if err := tx_generic.ValidateTxParams(txPrivacyParams); err != nil {
 		return err
 	}
@@ -326,6 +343,7 @@ func (txToken *TxToken) initTokenConversion(txNormal *Tx, params *TxTokenConvert
 		nil,
 		params.info,
 		params.kvArgs)
+	txConvertParams.SetNetworkParams(params.networkParams)
 
 	if err := validateTxConvertVer1ToVer2Params(txConvertParams); err != nil {
 		return utils.NewTransactionErr(utils.PrivacyTokenInitTokenDataError, err)
@@ -392,6 +392,7 @@ func (txToken *TxToken) Init(txTokenParams interface{}) error {
 		params.MetaData,
 		params.Info,
 		params.KvArgs)
+	txPrivacyParams.NetworkParams = params.NetworkParams
 	if err := tx_generic.ValidateTxParams(txPrivacyParams); err != nil {
 		return err
 	}
@@ -559,6 +560,7 @@ func (txToken *TxToken) initToken(txNormal *Tx, params *tx_generic.TxTokenParams
 				nil,
 				nil,
 				params.TokenParams.KvArgs)
+			txParams.NetworkParams = params.NetworkParams
 			isBurning, err := txNormal.proveToken(txParams)
 			if err != nil {
 				return utils.NewTransactionErr(utils.PrivacyTokenInitTokenDataError, err)
@@ -589,7 +591,7 @@ func (tx *Tx) provePRV(params *tx_generic.TxPrivacyInitParams) ([]coin.PlainCoin
 	outputCoins := make([]*coin.CoinV2, 0)
 	for _, paymentInfo := range params.PaymentInfo {
 		// We do not mind duplicated OTAs, server will handle them.
-		outputCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(paymentInfo, params.GetSenderShard()))
+		outputCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParamsWithNetwork(params.NetworkParams, paymentInfo, params.GetSenderShard()))
 		if err != nil {
 			log.Printf("Cannot parse outputCoinV2 to outputCoins, error %v\n", err)
 			return nil, nil, err
@@ -640,7 +642,7 @@ func makeTxToken(txPRV *Tx, pubKey, sig []byte, proof privacy.Proof) *Tx {
 			Type:                 txPRV.Type,
 			LockTime:             txPRV.LockTime,
 			Fee:                  0,
-			PubKeyLastByteSender: common.GetShardIDFromLastByte(txPRV.PubKeyLastByteSender),
+			PubKeyLastByteSender: txPRV.PubKeyLastByteSender, // already a shardID w.r.t the network params of txPRV
 			Metadata:             nil,
 		},
 	}
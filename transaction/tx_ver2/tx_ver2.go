@@ -239,7 +239,8 @@ func (tx *Tx) Init(txParams interface{}) error {
 	return nil
 }
 
-// InitTxSalary creates a PRV salary transaction to an OTA address.
+// InitTxSalary creates a PRV salary transaction to an OTA address. The shard of the sender is derived w.r.t the default
+// number of shards; hence, it only supports networks with the default parameters.
 func (tx *Tx) InitTxSalary(otaCoin *coin.CoinV2, privateKey *key.PrivateKey, metaData metadata.Metadata) error {
 	tokenID := &common.Hash{}
 	if err := tokenID.SetBytes(common.PRVCoinID[:]); err != nil {
@@ -331,7 +332,7 @@ func (tx *Tx) prove(params *tx_generic.TxPrivacyInitParams) error {
 	var err error
 	outputCoins := make([]*coin.CoinV2, 0)
 	for _, paymentInfo := range params.PaymentInfo {
-		outputCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(paymentInfo, params.GetSenderShard())) //We do not mind duplicated OTAs, server will handle them.
+		outputCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParamsWithNetwork(params.NetworkParams, paymentInfo, params.GetSenderShard())) //We do not mind duplicated OTAs, server will handle them.
 		if err != nil {
 			return err
 		}
@@ -222,7 +222,7 @@ func (tx *Tx) proveCA(params *tx_generic.TxPrivacyInitParams) (bool, error) {
 	var numOfCoinsBurned uint = 0
 	var isBurning = false
 	for _, info := range params.PaymentInfo {
-		c, ss, err := createUniqueOTACoinCA(coin.NewTransferCoinParams(info, params.GetSenderShard()), params.TokenID)
+		c, ss, err := createUniqueOTACoinCA(coin.NewTransferCoinParamsWithNetwork(params.NetworkParams, info, params.GetSenderShard()), params.TokenID)
 		if err != nil {
 			log.Printf("Cannot parse outputCoinV2 to outputCoins, error %v\n", err)
 			return false, err
@@ -266,7 +266,7 @@ func (tx *Tx) signCA(inp []coin.PlainCoin, out []*coin.CoinV2, outputSharedSecre
 		return piErr
 	}
 	var pi = int(piBig.Int64())
-	shardID := common.GetShardIDFromLastByte(tx.PubKeyLastByteSender)
+	shardID := params.NetworkParams.GetShardIDFromLastByte(tx.PubKeyLastByteSender)
 	ring, indexes, commitmentsToZero, err := generateMlsagRingWithIndexesCA(inp, out, params, pi, shardID, ringSize)
 	if err != nil {
 		log.Printf("generateMLSAGRingWithIndexes got error %v ", err)
@@ -82,6 +82,14 @@ func NewMasterKeyFromMnemonic(mnemonic string) (*KeyWallet, error) {
 // DeriveChild returns the i-th child of wallet w following the BIP-44 standard.
 // Call this function with increasing i to create as many wallets as you want.
 func (w *KeyWallet) DeriveChild(i uint32) (*KeyWallet, error) {
+	return w.DeriveAccountChild(0, i)
+}
+
+// DeriveAccountChild returns the i-th child of the given BIP-44 account of wallet w, i.e, m/44'/587'/account'/0/i.
+func (w *KeyWallet) DeriveAccountChild(account, i uint32) (*KeyWallet, error) {
+	if account >= HardenedKeyZeroIndex {
+		return nil, fmt.Errorf("account index %v out of range", account)
+	}
 	if w.HDKey == nil {
 		return nil, fmt.Errorf("cannot dereive child key: HDKey not found")
 	}
@@ -96,7 +104,7 @@ func (w *KeyWallet) DeriveChild(i uint32) (*KeyWallet, error) {
 		return nil, err
 	}
 
-	accountKey, err := coinTypeKey.Child(HardenedKeyZeroIndex)
+	accountKey, err := coinTypeKey.Child(HardenedKeyZeroIndex + account)
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +194,13 @@ func (w *KeyWallet) Serialize(keyType byte, isNewCheckSum bool) ([]byte, error)
 // in the standard Incognito base58 encoding.
 // It returns the encoding string of the key.
 func (w *KeyWallet) Base58CheckSerialize(keyType byte) string {
-	isNewEncoding := common.AddressVersion == 1
+	return w.Base58CheckSerializeWithParams(keyType, nil)
+}
+
+// Base58CheckSerializeWithParams is the same as Base58CheckSerialize, but uses the address version of the given
+// NetworkParams instead of the default one.
+func (w *KeyWallet) Base58CheckSerializeWithParams(keyType byte, params *common.NetworkParams) string {
+	isNewEncoding := params.GetAddressVersion() == 1
 	serializedKey, err := w.Serialize(keyType, isNewEncoding) //Must use the new checksum from now on
 	if err != nil {
 		return ""
@@ -355,3 +369,55 @@ func (w *KeyWallet) GetOTAPrivateKey() (string, error) {
 	readonlyKey = w.Base58CheckSerialize(OTAKeyType)
 	return readonlyKey, nil
 }
+
+// ShardChild is a child KeyWallet whose public key belongs to a specific shard, together with the BIP-44 indices
+// needed to re-derive it from the master wallet.
+type ShardChild struct {
+	AccountIndex uint32
+	ChildIndex   uint32
+	ShardID      byte
+	KeyWallet    *KeyWallet
+}
+
+// DeriveChildrenForShard returns the first numChildren children (starting from fromIdx) of wallet w whose public keys
+// belong to the given shard, w.r.t the default number of shards. See DeriveAccountChildrenForShard.
+func (w *KeyWallet) DeriveChildrenForShard(shardID byte, fromIdx uint32, numChildren int) ([]*ShardChild, error) {
+	return w.DeriveAccountChildrenForShard(nil, 0, shardID, fromIdx, numChildren)
+}
+
+// DeriveAccountChildrenForShard iterates the children of the given BIP-44 account of wallet w, starting from index
+// fromIdx, and returns the first numChildren children whose public keys belong to the given shard of the network
+// described by params (or the default one if params is nil). Unlike GenRandomWalletForShardID, the result is
+// deterministic and can be recovered from the mnemonic with DeriveAccountChild.
+func (w *KeyWallet) DeriveAccountChildrenForShard(params *common.NetworkParams, account uint32, shardID byte, fromIdx uint32, numChildren int) ([]*ShardChild, error) {
+	if !params.IsValidShardID(int(shardID)) {
+		return nil, fmt.Errorf("invalid shardID %v", shardID)
+	}
+	if numChildren <= 0 {
+		return nil, fmt.Errorf("numChildren must be positive")
+	}
+
+	res := make([]*ShardChild, 0)
+	for i := fromIdx; i < HardenedKeyZeroIndex; i++ {
+		childWallet, err := w.DeriveAccountChild(account, i)
+		if err != nil {
+			return nil, fmt.Errorf("childIdx %v error: %v", i, err)
+		}
+
+		pk := childWallet.KeySet.PaymentAddress.Pk
+		if params.GetShardIDFromLastByte(pk[len(pk)-1]) != shardID {
+			continue
+		}
+		res = append(res, &ShardChild{
+			AccountIndex: account,
+			ChildIndex:   i,
+			ShardID:      shardID,
+			KeyWallet:    childWallet,
+		})
+		if len(res) == numChildren {
+			return res, nil
+		}
+	}
+
+	return nil, fmt.Errorf("only found %v children for shard %v", len(res), shardID)
+}
@@ -317,3 +317,53 @@ func TestBase58CheckDeserialize(t *testing.T) {
 
 	}
 }
+
+func TestKeyWallet_DeriveChildrenForShard(t *testing.T) {
+	masterWallet, _, err := NewMasterKey()
+	assert.Nil(t, err)
+
+	for i := 0; i < 10; i++ {
+		shardID := byte(common.RandInt() % common.MaxShardNumber)
+		fromIdx := uint32(common.RandInt() % 100)
+		numChildren := 1 + common.RandInt()%5
+
+		children, err := masterWallet.DeriveChildrenForShard(shardID, fromIdx, numChildren)
+		assert.Nil(t, err)
+		assert.Equal(t, numChildren, len(children))
+
+		prevIdx := fromIdx
+		for j, child := range children {
+			pk := child.KeyWallet.KeySet.PaymentAddress.Pk
+			assert.Equal(t, shardID, common.GetShardIDFromLastByte(pk[len(pk)-1]))
+			assert.Equal(t, shardID, child.ShardID)
+
+			// children are returned in order, and none of the skipped ones belongs to the shard
+			assert.True(t, j == 0 || child.ChildIndex > prevIdx)
+			for idx := prevIdx; idx < child.ChildIndex; idx++ {
+				if j > 0 && idx == prevIdx {
+					continue
+				}
+				tmpWallet, err := masterWallet.DeriveChild(idx)
+				assert.Nil(t, err)
+				tmpPk := tmpWallet.KeySet.PaymentAddress.Pk
+				assert.NotEqual(t, shardID, common.GetShardIDFromLastByte(tmpPk[len(tmpPk)-1]))
+			}
+			prevIdx = child.ChildIndex
+
+			// the child can be re-derived from its index
+			tmpWallet, err := masterWallet.DeriveAccountChild(child.AccountIndex, child.ChildIndex)
+			assert.Nil(t, err)
+			assert.Equal(t, tmpWallet.Base58CheckSerialize(PrivateKeyType), child.KeyWallet.Base58CheckSerialize(PrivateKeyType))
+		}
+
+		// the derivation is deterministic
+		otherChildren, err := masterWallet.DeriveChildrenForShard(shardID, fromIdx, numChildren)
+		assert.Nil(t, err)
+		for j := range children {
+			assert.Equal(t, children[j].ChildIndex, otherChildren[j].ChildIndex)
+		}
+	}
+
+	_, err = masterWallet.DeriveChildrenForShard(byte(common.MaxShardNumber), 0, 1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,617 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+// KeyStore entry types.
+const (
+	KeyStorePrivateKeyType = "privatekey"
+	KeyStoreMnemonicType   = "mnemonic"
+)
+
+const (
+	keyStoreVersion = 1
+	keyStoreCipher  = "aes-256-gcm"
+	keyStoreKDF     = "scrypt"
+	keyStoreDKLen   = 32
+	keyStoreExt     = ".json"
+
+	// StandardScryptN is the N parameter of scrypt for KeyStore entries (i.e, 256MB of memory, about 1s of CPU time).
+	StandardScryptN = 1 << 18
+
+	// StandardScryptP is the P parameter of scrypt for KeyStore entries.
+	StandardScryptP = 1
+
+	// LightScryptN is the N parameter of scrypt for KeyStore entries on resource-constrained devices (i.e, 4MB of
+	// memory, about 100ms of CPU time).
+	LightScryptN = 1 << 12
+
+	// LightScryptP is the P parameter of scrypt for KeyStore entries on resource-constrained devices.
+	LightScryptP = 6
+
+	scryptR = 8
+
+	// maxScryptN, maxScryptR, maxScryptP and maxScryptMemory bound the scrypt parameters read from a KeyStore file, so
+	// that a crafted entry cannot make Decrypt exhaust the memory or the CPU of the host.
+	maxScryptN      = 1 << 20
+	maxScryptR      = 16
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30 // 128 * N * r bytes
+)
+
+// ErrKeyStoreDecryption is returned when a KeyStore entry cannot be decrypted (e.g, the password is wrong).
+var ErrKeyStoreDecryption = fmt.Errorf("could not decrypt key with given password")
+
+// KeyStoreEntry is an encrypted private key (or mnemonic) stored in a KeyStore. Its JSON format is similar to the
+// Ethereum keystore v3:
+//
+//	{
+//		"version": 1,
+//		"id": "3198bc9c-6672-4ab3-a5a2-a6f3b6e9c1d2",
+//		"name": "my-account",
+//		"type": "privatekey",
+//		"paymentAddress": "12s...",
+//		"crypto": {
+//			"cipher": "aes-256-gcm",
+//			"ciphertext": "9c0d...",
+//			"cipherparams": {"nonce": "5e2a..."},
+//			"kdf": "scrypt",
+//			"kdfparams": {"n": 262144, "r": 8, "p": 1, "dklen": 32, "salt": "ab0c..."}
+//		}
+//	}
+//
+// The encryption key is derived from the password with scrypt. The secret (i.e, the base58-encoded private key, or the
+// mnemonic) is encrypted with AES-256-GCM, using the type and the payment address as additional data; hence, neither of
+// them can be altered without failing the decryption. The name can be changed freely.
+type KeyStoreEntry struct {
+	Version        int            `json:"version"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	PaymentAddress string         `json:"paymentAddress"`
+	Crypto         KeyStoreCrypto `json:"crypto"`
+}
+
+// KeyStoreCrypto consists of the encrypted secret of a KeyStoreEntry, and the parameters to decrypt it.
+type KeyStoreCrypto struct {
+	Cipher       string               `json:"cipher"`
+	CipherText   string               `json:"ciphertext"`
+	CipherParams KeyStoreCipherParams `json:"cipherparams"`
+	KDF          string               `json:"kdf"`
+	KDFParams    KeyStoreKDFParams    `json:"kdfparams"`
+}
+
+// KeyStoreCipherParams consists of the parameters of the AES-GCM cipher.
+type KeyStoreCipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+// KeyStoreKDFParams consists of the parameters of the scrypt key-derivation function.
+type KeyStoreKDFParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// NewKeyStoreEntry encrypts a base58-encoded private key or a BIP39 mnemonic (depending on keyType) with the given
+// password, using scrypt parameters scryptN and scryptP.
+func NewKeyStoreEntry(secret, keyType, name, password string, scryptN, scryptP int) (*KeyStoreEntry, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password must not be empty")
+	}
+	w, err := getKeyWalletFromSecret(secret, keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &KeyStoreEntry{
+		Version:        keyStoreVersion,
+		ID:             newKeyStoreID(),
+		Name:           name,
+		Type:           keyType,
+		PaymentAddress: w.Base58CheckSerialize(PaymentAddressType),
+	}
+	err = entry.encrypt([]byte(secret), password, scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Decrypt decrypts a KeyStoreEntry, and returns its secret (i.e, the base58-encoded private key, or the mnemonic).
+func (e *KeyStoreEntry) Decrypt(password string) (string, error) {
+	if e.Version != keyStoreVersion {
+		return "", fmt.Errorf("keystore version %v not supported", e.Version)
+	}
+	if e.Crypto.Cipher != keyStoreCipher {
+		return "", fmt.Errorf("cipher %v not supported", e.Crypto.Cipher)
+	}
+	if e.Crypto.KDF != keyStoreKDF {
+		return "", fmt.Errorf("KDF %v not supported", e.Crypto.KDF)
+	}
+
+	params := e.Crypto.KDFParams
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return "", fmt.Errorf("invalid salt: %v", err)
+	}
+	nonce, err := hex.DecodeString(e.Crypto.CipherParams.Nonce)
+	if err != nil {
+		return "", fmt.Errorf("invalid nonce: %v", err)
+	}
+	cipherText, err := hex.DecodeString(e.Crypto.CipherText)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %v", err)
+	}
+	if params.DKLen != keyStoreDKLen {
+		return "", fmt.Errorf("invalid dklen %v", params.DKLen)
+	}
+	err = checkScryptParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newKeyStoreAEAD(derivedKey)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("invalid nonce length %v", len(nonce))
+	}
+
+	plainText, err := aead.Open(nil, nonce, cipherText, e.additionalData())
+	if err != nil {
+		return "", ErrKeyStoreDecryption
+	}
+
+	return string(plainText), nil
+}
+
+// GetKeyWallet decrypts a KeyStoreEntry, and returns its KeyWallet. For a mnemonic, it is the master KeyWallet.
+func (e *KeyStoreEntry) GetKeyWallet(password string) (*KeyWallet, error) {
+	secret, err := e.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return getKeyWalletFromSecret(secret, e.Type)
+}
+
+func (e *KeyStoreEntry) encrypt(secret []byte, password string, scryptN, scryptP int) error {
+	err := checkScryptParams(KeyStoreKDFParams{N: scryptN, R: scryptR, P: scryptP})
+	if err != nil {
+		return err
+	}
+	salt := common.RandBytes(32)
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, keyStoreDKLen)
+	if err != nil {
+		return err
+	}
+	aead, err := newKeyStoreAEAD(derivedKey)
+	if err != nil {
+		return err
+	}
+	nonce := common.RandBytes(aead.NonceSize())
+
+	e.Crypto = KeyStoreCrypto{
+		Cipher:       keyStoreCipher,
+		CipherText:   hex.EncodeToString(aead.Seal(nil, nonce, secret, e.additionalData())),
+		CipherParams: KeyStoreCipherParams{Nonce: hex.EncodeToString(nonce)},
+		KDF:          keyStoreKDF,
+		KDFParams: KeyStoreKDFParams{
+			N:     scryptN,
+			R:     scryptR,
+			P:     scryptP,
+			DKLen: keyStoreDKLen,
+			Salt:  hex.EncodeToString(salt),
+		},
+	}
+
+	return nil
+}
+
+// checkScryptParams checks if the scrypt parameters of an entry are within the limits of the KeyStore.
+func checkScryptParams(params KeyStoreKDFParams) error {
+	if params.N <= 1 || params.N > maxScryptN || params.N&(params.N-1) != 0 {
+		return fmt.Errorf("invalid scrypt N %v", params.N)
+	}
+	if params.R <= 0 || params.R > maxScryptR {
+		return fmt.Errorf("invalid scrypt r %v", params.R)
+	}
+	if params.P <= 0 || params.P > maxScryptP {
+		return fmt.Errorf("invalid scrypt p %v", params.P)
+	}
+	if 128*uint64(params.N)*uint64(params.R) > maxScryptMemory {
+		return fmt.Errorf("scrypt parameters N = %v, r = %v require too much memory", params.N, params.R)
+	}
+
+	return nil
+}
+
+func (e *KeyStoreEntry) additionalData() []byte {
+	return []byte(e.Type + ":" + e.PaymentAddress)
+}
+
+func newKeyStoreAEAD(derivedKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// newKeyStoreID returns a random (version 4) UUID.
+func newKeyStoreID() string {
+	b := common.RandBytes(16)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// isValidKeyStoreID checks if an ID can be safely used as a file name, i.e, it only consists of hex digits and dashes.
+func isValidKeyStoreID(id string) bool {
+	if len(id) == 0 || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// getKeyWalletFromSecret returns the KeyWallet of a base58-encoded private key, or of a mnemonic.
+func getKeyWalletFromSecret(secret, keyType string) (*KeyWallet, error) {
+	switch keyType {
+	case KeyStorePrivateKeyType:
+		w, err := Base58CheckDeserialize(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %v", err)
+		}
+		if len(w.KeySet.PrivateKey) == 0 {
+			return nil, fmt.Errorf("invalid private key")
+		}
+		return w, nil
+	case KeyStoreMnemonicType:
+		return NewMasterKeyFromMnemonic(secret)
+	default:
+		return nil, fmt.Errorf("key type %v not supported", keyType)
+	}
+}
+
+// KeyStore manages a directory of encrypted keys, one KeyStoreEntry per file.
+type KeyStore struct {
+	dir     string
+	scryptN int
+	scryptP int
+	mtx     sync.Mutex
+}
+
+// NewKeyStore creates a new KeyStore in the given directory. New entries are encrypted with scrypt parameters scryptN
+// and scryptP (e.g, StandardScryptN and StandardScryptP).
+func NewKeyStore(dir string, scryptN, scryptP int) (*KeyStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("keystore directory must not be empty")
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyStore{dir: dir, scryptN: scryptN, scryptP: scryptP}, nil
+}
+
+// List returns all entries of the KeyStore, sorted by name. The secrets remain encrypted.
+func (ks *KeyStore) List() ([]*KeyStoreEntry, error) {
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+
+	files, err := ioutil.ReadDir(ks.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*KeyStoreEntry, 0)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), keyStoreExt) {
+			continue
+		}
+		entry, err := readKeyStoreEntry(filepath.Join(ks.dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %v: %v", f.Name(), err)
+		}
+		res = append(res, entry)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+// Get returns the entry with the given ID, name or payment address.
+func (ks *KeyStore) Get(identifier string) (*KeyStoreEntry, error) {
+	entries, err := ks.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var res *KeyStoreEntry
+	for _, entry := range entries {
+		if entry.ID == identifier || entry.Name == identifier || entry.PaymentAddress == identifier {
+			if res != nil {
+				return nil, fmt.Errorf("multiple entries found for %v", identifier)
+			}
+			res = entry
+		}
+	}
+	if res == nil {
+		return nil, fmt.Errorf("entry %v not found", identifier)
+	}
+
+	return res, nil
+}
+
+// ImportPrivateKey encrypts a base58-encoded private key with the given password, and adds it to the KeyStore.
+func (ks *KeyStore) ImportPrivateKey(privateKey, name, password string) (*KeyStoreEntry, error) {
+	return ks.importSecret(privateKey, KeyStorePrivateKeyType, name, password)
+}
+
+// ImportMnemonic encrypts a BIP39 mnemonic with the given password, and adds it to the KeyStore.
+func (ks *KeyStore) ImportMnemonic(mnemonic, name, password string) (*KeyStoreEntry, error) {
+	return ks.importSecret(mnemonic, KeyStoreMnemonicType, name, password)
+}
+
+// ImportEntry adds an existing KeyStoreEntry (e.g, exported from another KeyStore) to the KeyStore. The entry is
+// decrypted with the given password to make sure it is valid, but is stored as is.
+func (ks *KeyStore) ImportEntry(entryJSON []byte, password string) (*KeyStoreEntry, error) {
+	var entry KeyStoreEntry
+	err := json.Unmarshal(entryJSON, &entry)
+	if err != nil {
+		return nil, err
+	}
+	w, err := entry.GetKeyWallet(password)
+	if err != nil {
+		return nil, err
+	}
+	if w.Base58CheckSerialize(PaymentAddressType) != entry.PaymentAddress {
+		return nil, fmt.Errorf("payment address mismatch")
+	}
+	if entry.ID == "" {
+		entry.ID = newKeyStoreID()
+	} else if !isValidKeyStoreID(entry.ID) {
+		return nil, fmt.Errorf("invalid entry id %v", entry.ID)
+	}
+
+	err = ks.add(&entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// ExportEntry returns the JSON-encoded (encrypted) entry with the given identifier, which can be imported into another
+// KeyStore via ImportEntry.
+func (ks *KeyStore) ExportEntry(identifier string) ([]byte, error) {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(entry, "", "\t")
+}
+
+// ExportPrivateKey decrypts the entry with the given identifier, and returns its base58-encoded private key. For a
+// mnemonic, it is the private key of the master KeyWallet.
+func (ks *KeyStore) ExportPrivateKey(identifier, password string) (string, error) {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return "", err
+	}
+	w, err := entry.GetKeyWallet(password)
+	if err != nil {
+		return "", err
+	}
+
+	return w.Base58CheckSerialize(PrivateKeyType), nil
+}
+
+// ExportMnemonic decrypts the entry with the given identifier, and returns its mnemonic.
+func (ks *KeyStore) ExportMnemonic(identifier, password string) (string, error) {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return "", err
+	}
+	if entry.Type != KeyStoreMnemonicType {
+		return "", fmt.Errorf("entry %v is not a mnemonic", identifier)
+	}
+
+	return entry.Decrypt(password)
+}
+
+// GetKeyWallet decrypts the entry with the given identifier, and returns its KeyWallet.
+func (ks *KeyStore) GetKeyWallet(identifier, password string) (*KeyWallet, error) {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.GetKeyWallet(password)
+}
+
+// ChangePassword re-encrypts the entry with the given identifier with a new password.
+func (ks *KeyStore) ChangePassword(identifier, oldPassword, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("password must not be empty")
+	}
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return err
+	}
+	secret, err := entry.Decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+	err = entry.encrypt([]byte(secret), newPassword, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	return writeKeyStoreEntry(ks.entryFile(entry.ID), entry)
+}
+
+// Rename changes the name of the entry with the given identifier.
+func (ks *KeyStore) Rename(identifier, newName string) error {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return err
+	}
+	entry.Name = newName
+
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	return writeKeyStoreEntry(ks.entryFile(entry.ID), entry)
+}
+
+// Delete removes the entry with the given identifier from the KeyStore. The password is required to prevent deleting
+// a key by mistake.
+func (ks *KeyStore) Delete(identifier, password string) error {
+	entry, err := ks.Get(identifier)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Decrypt(password)
+	if err != nil {
+		return err
+	}
+
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	return os.Remove(ks.entryFile(entry.ID))
+}
+
+func (ks *KeyStore) importSecret(secret, keyType, name, password string) (*KeyStoreEntry, error) {
+	entry, err := NewKeyStoreEntry(secret, keyType, name, password, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ks.add(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (ks *KeyStore) add(entry *KeyStoreEntry) error {
+	entries, err := ks.List()
+	if err != nil {
+		return err
+	}
+	for _, tmpEntry := range entries {
+		if tmpEntry.ID == entry.ID {
+			return fmt.Errorf("entry %v already exists", entry.ID)
+		}
+		if tmpEntry.PaymentAddress == entry.PaymentAddress && tmpEntry.Type == entry.Type {
+			return fmt.Errorf("key of %v already exists (entry %v)", entry.PaymentAddress, tmpEntry.ID)
+		}
+		if entry.Name != "" && tmpEntry.Name == entry.Name {
+			return fmt.Errorf("name %v already exists", entry.Name)
+		}
+	}
+
+	ks.mtx.Lock()
+	defer ks.mtx.Unlock()
+	return createKeyStoreEntry(ks.entryFile(entry.ID), entry)
+}
+
+func (ks *KeyStore) entryFile(id string) string {
+	return filepath.Join(ks.dir, id+keyStoreExt)
+}
+
+func readKeyStoreEntry(fileName string) (*KeyStoreEntry, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry KeyStoreEntry
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// createKeyStoreEntry writes a new entry to the given file. The file is created with O_EXCL, so that an existing entry
+// (e.g, one added by another process since the checks of KeyStore.add) is never overwritten.
+func createKeyStoreEntry(fileName string, entry *KeyStoreEntry) error {
+	data, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("entry %v already exists", entry.ID)
+		}
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(fileName)
+		return err
+	}
+
+	return nil
+}
+
+// writeKeyStoreEntry writes an entry to a temporary file, then renames it, so that an entry is never partially written.
+func writeKeyStoreEntry(fileName string, entry *KeyStoreEntry) error {
+	data, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(fileName+".tmp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(fileName+".tmp", fileName)
+}
@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+func TestKeyStoreEntry_Decrypt(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		w, err := NewMasterKeyFromSeed(common.RandBytes(32))
+		assert.Nil(t, err)
+		privateKey := w.Base58CheckSerialize(PrivateKeyType)
+
+		entry, err := NewKeyStoreEntry(privateKey, KeyStorePrivateKeyType, "test", "password", LightScryptN, LightScryptP)
+		assert.Nil(t, err)
+		assert.Equal(t, w.Base58CheckSerialize(PaymentAddressType), entry.PaymentAddress)
+
+		// the entry survives a JSON round-trip
+		jsb, err := json.Marshal(entry)
+		assert.Nil(t, err)
+		var tmpEntry KeyStoreEntry
+		err = json.Unmarshal(jsb, &tmpEntry)
+		assert.Nil(t, err)
+
+		decrypted, err := tmpEntry.Decrypt("password")
+		assert.Nil(t, err)
+		assert.Equal(t, privateKey, decrypted)
+
+		_, err = tmpEntry.Decrypt("wrong password")
+		assert.Equal(t, ErrKeyStoreDecryption, err)
+
+		// the payment address is authenticated
+		otherWallet, err := NewMasterKeyFromSeed(common.RandBytes(32))
+		assert.Nil(t, err)
+		tmpEntry.PaymentAddress = otherWallet.Base58CheckSerialize(PaymentAddressType)
+		_, err = tmpEntry.Decrypt("password")
+		assert.Equal(t, ErrKeyStoreDecryption, err)
+	}
+
+	_, err := NewKeyStoreEntry("abc", KeyStorePrivateKeyType, "test", "password", LightScryptN, LightScryptP)
+	assert.NotNil(t, err)
+	_, err = NewKeyStoreEntry("abc", KeyStoreMnemonicType, "test", "password", LightScryptN, LightScryptP)
+	assert.NotNil(t, err)
+
+	// scrypt parameters read from a file are capped
+	w, err := NewMasterKeyFromSeed(common.RandBytes(32))
+	assert.Nil(t, err)
+	entry, err := NewKeyStoreEntry(w.Base58CheckSerialize(PrivateKeyType), KeyStorePrivateKeyType, "test", "password", LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+	for _, params := range []KeyStoreKDFParams{
+		{N: 1 << 30, R: scryptR, P: LightScryptP},
+		{N: LightScryptN + 1, R: scryptR, P: LightScryptP},
+		{N: LightScryptN, R: 1 << 20, P: LightScryptP},
+		{N: LightScryptN, R: scryptR, P: 1 << 20},
+		{N: maxScryptN, R: maxScryptR, P: LightScryptP},
+	} {
+		tmpEntry := *entry
+		params.DKLen = entry.Crypto.KDFParams.DKLen
+		params.Salt = entry.Crypto.KDFParams.Salt
+		tmpEntry.Crypto.KDFParams = params
+		_, err = tmpEntry.Decrypt("password")
+		assert.NotNil(t, err)
+		assert.NotEqual(t, ErrKeyStoreDecryption, err)
+	}
+	_, err = NewKeyStoreEntry(w.Base58CheckSerialize(PrivateKeyType), KeyStorePrivateKeyType, "test", "password", 1<<30, LightScryptP)
+	assert.NotNil(t, err)
+}
+
+func TestKeyStore_add(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ks, err := NewKeyStore(dir, LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+	w, err := NewMasterKeyFromSeed(common.RandBytes(32))
+	assert.Nil(t, err)
+	entry, err := NewKeyStoreEntry(w.Base58CheckSerialize(PrivateKeyType), KeyStorePrivateKeyType, "test", "password", LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+
+	// a file created after the checks (e.g, by another process) is never overwritten
+	err = createKeyStoreEntry(ks.entryFile(entry.ID), entry)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadFile(ks.entryFile(entry.ID))
+	assert.Nil(t, err)
+	otherEntry := *entry
+	otherEntry.Name = "other"
+	err = createKeyStoreEntry(ks.entryFile(entry.ID), &otherEntry)
+	assert.NotNil(t, err)
+	tmpData, err := ioutil.ReadFile(ks.entryFile(entry.ID))
+	assert.Nil(t, err)
+	assert.Equal(t, data, tmpData)
+}
+
+func TestKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ks, err := NewKeyStore(dir, LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+
+	w, mnemonic, err := NewMasterKey()
+	assert.Nil(t, err)
+	child, err := w.DeriveChild(1)
+	assert.Nil(t, err)
+	privateKey := child.Base58CheckSerialize(PrivateKeyType)
+
+	mnemonicEntry, err := ks.ImportMnemonic(mnemonic, "master", "password1")
+	assert.Nil(t, err)
+	privateKeyEntry, err := ks.ImportPrivateKey(privateKey, "child", "password2")
+	assert.Nil(t, err)
+
+	// duplicates are rejected
+	_, err = ks.ImportPrivateKey(privateKey, "another", "password2")
+	assert.NotNil(t, err)
+	_, err = ks.ImportMnemonic(mnemonic, "master", "password1")
+	assert.NotNil(t, err)
+
+	entries, err := ks.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "child", entries[0].Name)
+	assert.Equal(t, "master", entries[1].Name)
+
+	// the secrets are not stored in plain text
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(data), privateKey)
+		assert.NotContains(t, string(data), mnemonic)
+	}
+
+	// export
+	exportedPrivateKey, err := ks.ExportPrivateKey("child", "password2")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey, exportedPrivateKey)
+	exportedPrivateKey, err = ks.ExportPrivateKey(mnemonicEntry.ID, "password1")
+	assert.Nil(t, err)
+	assert.Equal(t, w.Base58CheckSerialize(PrivateKeyType), exportedPrivateKey)
+	exportedMnemonic, err := ks.ExportMnemonic(mnemonicEntry.PaymentAddress, "password1")
+	assert.Nil(t, err)
+	assert.Equal(t, mnemonic, exportedMnemonic)
+	_, err = ks.ExportMnemonic("child", "password2")
+	assert.NotNil(t, err)
+	_, err = ks.ExportPrivateKey("child", "password1")
+	assert.Equal(t, ErrKeyStoreDecryption, err)
+
+	// change password
+	err = ks.ChangePassword(privateKeyEntry.ID, "password1", "newPassword")
+	assert.NotNil(t, err)
+	err = ks.ChangePassword(privateKeyEntry.ID, "password2", "newPassword")
+	assert.Nil(t, err)
+	_, err = ks.ExportPrivateKey("child", "password2")
+	assert.Equal(t, ErrKeyStoreDecryption, err)
+	exportedPrivateKey, err = ks.ExportPrivateKey("child", "newPassword")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey, exportedPrivateKey)
+
+	// move an entry to another keystore
+	entryJSON, err := ks.ExportEntry("child")
+	assert.Nil(t, err)
+	otherDir, err := ioutil.TempDir("", "keystore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(otherDir)
+	otherKS, err := NewKeyStore(otherDir, LightScryptN, LightScryptP)
+	assert.Nil(t, err)
+	_, err = otherKS.ImportEntry(entryJSON, "password2")
+	assert.NotNil(t, err)
+	_, err = otherKS.ImportEntry(entryJSON, "newPassword")
+	assert.Nil(t, err)
+	kw, err := otherKS.GetKeyWallet(privateKeyEntry.ID, "newPassword")
+	assert.Nil(t, err)
+	assert.Equal(t, privateKey, kw.Base58CheckSerialize(PrivateKeyType))
+
+	// rename & delete
+	err = ks.Rename("child", "renamed")
+	assert.Nil(t, err)
+	_, err = ks.ExportPrivateKey("renamed", "newPassword")
+	assert.Nil(t, err)
+	err = ks.Delete("renamed", "wrong")
+	assert.NotNil(t, err)
+	err = ks.Delete("renamed", "newPassword")
+	assert.Nil(t, err)
+	entries, err = ks.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+}